@@ -7,12 +7,14 @@ import (
 
 	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/commands"
 	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/network"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
 	"github.com/spf13/cobra"
 )
 
 var (
 	logger  *slog.Logger
 	verbose bool
+	quiet   bool
 )
 
 var rootCmd = &cobra.Command{
@@ -30,17 +32,31 @@ Features:
   - Interactive TUI for transaction review
   - Support for EIP-1559 and legacy transactions
   - Multi-network support (Ethereum, Polygon, Arbitrum, Optimism, Base, Linea)`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if verbose && quiet {
+			return fmt.Errorf("--verbose and --quiet cannot be used together")
+		}
+
 		// Configure logging
 		var logLevel slog.Level
-		if verbose {
+		switch {
+		case verbose:
 			logLevel = slog.LevelDebug
-		} else {
+		case quiet:
+			// Errors only; result files and exit codes are unaffected
+			logLevel = slog.LevelError
+		default:
 			logLevel = slog.LevelInfo
 		}
 
+		// Keep stdout clean when it carries machine-readable output
+		logOutput := os.Stdout
+		if commands.LogsToStderr() {
+			logOutput = os.Stderr
+		}
+
 		// Create text handler with specified log level
-		handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		handler := slog.NewTextHandler(logOutput, &slog.HandlerOptions{
 			Level: logLevel,
 		})
 		logger = slog.New(handler)
@@ -48,22 +64,38 @@ Features:
 		// Set logger for subpackages
 		commands.SetLogger(logger)
 		network.SetLogger(logger)
+		return nil
 	},
 }
 
 func init() {
 	// Global flags
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Suppress all non-error output (for scripting)")
 
 	// Add subcommands
 	rootCmd.AddCommand(commands.PrepareCmd)
+	rootCmd.AddCommand(commands.ReplayCmd)
+	rootCmd.AddCommand(commands.ReconstructCmd)
 	rootCmd.AddCommand(commands.SignCmd)
 	rootCmd.AddCommand(commands.BroadcastCmd)
+	rootCmd.AddCommand(commands.StatusCmd)
+	rootCmd.AddCommand(commands.ListOperationsCmd)
+	rootCmd.AddCommand(commands.HistoryCmd)
+	rootCmd.AddCommand(commands.AddressBookCmd)
+	rootCmd.AddCommand(commands.EstimateLifecycleCmd)
+	rootCmd.AddCommand(commands.WhoamiCmd)
+	rootCmd.AddCommand(commands.ServeCmd)
+	rootCmd.AddCommand(commands.VersionCmd)
+	rootCmd.AddCommand(commands.SelfTestCmd)
 }
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if code := types.CodeOf(err); code != "" {
+			fmt.Fprintf(os.Stderr, "Error code: %s\n", code)
+		}
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/store"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+)
+
+// AddressBookCmd represents the addressbook command
+var AddressBookCmd = &cobra.Command{
+	Use:   "addressbook",
+	Short: "Manage labelled beneficiary and contract addresses",
+	Long: `Store addresses under short labels so they can be passed as @label instead
+of being typed out, e.g. prepare deposit --beneficiary @daughter.
+
+The address book is kept in ~/.cryptoheir/addressbook.json (or under
+CRYPTOHEIR_HOME). Labels are never repointed silently: remove a label before
+storing a different address under it.`,
+}
+
+var addressBookAddCmd = &cobra.Command{
+	Use:   "add <label> <address>",
+	Short: "Store an address under a label",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runAddressBookAdd,
+}
+
+var addressBookListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the stored labels and addresses",
+	Args:  cobra.NoArgs,
+	RunE:  runAddressBookList,
+}
+
+var addressBookRemoveCmd = &cobra.Command{
+	Use:   "remove <label>",
+	Short: "Remove a label",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runAddressBookRemove,
+}
+
+func init() {
+	AddressBookCmd.AddCommand(addressBookAddCmd, addressBookListCmd, addressBookRemoveCmd)
+}
+
+func runAddressBookAdd(cmd *cobra.Command, args []string) error {
+	addr, err := types.ParseAddress(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid address: %w", err)
+	}
+	book, err := store.LoadAddressBook()
+	if err != nil {
+		return err
+	}
+
+	entry, err := book.Add(args[0], addr)
+	if err != nil {
+		return err
+	}
+	for _, e := range book.Lookalikes(addr) {
+		log.Warn("⚠ Address RESEMBLES an existing entry - check every character", "label", e.Label, "existing", e.Address.Hex(), "new", addr.Hex())
+	}
+	for _, e := range book.Sorted() {
+		if e.Address == addr && e.Label != entry.Label {
+			log.Warn("Address is already stored under another label", "label", e.Label)
+		}
+	}
+
+	if err := book.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("@%s -> %s\n", entry.Label, entry.Address.Hex())
+	return nil
+}
+
+func runAddressBookList(cmd *cobra.Command, args []string) error {
+	book, err := store.LoadAddressBook()
+	if err != nil {
+		return err
+	}
+	entries := book.Sorted()
+	if len(entries) == 0 {
+		path, _ := store.AddressBookPath()
+		fmt.Printf("No address book entries found in %s\n", path)
+		return nil
+	}
+	fmt.Printf("%-33s  %-42s  %s\n", "LABEL", "ADDRESS", "ADDED")
+	for _, e := range entries {
+		fmt.Printf("%-33s  %-42s  %s\n", "@"+e.Label, e.Address.Hex(), e.Added)
+	}
+	return nil
+}
+
+func runAddressBookRemove(cmd *cobra.Command, args []string) error {
+	book, err := store.LoadAddressBook()
+	if err != nil {
+		return err
+	}
+	if !book.Remove(args[0]) {
+		return fmt.Errorf("label %s is not in the address book", args[0])
+	}
+	if err := book.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("Removed %s\n", args[0])
+	return nil
+}
+
+// isAddressBookLabel reports whether an address argument is an @label
+func isAddressBookLabel(s string) bool {
+	return strings.HasPrefix(strings.TrimSpace(s), "@")
+}
+
+// resolveAddressBook looks up an @label in the address book. Returns the
+// normalized label.
+func resolveAddressBook(s string) (common.Address, string, error) {
+	book, err := store.LoadAddressBook()
+	if err != nil {
+		return common.Address{}, "", err
+	}
+	entry, ok := book.Lookup(s)
+	if !ok {
+		return common.Address{}, "", fmt.Errorf("%s is not in the address book (see 'cryptoheir addressbook list')", strings.TrimSpace(s))
+	}
+	log.Info("Resolved address book label", "label", entry.Label, "address", entry.Address.Hex())
+	return entry.Address, entry.Label, nil
+}
+
+// parseAddressArg parses a hex address or resolves an @label, returning the
+// label ("" for hex input)
+func parseAddressArg(s string) (common.Address, string, error) {
+	if isAddressBookLabel(s) {
+		return resolveAddressBook(s)
+	}
+	addr, err := types.ParseAddress(s)
+	return addr, "", err
+}
+
+// recordAddressLabel stores an address book label in the metadata so the
+// offline signer can show it next to the address
+func recordAddressLabel(txParams *types.TxParams, label string, addr common.Address) {
+	if txParams.Metadata.AdditionalInfo == nil {
+		txParams.Metadata.AdditionalInfo = make(map[string]interface{})
+	}
+	labels, ok := txParams.Metadata.AdditionalInfo["address_labels"].(map[string]string)
+	if !ok {
+		labels = make(map[string]string)
+		txParams.Metadata.AdditionalInfo["address_labels"] = labels
+	}
+	labels[addr.Hex()] = label
+}
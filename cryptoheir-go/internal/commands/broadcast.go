@@ -1,15 +1,26 @@
 package commands
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/contract"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/crypto"
 	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/network"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/store"
 	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
 	"github.com/ethereum/go-ethereum/common"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/spf13/cobra"
 )
 
@@ -26,26 +37,145 @@ It will submit the transaction and wait for confirmation.`,
 
 var (
 	broadcastInputFlag   string
+	broadcastBatchFlag   []string
+	broadcastDirFlag     string
+	broadcastGlobFlag    string
+	broadcastYesFlag     bool
 	broadcastNetworkFlag string
 	broadcastRPCURLFlag  string
+	broadcastRPCURLFile  string
+	broadcastWatchBlocks bool
+	broadcastNoWait      bool
+
+	broadcastPrivateRelay       string
+	broadcastPrivateRelayMethod string
+
+	broadcastIgnoreSchedule   bool
+	broadcastOverwriteReceipt bool
+	broadcastAllowSettled     bool
+	broadcastStrictMetadata   bool
+
+	broadcastExpectBeneficiary string
+	broadcastExpectAmount      string
+	broadcastExpectToken       string
+
+	broadcastDecrypt bool
+	broadcastKeyFile string
+
+	broadcastBeneficiaryCard bool
+
+	broadcastRequireIncludable bool
+
+	broadcastConfirmations uint64
+
+	broadcastDryRun bool
+
+	broadcastRPS float64
+
+	broadcastForgetNonces []uint
 )
 
 func init() {
 	BroadcastCmd.Flags().StringVarP(&broadcastInputFlag, "input", "i", "signed-tx.json", "Input signed transaction file")
+	BroadcastCmd.Flags().StringSliceVar(&broadcastBatchFlag, "batch", nil, "Broadcast several signed transaction files in nonce order, waiting for each to confirm (comma-separated)")
+	BroadcastCmd.Flags().StringVar(&broadcastDirFlag, "dir", "", "Broadcast all signed transaction files (.json or .cbor) in a directory, ordered by nonce")
+	BroadcastCmd.Flags().StringVar(&broadcastGlobFlag, "input-glob", "", "Broadcast the signed transaction files matching a glob pattern (e.g. 'signed-tx-*.json'), ordered by nonce")
+	BroadcastCmd.Flags().BoolVarP(&broadcastYesFlag, "yes", "y", false, "With --input-glob: broadcast several matched files without asking for confirmation")
+	BroadcastCmd.Flags().BoolVar(&broadcastDecrypt, "decrypt", false, "Decrypt signed transaction files written with 'sign --encrypt-out'")
+	BroadcastCmd.Flags().StringVar(&broadcastKeyFile, "key", "", "With --decrypt: file holding the hex private key for --recipient-pubkey ('-' for stdin)")
 	BroadcastCmd.Flags().StringVar(&broadcastNetworkFlag, "network", "", "Network name (must match signed transaction)")
 	BroadcastCmd.Flags().StringVar(&broadcastRPCURLFlag, "rpc-url", "", "Custom RPC URL (overrides network)")
+	BroadcastCmd.Flags().StringVar(&broadcastRPCURLFile, "rpc-url-file", "", "Read the custom RPC URL from a file ('-' for stdin) to keep API keys out of shell history")
+	BroadcastCmd.Flags().StringVar(&rpcSelectFlag, "rpc-select", rpcSelectFirst, rpcSelectUsage)
+	BroadcastCmd.Flags().UintSliceVar(&broadcastForgetNonces, "forget-nonce", nil, "Batch: forget the submission ledger entry of this nonce (repeatable), e.g. after its transaction was replaced outside this tool")
+	BroadcastCmd.Flags().BoolVar(&broadcastNoWait, "no-wait", false, "Submit and exit without waiting for confirmation (check later with 'cryptoheir status')")
+	BroadcastCmd.Flags().StringVar(&broadcastPrivateRelay, "private-relay", "", "Submit through a private relay URL instead of the public mempool (receipts are still read from --network/--rpc-url)")
+	BroadcastCmd.Flags().StringVar(&broadcastPrivateRelayMethod, "private-relay-method", network.RelayMethodPrivate, "Relay submission method: eth_sendPrivateTransaction or eth_sendRawTransaction (protected RPC endpoints)")
+	BroadcastCmd.Flags().BoolVar(&broadcastStrictMetadata, "strict-metadata", false, "Fail if --network differs from the network recorded in the signed transaction")
+	BroadcastCmd.Flags().BoolVar(&broadcastIgnoreSchedule, "ignore-schedule", false, "Broadcast even if the transaction is scheduled for a later time (prepare --broadcast-after)")
+	BroadcastCmd.Flags().BoolVar(&broadcastOverwriteReceipt, "overwrite-receipt", false, "Broadcast even if a receipt file exists, and overwrite it")
+	BroadcastCmd.Flags().BoolVar(&broadcastAllowSettled, "allow-settled", false, "Broadcast a claim, reclaim or deadline extension even if its inheritance is already settled (it will revert)")
+	BroadcastCmd.Flags().StringVar(&broadcastExpectBeneficiary, "expect-beneficiary", "", "Refuse to broadcast unless the signed deposit's beneficiary is this address")
+	BroadcastCmd.Flags().StringVar(&broadcastExpectAmount, "expect-amount", "", "Refuse to broadcast unless the signed deposit's amount is this much (ETH, or token units for a token deposit)")
+	BroadcastCmd.Flags().StringVar(&broadcastExpectToken, "expect-token", "", "Refuse to broadcast unless the signed deposit is of this ERC20 token")
+	BroadcastCmd.Flags().BoolVar(&broadcastBeneficiaryCard, "beneficiary-card", false, "After a deposit confirms, write a card with the inheritance ID, contract, chain, beneficiary and deadline to hand to the beneficiary (<input>-beneficiary.txt)")
+	BroadcastCmd.Flags().BoolVar(&broadcastRequireIncludable, "require-includable", false, "Refuse to broadcast a transaction whose max fee is below the current base fee (by default only a warning)")
+	BroadcastCmd.Flags().Uint64Var(&broadcastConfirmations, "confirmations", 0, "Blocks to wait for, counting the inclusion block (default: the network's default, e.g. 3 on mainnet and 1 on rollups)")
+	BroadcastCmd.Flags().BoolVar(&broadcastDryRun, "dry-run", false, "Run every pre-broadcast check (chain ID, signature, nonce, fees, funds, eth_call simulation) and report PASS/FAIL without submitting")
+	BroadcastCmd.Flags().Float64Var(&broadcastRPS, "rps", network.DefaultRPS, "Maximum RPC requests per second, to stay under provider rate limits (0 disables the limit)")
+	BroadcastCmd.Flags().BoolVar(&broadcastWatchBlocks, "poll-receipt-from-block", true, "Only look up the receipt when a new block arrives after the broadcast block (set =false to poll the receipt every interval)")
+}
+
+// signedTxFile pairs a loaded signed transaction with the file it came from
+type signedTxFile struct {
+	path     string
+	signedTx *types.SignedTx
 }
 
 func runBroadcast(cmd *cobra.Command, args []string) error {
-	// Load signed transaction
-	signedTxData, err := os.ReadFile(broadcastInputFlag)
-	if err != nil {
-		return fmt.Errorf("failed to read input file: %w", err)
+	sources := 0
+	for _, given := range []bool{len(broadcastBatchFlag) > 0, broadcastDirFlag != "", broadcastGlobFlag != ""} {
+		if given {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return fmt.Errorf("--batch, --dir and --input-glob cannot be used together")
+	}
+	if broadcastDecrypt != (broadcastKeyFile != "") {
+		return fmt.Errorf("--decrypt and --key must be used together")
+	}
+	if broadcastDecrypt {
+		key, err := loadDecryptionKey(broadcastKeyFile)
+		if err != nil {
+			return err
+		}
+		decryptionKey = key
+	}
+	if broadcastPrivateRelay != "" &&
+		broadcastPrivateRelayMethod != network.RelayMethodPrivate &&
+		broadcastPrivateRelayMethod != network.RelayMethodRaw {
+		return fmt.Errorf("unsupported --private-relay-method: %s (supported: %s, %s)",
+			broadcastPrivateRelayMethod, network.RelayMethodPrivate, network.RelayMethodRaw)
+	}
+
+	if broadcastRPS < 0 {
+		return fmt.Errorf("--rps must not be negative")
 	}
+	network.SetRateLimit(broadcastRPS)
 
-	var signedTx types.SignedTx
-	if err := json.Unmarshal(signedTxData, &signedTx); err != nil {
-		return fmt.Errorf("failed to parse signed transaction: %w", err)
+	if broadcastDirFlag != "" {
+		files, err := loadSignedTxDir(broadcastDirFlag)
+		if err != nil {
+			return err
+		}
+		return broadcastFiles(files)
+	}
+
+	if broadcastGlobFlag != "" {
+		files, err := loadSignedTxGlob(broadcastGlobFlag)
+		if err != nil {
+			return err
+		}
+		return broadcastFiles(files)
+	}
+
+	if len(broadcastBatchFlag) > 0 {
+		var files []signedTxFile
+		for _, path := range broadcastBatchFlag {
+			signedTx, err := loadSignedTx(path)
+			if err != nil {
+				return err
+			}
+			files = append(files, signedTxFile{path: path, signedTx: signedTx})
+		}
+		return broadcastFiles(files)
+	}
+
+	// Load signed transaction
+	signedTx, err := loadSignedTx(broadcastInputFlag)
+	if err != nil {
+		return err
 	}
 
 	log.Info("Signed transaction loaded")
@@ -54,50 +184,788 @@ func runBroadcast(cmd *cobra.Command, args []string) error {
 	log.Info("  Network",
 		"network", signedTx.Metadata.Network.Name,
 		"chain_id", signedTx.Metadata.Network.ChainID)
+	if sw := signedTx.Metadata.SignedWith; sw != nil {
+		log.Info("  Signed With",
+			"tool", sw.ToolVersion,
+			"commit", sw.GitCommit,
+			"go_ethereum", sw.GoEthereumVersion,
+			"go", sw.GoVersion)
+	}
 
-	// Load configuration
+	if broadcastDryRun {
+		return runDryRunBroadcast([]signedTxFile{{path: broadcastInputFlag, signedTx: signedTx}})
+	}
+
+	if err := checkMetadataNetwork(signedTxFile{path: broadcastInputFlag, signedTx: signedTx}); err != nil {
+		return err
+	}
+	if err := checkReceiptOverwrite(signedTxFile{path: broadcastInputFlag, signedTx: signedTx}); err != nil {
+		return err
+	}
+	checkPredictedAddress(signedTxFile{path: broadcastInputFlag, signedTx: signedTx})
+
+	ctx := context.Background()
+	client, err := connectForBroadcast(ctx, signedTx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := checkBroadcastSchedule(ctx, client, signedTxFile{path: broadcastInputFlag, signedTx: signedTx}); err != nil {
+		return err
+	}
+	if err := checkExpectedDeposit(ctx, client, signedTxFile{path: broadcastInputFlag, signedTx: signedTx}); err != nil {
+		return err
+	}
+	if err := checkSettledInheritances(ctx, client, signedTxFile{path: broadcastInputFlag, signedTx: signedTx}); err != nil {
+		return err
+	}
+	if err := checkIncludable(ctx, client, signedTxFile{path: broadcastInputFlag, signedTx: signedTx}); err != nil {
+		return err
+	}
+	if err := checkBundleOrder(ctx, client, signedTxFile{path: broadcastInputFlag, signedTx: signedTx}); err != nil {
+		return err
+	}
+
+	_, err = broadcastAndWait(ctx, client, signedTx, broadcastInputFlag)
+	return err
+}
+
+// broadcastFiles broadcasts several signed transactions, or only checks them
+// with --dry-run
+func broadcastFiles(files []signedTxFile) error {
+	if broadcastDryRun {
+		return runDryRunBroadcast(files)
+	}
+	return runBatchBroadcast(files)
+}
+
+// runBatchBroadcast broadcasts several signed transactions from the same
+// sender in nonce order, stopping at the first failure
+func runBatchBroadcast(files []signedTxFile) error {
+	files, err := validateBatch(files)
+	if err != nil {
+		return err
+	}
+	if err := checkMetadataNetwork(files...); err != nil {
+		return err
+	}
+	if err := checkReceiptOverwrite(files...); err != nil {
+		return err
+	}
+	checkPredictedAddress(files...)
+
+	log.Info("Batch loaded", "transactions", len(files))
+	for i, f := range files {
+		log.Info(fmt.Sprintf("  [%d/%d]", i+1, len(files)),
+			"file", f.path,
+			"nonce", f.signedTx.Nonce(),
+			"hash", f.signedTx.TxHash.Hex())
+	}
+
+	ctx := context.Background()
+	client, err := connectForBroadcast(ctx, files[0].signedTx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := checkBroadcastSchedule(ctx, client, files...); err != nil {
+		return err
+	}
+	if err := checkExpectedDeposit(ctx, client, files...); err != nil {
+		return err
+	}
+	if err := checkSettledInheritances(ctx, client, files...); err != nil {
+		return err
+	}
+	if err := checkIncludable(ctx, client, files...); err != nil {
+		return err
+	}
+	if err := checkBundleOrder(ctx, client, files...); err != nil {
+		return err
+	}
+
+	// Ledger of nonces attempted by earlier runs, so a re-run never sends a
+	// different transaction for the same nonce
+	ledger, err := store.LoadSubmissions()
+	if err != nil {
+		return fmt.Errorf("failed to load submission ledger: %w", err)
+	}
+	chainID := files[0].signedTx.Metadata.Network.ChainID
+	from := files[0].signedTx.From
+	confirmedNonce, err := network.GetConfirmedNonce(ctx, client, from)
+	if err != nil {
+		return err
+	}
+	if n := ledger.Prune(chainID, from, confirmedNonce); n > 0 {
+		log.Debug("Removed confirmed entries from submission ledger", "entries", n)
+	}
+	for _, nonce := range broadcastForgetNonces {
+		if entry, ok := ledger.Lookup(chainID, from, uint64(nonce)); ok {
+			log.Warn("⚠ Forgetting the recorded submission of this nonce (--forget-nonce)", "nonce", nonce, "hash", entry.TxHash.Hex(), "file", entry.File)
+			ledger.Remove(chainID, from, uint64(nonce))
+		} else {
+			log.Info("No recorded submission to forget", "nonce", nonce)
+		}
+	}
+	if len(broadcastForgetNonces) > 0 {
+		if err := ledger.Save(); err != nil {
+			return fmt.Errorf("failed to update submission ledger: %w", err)
+		}
+	}
+
+	for i, f := range files {
+		log.Info(fmt.Sprintf("Broadcasting %d/%d", i+1, len(files)), "file", f.path)
+
+		nonce := f.signedTx.Nonce()
+		if entry, ok := ledger.Lookup(chainID, from, nonce); ok {
+			if entry.TxHash != f.signedTx.TxHash {
+				return fmt.Errorf("batch stopped at %s: nonce %d was already submitted as %s (%s at %s); refusing to send a different transaction for the same nonce",
+					f.path, nonce, entry.TxHash.Hex(), entry.File, entry.SubmittedAt)
+			}
+			log.Info("  Already submitted by an earlier run, resuming", "hash", entry.TxHash.Hex())
+		} else {
+			// Record the attempt before sending, so an interrupted run counts
+			ledger.Record(chainID, from, nonce, f.signedTx.TxHash, f.path)
+			if err := ledger.Save(); err != nil {
+				return fmt.Errorf("failed to update submission ledger: %w", err)
+			}
+		}
+
+		receipt, err := broadcastAndWait(ctx, client, f.signedTx, f.path)
+		if errors.Is(err, errBroadcastRejected) {
+			// The node refused it, so the nonce is still free for another
+			// transaction
+			ledger.Remove(chainID, from, nonce)
+			if saveErr := ledger.Save(); saveErr != nil {
+				log.Warn("Failed to update submission ledger", "error", saveErr)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("batch stopped at %s (%d of %d not broadcast)%s: %w",
+				f.path, len(files)-i, len(files), bundleIncomplete(f), err)
+		}
+
+		// Mined (successfully or not): the nonce is used up
+		if receipt != nil {
+			ledger.Remove(chainID, from, nonce)
+			if err := ledger.Save(); err != nil {
+				log.Warn("Failed to update submission ledger", "error", err)
+			}
+		}
+		if receipt != nil && receipt.Status == 0 {
+			return types.Errorf(types.CodeTxFailed, "transaction in %s failed on-chain; %d remaining transaction(s) not broadcast%s",
+				f.path, len(files)-i-1, bundleIncomplete(f))
+		}
+	}
+
+	log.Info("✓ Batch complete", "transactions", len(files))
+	return nil
+}
+
+// checkBundleOrder enforces the dependency order of bundled transactions
+// (such as an approve followed by its deposit). Steps must be broadcast in
+// sequence without gaps, and earlier steps that are not part of this
+// broadcast must already be mined. This is not atomic: a later step can still
+// fail after an earlier one was mined, but a step never goes out before the
+// step it depends on.
+func checkBundleOrder(ctx context.Context, client *ethclient.Client, files ...signedTxFile) error {
+	// Group by bundle, keeping the nonce order of the files
+	var ids []string
+	bundles := make(map[string][]signedTxFile)
+	for _, f := range files {
+		b := f.signedTx.Metadata.Bundle
+		if b == nil {
+			continue
+		}
+		if _, ok := bundles[b.ID]; !ok {
+			ids = append(ids, b.ID)
+		}
+		bundles[b.ID] = append(bundles[b.ID], f)
+	}
+
+	for _, id := range ids {
+		members := bundles[id]
+		for i := 1; i < len(members); i++ {
+			prev, cur := members[i-1].signedTx.Metadata.Bundle, members[i].signedTx.Metadata.Bundle
+			switch {
+			case cur.Sequence == prev.Sequence:
+				return fmt.Errorf("bundle %s: %s and %s are both step %d", id, members[i-1].path, members[i].path, cur.Sequence)
+			case cur.Sequence < prev.Sequence:
+				return fmt.Errorf("bundle %s: step %d (%s, %s) has a higher nonce than step %d (%s, %s); the steps cannot be broadcast in order",
+					id, prev.Sequence, prev.Step, members[i-1].path, cur.Sequence, cur.Step, members[i].path)
+			case cur.Sequence > prev.Sequence+1:
+				return fmt.Errorf("bundle %s: step %d is missing between %s and %s", id, prev.Sequence+1, members[i-1].path, members[i].path)
+			}
+		}
+
+		// Earlier steps outside this broadcast must be mined already, which is
+		// the case once the confirmed nonce has reached the first step here
+		first := members[0]
+		b := first.signedTx.Metadata.Bundle
+		if b.Sequence > 1 {
+			confirmed, err := network.GetConfirmedNonce(ctx, client, first.signedTx.From)
+			if err != nil {
+				return err
+			}
+			if confirmed < first.signedTx.Nonce() {
+				return fmt.Errorf("%s is step %d/%d (%s) of bundle %s, but earlier steps are not in this broadcast and not yet mined (confirmed nonce %d, this nonce %d); broadcast the bundle in order",
+					first.path, b.Sequence, b.Size, b.Step, id, confirmed, first.signedTx.Nonce())
+			}
+			log.Info("  Earlier bundle steps already mined", "bundle_id", id, "step", fmt.Sprintf("%d/%d", b.Sequence, b.Size))
+		}
+
+		last := members[len(members)-1].signedTx.Metadata.Bundle
+		if last.Sequence < last.Size {
+			log.Warn("⚠ Bundle incomplete: later steps are not part of this broadcast",
+				"bundle_id", id, "last_step", fmt.Sprintf("%d/%d", last.Sequence, last.Size))
+		}
+	}
+	return nil
+}
+
+// bundleIncomplete describes the bundle left incomplete when the step in f
+// failed, or returns an empty string for unbundled transactions
+func bundleIncomplete(f signedTxFile) string {
+	b := f.signedTx.Metadata.Bundle
+	if b == nil {
+		return ""
+	}
+	if b.Sequence == b.Size {
+		return fmt.Sprintf("; bundle %s: final step %d/%d (%s) failed", b.ID, b.Sequence, b.Size, b.Step)
+	}
+	return fmt.Sprintf("; bundle %s is incomplete: step %d/%d (%s) failed, so steps %d-%d were aborted",
+		b.ID, b.Sequence, b.Size, b.Step, b.Sequence+1, b.Size)
+}
+
+// checkMetadataNetwork enforces --strict-metadata: an explicit --network must
+// name the network the transactions were prepared for. The chain ID check
+// alone does not catch every mix-up between networks.
+func checkMetadataNetwork(files ...signedTxFile) error {
+	if !broadcastStrictMetadata || broadcastNetworkFlag == "" {
+		return nil
+	}
+	for _, f := range files {
+		prepared := f.signedTx.Metadata.Network.Name
+		if !strings.EqualFold(prepared, broadcastNetworkFlag) {
+			return types.Errorf(types.CodeChainMismatch, "--network %s does not match the network %q recorded in %s (--strict-metadata)",
+				broadcastNetworkFlag, prepared, f.path)
+		}
+	}
+	return nil
+}
+
+// checkReceiptOverwrite refuses to broadcast when a receipt file already
+// exists, which usually means the transaction was broadcast before, unless
+// --overwrite-receipt is given. The check runs before broadcasting, because a receipt
+// that cannot be written afterwards would be lost.
+func checkReceiptOverwrite(files ...signedTxFile) error {
+	for _, f := range files {
+		if err := checkOverwriteFlag(receiptPath(f.path), broadcastOverwriteReceipt, "--overwrite-receipt"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkBroadcastSchedule refuses to broadcast transactions scheduled with
+// --broadcast-after until the latest block is past the scheduled time, since
+// time-gated calls (such as a reclaim after a deadline) would revert earlier
+func checkBroadcastSchedule(ctx context.Context, client *ethclient.Client, files ...signedTxFile) error {
+	var blockTime time.Time
+	for _, f := range files {
+		scheduled := f.signedTx.Metadata.BroadcastAfter
+		if scheduled == "" {
+			continue
+		}
+		after, err := time.Parse(time.RFC3339, scheduled)
+		if err != nil {
+			return fmt.Errorf("invalid broadcast_after in %s: %w", f.path, err)
+		}
+		log.Info("  Scheduled for broadcast after", "file", f.path, "time", after.Format(time.RFC3339))
+
+		if blockTime.IsZero() {
+			if blockTime, err = network.GetBlockTime(ctx, client); err != nil {
+				return err
+			}
+		}
+		if blockTime.After(after) {
+			continue
+		}
+
+		if broadcastIgnoreSchedule {
+			log.Warn("⚠ Broadcasting before the scheduled time (--ignore-schedule)",
+				"file", f.path,
+				"scheduled_after", after.Format(time.RFC3339),
+				"latest_block_time", blockTime.UTC().Format(time.RFC3339))
+			continue
+		}
+		return fmt.Errorf("%s is scheduled for broadcast after %s but the latest block is from %s (%s early); use --ignore-schedule to broadcast anyway",
+			f.path, after.Format(time.RFC3339), blockTime.UTC().Format(time.RFC3339), after.Sub(blockTime).Round(time.Second))
+	}
+	return nil
+}
+
+// checkExpectedDeposit enforces --expect-beneficiary, --expect-amount and
+// --expect-token by decoding the signed transaction itself, independent of
+// the metadata recorded at prepare and sign time. It fails closed: a deposit
+// that does not target CONTRACT_ADDRESS, a batch step that is neither a
+// deposit nor an approval for the contract of a token deposited in the batch,
+// or a batch without a deposit, is refused. The amount checked is what the
+// contract takes: the transaction value for a native deposit, the _amount
+// argument for a token deposit.
+func checkExpectedDeposit(ctx context.Context, client *ethclient.Client, files ...signedTxFile) error {
+	if broadcastExpectBeneficiary == "" && broadcastExpectAmount == "" && broadcastExpectToken == "" {
+		return nil
+	}
+
+	var beneficiary, expectedToken common.Address
+	if broadcastExpectBeneficiary != "" {
+		var err error
+		if beneficiary, err = types.ParseAddress(broadcastExpectBeneficiary); err != nil {
+			return fmt.Errorf("invalid --expect-beneficiary: %w", err)
+		}
+	}
+	if broadcastExpectToken != "" {
+		var err error
+		if expectedToken, err = types.ParseAddress(broadcastExpectToken); err != nil {
+			return fmt.Errorf("invalid --expect-token: %w", err)
+		}
+	}
+	config, err := types.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if config.ContractAddress == nil {
+		return fmt.Errorf("CONTRACT_ADDRESS not set in environment; it is needed to confirm the deposit targets the CryptoHeir contract")
+	}
+	contractAddress := *config.ContractAddress
+	if err := contract.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize contract: %w", err)
+	}
+
+	deposits := 0
+	depositedTokens := make(map[common.Address]bool)
+	approvals := make(map[common.Address]string) // Approved token -> file
+	for _, f := range files {
+		tx := new(coretypes.Transaction)
+		if err := tx.UnmarshalBinary(f.signedTx.SignedTransaction); err != nil {
+			return fmt.Errorf("failed to decode signed transaction in %s: %w", f.path, err)
+		}
+		if tx.To() == nil {
+			return fmt.Errorf("%s is a contract deployment, not a deposit; cannot check --expect-beneficiary/--expect-amount", f.path)
+		}
+		args, err := contract.DecodeDeposit(tx.Data())
+		if err != nil {
+			// A batch may contain the token approval for the deposit
+			if spender, _, approveErr := contract.DecodeApprove(tx.Data()); approveErr == nil && len(files) > 1 {
+				if spender != contractAddress {
+					return fmt.Errorf("%s approves %s, not the CryptoHeir contract %s (refusing to broadcast)", f.path, spender.Hex(), contractAddress.Hex())
+				}
+				approvals[*tx.To()] = f.path
+				continue
+			}
+			return fmt.Errorf("%s is not a deposit; cannot check --expect-beneficiary/--expect-amount", f.path)
+		}
+		deposits++
+
+		if *tx.To() != contractAddress {
+			return fmt.Errorf("contract mismatch in %s: the signed deposit is sent to %s, but CONTRACT_ADDRESS is %s (refusing to broadcast)",
+				f.path, tx.To().Hex(), contractAddress.Hex())
+		}
+
+		if broadcastExpectBeneficiary != "" && args.Beneficiary != beneficiary {
+			return fmt.Errorf("beneficiary mismatch in %s: the signed deposit pays %s, expected %s (refusing to broadcast)",
+				f.path, args.Beneficiary.Hex(), beneficiary.Hex())
+		}
+		if broadcastExpectToken != "" && args.Token != expectedToken {
+			return fmt.Errorf("token mismatch in %s: the signed deposit is of %s, expected %s (refusing to broadcast)",
+				f.path, tokenName(args.Token), expectedToken.Hex())
+		}
+		depositedTokens[args.Token] = true
+
+		// The contract takes msg.value for native deposits and ignores
+		// _amount; token deposits revert with a non-zero msg.value
+		amount := tx.Value()
+		if args.Token != (common.Address{}) {
+			if tx.Value().Sign() != 0 {
+				return fmt.Errorf("%s is a token deposit with a non-zero value of %s wei; the contract reverts it (refusing to broadcast)", f.path, tx.Value())
+			}
+			amount = args.Amount
+		}
+
+		if broadcastExpectAmount != "" {
+			decimals := uint8(18)
+			unit := "ETH"
+			if args.Token != (common.Address{}) {
+				info, err := fetchTokenInfo(ctx, client, args.Token, -1)
+				if err != nil {
+					return err
+				}
+				decimals, unit = info.Decimals, info.unitName()
+			}
+			expected, err := types.ParseUnits(broadcastExpectAmount, decimals)
+			if err != nil {
+				return fmt.Errorf("invalid --expect-amount: %w", err)
+			}
+			if amount.Cmp(expected) != 0 {
+				return fmt.Errorf("amount mismatch in %s: the signed deposit is %s %s (%s base units), expected %s %s (refusing to broadcast)",
+					f.path, types.FormatUnits(amount, decimals), unit, amount, broadcastExpectAmount, unit)
+			}
+		}
+
+		log.Info("  ✓ Deposit matches expectations", "file", f.path, "beneficiary", args.Beneficiary.Hex(), "amount", amount.String())
+	}
+
+	if deposits == 0 {
+		return fmt.Errorf("no deposit found in the batch; cannot check --expect-beneficiary/--expect-amount")
+	}
+	// An approval is only there to fund a deposit of the same token
+	for token, path := range approvals {
+		if !depositedTokens[token] {
+			return fmt.Errorf("%s approves token %s, but no deposit in the batch is of that token (refusing to broadcast)", path, token.Hex())
+		}
+	}
+	return nil
+}
+
+// checkPredictedAddress recomputes the address a deployment will create from
+// the sender recovered from the signature and the signed nonce, and compares
+// it with the predicted address stored in the file, so a mismatched or
+// tampered prediction is noticed before submission rather than after mining.
+// It only warns.
+func checkPredictedAddress(files ...signedTxFile) {
+	for _, f := range files {
+		from, tx, err := crypto.RecoverSigner(f.signedTx.SignedTransaction)
+		if err != nil {
+			continue
+		}
+		predicted := f.signedTx.PredictedContractAddress
+
+		if tx.To() != nil {
+			if predicted != nil {
+				log.Warn("⚠ The file records a predicted contract address but the transaction is not a deployment",
+					"file", f.path, "predicted", predicted.Hex())
+			}
+			continue
+		}
+
+		expected := ethcrypto.CreateAddress(from, tx.Nonce())
+		switch {
+		case predicted == nil:
+			log.Warn("⚠ The file records no predicted contract address", "file", f.path, "expected", expected.Hex())
+		case *predicted != expected:
+			log.Warn("⚠ Predicted contract address does not match the signed transaction",
+				"file", f.path,
+				"predicted", predicted.Hex(),
+				"expected", expected.Hex(),
+				"sender", from.Hex(),
+				"nonce", tx.Nonce())
+		default:
+			log.Info("  ✓ Contract will be deployed at the predicted address", "address", expected.Hex(), "sender", from.Hex(), "nonce", tx.Nonce())
+		}
+	}
+}
+
+// checkIncludable compares each transaction's fee cap (the max fee, or the
+// gas price of a legacy transaction) with the base fee of the latest block. A
+// transaction below the base fee cannot be included until the base fee falls
+// to it, which happens when it sat too long or was prepared during a low-fee
+// period. This warns, or refuses with --require-includable.
+func checkIncludable(ctx context.Context, client *ethclient.Client, files ...signedTxFile) error {
+	baseFee, block, err := network.GetBaseFee(ctx, client)
+	if err != nil {
+		log.Debug("Could not read the base fee", "error", err)
+		return nil
+	}
+	if baseFee == nil {
+		return nil
+	}
+
+	for _, f := range files {
+		tx := new(coretypes.Transaction)
+		if err := tx.UnmarshalBinary(f.signedTx.SignedTransaction); err != nil {
+			continue
+		}
+		if tx.GasFeeCap().Cmp(baseFee) >= 0 {
+			continue
+		}
+
+		msg := fmt.Sprintf("the max fee of %s gwei in %s is below the current base fee of %s gwei (block %d); the transaction cannot be included until the base fee falls to it",
+			types.FormatGwei(tx.GasFeeCap()), f.path, types.FormatGwei(baseFee), block)
+		hint := fmt.Sprintf("prepare it again with current fees and the same nonce (--nonce %d, or --from-params with --refetch-gas) and sign the new file", tx.Nonce())
+		if broadcastRequireIncludable {
+			return fmt.Errorf("%s (--require-includable); %s", msg, hint)
+		}
+		log.Warn("⚠ NOT INCLUDABLE: " + msg)
+		log.Warn("  To bump the fee, " + hint)
+	}
+	return nil
+}
+
+// checkSettledInheritances refuses to broadcast a claim, reclaim or deadline
+// extension of an inheritance that is already settled, since it would revert.
+// The call must target CONTRACT_ADDRESS when it is set, so the inheritance is
+// not read from whatever contract the transaction happens to call. Other
+// transactions are not checked.
+func checkSettledInheritances(ctx context.Context, client *ethclient.Client, files ...signedTxFile) error {
+	if err := contract.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize contract: %w", err)
+	}
 	config, err := types.LoadConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	for _, f := range files {
+		tx := new(coretypes.Transaction)
+		if err := tx.UnmarshalBinary(f.signedTx.SignedTransaction); err != nil || tx.To() == nil {
+			continue
+		}
+		method, inheritanceID, err := contract.DecodeInheritanceCall(tx.Data())
+		if err != nil {
+			continue
+		}
+		switch {
+		case config.ContractAddress == nil:
+			log.Warn("⚠ CONTRACT_ADDRESS not set; reading the inheritance from the transaction's recipient", "file", f.path, "to", tx.To().Hex())
+		case *tx.To() != *config.ContractAddress:
+			return fmt.Errorf("%s calls %s on %s, but CONTRACT_ADDRESS is %s (refusing to broadcast)",
+				f.path, method, tx.To().Hex(), config.ContractAddress.Hex())
+		}
+		log.Info("  Checking inheritance state", "file", f.path, "call", method, "inheritance_id", inheritanceID.String())
+		if err := checkInheritanceOpen(ctx, client, *tx.To(), inheritanceID, broadcastAllowSettled, "--allow-settled"); err != nil {
+			return fmt.Errorf("%s: %w", f.path, err)
+		}
+	}
+	return nil
+}
+
+// validateBatch checks that all transactions decode and share a sender and
+// chain, sorts them by nonce and resolves transactions competing for the same
+// nonce. A file that does not decode is rejected here, since its Nonce would
+// read as 0 and sort it first.
+func validateBatch(files []signedTxFile) ([]signedTxFile, error) {
+	for _, f := range files {
+		tx := new(coretypes.Transaction)
+		if err := tx.UnmarshalBinary(f.signedTx.SignedTransaction); err != nil {
+			return nil, fmt.Errorf("failed to decode signed transaction in %s: %w", f.path, err)
+		}
+	}
+
+	first := files[0].signedTx
+	for _, f := range files[1:] {
+		if f.signedTx.From != first.From {
+			return nil, fmt.Errorf("batch mixes senders: %s is from %s, %s is from %s",
+				files[0].path, first.From.Hex(), f.path, f.signedTx.From.Hex())
+		}
+		if f.signedTx.Metadata.Network.ChainID != first.Metadata.Network.ChainID {
+			return nil, types.Errorf(types.CodeChainMismatch, "batch mixes chains: %s is for chain %d, %s is for chain %d",
+				files[0].path, first.Metadata.Network.ChainID, f.path, f.signedTx.Metadata.Network.ChainID)
+		}
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].signedTx.Nonce() < files[j].signedTx.Nonce()
+	})
+	files, err := resolveNonceConflicts(files)
+	if err != nil {
+		return nil, err
+	}
+
+	// Report nonce gaps: transactions after a gap stay queued until it is filled
+	for i := 1; i < len(files); i++ {
+		prev, cur := files[i-1].signedTx.Nonce(), files[i].signedTx.Nonce()
+		if cur > prev+1 {
+			log.Warn("⚠ Nonce gap in batch: later transactions will not be mined until the gap is filled",
+				"after", files[i-1].path,
+				"missing_from", prev+1,
+				"missing_to", cur-1)
+		}
+	}
+
+	return files, nil
+}
+
+// loadSignedTxDir loads every signed transaction file in a directory,
+// skipping JSON files that are not signed transactions (params, receipts)
+func loadSignedTxDir(dir string) ([]signedTxFile, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+	cborPaths, err := filepath.Glob(filepath.Join(dir, "*.cbor"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+	paths = append(paths, cborPaths...)
+
+	var files []signedTxFile
+	for _, path := range paths {
+		signedTx, err := loadSignedTx(path)
+		if errors.Is(err, types.ErrUnsupportedSchema) || errors.Is(err, errEncryptedSignedTx) {
+			return nil, err
+		}
+		if err != nil || len(signedTx.SignedTransaction) == 0 {
+			log.Debug("Skipping non signed-tx file", "file", path)
+			continue
+		}
+		files = append(files, signedTxFile{path: path, signedTx: signedTx})
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no signed transaction files found in %s", dir)
+	}
+
+	log.Info("Loaded signed transactions from directory", "dir", dir, "files", len(files))
+	return files, nil
+}
+
+// loadSignedTxGlob loads the signed transaction files matching a glob
+// pattern. Unlike --dir, every match must be a signed transaction, since the
+// pattern selected them explicitly; only the receipts and submissions that
+// broadcast writes next to its inputs are skipped, so the same pattern can
+// resume a partly broadcast batch. Several matches are listed and must be
+// confirmed unless --yes is given.
+func loadSignedTxGlob(pattern string) ([]signedTxFile, error) {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --input-glob pattern %q: %w", pattern, err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no files match --input-glob %q", pattern)
+	}
+
+	var files []signedTxFile
+	for _, path := range paths {
+		signedTx, err := loadSignedTx(path)
+		isSigned := err == nil && len(signedTx.SignedTransaction) > 0
+		if !isSigned && isReceiptPath(path) {
+			log.Debug("Skipping receipt file", "file", path)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !isSigned {
+			return nil, fmt.Errorf("%s matches --input-glob but is not a signed transaction", path)
+		}
+		files = append(files, signedTxFile{path: path, signedTx: signedTx})
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("only receipts match --input-glob %q", pattern)
+	}
+
+	// Sort and check consistency before showing the list to confirm
+	files, err = validateBatch(files)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("Matched %d signed transaction file(s) for %q:\n", len(files), pattern)
+	for _, f := range files {
+		fmt.Printf("  nonce %-5d %s  %s\n", f.signedTx.Nonce(), f.signedTx.TxHash.Hex(), f.path)
+	}
+
+	if len(files) > 1 && !broadcastYesFlag && !broadcastDryRun {
+		fmt.Printf("Broadcast all %d transactions? [y/N] ", len(files))
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			return nil, fmt.Errorf("broadcast not confirmed (use --yes to skip the confirmation)")
+		}
+	}
+
+	return files, nil
+}
+
+// loadSignedTx reads and parses a signed transaction file
+func loadSignedTx(path string) (*types.SignedTx, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input file: %w", err)
+	}
+	data, err = decryptSignedTxFile(data, path)
+	if err != nil {
+		return nil, err
+	}
+	signedTx, err := types.ParseSignedTx(data, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if changes := types.MigrateSignedTx(signedTx); len(changes) > 0 && len(signedTx.SignedTransaction) > 0 {
+		log.Info("Migrated signed transaction from an older format", "file", path, "changes", strings.Join(changes, "; "))
+	}
+
+	return signedTx, nil
+}
+
+// connectForBroadcast connects to the network the transaction was signed for
+// and verifies the chain ID matches
+func connectForBroadcast(ctx context.Context, signedTx *types.SignedTx) (*ethclient.Client, error) {
+	return connectForSignedTx(ctx, signedTx, broadcastNetworkFlag, broadcastRPCURLFlag, broadcastRPCURLFile)
+}
+
+// connectForSignedTx connects using the given network flags, falling back to
+// the network recorded in the signed transaction, and verifies the chain ID
+func connectForSignedTx(ctx context.Context, signedTx *types.SignedTx, networkFlag, rpcURLFlag, rpcURLFile string) (*ethclient.Client, error) {
+	// Load configuration
+	config, err := types.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
 
 	// Determine RPC URL
-	rpcURL := broadcastRPCURLFlag
+	rpcURL, err := customRPCURL(rpcURLFlag, rpcURLFile)
+	if err != nil {
+		return nil, err
+	}
 	if rpcURL == "" {
 		// Use network flag if provided, otherwise use metadata
-		networkName := broadcastNetworkFlag
+		networkName := networkFlag
 		if networkName == "" {
 			networkName = signedTx.Metadata.Network.Name
 		}
-
 		rpcURL, err = network.GetRPCURL(networkName, config.InfuraAPIKey)
 		if err != nil {
-			return fmt.Errorf("failed to get RPC URL: %w", err)
+			return nil, fmt.Errorf("failed to get RPC URL: %w", err)
 		}
 	}
 
 	// Connect to network
-	ctx := context.Background()
 	client, err := network.CreateClient(ctx, rpcURL)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer client.Close()
 
 	log.Info("Connected to network")
 
 	// Verify chain ID matches
 	chainID, err := network.GetChainID(ctx, client)
 	if err != nil {
-		return err
+		client.Close()
+		return nil, err
 	}
 
+	// The verification itself never uses the cache, but keeps it fresh
+	refreshChainID(rpcURL, chainID)
+
 	if chainID != signedTx.Metadata.Network.ChainID {
-		return fmt.Errorf("chain ID mismatch: connected to chain %d but transaction is for chain %d",
+		client.Close()
+		return nil, types.Errorf(types.CodeChainMismatch, "chain ID mismatch: connected to chain %d but transaction is for chain %d",
 			chainID, signedTx.Metadata.Network.ChainID)
 	}
 	log.Info("✓ Chain ID verified", "chain_id", chainID)
 
+	return client, nil
+}
+
+// errBroadcastRejected marks a broadcast the node refused with an error
+// response, so the transaction was not submitted
+var errBroadcastRejected = errors.New("transaction rejected by the node")
+
+// broadcastAndWait submits a signed transaction (unless it is already known)
+// and waits for its receipt, saving it next to the input file
+func broadcastAndWait(ctx context.Context, client *ethclient.Client, signedTx *types.SignedTx, inputPath string) (*types.TxReceipt, error) {
 	// Check if transaction already broadcast (idempotent)
 	_, isPending, err := network.GetTransaction(ctx, client, signedTx.TxHash)
 	if err == nil {
@@ -117,7 +985,9 @@ func runBroadcast(cmd *cobra.Command, args []string) error {
 				if receipt.ContractAddress != (common.Address{}) {
 					log.Info("  Contract Address", "address", receipt.ContractAddress.Hex())
 				}
-				return nil
+				result := network.ToTxReceipt(receipt)
+				journalSignedTx("broadcast", receiptStatus(result), inputPath, signedTx, nil)
+				return result, nil
 			}
 		}
 
@@ -125,10 +995,35 @@ func runBroadcast(cmd *cobra.Command, args []string) error {
 		log.Info("Waiting for confirmation...")
 	} else {
 		// Transaction not found, broadcast it
-		log.Info("Broadcasting transaction...")
-		txHash, err := network.BroadcastTransaction(ctx, client, signedTx.SignedTransaction)
+		reportNonceState(ctx, client, signedTx)
+		var txHash common.Hash
+		if broadcastPrivateRelay != "" {
+			log.Info("Broadcasting transaction via private relay...",
+				"relay", redactRPCURL(broadcastPrivateRelay),
+				"method", broadcastPrivateRelayMethod)
+			txHash, err = network.BroadcastPrivate(ctx, broadcastPrivateRelay, broadcastPrivateRelayMethod, signedTx.SignedTransaction)
+		} else {
+			log.Info("Broadcasting transaction...")
+			txHash, err = network.BroadcastTransaction(ctx, client, signedTx.SignedTransaction)
+		}
+		// The node has it from an earlier submission that was not yet
+		// visible to the lookup above; waiting for it is all that is left
+		alreadyKnown := network.IsAlreadyKnown(err)
+		if alreadyKnown {
+			log.Warn("⚠ The node already has this transaction from an earlier submission; treating it as submitted", "response", err)
+			txHash, err = signedTx.TxHash, nil
+		}
+		if network.IsReplacementUnderpriced(err) {
+			err = replacementUnderpriced(ctx, client, signedTx, err)
+		}
 		if err != nil {
-			return fmt.Errorf("failed to broadcast transaction: %w", err)
+			if network.IsRejected(err) {
+				err = fmt.Errorf("%w: %w", errBroadcastRejected, err)
+			} else {
+				err = fmt.Errorf("failed to broadcast transaction: %w", err)
+			}
+			journalSignedTx("broadcast", "error", inputPath, signedTx, err)
+			return nil, err
 		}
 
 		if txHash != signedTx.TxHash {
@@ -137,28 +1032,74 @@ func runBroadcast(cmd *cobra.Command, args []string) error {
 				"signed_hash", signedTx.TxHash.Hex())
 		}
 
-		log.Info("✓ Transaction broadcast successfully")
+		if !alreadyKnown {
+			log.Info("✓ Transaction broadcast successfully")
+		}
 		log.Info("  TX Hash", "hash", txHash.Hex())
 	}
 
+	// Submit only: record the submission and check later with 'status'
+	if broadcastNoWait {
+		writeSubmission(signedTx, inputPath)
+		journalSignedTx("broadcast", "submitted", inputPath, signedTx, nil)
+		return nil, nil
+	}
+
 	// Wait for receipt
-	receipt, err := network.WaitForReceipt(ctx, client, signedTx.TxHash)
+	receipt, err := network.WaitForReceipt(ctx, client, signedTx.TxHash, broadcastWatchBlocks)
 	if err != nil {
-		return err
+		journalSignedTx("broadcast", "error", inputPath, signedTx, err)
+		return nil, err
+	}
+	confirmations := broadcastConfirmations
+	if confirmations == 0 {
+		confirmations = network.DefaultConfirmations(signedTx.Metadata.Network.ChainID)
 	}
+	receipt, err = network.WaitForConfirmations(ctx, client, receipt, confirmations)
+	// A mined transaction short of its confirmations still gets its receipt
+	// written; the error is returned after that
+	var insufficient *network.InsufficientConfirmationsError
+	if errors.As(err, &insufficient) {
+		log.Warn("⚠ Transaction mined but not confirmed to the requested depth; saving the receipt. Check it later with 'status'",
+			"reached", insufficient.Reached, "wanted", insufficient.Wanted)
+	} else if err != nil {
+		journalSignedTx("broadcast", "error", inputPath, signedTx, err)
+		return nil, err
+	}
+	journalSignedTx("broadcast", receiptStatus(receipt), inputPath, signedTx, err)
 
 	// Update metadata
 	receipt.Metadata["broadcast_at"] = time.Now().UTC().Format(time.RFC3339)
 	receipt.Metadata["network"] = signedTx.Metadata.Network.Name
+	receipt.Metadata["chain_id"] = signedTx.Metadata.Network.ChainID
+	receipt.Metadata["confirmations"] = confirmations
+	if insufficient != nil {
+		receipt.Metadata["confirmations_reached"] = insufficient.Reached
+	}
+	if signedTx.Metadata.Bundle != nil {
+		receipt.Metadata["bundle"] = signedTx.Metadata.Bundle
+	}
+	if signedTx.Metadata.Label != "" {
+		receipt.Metadata["label"] = signedTx.Metadata.Label
+	}
 
 	// Display receipt information
 	log.Info("═════════════════════════════════════════")
-	log.Info("✓ TRANSACTION CONFIRMED")
+	if insufficient != nil {
+		log.Warn("⚠ TRANSACTION MINED, NOT YET CONFIRMED",
+			"confirmations", fmt.Sprintf("%d/%d", insufficient.Reached, insufficient.Wanted))
+	} else {
+		log.Info("✓ TRANSACTION CONFIRMED")
+	}
 	log.Info("═════════════════════════════════════════")
 	log.Info("  TX Hash", "hash", receipt.TransactionHash.Hex())
 	log.Info("  Block", "block", receipt.BlockNumber)
 	log.Info("  Status (1=success, 0=failed)", "status", receipt.Status)
 	log.Info("  Gas Used", "gas_used", receipt.GasUsed)
+	chainID := signedTx.Metadata.Network.ChainID
+	if link := network.ExplorerTxURL(chainID, receipt.TransactionHash); link != "" {
+		log.Info("  Explorer", "url", link)
+	}
 
 	if receipt.Status == 0 {
 		log.Error("⚠ TRANSACTION FAILED - Check block explorer for details")
@@ -167,6 +1108,9 @@ func runBroadcast(cmd *cobra.Command, args []string) error {
 	if receipt.ContractAddress != nil && *receipt.ContractAddress != (common.Address{}) {
 		log.Info("  Contract Deployed:")
 		log.Info("    Address", "address", receipt.ContractAddress.Hex())
+		if link := network.ExplorerAddressURL(chainID, *receipt.ContractAddress); link != "" {
+			log.Info("    Explorer", "url", link)
+		}
 
 		if signedTx.PredictedContractAddress != nil &&
 			*receipt.ContractAddress != *signedTx.PredictedContractAddress {
@@ -175,8 +1119,11 @@ func runBroadcast(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	recordReceiptParties(receipt, signedTx)
+	recordReceiptEvents(receipt)
+
 	// Save receipt to file
-	receiptFilename := fmt.Sprintf("%s-receipt.json", broadcastInputFlag[:len(broadcastInputFlag)-5])
+	receiptFilename := receiptPath(inputPath)
 	receiptData, err := json.MarshalIndent(receipt, "", "  ")
 	if err != nil {
 		log.Warn("Failed to serialize receipt", "error", err)
@@ -187,13 +1134,178 @@ func runBroadcast(cmd *cobra.Command, args []string) error {
 			log.Info("  Receipt saved", "file", receiptFilename)
 		}
 	}
+	if broadcastBeneficiaryCard {
+		writeBeneficiaryCards(receipt, signedTx.Metadata.Network.ChainID, signedTx.Metadata.Network.Name, inputPath)
+	}
 
-	return nil
+	if insufficient != nil {
+		return receipt, err
+	}
+	return receipt, nil
+}
+
+// recordReceiptParties fills in the sender and recipient, which a node's
+// receipt does not include, from the signed transaction
+func recordReceiptParties(receipt *types.TxReceipt, signedTx *types.SignedTx) {
+	receipt.From = signedTx.From
+	tx := new(coretypes.Transaction)
+	if err := tx.UnmarshalBinary(signedTx.SignedTransaction); err == nil {
+		receipt.To = tx.To()
+	}
+}
+
+// recordReceiptEvents decodes every event the transaction emitted into
+// receipt.Metadata["events"] and logs them grouped by emitting contract, so
+// that e.g. the protocol fee and the net deposited amount can be reconciled
+func recordReceiptEvents(receipt *types.TxReceipt) {
+	if len(receipt.Logs) == 0 {
+		return
+	}
+	if err := contract.Initialize(); err != nil {
+		log.Warn("Could not load the contract ABI; receipt events are not decoded", "error", err)
+		return
+	}
+
+	events := contract.DecodeLogs(receipt.Logs)
+	receipt.Metadata["events"] = events
+
+	log.Info("  Events", "count", len(events))
+	var emitters []common.Address
+	byEmitter := make(map[common.Address][]types.DecodedEvent)
+	for _, event := range events {
+		if _, seen := byEmitter[event.Address]; !seen {
+			emitters = append(emitters, event.Address)
+		}
+		byEmitter[event.Address] = append(byEmitter[event.Address], event)
+	}
+	for _, emitter := range emitters {
+		log.Info("    Emitted by", "address", emitter.Hex(), "events", len(byEmitter[emitter]))
+		for _, event := range byEmitter[emitter] {
+			name := event.Name
+			if name == "" {
+				name = "unknown"
+			}
+			log.Info("      "+name, "log_index", event.LogIndex, "summary", event.Summary)
+		}
+	}
+}
+
+// reportNonceState compares the signed nonce with the account's latest
+// (mined) and pending nonces, so the user knows whether the transaction will
+// be included promptly. It only informs; the node decides.
+func reportNonceState(ctx context.Context, client *ethclient.Client, signedTx *types.SignedTx) {
+	nonce := signedTx.Nonce()
+	latest, err := network.GetConfirmedNonce(ctx, client, signedTx.From)
+	if err != nil {
+		log.Debug("Could not check the account nonce", "error", err)
+		return
+	}
+	pending, err := network.GetNonce(ctx, client, signedTx.From)
+	if err != nil {
+		log.Debug("Could not check the account nonce", "error", err)
+		return
+	}
+
+	switch {
+	case nonce < latest:
+		log.Warn("⚠ Stale nonce: a transaction with this nonce is already mined, so the node will reject this one (nonce too low)",
+			"nonce", nonce, "latest", latest, "pending", pending)
+	case nonce < pending:
+		log.Warn(fmt.Sprintf("⚠ A pending transaction already uses this nonce; this one only replaces it if its fees are at least %d%% higher", network.ReplacementBumpPercent),
+			"nonce", nonce, "latest", latest, "pending", pending)
+	case nonce == pending:
+		log.Info("  Nonce is next in line", "nonce", nonce, "latest", latest, "pending", pending)
+	default:
+		log.Warn("⚠ Nonce gap: the transaction will be queued until the missing nonces are used",
+			"nonce", nonce, "pending", pending, "missing_from", pending, "missing_to", nonce-1)
+	}
+}
+
+// replacementUnderpriced builds a ReplacementUnderpricedError for a rejected
+// replacement, looking up the pending transaction it replaces through the
+// journal so the minimum acceptable fees can be reported
+func replacementUnderpriced(ctx context.Context, client *ethclient.Client, signedTx *types.SignedTx, err error) error {
+	replacement := new(coretypes.Transaction)
+	if decodeErr := replacement.UnmarshalBinary(signedTx.SignedTransaction); decodeErr != nil {
+		return err
+	}
+	result := &network.ReplacementUnderpricedError{Err: err, Replacement: replacement}
+
+	entries, journalErr := store.ReadJournal()
+	if journalErr != nil {
+		log.Debug("Could not read journal to find the replaced transaction", "error", journalErr)
+		return result
+	}
+	// Newest first: the most recent transaction sent for this nonce
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.TxHash == nil || *e.TxHash == signedTx.TxHash ||
+			e.ChainID != signedTx.Metadata.Network.ChainID || e.From != signedTx.From || e.Nonce != replacement.Nonce() {
+			continue
+		}
+		original, isPending, getErr := network.GetTransaction(ctx, client, *e.TxHash)
+		if getErr == nil && isPending {
+			result.Original = original
+			break
+		}
+	}
+
+	return result
+}
+
+// receiptStatus names the outcome of a mined transaction
+func receiptStatus(receipt *types.TxReceipt) string {
+	if receipt.Status == 0 {
+		return "failed"
+	}
+	return "success"
+}
+
+// writeSubmission saves a minimal "submitted" result where the receipt
+// would go, to be replaced by 'cryptoheir status' once the transaction is mined
+func writeSubmission(signedTx *types.SignedTx, inputPath string) {
+	submission := types.TxSubmission{
+		TransactionHash: signedTx.TxHash,
+		Status:          "submitted",
+		From:            signedTx.From,
+		Nonce:           signedTx.Nonce(),
+		Metadata: map[string]interface{}{
+			"broadcast_at": time.Now().UTC().Format(time.RFC3339),
+			"network":      signedTx.Metadata.Network.Name,
+		},
+	}
+	if signedTx.Metadata.Bundle != nil {
+		submission.Metadata["bundle"] = signedTx.Metadata.Bundle
+	}
+	if signedTx.Metadata.Label != "" {
+		submission.Metadata["label"] = signedTx.Metadata.Label
+	}
+
+	log.Info("✓ Transaction submitted (not waiting for confirmation)")
+	log.Info("  TX Hash", "hash", signedTx.TxHash.Hex())
+
+	resultFilename := receiptPath(inputPath)
+	data, err := json.MarshalIndent(submission, "", "  ")
+	if err != nil {
+		log.Warn("Failed to serialize submission", "error", err)
+		return
+	}
+	if err := os.WriteFile(resultFilename, data, 0644); err != nil {
+		log.Warn("Failed to write submission file", "error", err)
+		return
+	}
+	log.Info("  Submission saved", "file", resultFilename)
+	log.Info("  Next", "instruction", fmt.Sprintf("Check confirmation with 'cryptoheir status -i %s'", inputPath))
+}
+
+// receiptPath derives the receipt filename from a signed transaction path
+// (signed-tx.json -> signed-tx-receipt.json)
+func receiptPath(inputPath string) string {
+	return strings.TrimSuffix(inputPath, filepath.Ext(inputPath)) + "-receipt.json"
 }
 
-// GetTransactionStatus checks the status of a transaction by hash
-func GetTransactionStatus(ctx context.Context, client interface{}, txHash common.Hash) (string, error) {
-	// This is a helper function for checking transaction status
-	// Implementation would query the blockchain for transaction details
-	return "unknown", fmt.Errorf("not implemented")
+// isReceiptPath reports whether a path is named like a receipt or submission
+// written by receiptPath
+func isReceiptPath(path string) bool {
+	return strings.HasSuffix(path, "-receipt.json")
 }
@@ -0,0 +1,182 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/contract"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/crypto"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// testSignerKey is the well-known key 0x4646...46 of the EIP-155 examples,
+// whose address is 0x9d8A62f656a8d1615C1294fd71e9CFb3E4855A4F
+const testSignerKey = "4646464646464646464646464646464646464646464646464646464646464646"
+
+// signCall signs a call with the given nonce from the test signer
+func signCall(t *testing.T, to common.Address, data []byte, value *big.Int, nonce uint64) *types.SignedTx {
+	t.Helper()
+	signedTx, err := crypto.SignTransaction(&types.TxParams{
+		Mode: types.TransactionModeCall,
+		Transaction: types.TransactionData{
+			TxType:               2,
+			From:                 common.HexToAddress("0x9d8A62f656a8d1615C1294fd71e9CFb3E4855A4F"),
+			To:                   &to,
+			Data:                 data,
+			Nonce:                nonce,
+			ChainID:              11155111,
+			GasLimit:             types.NewBigInt(big.NewInt(100000)),
+			MaxFeePerGas:         types.NewBigInt(big.NewInt(30_000_000_000)),
+			MaxPriorityFeePerGas: types.NewBigInt(big.NewInt(1_000_000_000)),
+			Value:                types.NewBigInt(value),
+		},
+	}, testSignerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signedTx
+}
+
+// writeSignedTx signs a transfer with the given nonce and writes it to path
+// the way sign does
+func writeSignedTx(t *testing.T, path string, nonce uint64) *types.SignedTx {
+	t.Helper()
+	signedTx := signCall(t, common.HexToAddress("0x1111111111111111111111111111111111111111"), nil, big.NewInt(1000), nonce)
+	data, err := json.MarshalIndent(signedTx, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return signedTx
+}
+
+func TestLoadSignedTxGlobSkipsReceipts(t *testing.T) {
+	saved := broadcastYesFlag
+	broadcastYesFlag = true
+	t.Cleanup(func() { broadcastYesFlag = saved })
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "signed-tx-1.json")
+	second := filepath.Join(dir, "signed-tx-2.json")
+	submitted := writeSignedTx(t, first, 0)
+	writeSignedTx(t, second, 1)
+
+	// The first transaction was submitted in an earlier run, which left its
+	// submission file where the receipt goes; it matches the pattern too
+	writeSubmission(submitted, first)
+	if _, err := os.Stat(receiptPath(first)); err != nil {
+		t.Fatalf("no submission file written: %v", err)
+	}
+
+	pattern := filepath.Join(dir, "signed-tx-*.json")
+	files, err := loadSignedTxGlob(pattern)
+	if err != nil {
+		t.Fatalf("loadSignedTxGlob() error = %v", err)
+	}
+	if len(files) != 2 || files[0].path != first || files[1].path != second {
+		t.Fatalf("loadSignedTxGlob() = %v, want the two signed transactions", files)
+	}
+
+	// A matching file that is neither a signed transaction nor a receipt is
+	// still refused
+	if err := os.WriteFile(filepath.Join(dir, "signed-tx-notes.json"), []byte(`{"note":"x"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadSignedTxGlob(pattern); err == nil || !strings.Contains(err.Error(), "not a signed transaction") {
+		t.Fatalf("loadSignedTxGlob() with a stray file error = %v, want it refused", err)
+	}
+
+	// Receipts alone are not a batch
+	if _, err := loadSignedTxGlob(filepath.Join(dir, "*-receipt.json")); err == nil || !strings.Contains(err.Error(), "only receipts") {
+		t.Fatalf("loadSignedTxGlob() of receipts error = %v, want it refused", err)
+	}
+}
+
+func TestValidateBatchRejectsUndecodable(t *testing.T) {
+	dir := t.TempDir()
+	good := writeSignedTx(t, filepath.Join(dir, "a.json"), 3)
+	corrupt := *good
+	corrupt.SignedTransaction = append([]byte(nil), good.SignedTransaction[:len(good.SignedTransaction)/2]...)
+
+	files := []signedTxFile{
+		{path: "a.json", signedTx: good},
+		{path: "corrupt.json", signedTx: &corrupt},
+	}
+	_, err := validateBatch(files)
+	if err == nil || !strings.Contains(err.Error(), "corrupt.json") {
+		t.Fatalf("validateBatch() error = %v, want the undecodable file rejected", err)
+	}
+
+	if _, err := validateBatch(files[:1]); err != nil {
+		t.Fatalf("validateBatch() of a valid file error = %v", err)
+	}
+}
+
+func TestCheckExpectedDepositToken(t *testing.T) {
+	if err := contract.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	contractAddress := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	t.Setenv("CONTRACT_ADDRESS", contractAddress.Hex())
+	beneficiary := common.HexToAddress("0x4444444444444444444444444444444444444444")
+	usdc := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	dai := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	amount := big.NewInt(1_500_000)
+
+	approve := func(token common.Address) signedTxFile {
+		data, err := contract.EncodeApprove(contractAddress, amount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signedTxFile{path: "approve.json", signedTx: signCall(t, token, data, big.NewInt(0), 0)}
+	}
+	deposit := func(token *common.Address) signedTxFile {
+		data, value, err := contract.EncodeDeposit(beneficiary, amount, big.NewInt(4102444800), token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signedTxFile{path: "deposit.json", signedTx: signCall(t, contractAddress, data, value, 1)}
+	}
+
+	tests := []struct {
+		name        string
+		files       []signedTxFile
+		expectToken string
+		wantErr     string
+	}{
+		{"approval of the deposited token", []signedTxFile{approve(usdc), deposit(&usdc)}, "", ""},
+		{"approval of another token", []signedTxFile{approve(dai), deposit(&usdc)}, "", "no deposit in the batch is of that token"},
+		{"approval with a native deposit", []signedTxFile{approve(usdc), deposit(nil)}, "", "no deposit in the batch is of that token"},
+		{"expected token", []signedTxFile{approve(usdc), deposit(&usdc)}, usdc.Hex(), ""},
+		{"unexpected token", []signedTxFile{approve(dai), deposit(&dai)}, usdc.Hex(), "token mismatch"},
+		{"native deposit where a token is expected", []signedTxFile{deposit(nil)}, usdc.Hex(), "token mismatch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := []string{broadcastExpectBeneficiary, broadcastExpectAmount, broadcastExpectToken}
+			t.Cleanup(func() {
+				broadcastExpectBeneficiary, broadcastExpectAmount, broadcastExpectToken = saved[0], saved[1], saved[2]
+			})
+			broadcastExpectBeneficiary, broadcastExpectAmount, broadcastExpectToken = beneficiary.Hex(), "", tt.expectToken
+
+			err := checkExpectedDeposit(context.Background(), nil, tt.files...)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkExpectedDeposit() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("checkExpectedDeposit() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
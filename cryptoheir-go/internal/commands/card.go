@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/contract"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// beneficiaryCard is the information a beneficiary needs to find and later
+// claim an inheritance. None of it is secret.
+type beneficiaryCard struct {
+	ChainID       uint64
+	Network       string
+	Contract      common.Address
+	InheritanceID string
+	Beneficiary   common.Address
+	Deadline      string
+	TxHash        common.Hash
+}
+
+// beneficiaryCards returns a card for every InheritanceCreated event in a
+// confirmed receipt whose events were decoded by recordReceiptEvents. Only
+// events emitted by the contract the deposit was sent to count: any contract
+// the call reaches, such as a token, can emit a look-alike event.
+func beneficiaryCards(receipt *types.TxReceipt, chainID uint64, networkName string) []beneficiaryCard {
+	if receipt.Status != 1 || receipt.To == nil {
+		return nil
+	}
+	events, _ := receipt.Metadata["events"].([]types.DecodedEvent)
+
+	var cards []beneficiaryCard
+	for _, e := range events {
+		if e.Name != "InheritanceCreated" {
+			continue
+		}
+		if e.Address != *receipt.To {
+			log.Warn("⚠ Ignoring an InheritanceCreated event from a contract the deposit was not sent to",
+				"emitter", e.Address.Hex(), "contract", receipt.To.Hex())
+			continue
+		}
+		args := make(map[string]string, len(e.Args))
+		for _, a := range e.Args {
+			args[a.Name] = a.Value
+		}
+		cards = append(cards, beneficiaryCard{
+			ChainID:       chainID,
+			Network:       networkName,
+			Contract:      e.Address,
+			InheritanceID: args["inheritanceId"],
+			Beneficiary:   common.HexToAddress(args["beneficiary"]),
+			Deadline:      args["deadline"],
+			TxHash:        receipt.TransactionHash,
+		})
+	}
+	return cards
+}
+
+// payload returns the card as a single line for QR code generators
+func (c beneficiaryCard) payload() string {
+	query := url.Values{}
+	query.Set("chain_id", fmt.Sprint(c.ChainID))
+	query.Set("contract", c.Contract.Hex())
+	query.Set("id", c.InheritanceID)
+	query.Set("beneficiary", c.Beneficiary.Hex())
+	query.Set("deadline", c.Deadline)
+	query.Set("tx", c.TxHash.Hex())
+	return "cryptoheir:inheritance?" + query.Encode()
+}
+
+// text renders the card for printing or handing over as a file
+func (c beneficiaryCard) text() string {
+	deadline := c.Deadline
+	if secs, ok := new(big.Int).SetString(c.Deadline, 10); ok && secs.IsInt64() {
+		deadline = fmt.Sprintf("%s (%s)", c.Deadline, time.Unix(secs.Int64(), 0).UTC().Format(time.RFC3339))
+	}
+
+	var b strings.Builder
+	b.WriteString("CryptoHeir inheritance\n")
+	fmt.Fprintf(&b, "  Inheritance ID: %s\n", c.InheritanceID)
+	fmt.Fprintf(&b, "  Contract:       %s\n", c.Contract.Hex())
+	fmt.Fprintf(&b, "  Chain:          %s (chain ID %d)\n", c.Network, c.ChainID)
+	fmt.Fprintf(&b, "  Beneficiary:    %s\n", c.Beneficiary.Hex())
+	fmt.Fprintf(&b, "  Claimable from: %s\n", deadline)
+	fmt.Fprintf(&b, "  Deposit TX:     %s\n", c.TxHash.Hex())
+	if secs, ok := new(big.Int).SetString(c.Deadline, 10); ok {
+		b.WriteString("\nWho can do what and when:\n")
+		for _, rule := range contract.NewClaimWindow(secs).Rules() {
+			b.WriteString("  - " + rule + "\n")
+		}
+	}
+	b.WriteString("\nVerify these details on-chain with the contract's getInheritance before relying on them.\n")
+	b.WriteString("\nQR payload:\n")
+	b.WriteString(c.payload() + "\n")
+	return b.String()
+}
+
+// writeBeneficiaryCards saves the cards of a confirmed deposit next to its
+// receipt (signed-tx.json -> signed-tx-beneficiary.txt) and prints them
+func writeBeneficiaryCards(receipt *types.TxReceipt, chainID uint64, networkName, inputPath string) {
+	cards := beneficiaryCards(receipt, chainID, networkName)
+	if len(cards) == 0 {
+		log.Warn("No confirmed deposit in the receipt; no beneficiary card written")
+		return
+	}
+
+	texts := make([]string, len(cards))
+	for i, c := range cards {
+		texts[i] = c.text()
+	}
+	content := strings.Join(texts, "\n")
+
+	path := strings.TrimSuffix(inputPath, filepath.Ext(inputPath)) + "-beneficiary.txt"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		log.Warn("Failed to write beneficiary card", "error", err)
+		return
+	}
+	fmt.Print("\n" + content + "\n")
+	log.Info("  Beneficiary card saved", "file", path)
+	log.Info("  Next", "instruction", "Hand the card to the beneficiary, or encode the QR payload line with any QR generator")
+}
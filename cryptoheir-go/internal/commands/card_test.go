@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestBeneficiaryCardsOnlyFromTargetContract(t *testing.T) {
+	contractAddress := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	token := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	created := func(emitter common.Address, id, beneficiary string) types.DecodedEvent {
+		return types.DecodedEvent{
+			Address: emitter,
+			Name:    "InheritanceCreated",
+			Args: []types.DecodedArg{
+				{Name: "inheritanceId", Value: id},
+				{Name: "beneficiary", Value: beneficiary},
+				{Name: "deadline", Value: "1900000000"},
+			},
+		}
+	}
+	receipt := &types.TxReceipt{
+		Status: 1,
+		To:     &contractAddress,
+		Metadata: map[string]interface{}{"events": []types.DecodedEvent{
+			created(token, "99", "0x4444444444444444444444444444444444444444"),
+			{Address: contractAddress, Name: "FeeCollected"},
+			created(contractAddress, "7", "0x2222222222222222222222222222222222222222"),
+		}},
+	}
+
+	cards := beneficiaryCards(receipt, 1, "mainnet")
+	if len(cards) != 1 {
+		t.Fatalf("beneficiaryCards() returned %d cards, want only the one from the contract", len(cards))
+	}
+	c := cards[0]
+	if c.InheritanceID != "7" || c.Contract != contractAddress || c.Beneficiary != common.HexToAddress("0x2222222222222222222222222222222222222222") {
+		t.Errorf("card = %+v", c)
+	}
+
+	receipt.Status = 0
+	if cards := beneficiaryCards(receipt, 1, "mainnet"); cards != nil {
+		t.Errorf("failed transaction: %d cards, want none", len(cards))
+	}
+	receipt.Status, receipt.To = 1, nil
+	if cards := beneficiaryCards(receipt, 1, "mainnet"); cards != nil {
+		t.Errorf("receipt without a recipient: %d cards, want none", len(cards))
+	}
+}
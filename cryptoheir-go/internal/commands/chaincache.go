@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/network"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/store"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// cachedChainID returns the chain ID of the endpoint, from the local cache
+// when it holds a recent entry, skipping the round-trip. Cache failures fall
+// back to asking the node.
+func cachedChainID(ctx context.Context, client *ethclient.Client, rpcURL string, noCache bool) (uint64, error) {
+	if noCache {
+		return network.GetChainID(ctx, client)
+	}
+
+	cache, err := store.LoadChainIDCache()
+	if err != nil {
+		log.Debug("Failed to load chain ID cache", "error", err)
+		return network.GetChainID(ctx, client)
+	}
+	if chainID, ok := cache.Lookup(rpcURL); ok {
+		log.Debug("Using cached chain ID", "chain_id", chainID)
+		return chainID, nil
+	}
+
+	chainID, err := network.GetChainID(ctx, client)
+	if err != nil {
+		return 0, err
+	}
+	recordChainID(cache, rpcURL, chainID)
+	return chainID, nil
+}
+
+// refreshChainID updates the cache with a chain ID that was just fetched from
+// the node, replacing a stale entry that disagrees with it
+func refreshChainID(rpcURL string, chainID uint64) {
+	cache, err := store.LoadChainIDCache()
+	if err != nil {
+		log.Debug("Failed to load chain ID cache", "error", err)
+		return
+	}
+	recordChainID(cache, rpcURL, chainID)
+}
+
+// recordChainID stores a fetched chain ID, warning when the endpoint used to
+// report a different one
+func recordChainID(cache *store.ChainIDCache, rpcURL string, chainID uint64) {
+	if cache.Record(rpcURL, chainID) {
+		log.Warn("RPC endpoint now reports a different chain ID; replaced the cached entry", "chain_id", chainID)
+	}
+	if err := cache.Save(); err != nil {
+		log.Debug("Failed to save chain ID cache", "error", err)
+	}
+}
@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/contract"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/network"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// parseInheritanceID parses a decimal inheritance ID
+func parseInheritanceID(s string) (*big.Int, error) {
+	id, ok := new(big.Int).SetString(strings.TrimSpace(s), 10)
+	if !ok || id.Sign() < 0 {
+		return nil, fmt.Errorf("invalid inheritance ID %q: expected a non-negative integer", s)
+	}
+	return id, nil
+}
+
+// prepareClaim prepares the beneficiary's claim of an inheritance held by
+// CONTRACT_ADDRESS. The signer must be the inheritance's beneficiary and the
+// deadline must have passed, otherwise the gas estimate reverts.
+func prepareClaim(ctx context.Context, client *ethclient.Client, config *types.Config, signerAddress common.Address, nonce uint64, chainID uint64, networkName, rpcURL string) (*types.TxParams, error) {
+	log.Info("Preparing claim transaction...")
+
+	if inheritanceIDFlag == "" {
+		return nil, fmt.Errorf("--inheritance-id is required")
+	}
+	inheritanceID, err := parseInheritanceID(inheritanceIDFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.ContractAddress == nil {
+		return nil, fmt.Errorf("CONTRACT_ADDRESS not set in environment")
+	}
+	contractAddress := *config.ContractAddress
+
+	data, err := contract.EncodeClaim(inheritanceID)
+	if err != nil {
+		return nil, err
+	}
+	if dumpCalldataFlag {
+		return nil, dumpCalldata(&contractAddress, data, nil)
+	}
+
+	// A claim of a settled or unknown inheritance can only revert
+	if err := checkInheritanceOpen(ctx, client, contractAddress, inheritanceID, allowSettledFlag, "--allow-settled"); err != nil {
+		return nil, err
+	}
+
+	gasLimit, err := network.EstimateGas(ctx, client, signerAddress, &contractAddress, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("gas estimation failed (only the beneficiary can claim, once the deadline has passed): %w", err)
+	}
+	log.Info("Estimated gas", "gas", gasLimit.String())
+	if err := checkGasEstimate("claim", gasLimit); err != nil {
+		return nil, err
+	}
+
+	gasPrices, err := getGasPrices(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	txData := types.TransactionData{
+		From:     signerAddress,
+		To:       &contractAddress,
+		Data:     data,
+		Nonce:    nonce,
+		ChainID:  chainID,
+		GasLimit: types.NewBigInt(gasLimit),
+	}
+	applyGasPrices(&txData, gasPrices)
+
+	if delegateFlag != "" {
+		if err := applyDelegation(&txData); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkFeeFields(&txData); err != nil {
+		return nil, err
+	}
+
+	paramsJSON, _ := json.Marshal(map[string]interface{}{
+		"inheritance_id": inheritanceID.String(),
+	})
+
+	txParams := &types.TxParams{
+		Mode:         types.TransactionModeCall,
+		FunctionName: "claim",
+		Params:       paramsJSON,
+		Transaction:  txData,
+		Metadata:     newMetadata(networkName, chainID, rpcURL),
+	}
+
+	log.Info("Claim prepared", "inheritance_id", inheritanceID.String())
+	return txParams, nil
+}
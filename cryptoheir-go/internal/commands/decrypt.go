@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/crypto"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// errEncryptedSignedTx marks failures to open an encrypted signed transaction
+// file, which must not be mistaken for an unrelated file
+var errEncryptedSignedTx = errors.New("encrypted signed transaction")
+
+// decryptionKey is the key loaded with broadcast --decrypt --key, or nil
+var decryptionKey *ecdsa.PrivateKey
+
+// loadDecryptionKey reads a hex private key from a file, or from stdin if
+// path is "-", so that the key stays out of shell history and ps output
+func loadDecryptionKey(path string) (*ecdsa.PrivateKey, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(io.LimitReader(os.Stdin, 4096))
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read decryption key file: %w", err)
+	}
+
+	privateKey, err := ethcrypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid decryption key: %w", err)
+	}
+	log.Info("Decryption key loaded", "address", ethcrypto.PubkeyToAddress(privateKey.PublicKey).Hex())
+	return privateKey, nil
+}
+
+// decryptSignedTxFile returns the signed transaction JSON held in data,
+// decrypting it with the loaded key if the file is encrypted. Plain files are
+// returned unchanged.
+func decryptSignedTxFile(data []byte, path string) ([]byte, error) {
+	var header struct {
+		Encryption string `json:"encryption"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil || header.Encryption == "" {
+		return data, nil
+	}
+	if decryptionKey == nil {
+		return nil, fmt.Errorf("cannot open %w %s without a key; broadcast it with --decrypt --key <key-file>", errEncryptedSignedTx, path)
+	}
+
+	var encrypted types.EncryptedSignedTx
+	if err := json.Unmarshal(data, &encrypted); err != nil {
+		return nil, fmt.Errorf("cannot open %w %s: %w", errEncryptedSignedTx, path, err)
+	}
+	if err := types.CheckSchemaVersion(encrypted.SchemaVersion); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	plaintext, err := crypto.DecryptSignedTx(&encrypted, decryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open %w %s: %w", errEncryptedSignedTx, path, err)
+	}
+	log.Info("Decrypted signed transaction", "file", path)
+	return plaintext, nil
+}
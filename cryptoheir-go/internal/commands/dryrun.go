@@ -0,0 +1,231 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/contract"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/crypto"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/network"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// Outcomes of a dry-run check. Only FAIL is blocking.
+const (
+	dryRunPass = "PASS"
+	dryRunWarn = "WARN"
+	dryRunFail = "FAIL"
+)
+
+// dryRunCheck is the outcome of one pre-broadcast check
+type dryRunCheck struct {
+	status string
+	name   string
+	detail string
+}
+
+// dryRunReport collects the checks of one signed transaction file
+type dryRunReport struct {
+	file   signedTxFile
+	checks []dryRunCheck
+}
+
+func (r *dryRunReport) add(status, name, format string, args ...interface{}) {
+	r.checks = append(r.checks, dryRunCheck{status: status, name: name, detail: fmt.Sprintf(format, args...)})
+}
+
+// addErr records a failed check, if err is set
+func (r *dryRunReport) addErr(name string, err error) {
+	if err != nil {
+		r.add(dryRunFail, name, "%v", err)
+	}
+}
+
+// runDryRunBroadcast runs every pre-broadcast check on the signed
+// transactions and reports whether they would be accepted and are likely to
+// succeed, without submitting anything. Unlike a real broadcast it does not
+// stop at the first problem, so all of them are reported at once.
+func runDryRunBroadcast(files []signedTxFile) error {
+	if len(files) > 1 {
+		var err error
+		if files, err = validateBatch(files); err != nil {
+			return err
+		}
+	}
+	if err := contract.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize contract: %w", err)
+	}
+	log.Info("Dry run: checking without broadcasting", "transactions", len(files))
+
+	reports := make([]*dryRunReport, len(files))
+	for i, f := range files {
+		reports[i] = &dryRunReport{file: f}
+		reports[i].addErr("metadata", checkMetadataNetwork(f))
+		reports[i].addErr("receipt", checkReceiptOverwrite(f))
+	}
+	checkPredictedAddress(files...)
+
+	// Connecting verifies the chain ID; nothing else can be checked without it
+	ctx := context.Background()
+	client, err := connectForBroadcast(ctx, files[0].signedTx)
+	if err != nil {
+		for _, r := range reports {
+			r.add(dryRunFail, "chain ID", "%v", err)
+		}
+		return printDryRun(reports, nil)
+	}
+	defer client.Close()
+
+	checkDryRunTransactions(ctx, client, reports)
+
+	var bundleErr error
+	if err := checkBundleOrder(ctx, client, files...); err != nil {
+		bundleErr = err
+	}
+	return printDryRun(reports, bundleErr)
+}
+
+// checkDryRunTransactions checks the signature, nonce, fees, funds and
+// execution of each transaction, in nonce order
+func checkDryRunTransactions(ctx context.Context, client *ethclient.Client, reports []*dryRunReport) {
+	from := reports[0].file.signedTx.From
+	latest, latestErr := network.GetConfirmedNonce(ctx, client, from)
+	pending, pendingErr := network.GetNonce(ctx, client, from)
+	balance, balanceErr := network.GetBalance(ctx, client, from)
+	baseFee, block, baseFeeErr := network.GetBaseFee(ctx, client)
+
+	expected := pending
+	spent := new(big.Int)
+	for _, r := range reports {
+		f := r.file
+		chainID := f.signedTx.Metadata.Network.ChainID
+
+		r.addErr("schedule", checkBroadcastSchedule(ctx, client, f))
+		r.addErr("deposit", checkExpectedDeposit(ctx, client, f))
+		r.addErr("inheritance", checkSettledInheritances(ctx, client, f))
+
+		// Signature: the raw transaction must recover to the recorded sender
+		signer, tx, err := crypto.RecoverSigner(f.signedTx.SignedTransaction)
+		if err != nil {
+			r.add(dryRunFail, "signature", "%v", err)
+			continue
+		}
+		switch {
+		case signer != f.signedTx.From:
+			r.add(dryRunFail, "signature", "recovers to %s, but the file records the sender %s", signer.Hex(), f.signedTx.From.Hex())
+		case tx.Hash() != f.signedTx.TxHash:
+			r.add(dryRunFail, "signature", "the raw transaction hashes to %s, but the file records %s", tx.Hash().Hex(), f.signedTx.TxHash.Hex())
+		default:
+			r.add(dryRunPass, "signature", "recovers to the sender %s", signer.Hex())
+		}
+
+		if tx.ChainId().Uint64() != chainID {
+			r.add(dryRunFail, "chain ID", "the transaction is signed for chain %s, but the file records chain %d", tx.ChainId(), chainID)
+		} else {
+			r.add(dryRunPass, "chain ID", "connected to chain %d, as signed", chainID)
+		}
+
+		// A known transaction would not be sent again, only waited for
+		if _, isPending, err := network.GetTransaction(ctx, client, f.signedTx.TxHash); err == nil {
+			state := "mined"
+			if isPending {
+				state = "pending"
+			}
+			r.add(dryRunWarn, "status", "already broadcast (%s); broadcast would only wait for its receipt", state)
+			continue
+		}
+
+		nonce := tx.Nonce()
+		switch {
+		case latestErr != nil || pendingErr != nil:
+			r.add(dryRunWarn, "nonce", "could not read the account nonce")
+		case nonce < latest:
+			r.add(dryRunFail, "nonce", "nonce %d is already mined (latest %d); the node will reject it (nonce too low)", nonce, latest)
+		case nonce < pending:
+			r.add(dryRunWarn, "nonce", "nonce %d is used by a pending transaction; this one only replaces it with fees at least %d%% higher", nonce, network.ReplacementBumpPercent)
+		case nonce == expected:
+			r.add(dryRunPass, "nonce", "nonce %d is next in line (latest %d, pending %d)", nonce, latest, pending)
+			expected++
+		default:
+			r.add(dryRunWarn, "nonce", "nonce gap: %d to %d are missing, so the transaction will be queued", expected, nonce-1)
+		}
+
+		switch {
+		case baseFeeErr != nil || baseFee == nil:
+			r.add(dryRunWarn, "fees", "could not read the base fee")
+		case tx.GasFeeCap().Cmp(baseFee) < 0:
+			status := dryRunWarn
+			if broadcastRequireIncludable {
+				status = dryRunFail
+			}
+			r.add(status, "fees", "max fee %s gwei is below the base fee of %s gwei (block %d); not includable until the base fee falls",
+				types.FormatGwei(tx.GasFeeCap()), types.FormatGwei(baseFee), block)
+		default:
+			r.add(dryRunPass, "fees", "max fee %s gwei covers the base fee of %s gwei (block %d)",
+				types.FormatGwei(tx.GasFeeCap()), types.FormatGwei(baseFee), block)
+		}
+
+		// Nodes require the full fee cap and value up front, for the whole batch
+		spent.Add(spent, tx.Cost())
+		if balanceErr != nil {
+			r.add(dryRunWarn, "funds", "could not read the sender's balance")
+		} else if spent.Cmp(balance) > 0 {
+			r.add(dryRunFail, "funds", "needs %s (gas at the max fee plus value) but the sender has %s (insufficient funds)",
+				types.FormatAmount(spent, 18, "ETH"), types.FormatAmount(balance, 18, "ETH"))
+		} else {
+			r.add(dryRunPass, "funds", "balance of %s covers %s", types.FormatAmount(balance, 18, "ETH"), types.FormatAmount(spent, 18, "ETH"))
+		}
+
+		// Simulate against the latest state. Transactions behind unmined
+		// ones may depend on them (an approve before its deposit), so their
+		// reverts are not conclusive.
+		revertData, err := network.SimulateTransaction(ctx, client, from, tx)
+		switch {
+		case err == nil:
+			r.add(dryRunPass, "simulation", "eth_call succeeded against the latest block")
+		case latestErr == nil && nonce > latest:
+			r.add(dryRunWarn, "simulation", "reverts against the latest block (%s), but %d earlier transaction(s) from the sender run first",
+				revertReason(revertData, err), nonce-latest)
+		default:
+			r.add(dryRunFail, "simulation", "reverts: %s", revertReason(revertData, err))
+		}
+	}
+}
+
+// revertReason decodes revert data with the contract's errors, falling back
+// to the node's message
+func revertReason(revertData []byte, err error) string {
+	if len(revertData) >= 4 {
+		return contract.DecodeContractError(revertData)
+	}
+	return err.Error()
+}
+
+// printDryRun prints each transaction's checks and the overall verdict,
+// returning an error when any check is blocking
+func printDryRun(reports []*dryRunReport, bundleErr error) error {
+	blocking := 0
+	for _, r := range reports {
+		fmt.Printf("\nDry run: %s (nonce %d, %s)\n", r.file.path, r.file.signedTx.Nonce(), r.file.signedTx.TxHash.Hex())
+		for _, c := range r.checks {
+			fmt.Printf("  %-4s  %-11s  %s\n", c.status, c.name, c.detail)
+			if c.status == dryRunFail {
+				blocking++
+			}
+		}
+	}
+	if bundleErr != nil {
+		fmt.Printf("\n  %-4s  %-11s  %v\n", dryRunFail, "bundle", bundleErr)
+		blocking++
+	}
+
+	fmt.Println()
+	if blocking > 0 {
+		fmt.Printf("Result: FAIL - %d blocking issue(s); nothing was broadcast\n\n", blocking)
+		return fmt.Errorf("dry run failed with %d blocking issue(s)", blocking)
+	}
+	fmt.Print("Result: PASS - the transaction(s) would be accepted and are expected to succeed; nothing was broadcast\n\n")
+	return nil
+}
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/contract"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/network"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// isENSName reports whether an address argument is an ENS name instead of a
+// hex address
+func isENSName(s string) bool {
+	return strings.Contains(s, ".") && !strings.HasPrefix(strings.ToLower(s), "0x")
+}
+
+// resolveENS resolves an ENS name to its address record through the ENS
+// registry on the connected chain. Returns the normalized name.
+func resolveENS(ctx context.Context, client *ethclient.Client, name string) (common.Address, string, error) {
+	normalized, err := contract.NormalizeENSName(name)
+	if err != nil {
+		return common.Address{}, "", err
+	}
+	node := contract.Namehash(normalized)
+
+	resolver, err := callENS(ctx, client, contract.ENSRegistryAddress, "resolver", node)
+	if err != nil {
+		return common.Address{}, "", fmt.Errorf("failed to look up ENS resolver for %s (is ENS deployed on this chain?): %w", normalized, err)
+	}
+	if resolver == (common.Address{}) {
+		return common.Address{}, "", fmt.Errorf("ENS name %s is not registered or has no resolver", normalized)
+	}
+
+	addr, err := callENS(ctx, client, resolver, "addr", node)
+	if err != nil {
+		return common.Address{}, "", fmt.Errorf("failed to resolve ENS name %s: %w", normalized, err)
+	}
+	if addr == (common.Address{}) {
+		return common.Address{}, "", fmt.Errorf("ENS name %s has no address record", normalized)
+	}
+
+	return addr, normalized, nil
+}
+
+// callENS performs a read-only ENS call returning an address
+func callENS(ctx context.Context, client *ethclient.Client, to common.Address, method string, node common.Hash) (common.Address, error) {
+	data, err := contract.EncodeENSCall(method, node)
+	if err != nil {
+		return common.Address{}, err
+	}
+	result, err := network.CallContract(ctx, client, to, data)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return contract.DecodeENSAddress(method, result)
+}
+
+// recordENSName stores a resolved name in the metadata so the offline signer
+// can show it next to the address without network access
+func recordENSName(txParams *types.TxParams, name string, addr common.Address) {
+	if txParams.Metadata.AdditionalInfo == nil {
+		txParams.Metadata.AdditionalInfo = make(map[string]interface{})
+	}
+	names, ok := txParams.Metadata.AdditionalInfo["ens_names"].(map[string]string)
+	if !ok {
+		names = make(map[string]string)
+		txParams.Metadata.AdditionalInfo["ens_names"] = names
+	}
+	names[addr.Hex()] = name
+	txParams.Metadata.AdditionalInfo["ens_resolved_at"] = time.Now().UTC().Format(time.RFC3339)
+}
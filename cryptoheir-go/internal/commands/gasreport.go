@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/network"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// printGasReport prints the gas estimates for a prepared transaction side by
+// side, marking the limit that was chosen. The report is informational only;
+// failures are logged and never change the prepared transaction.
+func printGasReport(ctx context.Context, client *ethclient.Client, txParams *types.TxParams) {
+	tx := txParams.Transaction
+	report, err := network.GetGasReport(ctx, client, tx.From, tx.To, tx.Data, tx.Value.ToBigInt())
+	if err != nil {
+		log.Warn("Gas report unavailable", "error", err)
+		return
+	}
+	chosen := tx.GasLimit.ToBigInt().Uint64()
+
+	// mark highlights the row whose value is the chosen limit
+	mark := func(gas uint64) string {
+		if gas == chosen {
+			return fmt.Sprintf("%-12d <- chosen", gas)
+		}
+		return fmt.Sprintf("%d", gas)
+	}
+
+	fmt.Printf("\nGas report (%s):\n", operationName(txParams.Mode, txParams.FunctionName))
+	fmt.Printf("  %-28s %-12s %s\n", "", "ESTIMATE", "+20% BUFFER")
+	fmt.Printf("  %-28s %-12d %s\n", "eth_estimateGas", report.Estimate, mark(report.Buffered))
+
+	if report.AccessListErr != nil {
+		fmt.Printf("  %-28s %s\n", "with access list", "unavailable: "+report.AccessListErr.Error())
+	} else {
+		label := fmt.Sprintf("with access list (%d)", report.AccessListEntries)
+		fmt.Printf("  %-28s %-12d %s\n", label, report.AccessListEstimate, mark(report.AccessListBuffered))
+	}
+
+	if chosen != report.Buffered && chosen != report.AccessListBuffered {
+		fmt.Printf("  %-28s %-12s %-12d <- chosen\n", "prepared gas limit", "", chosen)
+	}
+
+	fmt.Printf("  The buffer adds %d gas.", report.Buffered-report.Estimate)
+	if report.AccessListErr == nil {
+		saving := int64(report.Estimate) - int64(report.AccessListEstimate)
+		if saving > 0 {
+			fmt.Printf(" An access list would save %d gas, but the prepared transaction does not include one.", saving)
+		} else {
+			fmt.Print(" An access list would not save gas.")
+		}
+	}
+	fmt.Print("\n\n")
+}
@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/store"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// HistoryCmd represents the history command
+var HistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the local journal of prepared, signed and broadcast transactions",
+	Long: `Print the local transaction journal, oldest first.
+
+prepare, sign, broadcast and status append an entry for every transaction they
+handle. The journal is kept in ~/.cryptoheir/cryptoheir-journal.jsonl unless
+CRYPTOHEIR_JOURNAL points elsewhere.`,
+	Args: cobra.NoArgs,
+	RunE: runHistory,
+}
+
+var (
+	historyNetworkFlag string
+	historyStatusFlag  string
+	historyLabelFlag   string
+)
+
+func init() {
+	HistoryCmd.Flags().StringVar(&historyNetworkFlag, "network", "", "Only show entries for this network")
+	HistoryCmd.Flags().StringVar(&historyStatusFlag, "status", "", "Only show entries with this status (prepared, signed, submitted, success, failed, error)")
+	HistoryCmd.Flags().StringVar(&historyLabelFlag, "label", "", "Only show entries with this label (prepare --label)")
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	entries, err := store.ReadJournal()
+	if err != nil {
+		return err
+	}
+
+	path, _ := store.JournalPath()
+	shown := 0
+	for _, e := range entries {
+		if historyNetworkFlag != "" && !strings.EqualFold(e.Network, historyNetworkFlag) {
+			continue
+		}
+		if historyStatusFlag != "" && !strings.EqualFold(e.Status, historyStatusFlag) {
+			continue
+		}
+		if historyLabelFlag != "" && !strings.EqualFold(e.Label, historyLabelFlag) {
+			continue
+		}
+
+		if shown == 0 {
+			fmt.Printf("%-20s  %-9s  %-10s  %-10s  %5s  %-9s  %-66s  %-16s  %s\n",
+				"TIME", "EVENT", "OPERATION", "NETWORK", "NONCE", "STATUS", "TX HASH", "LABEL", "FILE")
+		}
+		hash := "-"
+		if e.TxHash != nil {
+			hash = e.TxHash.Hex()
+		}
+		label := "-"
+		if e.Label != "" {
+			label = e.Label
+		}
+		line := fmt.Sprintf("%-20s  %-9s  %-10s  %-10s  %5d  %-9s  %-66s  %-16s  %s",
+			e.Time, e.Event, e.Operation, e.Network, e.Nonce, e.Status, hash, label, e.File)
+		fmt.Println(strings.TrimRight(line, " "))
+		if e.Error != "" {
+			fmt.Printf("  error: %s\n", e.Error)
+		}
+		shown++
+	}
+
+	switch {
+	case len(entries) == 0:
+		fmt.Printf("No journal entries found in %s\n", path)
+	case shown == 0:
+		fmt.Println("No journal entries match the filters")
+	}
+
+	return nil
+}
+
+// journal appends an entry to the local journal. Failures are logged but
+// never interrupt the command itself.
+func journal(entry store.JournalEntry) {
+	if err := store.AppendJournal(entry); err != nil {
+		log.Warn("Failed to record journal entry", "error", err)
+	}
+}
+
+// journalTxParams records an event for a prepared transaction
+func journalTxParams(event, status, file string, txParams *types.TxParams) {
+	journal(store.JournalEntry{
+		Event:     event,
+		Operation: operationName(txParams.Mode, txParams.FunctionName),
+		Network:   txParams.Metadata.Network.Name,
+		ChainID:   txParams.Transaction.ChainID,
+		From:      txParams.Transaction.From,
+		Nonce:     txParams.Transaction.Nonce,
+		File:      file,
+		Label:     txParams.Metadata.Label,
+		Status:    status,
+	})
+}
+
+// journalSignedTx records an event for a signed transaction, with the error
+// message when the event failed
+func journalSignedTx(event, status, file string, signedTx *types.SignedTx, err error) {
+	hash := signedTx.TxHash
+	entry := store.JournalEntry{
+		Event:     event,
+		Operation: operationName(signedTx.Mode, signedTx.FunctionName),
+		Network:   signedTx.Metadata.Network.Name,
+		ChainID:   signedTx.Metadata.Network.ChainID,
+		From:      signedTx.From,
+		Nonce:     signedTx.Nonce(),
+		TxHash:    &hash,
+		File:      file,
+		Label:     signedTx.Metadata.Label,
+		Status:    status,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	journal(entry)
+}
+
+// operationName names the operation of a transaction for the journal
+func operationName(mode types.TransactionMode, functionName string) string {
+	if functionName != "" {
+		return functionName
+	}
+	return string(mode)
+}
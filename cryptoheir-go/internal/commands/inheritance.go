@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/contract"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/network"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// checkInheritanceOpen reads an inheritance before a claim, reclaim or
+// deadline extension is sent to it. All three revert once the inheritance is
+// settled (claimed or reclaimed) or if it does not exist. With allowSettled,
+// set by the command's flag of that name, a settled inheritance is only
+// reported.
+func checkInheritanceOpen(ctx context.Context, client *ethclient.Client, contractAddress common.Address, inheritanceID *big.Int, allowSettled bool, flag string) error {
+	data, err := contract.EncodeGetInheritance(inheritanceID)
+	if err != nil {
+		return err
+	}
+	result, err := network.CallContract(ctx, client, contractAddress, data)
+	if err != nil {
+		return fmt.Errorf("failed to read inheritance %s: %w", inheritanceID, err)
+	}
+	inheritance, err := contract.DecodeInheritance(result)
+	if err != nil {
+		return err
+	}
+
+	if inheritance.Depositor == (common.Address{}) {
+		return fmt.Errorf("inheritance %s does not exist on contract %s", inheritanceID, contractAddress.Hex())
+	}
+	if !inheritance.Claimed {
+		log.Info("  ✓ Inheritance is open", "inheritance_id", inheritanceID.String(), "deadline", inheritance.Deadline.String())
+		return nil
+	}
+
+	settled := fmt.Sprintf("inheritance %s was already settled", inheritanceID)
+	if how, block, ok := findSettlement(ctx, client, contractAddress, inheritanceID); ok {
+		settled = fmt.Sprintf("inheritance %s was already %s on block %d", inheritanceID, how, block)
+	}
+	if allowSettled {
+		log.Warn("⚠ "+settled+"; the transaction will revert ("+flag+")", "inheritance_id", inheritanceID.String())
+		return nil
+	}
+	return fmt.Errorf("%s; the transaction would revert (use %s to continue anyway)", settled, flag)
+}
+
+// findSettlement looks up the event that settled an inheritance and returns
+// "claimed" or "reclaimed" with its block. The log query starts at the
+// inheritance's deposit block, since nodes refuse a query from genesis. Nodes
+// without archive state or that limit log queries make this fail, in which
+// case ok is false.
+func findSettlement(ctx context.Context, client *ethclient.Client, contractAddress common.Address, inheritanceID *big.Int) (string, uint64, bool) {
+	claimedID, reclaimedID, err := contract.SettledEventIDs()
+	if err != nil {
+		return "", 0, false
+	}
+	from, err := depositBlock(ctx, client, contractAddress, inheritanceID)
+	if err != nil {
+		log.Debug("Could not find the deposit block", "error", err)
+		return "", 0, false
+	}
+	logs, err := client.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		Addresses: []common.Address{contractAddress},
+		Topics:    [][]common.Hash{{claimedID, reclaimedID}, {common.BigToHash(inheritanceID)}},
+	})
+	if err != nil {
+		log.Debug("Could not look up the settlement event", "error", err)
+		return "", 0, false
+	}
+	for _, l := range logs {
+		if l.Removed {
+			continue
+		}
+		if l.Topics[0] == claimedID {
+			return "claimed", l.BlockNumber, true
+		}
+		return "reclaimed", l.BlockNumber, true
+	}
+	return "", 0, false
+}
+
+// depositBlock finds the block that created an inheritance: the first block
+// whose state has nextInheritanceId past the ID. It bisects over historical
+// state, about 25 calls on mainnet.
+func depositBlock(ctx context.Context, client *ethclient.Client, contractAddress common.Address, inheritanceID *big.Int) (uint64, error) {
+	data, err := contract.EncodeNextInheritanceID()
+	if err != nil {
+		return 0, err
+	}
+	created := func(block uint64) (bool, error) {
+		result, err := network.CallContractAt(ctx, client, contractAddress, data, new(big.Int).SetUint64(block))
+		if err != nil {
+			return false, err
+		}
+		if len(result) == 0 {
+			// No code yet at this block
+			return false, nil
+		}
+		next, err := contract.DecodeNextInheritanceID(result)
+		if err != nil {
+			return false, err
+		}
+		return next.Cmp(inheritanceID) > 0, nil
+	}
+
+	latest, err := client.BlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if ok, err := created(latest); err != nil {
+		return 0, err
+	} else if !ok {
+		return 0, fmt.Errorf("inheritance %s does not exist at block %d", inheritanceID, latest)
+	}
+
+	low, high := uint64(0), latest
+	for low < high {
+		mid := low + (high-low)/2
+		ok, err := created(mid)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+	return low, nil
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/contract"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// historyNode serves eth_blockNumber and eth_call of nextInheritanceId
+// against a fixed history: no contract before block 100, then 5 deposits
+// made by block 100 and 3 more by block 500, at a latest block of 1000
+func historyNode(t *testing.T) *ethclient.Client {
+	t.Helper()
+	return stubNode(t, func(method string, params []json.RawMessage) interface{} {
+		switch method {
+		case "eth_blockNumber":
+			return "0x3e8"
+		case "eth_call":
+			var tag string
+			json.Unmarshal(params[1], &tag)
+			block, err := hexutil.DecodeUint64(tag)
+			if err != nil {
+				t.Errorf("eth_call at %q, want a block number", tag)
+			}
+			switch {
+			case block < 100:
+				return "0x"
+			case block < 500:
+				return hexutil.Encode(common.BigToHash(big.NewInt(5)).Bytes())
+			default:
+				return hexutil.Encode(common.BigToHash(big.NewInt(8)).Bytes())
+			}
+		}
+		return nil
+	})
+}
+
+func TestDepositBlock(t *testing.T) {
+	if err := contract.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	client := historyNode(t)
+	contractAddress := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	for id, want := range map[int64]uint64{0: 100, 4: 100, 5: 500, 7: 500} {
+		got, err := depositBlock(context.Background(), client, contractAddress, big.NewInt(id))
+		if err != nil {
+			t.Errorf("depositBlock(%d) error = %v", id, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("depositBlock(%d) = %d, want %d", id, got, want)
+		}
+	}
+
+	if _, err := depositBlock(context.Background(), client, contractAddress, big.NewInt(8)); err == nil {
+		t.Error("depositBlock() of an inheritance that does not exist: no error")
+	}
+}
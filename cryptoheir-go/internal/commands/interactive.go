@@ -0,0 +1,274 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/contract"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/network"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/store"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/tui"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// tokenInfo describes the ERC20 token used for a deposit
+type tokenInfo struct {
+	Decimals uint8
+	Symbol   string
+}
+
+// fetchTokenInfo reads the token's decimals and symbol. Both are optional in
+// ERC20, but guessing the decimals would scale the amount by orders of
+// magnitude, so a token without decimals() needs them given explicitly
+// (decimals >= 0). Explicit decimals must match the token's when it has them.
+// A missing symbol falls back to an empty one.
+func fetchTokenInfo(ctx context.Context, client *ethclient.Client, token common.Address, decimals int) (*tokenInfo, error) {
+	if decimals > 255 {
+		return nil, fmt.Errorf("invalid token decimals %d: must be between 0 and 255", decimals)
+	}
+	info := &tokenInfo{}
+
+	onChain, err := callERC20(ctx, client, token, "decimals")
+	switch {
+	case err == nil && decimals >= 0 && int(onChain.(uint8)) != decimals:
+		return nil, fmt.Errorf("token %s has %d decimals, but %d were given", token.Hex(), onChain.(uint8), decimals)
+	case err == nil:
+		info.Decimals = onChain.(uint8)
+	case decimals >= 0:
+		log.Warn("⚠ Could not read token decimals; using the given decimals", "token", token.Hex(), "decimals", decimals, "error", err)
+		info.Decimals = uint8(decimals)
+	default:
+		return nil, fmt.Errorf("failed to read the decimals of token %s: %w", token.Hex(), err)
+	}
+
+	if symbol, err := callERC20(ctx, client, token, "symbol"); err == nil {
+		info.Symbol = symbol.(string)
+	}
+
+	return info, nil
+}
+
+// prepareTokenInfo reads the token for prepare, with the decimals given by
+// --token-decimals
+func prepareTokenInfo(ctx context.Context, client *ethclient.Client, token common.Address) (*tokenInfo, error) {
+	info, err := fetchTokenInfo(ctx, client, token, tokenDecimalsFlag)
+	if err != nil && tokenDecimalsFlag < 0 {
+		return nil, fmt.Errorf("%w (pass --token-decimals if the token has no decimals() function)", err)
+	}
+	return info, err
+}
+
+// callERC20 performs a read-only ERC20 call and decodes its single result
+func callERC20(ctx context.Context, client *ethclient.Client, token common.Address, method string, args ...interface{}) (interface{}, error) {
+	data, err := contract.EncodeERC20Call(method, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := network.CallContract(ctx, client, token, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return contract.DecodeERC20Result(method, result)
+}
+
+// unitName returns the display name for amounts of the token (or ETH)
+func (t *tokenInfo) unitName() string {
+	if t == nil {
+		return "ETH"
+	}
+	if t.Symbol == "" {
+		return "tokens"
+	}
+	return t.Symbol
+}
+
+// parseDeadline parses a deadline given as a Unix timestamp, an RFC3339 time,
+// a local date (2030-01-31) or date and time (2030-01-31 12:00), or an offset
+// from now in days, weeks or years (+30d, +2w, +1y)
+func parseDeadline(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("deadline is required")
+	}
+
+	if strings.HasPrefix(s, "+") && len(s) > 2 {
+		n, err := strconv.Atoi(s[1 : len(s)-1])
+		if err != nil || n <= 0 {
+			return time.Time{}, fmt.Errorf("invalid offset %q (use e.g. +30d, +2w, +1y)", s)
+		}
+		now := time.Now()
+		switch s[len(s)-1] {
+		case 'd':
+			return now.AddDate(0, 0, n), nil
+		case 'w':
+			return now.AddDate(0, 0, 7*n), nil
+		case 'y':
+			return now.AddDate(n, 0, 0), nil
+		}
+		return time.Time{}, fmt.Errorf("invalid offset %q (use e.g. +30d, +2w, +1y)", s)
+	}
+
+	if unix, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(unix, 0), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized deadline %q (use a Unix timestamp, YYYY-MM-DD, YYYY-MM-DD HH:MM, RFC3339 or +30d)", s)
+}
+
+// promptDepositFlags runs the deposit wizard for any required deposit flags
+// that were not given on the command line
+func promptDepositFlags(ctx context.Context, client *ethclient.Client, config *types.Config, signerAddress common.Address, chainID uint64) error {
+	if beneficiaryFlag != "" && amountFlag != "" && deadlineFlag != 0 {
+		return nil
+	}
+	if !tui.IsTerminal() {
+		return fmt.Errorf("--interactive requires a terminal; pass --beneficiary, --amount and --deadline instead")
+	}
+	if config.ContractAddress == nil {
+		return fmt.Errorf("CONTRACT_ADDRESS not set in environment")
+	}
+	contractAddress := *config.ContractAddress
+
+	var token *tokenInfo
+	if tokenFlag != "" {
+		tokenAddr, _, err := parseAddressArg(tokenFlag)
+		if err != nil {
+			return fmt.Errorf("invalid token: %w", err)
+		}
+		if token, err = prepareTokenInfo(ctx, client, tokenAddr); err != nil {
+			return err
+		}
+	}
+
+	var history *store.BeneficiaryHistory
+	if !noHistoryFlag {
+		if h, err := store.LoadBeneficiaries(); err == nil {
+			history = h
+		}
+	}
+
+	var fields []tui.WizardField
+	var targets []func(string)
+
+	if beneficiaryFlag == "" {
+		fields = append(fields, tui.WizardField{
+			Label:       "Beneficiary address",
+			Help:        "The address that can claim the funds after the deadline. Verify it from a trusted source.",
+			Placeholder: "0x... or @label",
+			Validate: func(s string) (string, error) {
+				var beneficiary common.Address
+				var ensName, label string
+				var err error
+				if isAddressBookLabel(s) {
+					beneficiary, label, err = resolveAddressBook(s)
+				} else if isENSName(s) {
+					beneficiary, ensName, err = resolveENS(ctx, client, s)
+				} else {
+					beneficiary, err = types.ParseAddress(s)
+				}
+				if err != nil {
+					return "", err
+				}
+				display := beneficiary.Hex()
+				switch {
+				case label != "":
+					display = fmt.Sprintf("%s (@%s)", beneficiary.Hex(), label)
+				case ensName != "":
+					display = fmt.Sprintf("%s (%s)", beneficiary.Hex(), ensName)
+				}
+				if err := validateBeneficiary(beneficiary, contractAddress, signerAddress); err != nil {
+					return "", err
+				}
+
+				switch {
+				case history == nil:
+					return display, nil
+				case history.Known(chainID, beneficiary):
+					return display + " (previously used on this chain)", nil
+				case len(history.Lookalikes(chainID, beneficiary)) > 0:
+					return display + " ⚠ RESEMBLES A PREVIOUSLY USED ADDRESS - check every character", nil
+				}
+				return display + " (new beneficiary)", nil
+			},
+		})
+		targets = append(targets, func(v string) { beneficiaryFlag = v })
+	}
+
+	if amountFlag == "" {
+		decimals := uint8(18)
+		if token != nil {
+			decimals = token.Decimals
+		}
+		fields = append(fields, tui.WizardField{
+			Label:       fmt.Sprintf("Amount (%s)", token.unitName()),
+			Help:        fmt.Sprintf("Up to %d decimal places. A 0.1%% deposit fee is deducted.", decimals),
+			Placeholder: "1.5",
+			Validate: func(s string) (string, error) {
+				amount, err := types.ParseUnits(s, decimals)
+				if err != nil {
+					return "", err
+				}
+				if amount.Sign() == 0 {
+					return "", fmt.Errorf("amount must be greater than zero")
+				}
+				return fmt.Sprintf("%s %s (%s base units)", s, token.unitName(), amount.String()), nil
+			},
+		})
+		targets = append(targets, func(v string) { amountFlag = v })
+	}
+
+	if deadlineFlag == 0 {
+		fields = append(fields, tui.WizardField{
+			Label:       "Deadline",
+			Help:        "When the beneficiary can claim: YYYY-MM-DD, YYYY-MM-DD HH:MM, RFC3339, Unix timestamp or +30d/+2w/+1y.",
+			Placeholder: "+1y",
+			Validate: func(s string) (string, error) {
+				deadline, err := parseDeadline(s)
+				if err != nil {
+					return "", err
+				}
+				if !deadline.After(time.Now()) {
+					return "", fmt.Errorf("deadline must be in the future")
+				}
+				return fmt.Sprintf("%s (%s UTC, Unix %d)",
+					deadline.Local().Format("Mon, 02 Jan 2006 15:04 MST"),
+					deadline.UTC().Format("2006-01-02 15:04"),
+					deadline.Unix()), nil
+			},
+		})
+		targets = append(targets, func(v string) {
+			deadline, _ := parseDeadline(v)
+			deadlineFlag = deadline.Unix()
+		})
+	}
+
+	values, ok, err := tui.RunWizard("CryptoHeir Deposit", fields)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("deposit cancelled by user")
+	}
+
+	for i, v := range values {
+		targets[i](v)
+	}
+
+	return nil
+}
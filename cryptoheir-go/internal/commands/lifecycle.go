@@ -0,0 +1,259 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/contract"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/network"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/spf13/cobra"
+)
+
+// EstimateLifecycleCmd represents the estimate-lifecycle command
+var EstimateLifecycleCmd = &cobra.Command{
+	Use:   "estimate-lifecycle",
+	Short: "Estimate the total cost of an inheritance: deploy, deposit and claim",
+	Long: `Estimate the gas for each step of an inheritance's lifecycle at current
+prices: deploying the contract (when CONTRACT_ADDRESS has no contract yet),
+the native deposit, and the beneficiary's eventual claim. The protocol fees
+kept by the contract are shown as well.
+
+Nothing is signed or written. A claim can only be simulated once a deposit
+has reached its deadline, so its gas is a representative figure, and all
+costs use today's prices; the real cost of a claim years from now will
+differ. Each step's gas can be overridden with --deploy-gas, --deposit-gas
+and --claim-gas.`,
+	Args: cobra.NoArgs,
+	RunE: runEstimateLifecycle,
+}
+
+// representativeClaimGas is the gas limit assumed for a native claim: the
+// base transaction, the reentrancy guard, reading and updating the
+// inheritance, and two value transfers, with the usual 20% buffer
+const representativeClaimGas = 80000
+
+var (
+	lifecycleNetworkFlag     string
+	lifecycleRPCURLFlag      string
+	lifecycleRPCURLFile      string
+	lifecycleAmountFlag      string
+	lifecycleBeneficiaryFlag string
+	lifecycleIncludeDeploy   bool
+	lifecycleDeployGas       uint64
+	lifecycleDepositGas      uint64
+	lifecycleClaimGas        uint64
+)
+
+func init() {
+	EstimateLifecycleCmd.Flags().StringVar(&lifecycleNetworkFlag, "network", "sepolia", "Network name (sepolia, mainnet, etc.)")
+	EstimateLifecycleCmd.Flags().StringVar(&lifecycleRPCURLFlag, "rpc-url", "", "Custom RPC URL (overrides network)")
+	EstimateLifecycleCmd.Flags().StringVar(&lifecycleRPCURLFile, "rpc-url-file", "", "Read the custom RPC URL from a file ('-' for stdin)")
+	EstimateLifecycleCmd.Flags().StringVar(&rpcSelectFlag, "rpc-select", rpcSelectFirst, rpcSelectUsage)
+	EstimateLifecycleCmd.Flags().StringVar(&lifecycleAmountFlag, "amount", "1", "Deposit amount in ETH, used for the deposit simulation and the protocol fees")
+	EstimateLifecycleCmd.Flags().StringVar(&lifecycleBeneficiaryFlag, "beneficiary", "", "Beneficiary address or @label for the deposit simulation (default: a placeholder address)")
+	EstimateLifecycleCmd.Flags().BoolVar(&lifecycleIncludeDeploy, "include-deploy", false, "Include the deployment even when CONTRACT_ADDRESS already has a contract")
+	EstimateLifecycleCmd.Flags().Uint64Var(&lifecycleDeployGas, "deploy-gas", 0, "Use this gas for the deployment instead of estimating it")
+	EstimateLifecycleCmd.Flags().Uint64Var(&lifecycleDepositGas, "deposit-gas", 0, "Use this gas for the deposit instead of estimating it")
+	EstimateLifecycleCmd.Flags().Uint64Var(&lifecycleClaimGas, "claim-gas", 0, fmt.Sprintf("Use this gas for the claim instead of the representative %d", representativeClaimGas))
+}
+
+// lifecycleStep is the gas of one step and where it came from
+type lifecycleStep struct {
+	name   string
+	gas    uint64
+	source string
+}
+
+// placeholderBeneficiary stands in for the beneficiary when simulating a
+// deposit without --beneficiary
+var placeholderBeneficiary = common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+
+func runEstimateLifecycle(cmd *cobra.Command, args []string) error {
+	config, err := types.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	amount, err := parseEther(lifecycleAmountFlag)
+	if err != nil {
+		return fmt.Errorf("invalid --amount: %w", err)
+	}
+	if amount.Sign() <= 0 {
+		return fmt.Errorf("--amount must be positive")
+	}
+	if err := contract.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize contract: %w", err)
+	}
+
+	rpcURL, err := customRPCURL(lifecycleRPCURLFlag, lifecycleRPCURLFile)
+	if err != nil {
+		return err
+	}
+	if rpcURL == "" {
+		rpcURL, err = network.GetRPCURL(lifecycleNetworkFlag, config.InfuraAPIKey)
+		if err != nil {
+			return fmt.Errorf("failed to get RPC URL: %w", err)
+		}
+	}
+
+	ctx := context.Background()
+	client, err := network.CreateClient(ctx, rpcURL)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	chainID, err := cachedChainID(ctx, client, rpcURL, false)
+	if err != nil {
+		return err
+	}
+
+	// Estimates run from the signer when it is known
+	var from common.Address
+	if config.SignerAddress != nil {
+		from = *config.SignerAddress
+	}
+
+	// The deployment is part of the lifecycle until a contract exists
+	deployed := false
+	if config.ContractAddress != nil {
+		code, err := network.GetCode(ctx, client, *config.ContractAddress)
+		if err != nil {
+			return err
+		}
+		deployed = len(code) > 0
+	}
+
+	var steps []lifecycleStep
+	if !deployed || lifecycleIncludeDeploy {
+		deploy, err := estimateDeployStep(ctx, client, chainID, from)
+		if err != nil {
+			return err
+		}
+		steps = append(steps, deploy)
+	}
+	steps = append(steps, estimateDepositStep(ctx, client, config, deployed, from, amount))
+	claim := lifecycleStep{name: "claim", gas: representativeClaimGas, source: "representative"}
+	if lifecycleClaimGas > 0 {
+		claim.gas, claim.source = lifecycleClaimGas, "override"
+	}
+	steps = append(steps, claim)
+
+	current, maxFee, err := lifecyclePrices(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nLifecycle cost estimate (chain %d, at prices of %s):\n", chainID, time.Now().UTC().Format(time.RFC3339))
+	fmt.Printf("  %-8s %-10s %-15s %-26s %s\n", "STEP", "GAS", "SOURCE", "COST (CURRENT PRICE)", "COST (MAX FEE)")
+	var totalGas uint64
+	for _, s := range steps {
+		totalGas += s.gas
+		fmt.Printf("  %-8s %-10d %-15s %-26s %s\n", s.name, s.gas, s.source,
+			types.FormatAmount(gasCost(s.gas, current), 18, "ETH"), types.FormatAmount(gasCost(s.gas, maxFee), 18, "ETH"))
+	}
+	fmt.Printf("  %-8s %-10d %-15s %-26s %s\n", "total", totalGas, "",
+		types.FormatAmount(gasCost(totalGas, current), 18, "ETH"), types.FormatAmount(gasCost(totalGas, maxFee), 18, "ETH"))
+	fmt.Printf("  Prices: current %s gwei, max fee %s gwei per gas\n", types.FormatGwei(current), types.FormatGwei(maxFee))
+
+	depositFee := contract.DepositFee(amount)
+	held := new(big.Int).Sub(amount, depositFee)
+	claimFee := contract.ClaimFee(held)
+	received := new(big.Int).Sub(held, claimFee)
+	fmt.Printf("\nProtocol fees for a %s deposit:\n", types.FormatAmount(amount, 18, "ETH"))
+	fmt.Printf("  %-30s %s\n", "deposit fee (0.1%)", types.FormatAmount(depositFee, 18, "ETH"))
+	fmt.Printf("  %-30s %s\n", "claim fee (1% of held amount)", types.FormatAmount(claimFee, 18, "ETH"))
+	fmt.Printf("  %-30s %s\n", "beneficiary receives", types.FormatAmount(received, 18, "ETH"))
+
+	fmt.Println("\nThe claim is paid for by the beneficiary, possibly years from now. Its gas is")
+	fmt.Println("a representative figure and every cost uses current prices, so treat the")
+	fmt.Print("totals as an estimate. Gas figures include the 20% buffer prepare applies.\n\n")
+	return nil
+}
+
+// estimateDeployStep estimates the deployment of the embedded contract
+func estimateDeployStep(ctx context.Context, client *ethclient.Client, chainID uint64, from common.Address) (lifecycleStep, error) {
+	step := lifecycleStep{name: "deploy"}
+	if lifecycleDeployGas > 0 {
+		step.gas, step.source = lifecycleDeployGas, "override"
+		return step, nil
+	}
+	bytecode, err := contract.LoadBytecode()
+	if err != nil {
+		return step, err
+	}
+	if _, err := checkInitcodeSize(ctx, client, chainID, bytecode); err != nil {
+		return step, err
+	}
+	gas, err := network.EstimateGas(ctx, client, from, nil, bytecode, nil)
+	if err != nil {
+		return step, fmt.Errorf("failed to estimate the deployment (set it with --deploy-gas): %w", err)
+	}
+	step.gas, step.source = gas.Uint64(), "estimated"
+	return step, nil
+}
+
+// estimateDepositStep simulates a native deposit into CONTRACT_ADDRESS. When
+// no contract is deployed yet, or the simulation fails (e.g. the signer lacks
+// the amount), prepare's default deposit gas is used.
+func estimateDepositStep(ctx context.Context, client *ethclient.Client, config *types.Config, deployed bool, from common.Address, amount *big.Int) lifecycleStep {
+	step := lifecycleStep{name: "deposit", gas: defaultGasLimits["deposit"], source: "default"}
+	if lifecycleDepositGas > 0 {
+		step.gas, step.source = lifecycleDepositGas, "override"
+		return step
+	}
+	if !deployed {
+		return step
+	}
+
+	beneficiary := placeholderBeneficiary
+	if lifecycleBeneficiaryFlag != "" {
+		addr, _, err := parseAddressArg(lifecycleBeneficiaryFlag)
+		if err != nil {
+			log.Warn("⚠ Invalid --beneficiary; using a placeholder", "error", err)
+		} else {
+			beneficiary = addr
+		}
+	}
+	deadline := big.NewInt(time.Now().Add(365 * 24 * time.Hour).Unix())
+	data, value, err := contract.EncodeDeposit(beneficiary, amount, deadline, nil)
+	if err != nil {
+		log.Warn("⚠ Could not encode the deposit; using the default gas", "error", err)
+		return step
+	}
+	gas, err := network.EstimateGas(ctx, client, from, config.ContractAddress, data, value)
+	if err != nil {
+		log.Warn("⚠ Could not simulate the deposit; using the default gas", "error", err)
+		return step
+	}
+	step.gas, step.source = gas.Uint64(), "estimated"
+	return step
+}
+
+// lifecyclePrices returns the price per gas a transaction would pay now (the
+// base fee plus the priority fee, or the legacy gas price) and the max fee
+// prepare would set
+func lifecyclePrices(ctx context.Context, client *ethclient.Client) (*big.Int, *big.Int, error) {
+	gasPrices, err := network.GetGasPrices(ctx, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !gasPrices.IsEIP1559 {
+		return gasPrices.GasPrice, gasPrices.GasPrice, nil
+	}
+	current := gasPrices.MaxFeePerGas
+	if baseFee, _, err := network.GetBaseFee(ctx, client); err == nil && baseFee != nil {
+		if c := new(big.Int).Add(baseFee, gasPrices.MaxPriorityFeePerGas); c.Cmp(current) < 0 {
+			current = c
+		}
+	}
+	return current, gasPrices.MaxFeePerGas, nil
+}
+
+// gasCost returns gas × price in wei
+func gasCost(gas uint64, price *big.Int) *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(gas), price)
+}
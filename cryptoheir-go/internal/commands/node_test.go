@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// stubNode serves JSON-RPC requests with answer, which returns the result
+// for a method and its params. Unexpected methods fail the test.
+func stubNode(t *testing.T, answer func(method string, params []json.RawMessage) interface{}) *ethclient.Client {
+	t.Helper()
+	client, err := ethclient.Dial(stubNodeURL(t, answer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+// stubNodeURL is stubNode returning the node's URL instead of a client
+func stubNodeURL(t *testing.T, answer func(method string, params []json.RawMessage) interface{}) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result := answer(req.Method, req.Params)
+		if result == nil {
+			t.Errorf("unexpected method %s", req.Method)
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":-32601,"message":"method not found"}}`, req.ID)
+			return
+		}
+		encoded, err := json.Marshal(result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, encoded)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// stubHeader returns the JSON of a latest block header with a gas limit
+func stubHeader(gasLimit uint64) map[string]interface{} {
+	zero := fmt.Sprintf("0x%064x", 0)
+	return map[string]interface{}{
+		"parentHash":       zero,
+		"sha3Uncles":       zero,
+		"miner":            "0x0000000000000000000000000000000000000000",
+		"stateRoot":        zero,
+		"transactionsRoot": zero,
+		"receiptsRoot":     zero,
+		"logsBloom":        fmt.Sprintf("0x%0512x", 0),
+		"difficulty":       "0x0",
+		"number":           "0x3e8",
+		"gasLimit":         fmt.Sprintf("0x%x", gasLimit),
+		"gasUsed":          "0x0",
+		"timestamp":        "0x1",
+		"extraData":        "0x",
+		"mixHash":          zero,
+		"nonce":            "0x0000000000000000",
+		"baseFeePerGas":    "0x3b9aca00",
+	}
+}
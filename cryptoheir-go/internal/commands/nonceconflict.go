@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/tui"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// resolveNonceConflicts finds batch transactions that use the same nonce.
+// Identical copies are dropped. Different transactions for one nonce, such as
+// an original and a re-signed replacement, are listed with the fields that
+// differ; on a terminal the user picks the one to broadcast, otherwise the
+// batch is refused. files must be sorted by nonce.
+func resolveNonceConflicts(files []signedTxFile) ([]signedTxFile, error) {
+	var kept []signedTxFile
+	for i := 0; i < len(files); {
+		nonce := files[i].signedTx.Nonce()
+		j := i + 1
+		for j < len(files) && files[j].signedTx.Nonce() == nonce {
+			j++
+		}
+
+		group := uniqueSignedTxs(files[i:j])
+		if len(group) > 1 {
+			chosen, err := chooseNonceVariant(nonce, group)
+			if err != nil {
+				return nil, err
+			}
+			group = []signedTxFile{chosen}
+		}
+		kept = append(kept, group...)
+		i = j
+	}
+	return kept, nil
+}
+
+// uniqueSignedTxs drops files holding the same transaction as an earlier one
+func uniqueSignedTxs(files []signedTxFile) []signedTxFile {
+	seen := make(map[common.Hash]string)
+	var unique []signedTxFile
+	for _, f := range files {
+		if first, ok := seen[f.signedTx.TxHash]; ok {
+			log.Info("Skipping identical copy of a signed transaction", "file", f.path, "same_as", first)
+			continue
+		}
+		seen[f.signedTx.TxHash] = f.path
+		unique = append(unique, f)
+	}
+	return unique
+}
+
+// variantFields are the fields shown for each transaction competing for a
+// nonce, in display order
+var variantFields = []string{"hash", "type", "max fee", "priority fee", "gas price", "gas limit", "to", "value", "data", "signed at"}
+
+// describeVariant returns the displayed fields of a signed transaction
+func describeVariant(signedTx *types.SignedTx) map[string]string {
+	fields := map[string]string{
+		"hash":      signedTx.TxHash.Hex(),
+		"signed at": signedTx.Metadata.SignedAt,
+	}
+	tx := new(coretypes.Transaction)
+	if err := tx.UnmarshalBinary(signedTx.SignedTransaction); err != nil {
+		return fields
+	}
+	fields["type"] = strconv.Itoa(int(tx.Type()))
+	if tx.Type() == coretypes.LegacyTxType {
+		fields["gas price"] = types.FormatGwei(tx.GasPrice()) + " gwei"
+	} else {
+		fields["max fee"] = types.FormatGwei(tx.GasFeeCap()) + " gwei"
+		fields["priority fee"] = types.FormatGwei(tx.GasTipCap()) + " gwei"
+	}
+	fields["gas limit"] = strconv.FormatUint(tx.Gas(), 10)
+	if tx.To() != nil {
+		fields["to"] = tx.To().Hex()
+	} else {
+		fields["to"] = "(deployment)"
+	}
+	fields["value"] = types.FormatAmount(tx.Value(), 18, "ETH")
+	fields["data"] = fmt.Sprintf("%d bytes", len(tx.Data()))
+	return fields
+}
+
+// chooseNonceVariant shows the transactions competing for a nonce, marking the
+// fields that differ, and asks which one to broadcast
+func chooseNonceVariant(nonce uint64, group []signedTxFile) (signedTxFile, error) {
+	described := make([]map[string]string, len(group))
+	for i, f := range group {
+		described[i] = describeVariant(f.signedTx)
+	}
+	var differing []string
+	for _, field := range variantFields {
+		for _, d := range described[1:] {
+			if d[field] != described[0][field] {
+				differing = append(differing, field)
+				break
+			}
+		}
+	}
+
+	fmt.Printf("Nonce %d has %d different signed transactions:\n", nonce, len(group))
+	for i, f := range group {
+		fmt.Printf("  [%d] %s\n", i+1, f.path)
+		for _, field := range differing {
+			fmt.Printf("        %-13s %s\n", field+":", described[i][field])
+		}
+	}
+	fmt.Printf("  Differing fields: %s\n", strings.Join(differing, ", "))
+
+	paths := make([]string, len(group))
+	for i, f := range group {
+		paths[i] = f.path
+	}
+	ambiguous := fmt.Errorf("nonce %d has %d different signed transactions (%s); only one can be mined, so keep only the one to broadcast",
+		nonce, len(group), strings.Join(paths, ", "))
+	if !tui.IsTerminal() {
+		return signedTxFile{}, ambiguous
+	}
+
+	fmt.Printf("Broadcast which one for nonce %d? [1-%d, anything else cancels] ", nonce, len(group))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	choice, err := strconv.Atoi(strings.TrimSpace(answer))
+	if err != nil || choice < 1 || choice > len(group) {
+		return signedTxFile{}, ambiguous
+	}
+	log.Info("Chose the transaction for nonce", "nonce", nonce, "file", group[choice-1].path)
+	return group[choice-1], nil
+}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/contract"
+	"github.com/spf13/cobra"
+)
+
+// ListOperationsCmd represents the list-operations command
+var ListOperationsCmd = &cobra.Command{
+	Use:   "list-operations",
+	Short: "List the functions of the embedded CryptoHeir contract",
+	Long: `List every function in the embedded contract ABI with its signature and
+selector, grouped into state-changing and read-only functions.
+
+Functions the CLI can prepare directly are marked with the command to use.`,
+	Args: cobra.NoArgs,
+	RunE: runListOperations,
+}
+
+// dedicatedOperations maps contract functions to the CLI command that
+// prepares them
+var dedicatedOperations = map[string]string{
+	"deposit": "prepare deposit",
+	"claim":   "prepare claim",
+}
+
+func runListOperations(cmd *cobra.Command, args []string) error {
+	if err := contract.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize contract: %w", err)
+	}
+
+	methods, err := contract.Methods()
+	if err != nil {
+		return err
+	}
+
+	var write, view []contract.MethodInfo
+	for _, m := range methods {
+		if m.IsView() {
+			view = append(view, m)
+		} else {
+			write = append(write, m)
+		}
+	}
+
+	fmt.Println("State-changing functions (require a signed transaction):")
+	printOperations(write)
+	fmt.Println()
+	fmt.Println("Read-only functions:")
+	printOperations(view)
+	fmt.Println()
+	fmt.Println("Contract deployment: [cryptoheir prepare deploy]")
+
+	return nil
+}
+
+// printOperations prints one line per method plus its full declaration
+func printOperations(methods []contract.MethodInfo) {
+	for _, m := range methods {
+		line := fmt.Sprintf("  0x%x  %-45s", m.Selector, m.Signature)
+		if op, ok := dedicatedOperations[m.Name]; ok {
+			line += fmt.Sprintf(" [cryptoheir %s]", op)
+		}
+		if !m.IsView() {
+			if risk := contract.ClassifyCall(false, m.Selector, false); risk.Severity != contract.SeverityRoutine {
+				line += " " + risk.Tag()
+			}
+		}
+		fmt.Println(strings.TrimRight(line, " "))
+		fmt.Printf("              %s\n", m.Declaration)
+	}
+}
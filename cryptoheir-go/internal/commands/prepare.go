@@ -1,18 +1,31 @@
 package commands
 
 import (
+	"bufio"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"math/big"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/contract"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/crypto"
 	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/network"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/store"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/tui"
 	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/version"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/spf13/cobra"
@@ -25,44 +38,169 @@ func SetLogger(logger *slog.Logger) {
 	log = logger
 }
 
+// LogsToStderr reports whether the command's stdout carries output meant for
+// other tools, so log lines must go to stderr instead
+func LogsToStderr() bool {
+	return dumpCalldataFlag
+}
+
 // PrepareCmd represents the prepare command
 var PrepareCmd = &cobra.Command{
-	Use:   "prepare [deploy|deposit]",
+	Use:   "prepare [deploy|deposit|claim|send|setup]",
 	Short: "Prepare an unsigned transaction for offline signing",
 	Long: `Prepare an unsigned transaction by connecting to the network,
 estimating gas, and creating a transaction parameters file for offline signing.
 
 Supports:
   - deploy: Deploy a new CryptoHeir contract
-  - deposit: Create an inheritance deposit`,
+  - deposit: Create an inheritance deposit
+  - claim: Claim an inheritance as its beneficiary, once the deadline has
+    passed
+  - send: Send native ETH to an address, or sweep the whole balance
+  - setup: Deploy a new contract and make a first deposit into it, as a
+    two-transaction bundle`,
 	Args: cobra.ExactArgs(1),
 	RunE: runPrepare,
 }
 
 var (
 	// Common flags
-	networkFlag string
-	rpcURLFlag  string
-	outputFlag  string
+	networkFlag    string
+	rpcURLFlag     string
+	rpcURLFileFlag string
+	outputFlag     string
 
 	// Deposit flags
-	beneficiaryFlag string
-	amountFlag      string
-	deadlineFlag    int64
-	tokenFlag       string
+	beneficiaryFlag   string
+	amountFlag        string
+	deadlineFlag      int64
+	tokenFlag         string
+	tokenDecimalsFlag int
+
+	withApproveFlag        bool
+	noHistoryFlag          bool
+	confirmBeneficiaryFlag bool
+	interactiveFlag        bool
+	paramsFromEnv          bool
+
+	// EIP-7702 flags
+	delegateFlag string
+
+	nonceFlag          int64
+	priceUSDFlag       float64
+	broadcastAfterFlag string
+	bundleFlag         string
+	gasReportFlag      bool
+	fromParamsFlag     string
+	refetchGasFlag     bool
+	noCacheFlag        bool
+	dumpCalldataFlag   bool
+	forceFlag          bool
+	allowSettledFlag   bool
+	cborFlag           bool
+	captureBalanceFlag bool
+	gasLimitPctFlag    float64
+	maxGasPriceFlag    string
+	safeFlag           bool
+	verifyReleaseFlag  string
+	labelFlag          string
+	selfContainedFlag  bool
+
+	// Deposit guardrail flags
+	maxAmountFlag      string
+	maxTokenAmountFlag []string
+	confirmLargeFlag   bool
+
+	// Claim flags
+	inheritanceIDFlag string
+
+	// Send flags
+	toFlag    string
+	sweepFlag bool
+
+	// Deploy flags
+	noRedeployGuardFlag     bool
+	strictRedeployGuardFlag bool
+	strictGasFlag           bool
 )
 
+// defaultGasLimits are used when gas cannot be estimated because the
+// transaction depends on an earlier one that has not been mined yet
+var defaultGasLimits = map[string]uint64{
+	"deposit": 250000,
+}
+
+// minimumGasEstimates are the lowest plausible gas estimates (including the
+// 20% buffer) per operation. An estimate below its floor usually means the
+// node did not execute the intended path, e.g. an RPC glitch returning 21000
+// for a contract call, and the transaction would run out of gas.
+var minimumGasEstimates = map[string]uint64{
+	"deploy":  300000,
+	"deposit": 50000,
+	"approve": 24000,
+	"claim":   40000,
+}
+
 func init() {
 	// Common flags
 	PrepareCmd.PersistentFlags().StringVar(&networkFlag, "network", "sepolia", "Network name (sepolia, mainnet, etc.)")
 	PrepareCmd.PersistentFlags().StringVar(&rpcURLFlag, "rpc-url", "", "Custom RPC URL (overrides network)")
+	PrepareCmd.PersistentFlags().BoolVar(&noCacheFlag, "no-cache", false, "Fetch the chain ID from the node instead of the local cache")
+	PrepareCmd.PersistentFlags().StringVar(&rpcURLFileFlag, "rpc-url-file", "", "Read the custom RPC URL from a file ('-' for stdin) to keep API keys out of shell history")
+	PrepareCmd.PersistentFlags().StringVar(&rpcSelectFlag, "rpc-select", rpcSelectFirst, rpcSelectUsage)
 	PrepareCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", "tx-params.json", "Output file path")
+	PrepareCmd.PersistentFlags().BoolVar(&cborFlag, "cbor", false, "Write the compact CBOR format instead of JSON for size-limited transfers such as QR codes (default output tx-params.cbor)")
+	PrepareCmd.PersistentFlags().BoolVar(&safeFlag, "safe", false, "Write a Safe{Wallet} Transaction Builder batch for import as a multisig proposal instead of tx-params; SIGNER_ADDRESS is the Safe (default output safe-tx.json)")
+	PrepareCmd.PersistentFlags().BoolVar(&strictGasFlag, "strict-gas", false, "Fail instead of warning when a gas estimate is implausibly low for the operation")
+	PrepareCmd.PersistentFlags().BoolVar(&forceFlag, "force", false, "Overwrite existing output files")
+	PrepareCmd.PersistentFlags().BoolVar(&allowSettledFlag, "allow-settled", false, "Claim: prepare even if the inheritance is already settled (the claim will revert)")
+	PrepareCmd.PersistentFlags().BoolVar(&selfContainedFlag, "self-contained", false, "Embed everything the offline review needs in the output: the decoded call, the called function's ABI fragment, the predicted contract address of a deployment and the balance snapshot")
+	PrepareCmd.PersistentFlags().StringVar(&labelFlag, "label", "", "Tag the operation: added to the default output file names (tx-params-<label>.json), the metadata and the journal, and carried through sign and broadcast")
+	PrepareCmd.PersistentFlags().StringVar(&bundleFlag, "bundle", "", "Write a portable bundle to this path instead of --output, embedding the decoded call for signers that cannot decode it locally")
 
 	// Deposit-specific flags
-	PrepareCmd.PersistentFlags().StringVar(&beneficiaryFlag, "beneficiary", "", "Beneficiary address")
-	PrepareCmd.PersistentFlags().StringVar(&amountFlag, "amount", "", "Amount in ETH (e.g., 1.5)")
+	PrepareCmd.PersistentFlags().StringVar(&beneficiaryFlag, "beneficiary", "", "Beneficiary address or ENS name (resolved at prepare time)")
+	PrepareCmd.PersistentFlags().StringVar(&amountFlag, "amount", "", "Amount in ETH, or in token units with --token (e.g., 1.5)")
 	PrepareCmd.PersistentFlags().Int64Var(&deadlineFlag, "deadline", 0, "Deadline as Unix timestamp")
 	PrepareCmd.PersistentFlags().StringVar(&tokenFlag, "token", "", "ERC20 token address (omit for native ETH)")
+	PrepareCmd.PersistentFlags().IntVar(&tokenDecimalsFlag, "token-decimals", -1, "With --token: the token's decimals, required when it has no decimals() function and checked against it otherwise")
+	PrepareCmd.PersistentFlags().StringVar(&maxAmountFlag, "max-amount", "", "Require confirmation for native deposits above this many ETH (overrides MAX_AMOUNT)")
+	PrepareCmd.PersistentFlags().StringSliceVar(&maxTokenAmountFlag, "max-token-amount", nil, "Require confirmation for deposits of a token above this many token units, as <token address>=<amount> (repeatable; overrides MAX_TOKEN_AMOUNT)")
+	PrepareCmd.PersistentFlags().BoolVar(&confirmLargeFlag, "confirm-large", false, "Confirm a deposit above --max-amount or --max-token-amount without being asked")
+	PrepareCmd.PersistentFlags().BoolVar(&confirmBeneficiaryFlag, "confirm-beneficiary", false, "Record a beneficiary that resembles one used before, after verifying it")
+	PrepareCmd.PersistentFlags().BoolVar(&noHistoryFlag, "no-history", false, "Do not check or record beneficiaries in the local history (~/.cryptoheir/beneficiaries.json)")
+	PrepareCmd.PersistentFlags().BoolVar(&withApproveFlag, "with-approve", false, "With --token: also prepare the ERC20 approve transaction (nonce N) linked to the deposit (nonce N+1)")
+	PrepareCmd.PersistentFlags().BoolVar(&paramsFromEnv, "params-from-env", false, "Deposit: read beneficiary, amount, deadline and token from CH_* environment variables when the flags are not given")
+	PrepareCmd.PersistentFlags().BoolVar(&interactiveFlag, "interactive", false, "Deposit: prompt for missing beneficiary, amount and deadline step by step")
+
+	PrepareCmd.PersistentFlags().BoolVar(&captureBalanceFlag, "capture-balance", true, "Snapshot the signer's ETH balance (and token balance and allowance for token deposits) so the offline review can check affordability")
+	PrepareCmd.PersistentFlags().Float64Var(&priceUSDFlag, "price-usd", 0, "Native token price in USD, snapshotted to show USD estimates during offline review")
+	PrepareCmd.PersistentFlags().StringVar(&broadcastAfterFlag, "broadcast-after", "", "Refuse to broadcast before this time (same formats as deposit deadlines, e.g. 2030-01-31 or +30d)")
+	PrepareCmd.PersistentFlags().Int64Var(&nonceFlag, "nonce", -1, "Explicit nonce (default: the pending nonce for new transactions)")
+	PrepareCmd.PersistentFlags().StringVar(&fromParamsFlag, "from-params", "", "Use an existing tx-params.json as a template: reuse its recipient, parameters and gas settings, with flags overriding individual fields (the nonce is always refetched)")
+	PrepareCmd.PersistentFlags().BoolVar(&refetchGasFlag, "refetch-gas", false, "With --from-params: estimate gas and fetch fees again instead of reusing the template's")
+	PrepareCmd.PersistentFlags().BoolVar(&dumpCalldataFlag, "dump-calldata", false, "Print the encoded calldata, value and recipient to stdout and exit without writing transaction parameters (logs go to stderr)")
+	PrepareCmd.PersistentFlags().BoolVar(&gasReportFlag, "gas-report", false, "Print the raw, buffered and access-list gas estimates side by side")
+	PrepareCmd.PersistentFlags().StringVar(&maxGasPriceFlag, "max-gas-price", "", "Abort if the current gas price (legacy) or max fee per gas (EIP-1559) exceeds this many gwei, e.g. during a fee spike")
+	PrepareCmd.PersistentFlags().Float64Var(&gasLimitPctFlag, "gas-limit-percent", 0, "Set the gas limit to this percentage of the latest block's gas limit instead of the estimate (e.g. 30 for a heavy deploy)")
+
+	// Claim-specific flags
+	PrepareCmd.PersistentFlags().StringVar(&inheritanceIDFlag, "inheritance-id", "", "Claim: ID of the inheritance to claim")
+
+	// Send-specific flags
+	PrepareCmd.PersistentFlags().StringVar(&toFlag, "to", "", "Send: recipient address or ENS name (resolved at prepare time)")
+	PrepareCmd.PersistentFlags().BoolVar(&sweepFlag, "sweep", false, "Send: send the whole balance minus the worst-case gas cost instead of --amount")
+
+	// Deploy-specific flags
+	PrepareCmd.PersistentFlags().StringVar(&verifyReleaseFlag, "verify-release", "", "Refuse to proceed unless the contract matches this official CryptoHeir release: the embedded bytecode for deploy and setup, the on-chain code at CONTRACT_ADDRESS for deposits and claims")
+	PrepareCmd.PersistentFlags().BoolVar(&noRedeployGuardFlag, "no-redeploy-guard", false, "Deploy: skip the check for an existing contract at CONTRACT_ADDRESS")
+	PrepareCmd.PersistentFlags().BoolVar(&strictRedeployGuardFlag, "strict-redeploy-guard", false, "Deploy: fail instead of warning when CONTRACT_ADDRESS already has code")
+
+	// EIP-7702 flags
+	PrepareCmd.PersistentFlags().StringVar(&delegateFlag, "delegate", "", "EIP-7702: delegate the signer account to this contract (type-4 transaction, Prague-enabled chains only)")
+
+	// replay accepts every prepare flag to override the replayed fields
+	ReplayCmd.Flags().AddFlagSet(PrepareCmd.PersistentFlags())
 }
 
 func runPrepare(cmd *cobra.Command, args []string) error {
@@ -74,13 +212,89 @@ func runPrepare(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	if bundleFlag != "" {
+		if cmd.Flags().Changed("output") {
+			return fmt.Errorf("--bundle and --output cannot be used together")
+		}
+		outputFlag = bundleFlag
+	} else if cborFlag && !cmd.Flags().Changed("output") {
+		outputFlag = "tx-params.cbor"
+	} else if safeFlag && !cmd.Flags().Changed("output") {
+		outputFlag = "safe-tx.json"
+	}
+	if labelFlag != "" {
+		if labelFlag, err = sanitizeLabel(labelFlag); err != nil {
+			return err
+		}
+		if bundleFlag == "" && !cmd.Flags().Changed("output") {
+			outputFlag = labeledPath(outputFlag, labelFlag)
+		}
+	}
+
+	if fromParamsFlag != "" && withApproveFlag {
+		return fmt.Errorf("--from-params cannot be used with --with-approve")
+	}
+	if refetchGasFlag && fromParamsFlag == "" {
+		return fmt.Errorf("--refetch-gas requires --from-params")
+	}
+	if dumpCalldataFlag && (withApproveFlag || bundleFlag != "" || gasReportFlag) {
+		return fmt.Errorf("--dump-calldata cannot be used with --with-approve, --bundle or --gas-report")
+	}
+	if (operation == "send" || operation == "claim") && (fromParamsFlag != "" || withApproveFlag) {
+		return fmt.Errorf("%s cannot be used with --from-params or --with-approve", operation)
+	}
+	if operation == "setup" && (fromParamsFlag != "" || withApproveFlag || tokenFlag != "" || delegateFlag != "" || dumpCalldataFlag || interactiveFlag) {
+		return fmt.Errorf("setup cannot be used with --from-params, --with-approve, --token, --delegate, --dump-calldata or --interactive")
+	}
+	if safeFlag && (operation == "deploy" || operation == "setup" || cborFlag || bundleFlag != "" || delegateFlag != "" || dumpCalldataFlag) {
+		return fmt.Errorf("--safe cannot be used with deploy, setup, --cbor, --bundle, --delegate or --dump-calldata")
+	}
+	if selfContainedFlag && (safeFlag || dumpCalldataFlag || !captureBalanceFlag) {
+		return fmt.Errorf("--self-contained cannot be used with --safe, --dump-calldata or --capture-balance=false")
+	}
+	if cmd.Flags().Changed("gas-limit-percent") {
+		if gasLimitPctFlag <= 0 || gasLimitPctFlag >= 100 {
+			return fmt.Errorf("--gas-limit-percent must be greater than 0 and below 100")
+		}
+		if withApproveFlag || sweepFlag || operation == "setup" {
+			return fmt.Errorf("--gas-limit-percent cannot be used with --with-approve, --sweep or setup")
+		}
+	}
+
+	if maxGasPriceFlag != "" {
+		if _, err := maxGasPrice(); err != nil {
+			return err
+		}
+	}
+
+	// Normalize the broadcast schedule before doing any network work
+	if broadcastAfterFlag != "" {
+		after, err := parseDeadline(broadcastAfterFlag)
+		if err != nil {
+			return fmt.Errorf("invalid --broadcast-after: %w", err)
+		}
+		if !after.After(time.Now()) {
+			return fmt.Errorf("--broadcast-after must be in the future")
+		}
+		broadcastAfterFlag = after.UTC().Format(time.RFC3339)
+		log.Info("Scheduled for broadcast after", "time", broadcastAfterFlag)
+	}
+
 	// Initialize contract module
 	if err := contract.Initialize(); err != nil {
 		return fmt.Errorf("failed to initialize contract: %w", err)
 	}
+	if verifyReleaseFlag != "" {
+		if err := verifyArtifactRelease(operation); err != nil {
+			return err
+		}
+	}
 
 	// Determine RPC URL
-	rpcURL := rpcURLFlag
+	rpcURL, err := customRPCURL(rpcURLFlag, rpcURLFileFlag)
+	if err != nil {
+		return err
+	}
 	if rpcURL == "" {
 		rpcURL, err = network.GetRPCURL(networkFlag, config.InfuraAPIKey)
 		if err != nil {
@@ -88,6 +302,12 @@ func runPrepare(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// A URL read from a file is kept out of the output files as well
+	recordedRPCURL := rpcURL
+	if rpcURLFileFlag != "" {
+		recordedRPCURL = redactRPCURL(rpcURL)
+	}
+
 	// Connect to network
 	ctx := context.Background()
 	client, err := network.CreateClient(ctx, rpcURL)
@@ -99,261 +319,1493 @@ func runPrepare(cmd *cobra.Command, args []string) error {
 	log.Info("Connected to network")
 
 	// Get chain ID
-	chainID, err := network.GetChainID(ctx, client)
+	chainID, err := cachedChainID(ctx, client, rpcURL, noCacheFlag)
 	if err != nil {
 		return err
 	}
 	log.Info("Chain ID", "chain_id", chainID)
 
 	// Get signer address
-	if config.SignerAddress == nil {
-		return fmt.Errorf("SIGNER_ADDRESS not set in environment")
+	if err := config.RequireSignerAddress(); err != nil {
+		return err
 	}
 	signerAddress := *config.SignerAddress
 	log.Info("Signer address", "address", signerAddress.Hex())
+	if safeFlag {
+		checkSafeAddress(ctx, client, signerAddress)
+	}
 
 	// Get nonce
-	nonce, err := network.GetNonce(ctx, client, signerAddress)
+	nonce, err := resolveNonce(ctx, client, signerAddress)
 	if err != nil {
 		return err
 	}
-	log.Info("Nonce", "nonce", nonce)
+
+	// An unused account usually means the wrong network or the wrong key
+	checkSignerHistory(ctx, client, signerAddress, chainID)
+
+	// Local development nodes: help the developer fund or impersonate the signer
+	logDevNodeHints(ctx, client, signerAddress, rpcURL)
+
+	// Load the template for a similar transaction
+	var template *types.TxParams
+	if fromParamsFlag != "" {
+		template, err = loadTemplate(fromParamsFlag, operation, chainID, signerAddress)
+		if err != nil {
+			return err
+		}
+	}
 
 	// Prepare transaction based on operation
 	var txParams *types.TxParams
 	switch operation {
 	case "deploy":
-		txParams, err = prepareDeploy(ctx, client, signerAddress, nonce, chainID, networkFlag, rpcURL)
+		if err := checkExistingDeployment(ctx, client, config); err != nil {
+			return err
+		}
+		if template != nil {
+			checkTemplateDeploy(template)
+		}
+		txParams, err = prepareDeploy(ctx, client, signerAddress, nonce, chainID, networkFlag, recordedRPCURL)
 	case "deposit":
-		txParams, err = prepareDeposit(ctx, client, config, signerAddress, nonce, chainID, networkFlag, rpcURL)
+		if paramsFromEnv {
+			if err := applyDepositEnv(cmd); err != nil {
+				return err
+			}
+		}
+		if template != nil {
+			if err := applyTemplateContract(config, template); err != nil {
+				return err
+			}
+			if err := applyDepositTemplate(ctx, cmd, client, template); err != nil {
+				return err
+			}
+		}
+		if verifyReleaseFlag != "" {
+			if err := verifyDeployedRelease(ctx, client, config); err != nil {
+				return err
+			}
+		}
+		if interactiveFlag {
+			if err := promptDepositFlags(ctx, client, config, signerAddress, chainID); err != nil {
+				return err
+			}
+		}
+		if withApproveFlag {
+			bundle, err := prepareApproveAndDeposit(ctx, client, config, signerAddress, nonce, chainID, networkFlag, recordedRPCURL)
+			if err != nil {
+				return err
+			}
+			if safeFlag {
+				return writeSafeBatch(outputFlag, bundle, signerAddress, "CryptoHeir approve and deposit")
+			}
+			for _, txParams := range bundle {
+				annotateTxParams(ctx, client, txParams)
+			}
+			return writeBundle(bundle)
+		}
+		txParams, err = prepareDeposit(ctx, client, config, signerAddress, nonce, chainID, networkFlag, recordedRPCURL, "")
+	case "claim":
+		if verifyReleaseFlag != "" {
+			if err := verifyDeployedRelease(ctx, client, config); err != nil {
+				return err
+			}
+		}
+		txParams, err = prepareClaim(ctx, client, config, signerAddress, nonce, chainID, networkFlag, recordedRPCURL)
+	case "send":
+		txParams, err = prepareSend(ctx, client, signerAddress, nonce, chainID, networkFlag, recordedRPCURL)
+	case "setup":
+		if err := checkExistingDeployment(ctx, client, config); err != nil {
+			return err
+		}
+		if paramsFromEnv {
+			if err := applyDepositEnv(cmd); err != nil {
+				return err
+			}
+		}
+		bundle, err := prepareSetup(ctx, client, config, signerAddress, nonce, chainID, networkFlag, recordedRPCURL)
+		if err != nil {
+			return err
+		}
+		for _, txParams := range bundle {
+			annotateTxParams(ctx, client, txParams)
+		}
+		if err := writeBundle(bundle); err != nil {
+			return err
+		}
+		log.Info("  Once deployed, set CONTRACT_ADDRESS for later deposits", "contract_address", bundle[1].Transaction.To.Hex())
+		return nil
 	default:
-		return fmt.Errorf("unsupported operation: %s (supported: deploy, deposit)", operation)
+		return fmt.Errorf("unsupported operation: %s (supported: deploy, deposit, claim, send, setup)", operation)
 	}
 
 	if err != nil {
 		return err
 	}
+	if dumpCalldataFlag {
+		// The encoding has been printed; there are no parameters to write
+		return nil
+	}
 
-	// Save to file
-	data, err := json.MarshalIndent(txParams, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to serialize transaction: %w", err)
+	if template != nil {
+		applyTemplateGas(&txParams.Transaction, template)
+		if err := checkFeeFields(&txParams.Transaction); err != nil {
+			return err
+		}
+	}
+	if gasLimitPctFlag > 0 {
+		if err := applyGasLimitPercent(ctx, client, &txParams.Transaction); err != nil {
+			return err
+		}
+	}
+	if safeFlag {
+		return writeSafeBatch(outputFlag, []*types.TxParams{txParams}, signerAddress, "CryptoHeir "+operation)
 	}
+	annotateTxParams(ctx, client, txParams)
 
-	if err := os.WriteFile(outputFlag, data, 0644); err != nil {
-		return fmt.Errorf("failed to write output file: %w", err)
+	// Save to file
+	if err := writeTxParams(outputFlag, txParams); err != nil {
+		return err
 	}
 
-	log.Info("✓ Transaction prepared successfully")
+	log.Info("✓ Transaction prepared successfully", "type", txParams.Metadata.TxTypeName)
 	log.Info("  Output", "file", outputFlag)
 	log.Info("  Next", "instruction", fmt.Sprintf("Transfer to offline machine and run 'cryptoheir sign -i %s'", outputFlag))
 
 	return nil
 }
 
-func prepareDeploy(ctx context.Context, client *ethclient.Client, signerAddress common.Address, nonce uint64, chainID uint64, networkName, rpcURL string) (*types.TxParams, error) {
-	log.Info("Preparing contract deployment...")
-
-	// Load bytecode
-	bytecode, err := contract.LoadBytecode()
+// resolveNonce selects the nonce for a transaction: the pending nonce for a
+// new transaction, or the --nonce given explicitly, which may replace a
+// pending one
+func resolveNonce(ctx context.Context, client *ethclient.Client, signerAddress common.Address) (uint64, error) {
+	pending, err := network.GetNonce(ctx, client, signerAddress)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	log.Info("Contract bytecode loaded", "bytes", len(bytecode))
 
-	// Estimate gas
-	gasLimit, err := network.EstimateGas(ctx, client, signerAddress, nil, bytecode, nil)
-	if err != nil {
-		return nil, fmt.Errorf("gas estimation failed: %w", err)
+	if nonceFlag < 0 {
+		log.Info("Nonce (pending, new transaction)", "nonce", pending)
+		return pending, nil
 	}
-	log.Info("Estimated gas", "gas", gasLimit.String())
 
-	// Get gas prices
-	gasPrices, err := network.GetGasPrices(ctx, client)
+	nonce := uint64(nonceFlag)
+	confirmed, err := network.GetConfirmedNonce(ctx, client, signerAddress)
 	if err != nil {
-		return nil, err
+		return 0, err
+	}
+	if nonce < confirmed {
+		return 0, fmt.Errorf("nonce %d is already used by a confirmed transaction (next confirmed nonce is %d)", nonce, confirmed)
 	}
 
-	// Build transaction data
-	txData := types.TransactionData{
-		From:     signerAddress,
-		To:       nil, // Contract deployment
-		Data:     bytecode,
-		Nonce:    nonce,
-		ChainID:  chainID,
-		GasLimit: types.NewBigInt(gasLimit),
+	switch {
+	case nonce < pending:
+		log.Warn("⚠ Nonce (explicit) is used by a pending transaction; this transaction will REPLACE it if its fees are high enough",
+			"nonce", nonce, "pending_nonce", pending)
+	case nonce > pending:
+		log.Warn("⚠ Nonce (explicit) leaves a gap; the transaction will not be mined until earlier nonces are used",
+			"nonce", nonce, "pending_nonce", pending)
+	default:
+		log.Info("Nonce (explicit, matches pending)", "nonce", nonce)
 	}
 
-	// Set gas prices based on transaction type
-	if gasPrices.IsEIP1559 {
-		txData.TxType = 2
-		txData.MaxFeePerGas = types.NewBigInt(gasPrices.MaxFeePerGas)
-		txData.MaxPriorityFeePerGas = types.NewBigInt(gasPrices.MaxPriorityFeePerGas)
-		log.Info("EIP-1559",
-			"max_fee_gwei", weiToGwei(gasPrices.MaxFeePerGas),
-			"priority_fee_gwei", weiToGwei(gasPrices.MaxPriorityFeePerGas))
-	} else {
-		txData.TxType = 0
-		txData.GasPrice = types.NewBigInt(gasPrices.GasPrice)
-		log.Info("Legacy", "gas_price_gwei", weiToGwei(gasPrices.GasPrice))
+	return nonce, nil
+}
+
+// checkSignerHistory warns when the signer has neither sent a transaction nor
+// holds a balance on the target chain. This is advisory: a new account is
+// legitimate, but it is more often a wrong-network or wrong-account setup,
+// which is better caught before the offline signing step.
+func checkSignerHistory(ctx context.Context, client *ethclient.Client, signerAddress common.Address, chainID uint64) {
+	nonce, err := network.GetNonce(ctx, client, signerAddress)
+	if err != nil || nonce > 0 {
+		return
+	}
+	balance, err := network.GetBalance(ctx, client, signerAddress)
+	if err != nil {
+		log.Debug("Could not check the signer balance", "error", err)
+		return
 	}
+	if balance.Sign() > 0 {
+		return
+	}
+	log.Warn(fmt.Sprintf("⚠ Signer account has no history on chain %d; is this the right network?", chainID),
+		"address", signerAddress.Hex(),
+		"nonce", nonce,
+		"balance", types.FormatEth(balance))
+}
 
-	// Build metadata
-	metadata := types.Metadata{
-		PreparedAt:  time.Now().UTC().Format(time.RFC3339),
-		Network:     types.NetworkInfo{Name: networkName, ChainID: chainID, RPCURL: rpcURL},
-		ToolVersion: "cryptoheir-go v0.1.0",
+// logDevNodeHints detects an Anvil or Hardhat node and, when the signer has
+// no balance, prints the RPC calls that fund and impersonate it. Production
+// networks are unaffected because detection relies on the client version.
+func logDevNodeHints(ctx context.Context, client *ethclient.Client, signerAddress common.Address, rpcURL string) {
+	clientVersion, err := network.GetClientVersion(ctx, client)
+	if err != nil {
+		log.Debug("Could not detect node client", "error", err)
+		return
+	}
+	prefix := network.DevNodeRPCPrefix(clientVersion)
+	if prefix == "" {
+		return
 	}
 
-	// Build TxParams
-	txParams := &types.TxParams{
-		Mode:        types.TransactionModeDeploy,
-		Transaction: txData,
-		Metadata:    metadata,
+	log.Info("Local development node detected", "client", clientVersion)
+	log.Info("  On a fork the signer may need funding; impersonation lets you broadcast without its key")
+
+	balance, err := network.GetBalance(ctx, client, signerAddress)
+	if err != nil || balance.Sign() > 0 {
+		return
 	}
 
-	return txParams, nil
+	log.Warn("⚠ Signer has no balance on this node. To fund and impersonate it, run:")
+	log.Warn(fmt.Sprintf("  cast rpc --rpc-url %s %s_setBalance %s 0x56BC75E2D63100000", rpcURL, prefix, signerAddress.Hex()))
+	log.Warn(fmt.Sprintf("  cast rpc --rpc-url %s %s_impersonateAccount %s", rpcURL, prefix, signerAddress.Hex()))
 }
 
-func prepareDeposit(ctx context.Context, client *ethclient.Client, config *types.Config, signerAddress common.Address, nonce uint64, chainID uint64, networkName, rpcURL string) (*types.TxParams, error) {
-	log.Info("Preparing deposit transaction...")
-
-	// Validate required flags
-	if beneficiaryFlag == "" {
-		return nil, fmt.Errorf("--beneficiary is required")
-	}
-	if amountFlag == "" {
-		return nil, fmt.Errorf("--amount is required")
+// annotateTxSize records the signed transaction size and, on OP Stack L2s,
+// the L1 data fee it will incur. Large calldata (e.g. a deployment) dominates
+// the cost on rollups, so this is shown during review.
+func annotateTxSize(ctx context.Context, client *ethclient.Client, txParams *types.TxParams) {
+	size, unsigned, err := crypto.SerializedSize(&txParams.Transaction)
+	if err != nil {
+		log.Warn("Failed to compute transaction size", "error", err)
+		return
 	}
-	if deadlineFlag == 0 {
-		return nil, fmt.Errorf("--deadline is required")
+	if txParams.Metadata.AdditionalInfo == nil {
+		txParams.Metadata.AdditionalInfo = make(map[string]interface{})
 	}
+	txParams.Metadata.AdditionalInfo["serialized_size_bytes"] = size
+	log.Info("Serialized transaction size", "bytes", size, "calldata_bytes", len(txParams.Transaction.Data))
 
-	// Parse beneficiary address
-	beneficiary := common.HexToAddress(beneficiaryFlag)
-	if beneficiary == (common.Address{}) {
-		return nil, fmt.Errorf("invalid beneficiary address")
+	l1Fee := estimateL1Fee(ctx, client, unsigned)
+	if l1Fee == nil {
+		return
 	}
+	txParams.Metadata.AdditionalInfo["l1_data_fee_wei"] = l1Fee.String()
+	log.Info("Estimated L1 data fee (OP Stack)", "fee", types.FormatEth(l1Fee))
+}
 
-	// Parse amount (ETH to wei)
-	amount, err := parseEther(amountFlag)
+// estimateL1Fee returns the L1 data fee of an unsigned transaction on OP
+// Stack L2s, or nil on other chains
+func estimateL1Fee(ctx context.Context, client *ethclient.Client, unsigned []byte) *big.Int {
+	// Only OP Stack chains have the gas price oracle predeploy
+	data, err := contract.EncodeGetL1Fee(unsigned)
 	if err != nil {
-		return nil, fmt.Errorf("invalid amount: %w", err)
+		return nil
+	}
+	result, err := network.CallContract(ctx, client, contract.GasPriceOracleAddress, data)
+	if err != nil || len(result) == 0 {
+		return nil
+	}
+	l1Fee, err := contract.DecodeGetL1Fee(result)
+	if err != nil {
+		log.Debug("Failed to decode L1 data fee", "error", err)
+		return nil
 	}
+	return l1Fee
+}
 
-	// Parse deadline
-	deadline := big.NewInt(deadlineFlag)
+// depositEnvVars maps deposit flags to the environment variables that
+// --params-from-env reads them from
+var depositEnvVars = []struct{ flag, env string }{
+	{"beneficiary", "CH_BENEFICIARY"},
+	{"amount", "CH_AMOUNT"},
+	{"deadline", "CH_DEADLINE"},
+	{"token", "CH_TOKEN"},
+}
 
-	// Parse token address (optional)
-	var token *common.Address
-	if tokenFlag != "" {
-		tokenAddr := common.HexToAddress(tokenFlag)
-		token = &tokenAddr
+// applyDepositEnv fills deposit flags that were not given on the command
+// line from CH_* environment variables, for CI pipelines. Values are parsed
+// exactly as the flags are.
+func applyDepositEnv(cmd *cobra.Command) error {
+	for _, v := range depositEnvVars {
+		if cmd.Flags().Changed(v.flag) {
+			log.Info("Deposit parameter source", "param", v.flag, "source", "--"+v.flag)
+			continue
+		}
+		value, ok := os.LookupEnv(v.env)
+		if !ok || value == "" {
+			continue
+		}
+		if err := cmd.Flags().Set(v.flag, value); err != nil {
+			return fmt.Errorf("invalid %s: %w", v.env, err)
+		}
+		log.Info("Deposit parameter source", "param", v.flag, "source", v.env)
 	}
+	return nil
+}
 
-	// Get contract address
-	if config.ContractAddress == nil {
-		return nil, fmt.Errorf("CONTRACT_ADDRESS not set in environment")
+// annotateTxParams adds review and scheduling information to prepared
+// transaction parameters
+func annotateTxParams(ctx context.Context, client *ethclient.Client, txParams *types.TxParams) {
+	annotateTxSize(ctx, client, txParams)
+	annotatePrice(txParams)
+	if captureBalanceFlag {
+		annotateBalance(ctx, client, txParams)
 	}
-	contractAddress := *config.ContractAddress
-
-	// Encode deposit function
-	data, value, err := contract.EncodeDeposit(beneficiary, amount, deadline, token)
-	if err != nil {
-		return nil, err
+	txParams.Metadata.BroadcastAfter = broadcastAfterFlag
+	if gasReportFlag {
+		printGasReport(ctx, client, txParams)
 	}
 
-	// Estimate gas
-	gasLimit, err := network.EstimateGas(ctx, client, signerAddress, &contractAddress, data, value)
-	if err != nil {
-		return nil, fmt.Errorf("gas estimation failed: %w", err)
+	if bundleFlag != "" || selfContainedFlag {
+		decoded, err := contract.DecodeCall(txParams.Transaction.To, txParams.Transaction.Data, txParams.Transaction.Value.ToBigInt())
+		if err != nil {
+			log.Warn("Could not decode the call to embed in the file", "error", err)
+		} else {
+			txParams.Decoded = decoded
+		}
 	}
-	log.Info("Estimated gas", "gas", gasLimit.String())
+	if selfContainedFlag {
+		annotateSelfContained(txParams)
+	}
+}
 
-	// Get gas prices
-	gasPrices, err := network.GetGasPrices(ctx, client)
-	if err != nil {
-		return nil, err
+// annotateSelfContained adds what the offline review cannot derive without
+// the contract artifact: the ABI entry of the called function, and for a
+// deployment the address it will create
+func annotateSelfContained(txParams *types.TxParams) {
+	tx := txParams.Transaction
+	sc := &types.SelfContained{}
+	switch {
+	case tx.To == nil:
+		predicted := crypto.PredictContractAddress(tx.From, tx.Nonce)
+		sc.PredictedContractAddress = &predicted
+	case len(tx.Data) > 0:
+		fragment, err := contract.ABIFragment(tx.Data)
+		if err != nil {
+			log.Warn("⚠ No ABI fragment for the call; the offline review will not be able to decode it", "error", err)
+		} else {
+			sc.ABI = fragment
+		}
 	}
+	txParams.SelfContained = sc
+}
 
-	// Build transaction data
-	txData := types.TransactionData{
-		From:     signerAddress,
-		To:       &contractAddress,
-		Data:     data,
-		Nonce:    nonce,
-		ChainID:  chainID,
-		GasLimit: types.NewBigInt(gasLimit),
+// annotatePrice snapshots the --price-usd price into the metadata, since the
+// offline signer cannot look it up at review time
+func annotatePrice(txParams *types.TxParams) {
+	if priceUSDFlag <= 0 {
+		return
+	}
+	if txParams.Metadata.AdditionalInfo == nil {
+		txParams.Metadata.AdditionalInfo = make(map[string]interface{})
 	}
+	txParams.Metadata.AdditionalInfo["price_usd"] = priceUSDFlag
+	txParams.Metadata.AdditionalInfo["price_snapshot_at"] = time.Now().UTC().Format(time.RFC3339)
+}
 
-	// Set value if native token
-	if value != nil {
-		txData.Value = types.NewBigInt(value)
-		log.Info("Deposit value", "value", network.FormatEth(value))
+// annotateBalance snapshots the signer's native balance and, for token
+// deposits, its token balance and allowance for the contract, so the offline
+// review can show what remains after the transaction. The allowance is not
+// recorded for bundles, whose approval raises it before the deposit runs.
+func annotateBalance(ctx context.Context, client *ethclient.Client, txParams *types.TxParams) {
+	from := txParams.Transaction.From
+	balance, err := network.GetBalance(ctx, client, from)
+	if err != nil {
+		log.Warn("Could not capture the signer balance", "error", err)
+		return
+	}
+	if txParams.Metadata.AdditionalInfo == nil {
+		txParams.Metadata.AdditionalInfo = make(map[string]interface{})
+	}
+	info := txParams.Metadata.AdditionalInfo
+	info["signer_balance_wei"] = balance.String()
+	info["balance_snapshot_at"] = time.Now().UTC().Format(time.RFC3339)
+	if block, err := network.GetBlockNumber(ctx, client); err == nil {
+		info["balance_snapshot_block"] = block
 	}
+	log.Info("Captured signer balance", "balance", types.FormatAmount(balance, 18, "ETH"))
 
-	// Set gas prices
-	if gasPrices.IsEIP1559 {
-		txData.TxType = 2
-		txData.MaxFeePerGas = types.NewBigInt(gasPrices.MaxFeePerGas)
-		txData.MaxPriorityFeePerGas = types.NewBigInt(gasPrices.MaxPriorityFeePerGas)
+	var params map[string]interface{}
+	_ = json.Unmarshal(txParams.Params, &params)
+	tokenHex, ok := params["token"].(string)
+	if !ok || txParams.FunctionName != "deposit" || txParams.Transaction.To == nil {
+		return
+	}
+	token := common.HexToAddress(tokenHex)
+	if tokenBalance, err := callERC20(ctx, client, token, "balanceOf", from); err == nil {
+		info["token_balance"] = tokenBalance.(*big.Int).String()
 	} else {
-		txData.TxType = 0
-		txData.GasPrice = types.NewBigInt(gasPrices.GasPrice)
+		log.Warn("Could not capture the token balance", "token", token.Hex(), "error", err)
 	}
-
-	// Build parameters JSON
-	params := map[string]interface{}{
-		"beneficiary": beneficiary.Hex(),
-		"amount":      amount.String(),
-		"deadline":    deadline.String(),
+	if txParams.Metadata.Bundle != nil {
+		return
 	}
-	if token != nil {
-		params["token"] = token.Hex()
+	if allowance, err := callERC20(ctx, client, token, "allowance", from, *txParams.Transaction.To); err == nil {
+		info["token_allowance"] = allowance.(*big.Int).String()
+	} else {
+		log.Warn("Could not capture the token allowance", "token", token.Hex(), "error", err)
 	}
-	paramsJSON, _ := json.Marshal(params)
+}
 
-	// Build metadata
-	metadata := types.Metadata{
-		PreparedAt:  time.Now().UTC().Format(time.RFC3339),
-		Network:     types.NetworkInfo{Name: networkName, ChainID: chainID, RPCURL: rpcURL},
-		ToolVersion: "cryptoheir-go v0.1.0",
+// dumpCalldata prints the encoded calldata, the value in wei and the
+// recipient (empty for a deployment) on separate lines, for --dump-calldata
+func dumpCalldata(to *common.Address, data []byte, value *big.Int) error {
+	if value == nil {
+		value = new(big.Int)
 	}
-
-	// Build TxParams
-	txParams := &types.TxParams{
-		Mode:         types.TransactionModeCall,
-		FunctionName: "deposit",
-		Params:       paramsJSON,
-		Transaction:  txData,
-		Metadata:     metadata,
+	recipient := ""
+	if to != nil {
+		recipient = to.Hex()
 	}
+	_, err := fmt.Printf("%s\n%s\n%s\n", hexutil.Encode(data), value.String(), recipient)
+	if err != nil {
+		return fmt.Errorf("failed to write calldata: %w", err)
+	}
+	log.Info("✓ Calldata written to stdout; no transaction parameters file was created")
+	return nil
+}
 
-	log.Info("Deposit prepared for beneficiary", "beneficiary", beneficiary.Hex())
-	return txParams, nil
+// checkOverwrite refuses to replace an existing file unless force is set, so
+// a prepared or signed transaction that is still needed is not lost
+func checkOverwrite(path string, force bool) error {
+	return checkOverwriteFlag(path, force, "--force")
 }
 
-// parseEther converts an ETH string to wei (*big.Int)
-func parseEther(ethStr string) (*big.Int, error) {
-	// Parse as float first
-	var ethFloat float64
-	if _, err := fmt.Sscanf(ethStr, "%f", &ethFloat); err != nil {
-		return nil, fmt.Errorf("invalid number format: %w", err)
+// checkOverwriteFlag is checkOverwrite for a command whose override flag is
+// not --force
+func checkOverwriteFlag(path string, force bool, flag string) error {
+	_, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err):
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to check output file %s: %w", path, err)
+	case force:
+		log.Warn("Overwriting existing file ("+flag+")", "file", path)
+		return nil
 	}
+	return types.Errorf(types.CodeFileExists, "%s: file exists; use %s to overwrite", path, flag)
+}
 
-	// Convert to wei (multiply by 1e18)
-	weiFloat := ethFloat * params.Ether
-	wei := new(big.Int)
-	wei.SetUint64(uint64(weiFloat))
+// maxLabelLength keeps labelled file names well within filesystem limits
+const maxLabelLength = 64
 
-	return wei, nil
+// sanitizeLabel makes a --label safe to use in file names: letters, digits,
+// '.', '_' and '-' are kept and every other run of characters becomes a
+// single '-'
+func sanitizeLabel(label string) (string, error) {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.TrimSpace(label) {
+		switch {
+		case r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '_' || r == '-'):
+			b.WriteRune(r)
+			dash = false
+		case !dash:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	sanitized := strings.Trim(b.String(), "-.")
+	if sanitized == "" {
+		return "", fmt.Errorf("invalid --label %q: it must contain letters or digits", label)
+	}
+	if len(sanitized) > maxLabelLength {
+		return "", fmt.Errorf("invalid --label %q: at most %d characters", label, maxLabelLength)
+	}
+	if sanitized != label {
+		log.Info("Label sanitized for file names", "label", sanitized)
+	}
+	return sanitized, nil
+}
+
+// labeledPath inserts a label before a file's extension
+// (tx-params.json -> tx-params-<label>.json)
+func labeledPath(path, label string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + label + ext
 }
 
-// weiToGwei converts wei to gwei string
-func weiToGwei(wei *big.Int) string {
-	if wei == nil {
-		return "0"
+// writeTxParams serializes transaction parameters to a file
+func writeTxParams(path string, txParams *types.TxParams) error {
+	if err := checkOverwrite(path, forceFlag); err != nil {
+		return err
+	}
+	if err := checkMaxGasPrice(&txParams.Transaction); err != nil {
+		return err
+	}
+	txParams.SchemaVersion = types.SchemaVersion
+	txParams.Metadata.TxTypeName = types.TxTypeName(txParams.Transaction.TxType)
+	if labelFlag != "" {
+		txParams.Metadata.Label = labelFlag
+	}
+	var data []byte
+	var err error
+	if cborFlag {
+		data, err = types.EncodeCBOR(txParams)
+	} else {
+		data, err = json.MarshalIndent(txParams, "", "  ")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to serialize transaction: %w", err)
 	}
-	gwei := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(params.GWei))
-	return fmt.Sprintf("%.2f", gwei)
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	journalTxParams("prepare", "prepared", path, txParams)
+
+	return nil
+}
+
+// writeBundle writes each transaction of a bundle to its own file, derived
+// from the output path (e.g. tx-params-1-approve.json, tx-params-2-deposit.json)
+func writeBundle(bundle []*types.TxParams) error {
+	base := strings.TrimSuffix(outputFlag, filepath.Ext(outputFlag))
+
+	ext := ".json"
+	if cborFlag {
+		ext = ".cbor"
+	}
+
+	// Check every path first so that a bundle is never written partially
+	var files []string
+	for _, txParams := range bundle {
+		b := txParams.Metadata.Bundle
+		path := fmt.Sprintf("%s-%d-%s%s", base, b.Sequence, b.Step, ext)
+		if err := checkOverwrite(path, forceFlag); err != nil {
+			return err
+		}
+		if err := checkMaxGasPrice(&txParams.Transaction); err != nil {
+			return err
+		}
+		files = append(files, path)
+	}
+	for i, txParams := range bundle {
+		if err := writeTxParams(files[i], txParams); err != nil {
+			return err
+		}
+	}
+
+	log.Info("✓ Transaction bundle prepared successfully", "bundle_id", bundle[0].Metadata.Bundle.ID)
+	for i, f := range files {
+		log.Info(fmt.Sprintf("  [%d/%d] Output", i+1, len(files)),
+			"file", f,
+			"nonce", bundle[i].Transaction.Nonce)
+	}
+	log.Info("  Next", "instruction", "Sign each file offline, then broadcast them together with 'cryptoheir broadcast --batch <signed files>'")
+
+	return nil
+}
+
+// checkExistingDeployment guards against accidentally deploying a second
+// contract while CONTRACT_ADDRESS already points to a live one, which would
+// leave the old contract and its deposits orphaned
+func checkExistingDeployment(ctx context.Context, client *ethclient.Client, config *types.Config) error {
+	if strictRedeployGuardFlag && noRedeployGuardFlag {
+		return fmt.Errorf("--strict-redeploy-guard and --no-redeploy-guard are mutually exclusive")
+	}
+	if noRedeployGuardFlag || config.ContractAddress == nil {
+		return nil
+	}
+
+	code, err := network.GetCode(ctx, client, *config.ContractAddress)
+	if err != nil {
+		return err
+	}
+	if len(code) == 0 {
+		return nil
+	}
+
+	if strictRedeployGuardFlag {
+		return fmt.Errorf("a contract is already deployed at CONTRACT_ADDRESS %s; pass --no-redeploy-guard to deploy another", config.ContractAddress.Hex())
+	}
+	log.Warn("⚠ A CONTRACT IS ALREADY DEPLOYED AT CONTRACT_ADDRESS - deploying another leaves the existing contract and its deposits behind",
+		"contract_address", config.ContractAddress.Hex(),
+		"code_bytes", len(code))
+	log.Warn("If this is intended, pass --no-redeploy-guard to silence this warning; use --strict-redeploy-guard to make it an error")
+	return nil
+}
+
+// verifyArtifactRelease checks the embedded artifact against the
+// --verify-release version. Deployments send the artifact itself; for calls
+// it is checked too, since its ABI encodes the call.
+func verifyArtifactRelease(operation string) error {
+	if operation == "send" {
+		return fmt.Errorf("--verify-release does not apply to send, which does not use the contract")
+	}
+	if err := contract.VerifyArtifactRelease(verifyReleaseFlag); err != nil {
+		return err
+	}
+	log.Info("✓ Embedded contract matches the official release", "version", verifyReleaseFlag)
+	return nil
+}
+
+// verifyDeployedRelease checks the code at CONTRACT_ADDRESS against the
+// --verify-release version, so a call is never prepared for a contract that
+// is not an official deployment
+func verifyDeployedRelease(ctx context.Context, client *ethclient.Client, config *types.Config) error {
+	if config.ContractAddress == nil {
+		return fmt.Errorf("CONTRACT_ADDRESS not set in environment")
+	}
+	code, err := network.GetCode(ctx, client, *config.ContractAddress)
+	if err != nil {
+		return err
+	}
+	if err := contract.VerifyRuntimeRelease(verifyReleaseFlag, code); err != nil {
+		return fmt.Errorf("contract at %s: %w", config.ContractAddress.Hex(), err)
+	}
+	log.Info("✓ On-chain contract code matches the official release", "version", verifyReleaseFlag, "contract_address", config.ContractAddress.Hex())
+	return nil
+}
+
+func prepareDeploy(ctx context.Context, client *ethclient.Client, signerAddress common.Address, nonce uint64, chainID uint64, networkName, rpcURL string) (*types.TxParams, error) {
+	log.Info("Preparing contract deployment...")
+
+	if delegateFlag != "" {
+		return nil, fmt.Errorf("--delegate cannot be used with deploy (EIP-7702 transactions require a recipient)")
+	}
+
+	// Load bytecode
+	bytecode, err := contract.LoadBytecode()
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Contract bytecode loaded", "bytes", len(bytecode))
+	if dumpCalldataFlag {
+		return nil, dumpCalldata(nil, bytecode, nil)
+	}
+	enforced, err := checkInitcodeSize(ctx, client, chainID, bytecode)
+	if err != nil {
+		return nil, err
+	}
+
+	// Estimate gas
+	gasLimit, err := network.EstimateGas(ctx, client, signerAddress, nil, bytecode, nil)
+	if err != nil {
+		return nil, fmt.Errorf("gas estimation failed: %w", err)
+	}
+	log.Info("Estimated gas", "gas", gasLimit.String())
+	if err := checkGasEstimate("deploy", gasLimit); err != nil {
+		return nil, err
+	}
+	logInitcodeGas(bytecode, enforced)
+
+	// Get gas prices
+	gasPrices, err := getGasPrices(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build transaction data
+	txData := types.TransactionData{
+		From:     signerAddress,
+		To:       nil, // Contract deployment
+		Data:     bytecode,
+		Nonce:    nonce,
+		ChainID:  chainID,
+		GasLimit: types.NewBigInt(gasLimit),
+	}
+
+	// Set gas prices based on transaction type
+	applyGasPrices(&txData, gasPrices)
+	if err := checkFeeFields(&txData); err != nil {
+		return nil, err
+	}
+
+	// Build TxParams
+	txParams := &types.TxParams{
+		Mode:        types.TransactionModeDeploy,
+		Transaction: txData,
+		Metadata:    newMetadata(networkName, chainID, rpcURL),
+	}
+
+	return txParams, nil
+}
+
+// prepareDeposit prepares a deposit into CONTRACT_ADDRESS. awaiting names
+// the earlier transaction of a bundle the deposit depends on ("approval",
+// "deployment"), or is empty for a deposit that can be simulated now.
+func prepareDeposit(ctx context.Context, client *ethclient.Client, config *types.Config, signerAddress common.Address, nonce uint64, chainID uint64, networkName, rpcURL, awaiting string) (*types.TxParams, error) {
+	log.Info("Preparing deposit transaction...")
+
+	// Validate required flags
+	if beneficiaryFlag == "" {
+		return nil, fmt.Errorf("--beneficiary is required")
+	}
+	if amountFlag == "" {
+		return nil, fmt.Errorf("--amount is required")
+	}
+	if deadlineFlag == 0 {
+		return nil, fmt.Errorf("--deadline is required")
+	}
+
+	// Get contract address
+	if config.ContractAddress == nil {
+		return nil, fmt.Errorf("CONTRACT_ADDRESS not set in environment")
+	}
+	contractAddress := *config.ContractAddress
+
+	// Parse and validate beneficiary address (or resolve its ENS name or
+	// address book label)
+	var beneficiary common.Address
+	var beneficiaryENS, beneficiaryLabel string
+	var err error
+	if isAddressBookLabel(beneficiaryFlag) {
+		beneficiary, beneficiaryLabel, err = resolveAddressBook(beneficiaryFlag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid beneficiary: %w", err)
+		}
+	} else if isENSName(beneficiaryFlag) {
+		beneficiary, beneficiaryENS, err = resolveENS(ctx, client, beneficiaryFlag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid beneficiary: %w", err)
+		}
+		log.Info("Resolved ENS name", "name", beneficiaryENS, "address", beneficiary.Hex())
+	} else {
+		beneficiary, err = types.ParseAddress(beneficiaryFlag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid beneficiary: %w", err)
+		}
+	}
+	if err := validateBeneficiary(beneficiary, contractAddress, signerAddress); err != nil {
+		return nil, err
+	}
+
+	// Compare against previously used beneficiaries (address poisoning)
+	var history *store.BeneficiaryHistory
+	if !noHistoryFlag {
+		history = checkBeneficiaryHistory(chainID, beneficiary)
+	}
+
+	// Parse token address (optional)
+	var token *common.Address
+	var tokenLabel string
+	if tokenFlag != "" {
+		tokenAddr, label, err := parseAddressArg(tokenFlag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token: %w", err)
+		}
+		token, tokenLabel = &tokenAddr, label
+	}
+
+	// Parse amount (ETH to wei, or token units using the token's decimals)
+	var amount *big.Int
+	var info *tokenInfo
+	if token != nil {
+		if info, err = prepareTokenInfo(ctx, client, *token); err != nil {
+			return nil, err
+		}
+		log.Info("Token", "address", token.Hex(), "symbol", info.Symbol, "decimals", info.Decimals)
+		amount, err = types.ParseUnits(amountFlag, info.Decimals)
+	} else {
+		amount, err = parseEther(amountFlag)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount: %w", err)
+	}
+	if info != nil {
+		log.Info("Deposit amount", "amount", types.FormatAmount(amount, info.Decimals, info.unitName()))
+	}
+	if err := checkMaxAmount(config, amount, token, info); err != nil {
+		return nil, err
+	}
+
+	// Parse deadline
+	deadline := big.NewInt(deadlineFlag)
+	if err := checkDepositDeadline(ctx, client, deadline); err != nil {
+		return nil, err
+	}
+
+	// Encode deposit function
+	data, value, err := contract.EncodeDeposit(beneficiary, amount, deadline, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if dumpCalldataFlag {
+		return nil, dumpCalldata(&contractAddress, data, value)
+	}
+
+	// Estimate gas. When the approval or the contract is prepared in the
+	// same bundle it has not been mined yet, so the deposit cannot be
+	// simulated successfully.
+	var gasLimit *big.Int
+	if awaiting != "" {
+		gasLimit = new(big.Int).SetUint64(defaultGasLimits["deposit"])
+		log.Info(fmt.Sprintf("Using default deposit gas limit (%s not yet mined)", awaiting), "gas", gasLimit.String())
+	} else {
+		gasLimit, err = network.EstimateGas(ctx, client, signerAddress, &contractAddress, data, value)
+		if err != nil {
+			return nil, fmt.Errorf("gas estimation failed: %w", err)
+		}
+		log.Info("Estimated gas", "gas", gasLimit.String())
+		if err := checkGasEstimate("deposit", gasLimit); err != nil {
+			return nil, err
+		}
+	}
+
+	// Get gas prices
+	gasPrices, err := getGasPrices(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build transaction data
+	txData := types.TransactionData{
+		From:     signerAddress,
+		To:       &contractAddress,
+		Data:     data,
+		Nonce:    nonce,
+		ChainID:  chainID,
+		GasLimit: types.NewBigInt(gasLimit),
+	}
+
+	// Set value (native deposit amount)
+	if value != nil {
+		txData.Value = types.NewBigInt(value)
+		log.Info("Deposit value", "value", types.FormatAmount(value, 18, "ETH"))
+	}
+
+	// Set gas prices
+	applyGasPrices(&txData, gasPrices)
+
+	// Attach EIP-7702 delegation if requested
+	if delegateFlag != "" {
+		if err := applyDelegation(&txData); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkFeeFields(&txData); err != nil {
+		return nil, err
+	}
+
+	// Build parameters JSON
+	params := map[string]interface{}{
+		"beneficiary": beneficiary.Hex(),
+		"amount":      amount.String(),
+		"deadline":    deadline.String(),
+	}
+	if beneficiaryENS != "" {
+		params["beneficiary_ens"] = beneficiaryENS
+	}
+	if token != nil {
+		params["token"] = token.Hex()
+	}
+	paramsJSON, _ := json.Marshal(params)
+
+	// Build TxParams
+	txParams := &types.TxParams{
+		Mode:         types.TransactionModeCall,
+		FunctionName: "deposit",
+		Params:       paramsJSON,
+		Transaction:  txData,
+		Metadata:     newMetadata(networkName, chainID, rpcURL),
+	}
+	if beneficiaryENS != "" {
+		recordENSName(txParams, beneficiaryENS, beneficiary)
+	}
+	if beneficiaryLabel != "" {
+		recordAddressLabel(txParams, beneficiaryLabel, beneficiary)
+	}
+	if tokenLabel != "" {
+		recordAddressLabel(txParams, tokenLabel, *token)
+	}
+	if info != nil {
+		recordTokenInfo(txParams, info)
+	}
+
+	if history != nil {
+		history.Record(chainID, beneficiary)
+		if err := history.Save(); err != nil {
+			log.Warn("Failed to update beneficiary history", "error", err)
+		}
+	}
+
+	window := contract.NewClaimWindow(deadline)
+	if txParams.Metadata.AdditionalInfo == nil {
+		txParams.Metadata.AdditionalInfo = make(map[string]interface{})
+	}
+	txParams.Metadata.AdditionalInfo["claim_window"] = window.Metadata()
+
+	log.Info("Deposit prepared for beneficiary", "beneficiary", beneficiary.Hex())
+	log.Info("  Claim window: " + window.Summary())
+	return txParams, nil
+}
+
+// depositDeadlineMargin is how far ahead a deadline must be not to warn that
+// the deposit may be mined after it
+const depositDeadlineMargin = time.Hour
+
+// checkDepositDeadline refuses a deadline the contract would reject: deposit
+// reverts with InvalidDeadline unless the deadline is after the timestamp of
+// the block including it. It is compared with the latest block, or with this
+// machine's clock when the node cannot be asked. A deadline that close warns,
+// since the deposit is usually mined only after offline signing.
+func checkDepositDeadline(ctx context.Context, client *ethclient.Client, deadline *big.Int) error {
+	now, err := network.GetBlockTime(ctx, client)
+	if err != nil {
+		log.Debug("Could not get the latest block time; comparing the deadline with the local clock", "error", err)
+		now = time.Now()
+	}
+	if deadline.Cmp(big.NewInt(now.Unix())) <= 0 {
+		return fmt.Errorf("deadline %s (%s) is not after the latest block (%s); the contract rejects such deposits with InvalidDeadline",
+			deadline, time.Unix(deadline.Int64(), 0).UTC().Format(time.RFC3339), now.UTC().Format(time.RFC3339))
+	}
+	if remaining := time.Duration(deadline.Int64()-now.Unix()) * time.Second; remaining < depositDeadlineMargin {
+		log.Warn("⚠ The deadline is less than an hour away; the deposit reverts (InvalidDeadline) if it is mined after it",
+			"remaining", remaining)
+	}
+	return nil
+}
+
+// checkGasEstimate compares a gas estimate with the operation's floor in
+// minimumGasEstimates. An estimate below it warns, or fails with --strict-gas.
+func checkGasEstimate(operation string, gasLimit *big.Int) error {
+	floor, ok := minimumGasEstimates[operation]
+	if !ok || gasLimit.Cmp(new(big.Int).SetUint64(floor)) >= 0 {
+		return nil
+	}
+	msg := fmt.Sprintf("the %s gas estimate of %s is below the plausible minimum of %d; the node may not have executed the intended call, and the transaction could run out of gas",
+		operation, gasLimit, floor)
+	if strictGasFlag {
+		return fmt.Errorf("%s (--strict-gas)", msg)
+	}
+	log.Warn("⚠ SUSPICIOUS GAS ESTIMATE: " + msg)
+	log.Warn("  Check the RPC endpoint and prepare again, or use --strict-gas to refuse such estimates")
+	return nil
+}
+
+// recordTokenInfo stores the token's symbol and decimals so the offline
+// review can show token amounts in token units
+func recordTokenInfo(txParams *types.TxParams, info *tokenInfo) {
+	if txParams.Metadata.AdditionalInfo == nil {
+		txParams.Metadata.AdditionalInfo = make(map[string]interface{})
+	}
+	txParams.Metadata.AdditionalInfo["token_symbol"] = info.Symbol
+	txParams.Metadata.AdditionalInfo["token_decimals"] = info.Decimals
+}
+
+// validateBeneficiary rejects beneficiaries that would lock funds where no
+// one can claim them, or that the contract refuses
+func validateBeneficiary(beneficiary, contractAddress, depositor common.Address) error {
+	if beneficiary == (common.Address{}) {
+		return fmt.Errorf("beneficiary must not be the zero address (funds could never be claimed)")
+	}
+	if beneficiary == contractAddress {
+		return fmt.Errorf("beneficiary must not be the CryptoHeir contract itself (%s)", contractAddress.Hex())
+	}
+	if beneficiary == depositor {
+		// The contract reverts with InvalidBeneficiary for self-inheritance;
+		// catch it here with a clear message instead of a failed estimation
+		return fmt.Errorf("beneficiary %s is the depositor (signer) address; self-inheritance is not allowed", beneficiary.Hex())
+	}
+	return nil
+}
+
+// checkBeneficiaryHistory warns when the beneficiary resembles, but differs
+// from, an address used before on the same chain. Returns nil if the history
+// cannot be loaded, which disables recording. A look-alike is not recorded
+// without --confirm-beneficiary: once known, it would no longer be flagged.
+func checkBeneficiaryHistory(chainID uint64, beneficiary common.Address) *store.BeneficiaryHistory {
+	history, err := store.LoadBeneficiaries()
+	if err != nil {
+		log.Warn("Failed to load beneficiary history", "error", err)
+		return nil
+	}
+
+	if history.Known(chainID, beneficiary) {
+		log.Info("Beneficiary previously used on this chain", "beneficiary", beneficiary.Hex())
+		return history
+	}
+
+	lookalikes := history.Lookalikes(chainID, beneficiary)
+	if len(lookalikes) == 0 {
+		log.Info("New beneficiary for this chain (not in local history)", "beneficiary", beneficiary.Hex())
+		return history
+	}
+
+	log.Warn("═════════════════════════════════════════")
+	log.Warn("⚠ POSSIBLE ADDRESS POISONING")
+	log.Warn("═════════════════════════════════════════")
+	log.Warn("  The beneficiary looks like an address you used before but is DIFFERENT",
+		"beneficiary", beneficiary.Hex())
+	for _, e := range lookalikes {
+		log.Warn("    Previously used", "address", e.Address.Hex(), "last_used", e.LastUsed)
+	}
+	log.Warn("  Verify every character of the beneficiary address from a trusted source.")
+	log.Warn("  Do not copy addresses from your transaction history.")
+
+	if !confirmBeneficiaryFlag {
+		log.Warn("  The beneficiary is not added to the history; once verified, prepare again with --confirm-beneficiary to record it.")
+		return nil
+	}
+	log.Warn("  Beneficiary confirmed (--confirm-beneficiary); recording it in the history.")
+	return history
+}
+
+// prepareApproveAndDeposit prepares an ERC20 approve (nonce N) and the
+// token deposit (nonce N+1) as a linked two-transaction bundle
+func prepareApproveAndDeposit(ctx context.Context, client *ethclient.Client, config *types.Config, signerAddress common.Address, nonce uint64, chainID uint64, networkName, rpcURL string) ([]*types.TxParams, error) {
+	if tokenFlag == "" {
+		return nil, fmt.Errorf("--with-approve requires --token")
+	}
+
+	// Deposit follows at the next nonce (this also validates the deposit flags)
+	deposit, err := prepareDeposit(ctx, client, config, signerAddress, nonce+1, chainID, networkName, rpcURL, "approval")
+	if err != nil {
+		return nil, err
+	}
+	contractAddress := *deposit.Transaction.To
+
+	// Approve exactly the amount the deposit will transfer, of the token it
+	// resolved
+	var depositParams struct {
+		Amount string `json:"amount"`
+		Token  string `json:"token"`
+	}
+	if err := json.Unmarshal(deposit.Params, &depositParams); err != nil {
+		return nil, fmt.Errorf("failed to read deposit parameters: %w", err)
+	}
+	token, err := types.ParseAddress(depositParams.Token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	amount, ok := new(big.Int).SetString(depositParams.Amount, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid deposit amount: %s", depositParams.Amount)
+	}
+
+	log.Info("Preparing token approval...", "token", token.Hex(), "spender", contractAddress.Hex())
+
+	// Encode approve(contract, amount)
+	data, err := contract.EncodeApprove(contractAddress, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	gasLimit, err := network.EstimateGas(ctx, client, signerAddress, &token, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("gas estimation failed: %w", err)
+	}
+	log.Info("Estimated gas", "gas", gasLimit.String())
+	if err := checkGasEstimate("approve", gasLimit); err != nil {
+		return nil, err
+	}
+
+	gasPrices, err := getGasPrices(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	approveData := types.TransactionData{
+		From:     signerAddress,
+		To:       &token,
+		Data:     data,
+		Nonce:    nonce,
+		ChainID:  chainID,
+		GasLimit: types.NewBigInt(gasLimit),
+	}
+	applyGasPrices(&approveData, gasPrices)
+	if err := checkFeeFields(&approveData); err != nil {
+		return nil, err
+	}
+
+	paramsJSON, _ := json.Marshal(map[string]interface{}{
+		"spender": contractAddress.Hex(),
+		"amount":  amount.String(),
+		"token":   token.Hex(),
+	})
+
+	approve := &types.TxParams{
+		Mode:         types.TransactionModeCall,
+		FunctionName: "approve",
+		Params:       paramsJSON,
+		Transaction:  approveData,
+		Metadata:     newMetadata(networkName, chainID, rpcURL),
+	}
+
+	if symbol, ok := deposit.Metadata.AdditionalInfo["token_symbol"]; ok {
+		approve.Metadata.AdditionalInfo = map[string]interface{}{
+			"token_symbol":   symbol,
+			"token_decimals": deposit.Metadata.AdditionalInfo["token_decimals"],
+		}
+	}
+
+	bundle := []*types.TxParams{approve, deposit}
+	if err := linkBundle(bundle, []string{"approve", "deposit"}); err != nil {
+		return nil, err
+	}
+
+	return bundle, nil
+}
+
+// prepareSetup prepares the deployment of a new contract (nonce N) and a
+// first native deposit into it (nonce N+1). The deposit targets the address
+// the deployment will create, which is known before it exists.
+func prepareSetup(ctx context.Context, client *ethclient.Client, config *types.Config, signerAddress common.Address, nonce uint64, chainID uint64, networkName, rpcURL string) ([]*types.TxParams, error) {
+	deploy, err := prepareDeploy(ctx, client, signerAddress, nonce, chainID, networkName, rpcURL)
+	if err != nil {
+		return nil, err
+	}
+
+	predicted := crypto.PredictContractAddress(signerAddress, nonce)
+	log.Info("Contract will be deployed at", "address", predicted.Hex(), "nonce", nonce)
+
+	// The deposit goes to the predicted address, not CONTRACT_ADDRESS
+	depositConfig := *config
+	depositConfig.ContractAddress = &predicted
+	deposit, err := prepareDeposit(ctx, client, &depositConfig, signerAddress, nonce+1, chainID, networkName, rpcURL, "deployment")
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := []*types.TxParams{deploy, deposit}
+	if err := linkBundle(bundle, []string{"deploy", "deposit"}); err != nil {
+		return nil, err
+	}
+
+	return bundle, nil
+}
+
+// checkInitcodeSize fails when the initcode (bytecode plus any constructor
+// arguments) exceeds the EIP-3860 limit on a chain that enforces it, where
+// the creation transaction would be rejected. It reports whether the limit
+// applies. When the chain cannot be checked, the limit is assumed.
+func checkInitcodeSize(ctx context.Context, client *ethclient.Client, chainID uint64, initcode []byte) (bool, error) {
+	enforced, err := network.EnforcesInitcodeLimit(ctx, client, chainID)
+	if err != nil {
+		log.Debug("Could not check for the EIP-3860 initcode limit; assuming it applies", "error", err)
+		enforced = true
+	}
+	if len(initcode) <= params.MaxInitCodeSize {
+		return enforced, nil
+	}
+	if !enforced {
+		log.Warn("⚠ Initcode exceeds the EIP-3860 limit, which this chain does not enforce yet; it will be rejected once Shanghai activates",
+			"bytes", len(initcode), "limit", params.MaxInitCodeSize)
+		return false, nil
+	}
+	return true, fmt.Errorf("initcode is %d bytes, above the EIP-3860 limit of %d bytes enforced since Shanghai; the deployment would be rejected. Reduce the contract size (e.g. enable the optimizer, split it into libraries) and rebuild",
+		len(initcode), params.MaxInitCodeSize)
+}
+
+// logInitcodeGas shows the intrinsic gas of a deployment, including the
+// EIP-3860 charge per 32-byte word of initcode, next to the estimate
+func logInitcodeGas(initcode []byte, eip3860 bool) {
+	dataGas := uint64(0)
+	for _, b := range initcode {
+		if b == 0 {
+			dataGas += params.TxDataZeroGas
+		} else {
+			dataGas += params.TxDataNonZeroGasEIP2028
+		}
+	}
+	var wordGas uint64
+	if eip3860 {
+		wordGas = (uint64(len(initcode)) + 31) / 32 * params.InitCodeWordGas
+	}
+	log.Info("Deployment intrinsic gas (included in the estimate)",
+		"intrinsic", params.TxGasContractCreation+dataGas+wordGas,
+		"calldata_gas", dataGas,
+		"initcode_word_gas", wordGas,
+		"initcode_bytes", len(initcode))
+}
+
+// linkBundle records shared bundle metadata on each transaction so they
+// can be broadcast together in order
+func linkBundle(bundle []*types.TxParams, steps []string) error {
+	idBytes := make([]byte, 8)
+	if _, err := rand.Read(idBytes); err != nil {
+		return fmt.Errorf("failed to generate bundle ID: %w", err)
+	}
+	id := hex.EncodeToString(idBytes)
+
+	for i, txParams := range bundle {
+		txParams.Metadata.Bundle = &types.BundleInfo{
+			ID:       id,
+			Sequence: i + 1,
+			Size:     len(bundle),
+			Step:     steps[i],
+		}
+	}
+
+	return nil
+}
+
+// checkFeeFields verifies that the fee fields match the transaction type, so
+// an inconsistent transaction fails here rather than at signing time
+func checkFeeFields(txData *types.TransactionData) error {
+	switch txData.TxType {
+	case 2, 4:
+		if txData.MaxFeePerGas == nil || txData.MaxPriorityFeePerGas == nil {
+			return fmt.Errorf("inconsistent fees: type-%d transaction requires both max_fee_per_gas and max_priority_fee_per_gas", txData.TxType)
+		}
+		if txData.GasPrice != nil {
+			return fmt.Errorf("inconsistent fees: type-%d transaction must not set gas_price", txData.TxType)
+		}
+		maxFee, priorityFee := txData.MaxFeePerGas.ToBigInt(), txData.MaxPriorityFeePerGas.ToBigInt()
+		if maxFee.Cmp(priorityFee) < 0 {
+			return fmt.Errorf("inconsistent fees: max_fee_per_gas (%s gwei) is below max_priority_fee_per_gas (%s gwei)",
+				types.FormatGwei(maxFee), types.FormatGwei(priorityFee))
+		}
+	case 0:
+		if txData.GasPrice == nil {
+			return fmt.Errorf("inconsistent fees: legacy transaction requires gas_price")
+		}
+		if txData.MaxFeePerGas != nil || txData.MaxPriorityFeePerGas != nil {
+			return fmt.Errorf("inconsistent fees: legacy transaction must not set EIP-1559 fee fields")
+		}
+	default:
+		return fmt.Errorf("unsupported transaction type: %d", txData.TxType)
+	}
+	return nil
+}
+
+// applyGasLimitPercent replaces the gas limit with --gas-limit-percent of the
+// latest block's gas limit. The result stays below the block limit, but a
+// limit under the estimate makes the transaction run out of gas, so that case
+// is refused.
+func applyGasLimitPercent(ctx context.Context, client *ethclient.Client, txData *types.TransactionData) error {
+	blockGasLimit, err := network.GetBlockGasLimit(ctx, client)
+	if err != nil {
+		return err
+	}
+	gasLimit := gasLimitFromPercent(blockGasLimit, gasLimitPctFlag)
+	if gasLimit < params.TxGas {
+		return fmt.Errorf("--gas-limit-percent %g of the block gas limit %d gives %d gas, below the minimum of %d",
+			gasLimitPctFlag, blockGasLimit, gasLimit, params.TxGas)
+	}
+	if gasLimit >= blockGasLimit {
+		return fmt.Errorf("--gas-limit-percent %g gives %d gas, which does not fit below the block gas limit %d",
+			gasLimitPctFlag, gasLimit, blockGasLimit)
+	}
+
+	estimated := txData.GasLimit.ToBigInt()
+	if estimated.Cmp(new(big.Int).SetUint64(gasLimit)) > 0 {
+		return fmt.Errorf("--gas-limit-percent %g gives %d gas, below the estimated %s; the transaction would run out of gas",
+			gasLimitPctFlag, gasLimit, estimated)
+	}
+
+	txData.GasLimit = types.NewBigInt(new(big.Int).SetUint64(gasLimit))
+	log.Info("Gas limit from block percentage",
+		"percent", gasLimitPctFlag,
+		"block_gas_limit", blockGasLimit,
+		"gas", gasLimit,
+		"estimated", estimated)
+	return nil
+}
+
+// gasLimitFromPercent returns percent of a block gas limit, rounded down.
+// The percentage is taken as the decimal it was written as, since float
+// arithmetic would turn e.g. 33.3% of 30,000,000 into 9,989,999.
+func gasLimitFromPercent(blockGasLimit uint64, percent float64) uint64 {
+	pct, ok := new(big.Rat).SetString(strconv.FormatFloat(percent, 'f', -1, 64))
+	if !ok || pct.Sign() <= 0 {
+		return 0
+	}
+	gas := new(big.Rat).Mul(new(big.Rat).SetUint64(blockGasLimit), pct)
+	gas.Quo(gas, big.NewRat(100, 1))
+	return new(big.Int).Quo(gas.Num(), gas.Denom()).Uint64()
+}
+
+// getGasPrices fetches gas prices and enforces --max-gas-price, so a fee
+// spike stops the workflow before anything is written
+func getGasPrices(ctx context.Context, client *ethclient.Client) (*network.GasPrices, error) {
+	gasPrices, err := network.GetGasPrices(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	if maxGasPriceFlag == "" {
+		return gasPrices, nil
+	}
+
+	limit, err := maxGasPrice()
+	if err != nil {
+		return nil, err
+	}
+	current, field := gasPrices.GasPrice, "gas price"
+	if gasPrices.IsEIP1559 {
+		current, field = gasPrices.MaxFeePerGas, "max fee per gas"
+	}
+	if current.Cmp(limit) > 0 {
+		return nil, fmt.Errorf("current %s of %s gwei exceeds --max-gas-price %s gwei; try again later or raise the cap",
+			field, types.FormatGwei(current), maxGasPriceFlag)
+	}
+	log.Info("Gas price below cap", "current_gwei", types.FormatGwei(current), "max_gwei", maxGasPriceFlag)
+	return gasPrices, nil
+}
+
+// checkMaxGasPrice enforces --max-gas-price on the fee the transaction will
+// actually be signed with, which may come from a template rather than the
+// prices fetched by getGasPrices
+func checkMaxGasPrice(txData *types.TransactionData) error {
+	if maxGasPriceFlag == "" {
+		return nil
+	}
+	limit, err := maxGasPrice()
+	if err != nil {
+		return err
+	}
+	fee, field := txData.MaxFeePerGas, "max fee per gas"
+	if txData.TxType == 0 {
+		fee, field = txData.GasPrice, "gas price"
+	}
+	if fee.ToBigInt().Cmp(limit) > 0 {
+		return fmt.Errorf("the transaction's %s of %s gwei exceeds --max-gas-price %s gwei",
+			field, types.FormatGwei(fee.ToBigInt()), maxGasPriceFlag)
+	}
+	return nil
+}
+
+// maxGasPrice parses --max-gas-price from gwei to wei
+func maxGasPrice() (*big.Int, error) {
+	limit, err := types.ParseUnits(maxGasPriceFlag, 9)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --max-gas-price: %w", err)
+	}
+	if limit.Sign() == 0 {
+		return nil, fmt.Errorf("--max-gas-price must be positive")
+	}
+	return limit, nil
+}
+
+// applyGasPrices sets the fee fields and transaction type from fetched gas prices
+func applyGasPrices(txData *types.TransactionData, gasPrices *network.GasPrices) {
+	if gasPrices.IsEIP1559 {
+		txData.TxType = 2
+		txData.MaxFeePerGas = types.NewBigInt(gasPrices.MaxFeePerGas)
+		txData.MaxPriorityFeePerGas = types.NewBigInt(gasPrices.MaxPriorityFeePerGas)
+		log.Info("EIP-1559",
+			"max_fee_gwei", types.FormatGwei(gasPrices.MaxFeePerGas),
+			"priority_fee_gwei", types.FormatGwei(gasPrices.MaxPriorityFeePerGas))
+	} else {
+		txData.TxType = 0
+		txData.GasPrice = types.NewBigInt(gasPrices.GasPrice)
+		log.Info("Legacy", "gas_price_gwei", types.FormatGwei(gasPrices.GasPrice))
+	}
+}
+
+// newMetadata builds the metadata recorded for a freshly prepared transaction
+func newMetadata(networkName string, chainID uint64, rpcURL string) types.Metadata {
+	return types.Metadata{
+		PreparedAt:  time.Now().UTC().Format(time.RFC3339),
+		Network:     types.NetworkInfo{Name: networkName, ChainID: chainID, RPCURL: rpcURL},
+		ToolVersion: version.ToolVersion(),
+	}
+}
+
+// applyDelegation converts an EIP-1559 transaction into an EIP-7702 set-code
+// transaction carrying an unsigned authorization for the signer account.
+// The authorization is signed together with the transaction by the offline signer.
+func applyDelegation(txData *types.TransactionData) error {
+	target, err := types.ParseAddress(delegateFlag)
+	if err != nil {
+		return fmt.Errorf("invalid --delegate: %w", err)
+	}
+
+	if txData.TxType != 2 {
+		return fmt.Errorf("--delegate requires a network with EIP-1559 support")
+	}
+	if txData.To == nil {
+		return fmt.Errorf("--delegate requires a transaction recipient")
+	}
+
+	// The sender's nonce is incremented before authorizations are processed,
+	// so a self-sponsored authorization must commit to the following nonce
+	txData.TxType = 4
+	txData.AuthorizationList = []types.Authorization{{
+		ChainID: txData.ChainID,
+		Address: target,
+		Nonce:   txData.Nonce + 1,
+	}}
+
+	// Each authorization is charged on top of the estimated execution gas
+	gasLimit := new(big.Int).Add(txData.GasLimit.ToBigInt(), new(big.Int).SetUint64(params.CallNewAccountGas))
+	txData.GasLimit = types.NewBigInt(gasLimit)
+
+	log.Warn("⚠ EIP-7702 delegation attached: the signer account will execute code from",
+		"delegate", target.Hex())
+	return nil
+}
+
+// checkMaxAmount guards against an extra zero in the deposited principal.
+// Above the configured maximum the deposit needs --confirm-large or, on a
+// terminal, an explicit confirmation. token and info are nil for native
+// deposits. Token limits are set per token, since one number of units means
+// very different values for different tokens.
+func checkMaxAmount(config *types.Config, amount *big.Int, token *common.Address, info *tokenInfo) error {
+	limitStr, source := maxAmountFlag, "--max-amount"
+	if limitStr == "" {
+		limitStr, source = config.MaxAmount, "MAX_AMOUNT"
+	}
+	decimals, unit := uint8(18), "ETH"
+	if token != nil {
+		spec, specSource := maxTokenAmountFlag, "--max-token-amount"
+		if len(spec) == 0 && config.MaxTokenAmount != "" {
+			spec, specSource = strings.Split(config.MaxTokenAmount, ","), "MAX_TOKEN_AMOUNT"
+		}
+		limits, err := parseTokenLimits(spec)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", specSource, err)
+		}
+		limitStr, source = limits[*token], fmt.Sprintf("%s for %s", specSource, token.Hex())
+		if limitStr == "" && len(limits) > 0 {
+			log.Warn("⚠ No deposit limit is set for this token", "token", token.Hex(), "limits_from", specSource)
+		}
+		decimals, unit = info.Decimals, info.unitName()
+	}
+	if limitStr == "" {
+		return nil
+	}
+
+	limit, err := types.ParseUnits(limitStr, decimals)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", source, err)
+	}
+	if amount.Cmp(limit) <= 0 {
+		return nil
+	}
+
+	formatted, max := types.FormatAmount(amount, decimals, unit), types.FormatAmount(limit, decimals, unit)
+	if confirmLargeFlag {
+		log.Warn("⚠ Deposit amount exceeds the configured maximum (--confirm-large)", "amount", formatted, "max", max, "limit_from", source)
+		return nil
+	}
+	if tui.IsTerminal() {
+		prompt := os.Stdout
+		if LogsToStderr() {
+			prompt = os.Stderr
+		}
+		fmt.Fprintf(prompt, "Deposit amount %s exceeds the maximum of %s (%s). Deposit anyway? [y/N] ", formatted, max, source)
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a == "y" || a == "yes" {
+			log.Warn("⚠ Large deposit confirmed", "amount", formatted, "max", max)
+			return nil
+		}
+	}
+	return fmt.Errorf("deposit amount %s exceeds the maximum of %s (%s); use --confirm-large to proceed", formatted, max, source)
+}
+
+// parseTokenLimits parses per-token deposit limits given as
+// <token address>=<amount> entries
+func parseTokenLimits(entries []string) (map[common.Address]string, error) {
+	limits := make(map[common.Address]string, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		tokenStr, amount, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(amount) == "" {
+			return nil, fmt.Errorf("%q: expected <token address>=<amount>, since token units differ between tokens", entry)
+		}
+		token, err := types.ParseAddress(strings.TrimSpace(tokenStr))
+		if err != nil {
+			return nil, fmt.Errorf("%q: invalid token address: %w", entry, err)
+		}
+		if _, dup := limits[token]; dup {
+			return nil, fmt.Errorf("token %s is listed more than once", token.Hex())
+		}
+		limits[token] = strings.TrimSpace(amount)
+	}
+	return limits, nil
+}
+
+// parseEther converts an ETH amount to wei exactly, rejecting more than 18
+// decimal places
+func parseEther(ethStr string) (*big.Int, error) {
+	return types.ParseUnits(ethStr, 18)
 }
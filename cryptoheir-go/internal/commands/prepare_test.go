@@ -0,0 +1,408 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/network"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/store"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+func TestMain(m *testing.M) {
+	SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+func TestValidateBeneficiary(t *testing.T) {
+	contractAddress := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	depositor := common.HexToAddress("0x9d8A62f656a8d1615C1294fd71e9CFb3E4855A4F")
+	tests := []struct {
+		name        string
+		beneficiary common.Address
+		wantErr     string
+	}{
+		{"valid", common.HexToAddress("0x2222222222222222222222222222222222222222"), ""},
+		{"zero address", common.Address{}, "zero address"},
+		{"contract address", contractAddress, "contract itself"},
+		{"depositor", depositor, "self-inheritance"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBeneficiary(tt.beneficiary, contractAddress, depositor)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validateBeneficiary() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validateBeneficiary() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseEther(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string // Wei; empty when an error is expected
+		wantErr string
+	}{
+		{"one wei", "0.000000000000000001", "1", ""},
+		{"one million", "1000000", "1000000000000000000000000", ""},
+		{"whole", "1", "1000000000000000000", ""},
+		{"fraction", "1.5", "1500000000000000000", ""},
+		{"trailing zeros", "1.50", "1500000000000000000", ""},
+		{"trailing zeros to 18 places", "1.500000000000000000", "1500000000000000000", ""},
+		{"all 18 places", "1.123456789012345678", "1123456789012345678", ""},
+		{"leading dot", ".5", "500000000000000000", ""},
+		{"trailing dot", "2.", "2000000000000000000", ""},
+		{"surrounding space", " 0.1 ", "100000000000000000", ""},
+		{"zero", "0", "0", ""},
+		{"19 places", "0.0000000000000000001", "", "more than 18 decimal places"},
+		{"19 places of trailing zeros", "1.0000000000000000000", "", "more than 18 decimal places"},
+		{"empty", "", "", "invalid number format"},
+		{"negative", "-1", "", "invalid number format"},
+		{"plus sign", "+1", "", "invalid number format"},
+		{"exponent", "1e18", "", "invalid number format"},
+		{"fraction notation", "1/2", "", "invalid number format"},
+		{"hex", "0x10", "", "invalid number format"},
+		{"digit separator", "1_000", "", "invalid number format"},
+		{"comma", "1,5", "", "invalid number format"},
+		{"two dots", "1.2.3", "", "invalid number format"},
+		{"lone dot", ".", "", "invalid number format"},
+		{"word", "abc", "", "invalid number format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseEther(tt.input)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseEther(%q) = %v, %v; want error mentioning %q", tt.input, got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseEther(%q) error = %v", tt.input, err)
+			}
+			if got.String() != tt.want {
+				t.Fatalf("parseEther(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckMaxAmountPerToken(t *testing.T) {
+	usdc := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	weth := common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+	usdcInfo := &tokenInfo{Decimals: 6, Symbol: "USDC"}
+	wethInfo := &tokenInfo{Decimals: 18, Symbol: "WETH"}
+	units := func(n int64, decimals int) *big.Int {
+		return new(big.Int).Mul(big.NewInt(n), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	}
+
+	tests := []struct {
+		name    string
+		env     string
+		flag    []string
+		token   common.Address
+		info    *tokenInfo
+		amount  *big.Int
+		wantErr string
+	}{
+		{"below the token's limit", usdc.Hex() + "=50000," + weth.Hex() + "=20", nil, usdc, usdcInfo, units(40000, 6), ""},
+		{"above the token's limit", usdc.Hex() + "=50000," + weth.Hex() + "=20", nil, usdc, usdcInfo, units(60000, 6), "exceeds the maximum of 50,000.000000 USDC"},
+		{"limit of another token does not apply", usdc.Hex() + "=50000", nil, weth, wethInfo, units(60000, 18), ""},
+		{"flag overrides the environment", usdc.Hex() + "=50000", []string{usdc.Hex() + "=100"}, usdc, usdcInfo, units(200, 6), "--max-token-amount"},
+		{"bare amount is rejected", "50000", nil, usdc, usdcInfo, units(1, 6), "expected <token address>=<amount>"},
+		{"invalid token address", "0x123=5", nil, usdc, usdcInfo, units(1, 6), "invalid token address"},
+		{"duplicate token", usdc.Hex() + "=5," + usdc.Hex() + "=6", nil, usdc, usdcInfo, units(1, 6), "more than once"},
+	}
+	// Keep stdin off the terminal so a large deposit fails instead of prompting
+	stdin, _, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := os.Stdin
+	os.Stdin = stdin
+	t.Cleanup(func() { os.Stdin = saved; stdin.Close() })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxTokenAmountFlag, confirmLargeFlag = tt.flag, false
+			t.Cleanup(func() { maxTokenAmountFlag = nil })
+			token := tt.token
+			err := checkMaxAmount(&types.Config{MaxTokenAmount: tt.env}, tt.amount, &token, tt.info)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkMaxAmount() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkMaxAmount() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGasLimitFromPercent(t *testing.T) {
+	tests := []struct {
+		block   uint64
+		percent float64
+		want    uint64
+	}{
+		{30_000_000, 50, 15_000_000},
+		{30_000_000, 0.07, 21_000},
+		{30_000_000, 33.3, 9_990_000},
+		{36_000_000, 100.0 / 3, 12_000_000},
+		{30_000_000, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := gasLimitFromPercent(tt.block, tt.percent); got != tt.want {
+			t.Errorf("gasLimitFromPercent(%d, %g) = %d, want %d", tt.block, tt.percent, got, tt.want)
+		}
+	}
+}
+
+func TestApplyGasLimitPercent(t *testing.T) {
+	client := stubNode(t, func(method string, params []json.RawMessage) interface{} {
+		if method == "eth_getBlockByNumber" {
+			return stubHeader(30_000_000)
+		}
+		return nil
+	})
+	t.Cleanup(func() { gasLimitPctFlag = 0 })
+
+	tests := []struct {
+		name      string
+		percent   float64
+		estimated int64
+		want      uint64
+		wantErr   string
+	}{
+		{"half a block", 50, 2_000_000, 15_000_000, ""},
+		{"below the estimate", 1, 2_000_000, 0, "below the estimated"},
+		{"below the minimum transaction gas", 0.01, 0, 0, "below the minimum"},
+		{"whole block", 100, 2_000_000, 0, "does not fit below the block gas limit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gasLimitPctFlag = tt.percent
+			txData := &types.TransactionData{GasLimit: types.NewBigInt(big.NewInt(tt.estimated))}
+			err := applyGasLimitPercent(context.Background(), client, txData)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("applyGasLimitPercent() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				if txData.GasLimit.ToBigInt().Int64() != tt.estimated {
+					t.Errorf("gas limit changed to %s on error", txData.GasLimit)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyGasLimitPercent() error = %v", err)
+			}
+			if got := txData.GasLimit.ToBigInt().Uint64(); got != tt.want {
+				t.Errorf("gas limit = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckInitcodeSize(t *testing.T) {
+	shanghai := stubHeader(30_000_000)
+	shanghai["withdrawalsRoot"] = fmt.Sprintf("0x%064x", 0)
+	preShanghai := stubHeader(30_000_000)
+
+	tests := []struct {
+		name         string
+		chainID      uint64
+		header       map[string]interface{} // nil: the node is not asked
+		size         int
+		wantEnforced bool
+		wantErr      bool
+	}{
+		{"known chain at the limit", 1, nil, 49152, true, false},
+		{"known chain over the limit", 1, nil, 49153, true, true},
+		{"oversized on a Shanghai chain", 31337, shanghai, 60000, true, true},
+		{"oversized before Shanghai only warns", 31337, preShanghai, 60000, false, false},
+		{"within the limit before Shanghai", 31337, preShanghai, 1000, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := stubNode(t, func(method string, params []json.RawMessage) interface{} {
+				if method == "eth_getBlockByNumber" && tt.header != nil {
+					return tt.header
+				}
+				return nil
+			})
+			initcode := bytes.Repeat([]byte{0x60}, tt.size)
+			enforced, err := checkInitcodeSize(context.Background(), client, tt.chainID, initcode)
+			if enforced != tt.wantEnforced {
+				t.Errorf("enforced = %v, want %v", enforced, tt.wantEnforced)
+			}
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "EIP-3860 limit of 49152 bytes") {
+					t.Errorf("error = %v, want the EIP-3860 limit error", err)
+				}
+			} else if err != nil {
+				t.Errorf("checkInitcodeSize() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckGasEstimateLowMock(t *testing.T) {
+	from := common.HexToAddress("0x9d8A62f656a8d1615C1294fd71e9CFb3E4855A4F")
+	contractAddress := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	tests := []struct {
+		name      string
+		operation string
+		estimate  string // raw eth_estimateGas result, before the 20% buffer
+		wantWarn  bool
+	}{
+		{"deposit estimated as a plain transfer", "deposit", "0x5208", true}, // 21000
+		{"plausible deposit", "deposit", "0x1d4c0", false},                   // 120000
+		{"deploy estimated far too low", "deploy", "0x186a0", true},          // 100000
+		{"plausible approve", "approve", "0xb5e6", false},                    // 46566
+		{"operation without a floor", "extend", "0x5208", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := stubNode(t, func(method string, params []json.RawMessage) interface{} {
+				if method == "eth_estimateGas" {
+					return tt.estimate
+				}
+				return nil
+			})
+			gasLimit, err := network.EstimateGas(context.Background(), client, from, &contractAddress, []byte{0x01}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var logged bytes.Buffer
+			SetLogger(slog.New(slog.NewTextHandler(&logged, nil)))
+			t.Cleanup(func() { SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil))) })
+			strictGasFlag = false
+			if err := checkGasEstimate(tt.operation, gasLimit); err != nil {
+				t.Fatalf("checkGasEstimate() error = %v, want only a warning", err)
+			}
+			if warned := strings.Contains(logged.String(), "SUSPICIOUS GAS ESTIMATE"); warned != tt.wantWarn {
+				t.Errorf("warned = %v, want %v (estimate %s)", warned, tt.wantWarn, gasLimit)
+			}
+
+			strictGasFlag = true
+			t.Cleanup(func() { strictGasFlag = false })
+			err = checkGasEstimate(tt.operation, gasLimit)
+			if tt.wantWarn && (err == nil || !strings.Contains(err.Error(), "--strict-gas")) {
+				t.Errorf("checkGasEstimate() with --strict-gas error = %v, want a refusal", err)
+			}
+			if !tt.wantWarn && err != nil {
+				t.Errorf("checkGasEstimate() with --strict-gas error = %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckDepositDeadline(t *testing.T) {
+	const head = 1_800_000_000
+	header := stubHeader(30_000_000)
+	header["timestamp"] = fmt.Sprintf("0x%x", head)
+	client := stubNode(t, func(method string, params []json.RawMessage) interface{} {
+		if method == "eth_getBlockByNumber" {
+			return header
+		}
+		return nil
+	})
+
+	tests := []struct {
+		name     string
+		deadline int64
+		wantErr  bool
+		wantWarn bool
+	}{
+		{"before the latest block", head - 86400, true, false},
+		{"at the latest block", head, true, false},
+		{"within the hour", head + 600, false, true},
+		{"a year ahead", head + 365*86400, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logged bytes.Buffer
+			SetLogger(slog.New(slog.NewTextHandler(&logged, nil)))
+			t.Cleanup(func() { SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil))) })
+
+			err := checkDepositDeadline(context.Background(), client, big.NewInt(tt.deadline))
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "InvalidDeadline") {
+					t.Errorf("checkDepositDeadline() error = %v, want an InvalidDeadline refusal", err)
+				}
+			} else if err != nil {
+				t.Errorf("checkDepositDeadline() error = %v", err)
+			}
+			if warned := strings.Contains(logged.String(), "less than an hour away"); warned != tt.wantWarn {
+				t.Errorf("warned = %v, want %v", warned, tt.wantWarn)
+			}
+		})
+	}
+
+	t.Run("unreachable node falls back to the local clock", func(t *testing.T) {
+		offline, err := ethclient.Dial("http://127.0.0.1:1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer offline.Close()
+		past := big.NewInt(time.Now().Add(-time.Minute).Unix())
+		if err := checkDepositDeadline(context.Background(), offline, past); err == nil {
+			t.Error("checkDepositDeadline() accepted a deadline in the past by the local clock")
+		}
+		future := big.NewInt(time.Now().Add(24 * time.Hour).Unix())
+		if err := checkDepositDeadline(context.Background(), offline, future); err != nil {
+			t.Errorf("checkDepositDeadline() error = %v", err)
+		}
+	})
+}
+
+func TestCheckBeneficiaryHistoryLookalike(t *testing.T) {
+	original := common.HexToAddress("0x1234aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa5678")
+	lookalike := common.HexToAddress("0x1234bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb5678")
+	t.Cleanup(func() { confirmBeneficiaryFlag = false })
+
+	for _, confirm := range []bool{false, true} {
+		t.Run(fmt.Sprintf("confirm=%v", confirm), func(t *testing.T) {
+			t.Setenv("CRYPTOHEIR_HOME", t.TempDir())
+			seed, err := store.LoadBeneficiaries()
+			if err != nil {
+				t.Fatal(err)
+			}
+			seed.Record(1, original)
+			if err := seed.Save(); err != nil {
+				t.Fatal(err)
+			}
+
+			if history := checkBeneficiaryHistory(1, original); history == nil {
+				t.Fatal("a known beneficiary disabled recording")
+			}
+
+			confirmBeneficiaryFlag = confirm
+			history := checkBeneficiaryHistory(1, lookalike)
+			if (history != nil) != confirm {
+				t.Fatalf("recording enabled = %v for a look-alike, want %v", history != nil, confirm)
+			}
+		})
+	}
+}
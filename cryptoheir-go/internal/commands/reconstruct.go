@@ -0,0 +1,258 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/contract"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/network"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/spf13/cobra"
+)
+
+// ReconstructCmd represents the reconstruct command
+var ReconstructCmd = &cobra.Command{
+	Use:   "reconstruct",
+	Short: "Rebuild the transaction parameters of a broadcast transaction from its hash",
+	Long: `Fetch a previously broadcast transaction by its hash, decode its calldata
+against the CryptoHeir and ERC20 ABIs, and write equivalent transaction
+parameters (tx-params.json), without the signature.
+
+This recovers the parameters when the local files were lost, to reference the
+transaction or to repeat it with 'replay --from-params'. Calldata that matches
+neither ABI is written as a generic call with the raw data. The nonce and fees
+are those of the original transaction, so signing the file as is only
+replaces or conflicts with it.`,
+	Args: cobra.NoArgs,
+	RunE: runReconstruct,
+}
+
+var (
+	reconstructTxHashFlag  string
+	reconstructOutputFlag  string
+	reconstructNetworkFlag string
+	reconstructRPCURLFlag  string
+	reconstructRPCURLFile  string
+	reconstructForceFlag   bool
+)
+
+func init() {
+	ReconstructCmd.Flags().StringVar(&reconstructTxHashFlag, "tx-hash", "", "Hash of the broadcast transaction (required)")
+	ReconstructCmd.Flags().StringVarP(&reconstructOutputFlag, "output", "o", "tx-params.json", "Output transaction parameters file")
+	ReconstructCmd.Flags().StringVar(&reconstructNetworkFlag, "network", "sepolia", "Network name (sepolia, mainnet, etc.)")
+	ReconstructCmd.Flags().StringVar(&reconstructRPCURLFlag, "rpc-url", "", "Custom RPC URL (overrides network)")
+	ReconstructCmd.Flags().StringVar(&reconstructRPCURLFile, "rpc-url-file", "", "Read the custom RPC URL from a file ('-' for stdin)")
+	ReconstructCmd.Flags().StringVar(&rpcSelectFlag, "rpc-select", rpcSelectFirst, rpcSelectUsage)
+	ReconstructCmd.Flags().BoolVar(&reconstructForceFlag, "force", false, "Overwrite an existing output file")
+	ReconstructCmd.MarkFlagRequired("tx-hash")
+}
+
+func runReconstruct(cmd *cobra.Command, args []string) error {
+	raw, err := hexutil.Decode(strings.TrimSpace(reconstructTxHashFlag))
+	if err != nil || len(raw) != common.HashLength {
+		return fmt.Errorf("invalid --tx-hash %q: expected a 32-byte hex hash", reconstructTxHashFlag)
+	}
+	txHash := common.BytesToHash(raw)
+	if err := checkOverwrite(reconstructOutputFlag, reconstructForceFlag); err != nil {
+		return err
+	}
+	if err := contract.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize contract: %w", err)
+	}
+
+	config, err := types.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	rpcURL, err := customRPCURL(reconstructRPCURLFlag, reconstructRPCURLFile)
+	if err != nil {
+		return err
+	}
+	if rpcURL == "" {
+		rpcURL, err = network.GetRPCURL(reconstructNetworkFlag, config.InfuraAPIKey)
+		if err != nil {
+			return fmt.Errorf("failed to get RPC URL: %w", err)
+		}
+	}
+	recordedRPCURL := rpcURL
+	if reconstructRPCURLFile != "" {
+		recordedRPCURL = redactRPCURL(rpcURL)
+	}
+
+	ctx := context.Background()
+	client, err := network.CreateClient(ctx, rpcURL)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	chainID, err := cachedChainID(ctx, client, rpcURL, false)
+	if err != nil {
+		return err
+	}
+
+	tx, isPending, err := network.GetTransaction(ctx, client, txHash)
+	if err != nil {
+		return fmt.Errorf("transaction %s not found on chain %d: %w", txHash.Hex(), chainID, err)
+	}
+	if id := tx.ChainId(); id.Sign() > 0 && id.Uint64() != chainID {
+		return types.Errorf(types.CodeChainMismatch, "transaction %s is for chain %s but the node is on chain %d", txHash.Hex(), id, chainID)
+	}
+	from, err := coretypes.Sender(coretypes.LatestSignerForChainID(new(big.Int).SetUint64(chainID)), tx)
+	if err != nil {
+		return fmt.Errorf("failed to recover the sender of %s: %w", txHash.Hex(), err)
+	}
+
+	txParams, err := reconstructTxParams(tx, from, chainID)
+	if err != nil {
+		return err
+	}
+	txParams.Metadata = newMetadata(reconstructNetworkFlag, chainID, recordedRPCURL)
+
+	source := map[string]interface{}{"tx_hash": txHash.Hex()}
+	if isPending {
+		source["status"] = "pending"
+	} else if receipt, err := client.TransactionReceipt(ctx, txHash); err == nil {
+		source["block"] = receipt.BlockNumber.Uint64()
+		source["status"] = receipt.Status
+		if receipt.Status == 0 {
+			log.Warn("⚠ The transaction failed on-chain; repeating it may fail the same way")
+		}
+	}
+	if decoded, err := contract.DecodeCall(tx.To(), tx.Data(), tx.Value()); err == nil {
+		source["summary"] = decoded.Summary
+	}
+	txParams.Metadata.AdditionalInfo = map[string]interface{}{"reconstructed_from": source}
+
+	txParams.SchemaVersion = types.SchemaVersion
+	txParams.Metadata.TxTypeName = types.TxTypeName(txParams.Transaction.TxType)
+	data, err := json.MarshalIndent(txParams, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize transaction: %w", err)
+	}
+	if err := os.WriteFile(reconstructOutputFlag, data, 0644); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	log.Info("Transaction parameters reconstructed",
+		"operation", operationName(txParams.Mode, txParams.FunctionName),
+		"from", from.Hex(),
+		"nonce", tx.Nonce())
+	if summary, ok := source["summary"].(string); ok {
+		log.Info("  " + summary)
+	}
+	log.Info("  Written", "file", reconstructOutputFlag)
+	log.Info("  The nonce and fees are the original transaction's; use 'replay --from-params' to repeat a deposit or deployment with new ones")
+	return nil
+}
+
+// reconstructTxParams rebuilds unsigned transaction parameters from a
+// transaction. Deposits, approvals and plain transfers get the parameters
+// prepare writes for them; other calls to the contract get their decoded
+// arguments, and unknown calldata a generic to/value/data record.
+func reconstructTxParams(tx *coretypes.Transaction, from common.Address, chainID uint64) (*types.TxParams, error) {
+	txData := types.TransactionData{
+		From:     from,
+		To:       tx.To(),
+		Data:     tx.Data(),
+		Nonce:    tx.Nonce(),
+		ChainID:  chainID,
+		GasLimit: types.NewBigInt(new(big.Int).SetUint64(tx.Gas())),
+	}
+	if tx.Value().Sign() > 0 {
+		txData.Value = types.NewBigInt(tx.Value())
+	}
+
+	switch tx.Type() {
+	case coretypes.LegacyTxType, coretypes.AccessListTxType:
+		txData.TxType = 0
+		txData.GasPrice = types.NewBigInt(tx.GasPrice())
+	case coretypes.DynamicFeeTxType, coretypes.SetCodeTxType:
+		txData.TxType = tx.Type()
+		txData.MaxFeePerGas = types.NewBigInt(tx.GasFeeCap())
+		txData.MaxPriorityFeePerGas = types.NewBigInt(tx.GasTipCap())
+	default:
+		return nil, fmt.Errorf("transaction type %d is not supported", tx.Type())
+	}
+	if len(tx.AccessList()) > 0 {
+		log.Warn("⚠ The transaction's access list is not kept; the reconstructed parameters do not carry one")
+	}
+	// Authorizations are kept unsigned, like prepare writes them
+	for _, a := range tx.SetCodeAuthorizations() {
+		txData.AuthorizationList = append(txData.AuthorizationList, types.Authorization{
+			ChainID: a.ChainID.Uint64(),
+			Address: a.Address,
+			Nonce:   a.Nonce,
+		})
+	}
+
+	txParams := &types.TxParams{Mode: types.TransactionModeCall, Transaction: txData}
+	var params map[string]interface{}
+	switch {
+	case tx.To() == nil:
+		txParams.Mode = types.TransactionModeDeploy
+		return txParams, nil
+
+	case len(tx.Data()) == 0:
+		params = map[string]interface{}{
+			"to":     tx.To().Hex(),
+			"amount": tx.Value().String(),
+		}
+
+	default:
+		params = reconstructCallParams(txParams, tx)
+	}
+
+	txParams.Params, _ = json.Marshal(params)
+	return txParams, nil
+}
+
+// reconstructCallParams decodes a contract call's parameters, setting the
+// function name when the calldata matches a known function
+func reconstructCallParams(txParams *types.TxParams, tx *coretypes.Transaction) map[string]interface{} {
+	if deposit, err := contract.DecodeDeposit(tx.Data()); err == nil {
+		txParams.FunctionName = "deposit"
+		params := map[string]interface{}{
+			"beneficiary": deposit.Beneficiary.Hex(),
+			"amount":      deposit.Amount.String(),
+			"deadline":    deposit.Deadline.String(),
+		}
+		if deposit.Token == (common.Address{}) {
+			// The contract deposits msg.value and ignores _amount for the
+			// native token
+			params["amount"] = tx.Value().String()
+			if deposit.Amount.Sign() != 0 && deposit.Amount.Cmp(tx.Value()) != 0 {
+				params["calldata_amount"] = deposit.Amount.String()
+			}
+		} else {
+			params["token"] = deposit.Token.Hex()
+		}
+		return params
+	}
+
+	decoded, err := contract.DecodeCall(tx.To(), tx.Data(), tx.Value())
+	if err != nil {
+		log.Warn("⚠ The calldata does not match the CryptoHeir or ERC20 ABI; writing a generic call", "reason", err)
+		return map[string]interface{}{
+			"to":    tx.To().Hex(),
+			"value": tx.Value().String(),
+			"data":  hexutil.Encode(tx.Data()),
+		}
+	}
+
+	txParams.FunctionName = strings.SplitN(decoded.Function, "(", 2)[0]
+	params := make(map[string]interface{}, len(decoded.Args)+1)
+	for _, a := range decoded.Args {
+		params[strings.TrimPrefix(a.Name, "_")] = a.Value
+	}
+	if txParams.FunctionName == "approve" {
+		params["token"] = tx.To().Hex()
+	}
+	return params
+}
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/contract"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestReconstructDepositAmount(t *testing.T) {
+	if err := contract.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	contractAddr := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	beneficiary := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	token := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	deadline := big.NewInt(1798761600)
+	oneEth := big.NewInt(1e18)
+
+	tests := []struct {
+		name               string
+		token              *common.Address
+		calldataAmount     *big.Int
+		value              *big.Int
+		wantAmount         string
+		wantCalldataAmount string // "" when not recorded
+	}{
+		{"native, calldata amount matches", nil, oneEth, oneEth, "1000000000000000000", ""},
+		{"native, zero calldata amount", nil, big.NewInt(0), oneEth, "1000000000000000000", ""},
+		{"native, ignored calldata amount differs", nil, big.NewInt(5e17), oneEth, "1000000000000000000", "500000000000000000"},
+		{"token", &token, big.NewInt(2500), big.NewInt(0), "2500", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _, err := contract.EncodeDeposit(beneficiary, tt.calldataAmount, deadline, tt.token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tx := coretypes.NewTx(&coretypes.DynamicFeeTx{To: &contractAddr, Value: tt.value, Data: data, Gas: 100000})
+			txParams := &types.TxParams{}
+			params := reconstructCallParams(txParams, tx)
+
+			if txParams.FunctionName != "deposit" {
+				t.Errorf("FunctionName = %q, want deposit", txParams.FunctionName)
+			}
+			if params["amount"] != tt.wantAmount {
+				t.Errorf("amount = %v, want %s", params["amount"], tt.wantAmount)
+			}
+			got, recorded := params["calldata_amount"]
+			if tt.wantCalldataAmount == "" && recorded {
+				t.Errorf("calldata_amount = %v, want none", got)
+			}
+			if tt.wantCalldataAmount != "" && got != tt.wantCalldataAmount {
+				t.Errorf("calldata_amount = %v, want %s", got, tt.wantCalldataAmount)
+			}
+			if _, hasToken := params["token"]; hasToken != (tt.token != nil) {
+				t.Errorf("token recorded = %v, want %v", hasToken, tt.token != nil)
+			}
+
+			decoded, err := contract.DecodeCall(tx.To(), tx.Data(), tx.Value())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.token == nil && !strings.HasPrefix(decoded.Summary, "Deposit 1 ETH ") {
+				t.Errorf("summary = %q, want the deposited value of 1 ETH", decoded.Summary)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+)
+
+// ReplayCmd represents the replay command
+var ReplayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Prepare the same operation again from a receipt or transaction parameters",
+	Long: `Prepare a new transaction that repeats an earlier one, e.g. another
+deposit to the same beneficiary with a new deadline.
+
+The operation and its parameters are reconstructed from a receipt saved by
+'broadcast' (--from-receipt) or from a prepared tx-params.json (--from-params).
+Any prepare flag overrides the corresponding field. The nonce, gas and fees are
+always fetched again.`,
+	Args: cobra.NoArgs,
+	RunE: runReplay,
+}
+
+var replayFromReceiptFlag string
+
+func init() {
+	ReplayCmd.Flags().StringVar(&replayFromReceiptFlag, "from-receipt", "", "Receipt file of a confirmed deposit or deployment to replay")
+}
+
+func runReplay(cmd *cobra.Command, args []string) error {
+	if (replayFromReceiptFlag == "") == (fromParamsFlag == "") {
+		return fmt.Errorf("exactly one of --from-receipt or --from-params is required")
+	}
+
+	path := fromParamsFlag
+	var template *types.TxParams
+	var err error
+	if replayFromReceiptFlag != "" {
+		path = replayFromReceiptFlag
+		template, err = templateFromReceipt(path)
+	} else {
+		template, err = types.LoadTxParams(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	operation, err := replayOperation(template)
+	if err != nil {
+		return fmt.Errorf("cannot replay %s: %w", path, err)
+	}
+	log.Info("Replaying", "operation", operation, "source", path)
+
+	// The prepare flow reads the template from --from-params; a replay is a
+	// new transaction, so gas and fees are never reused
+	fromParamsFlag = path
+	refetchGasFlag = true
+	return runPrepare(cmd, []string{operation})
+}
+
+// replayOperation returns the prepare operation that recreates a template
+func replayOperation(template *types.TxParams) (string, error) {
+	switch {
+	case template.Mode == types.TransactionModeDeploy:
+		return "deploy", nil
+	case template.Mode == types.TransactionModeCall && template.FunctionName == "deposit":
+		return "deposit", nil
+	}
+	return "", fmt.Errorf("only deposits and deployments can be replayed (found %s)", operationName(template.Mode, template.FunctionName))
+}
+
+// isReceiptFile reports whether a JSON file is a receipt saved by broadcast
+// or status rather than transaction parameters
+func isReceiptFile(data []byte) bool {
+	var probe struct {
+		TransactionHash *string          `json:"transaction_hash"`
+		Transaction     *json.RawMessage `json:"transaction"`
+	}
+	return json.Unmarshal(data, &probe) == nil && probe.TransactionHash != nil && probe.Transaction == nil
+}
+
+// templateFromReceipt reconstructs transaction parameters from a receipt for
+// use as a template. A deposit is rebuilt from its InheritanceCreated event,
+// whose amount excludes the deposit fee, so the fee from the matching
+// FeeCollected event is added back to recover the amount that was deposited.
+// Receipts carry no gas settings.
+func templateFromReceipt(path string) (*types.TxParams, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read receipt %s: %w", path, err)
+	}
+	var receipt types.TxReceipt
+	if err := json.Unmarshal(data, &receipt); err != nil {
+		return nil, fmt.Errorf("failed to parse receipt %s: %w", path, err)
+	}
+	if receipt.Status != 1 {
+		log.Warn("⚠ The receipt is of a failed transaction; replaying it may fail the same way", "file", path)
+	}
+
+	template := &types.TxParams{
+		Transaction: types.TransactionData{From: receipt.From, To: receipt.To},
+	}
+	if chainID, ok := receipt.Metadata["chain_id"].(float64); ok {
+		template.Transaction.ChainID = uint64(chainID)
+	}
+
+	if receipt.To == nil {
+		template.Mode = types.TransactionModeDeploy
+		return template, nil
+	}
+
+	var events []types.DecodedEvent
+	if raw, ok := receipt.Metadata["events"]; ok {
+		encoded, _ := json.Marshal(raw)
+		if err := json.Unmarshal(encoded, &events); err != nil {
+			return nil, fmt.Errorf("failed to parse receipt events in %s: %w", path, err)
+		}
+	}
+
+	var created map[string]string
+	var fee *big.Int
+	for _, e := range events {
+		if e.Address != *receipt.To {
+			continue
+		}
+		args := make(map[string]string, len(e.Args))
+		for _, a := range e.Args {
+			args[a.Name] = a.Value
+		}
+		switch e.Name {
+		case "InheritanceCreated":
+			created = args
+		case "FeeCollected":
+			if args["feeType"] == "deposit" {
+				fee, _ = new(big.Int).SetString(args["amount"], 10)
+			}
+		}
+	}
+	if created == nil {
+		return nil, fmt.Errorf("receipt %s has no InheritanceCreated event from %s; only deposits and deployments can be replayed", path, receipt.To.Hex())
+	}
+
+	amount, ok := new(big.Int).SetString(created["amount"], 10)
+	if !ok {
+		return nil, fmt.Errorf("receipt %s has an invalid deposit amount %q", path, created["amount"])
+	}
+	if fee != nil {
+		amount.Add(amount, fee)
+	} else {
+		log.Warn("No deposit fee in the receipt; replaying the net deposited amount", "amount", amount.String())
+	}
+
+	params := map[string]string{
+		"beneficiary": created["beneficiary"],
+		"amount":      amount.String(),
+		"deadline":    created["deadline"],
+	}
+	if token := common.HexToAddress(created["token"]); token != (common.Address{}) {
+		params["token"] = token.Hex()
+	}
+	template.Params, _ = json.Marshal(params)
+	template.Mode = types.TransactionModeCall
+	template.FunctionName = "deposit"
+	return template, nil
+}
@@ -0,0 +1,173 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/network"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/store"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+)
+
+// RPC endpoint selection modes for --rpc-select
+const (
+	rpcSelectFirst      = "first"
+	rpcSelectFastest    = "fastest"
+	rpcSelectRoundRobin = "round-robin"
+)
+
+// rpcProbeTimeout bounds how long an endpoint may take to report its chain ID
+const rpcProbeTimeout = 5 * time.Second
+
+// rpcSelectFlag is shared by every command that accepts --rpc-url
+var rpcSelectFlag string
+
+const rpcSelectUsage = "How to choose among several --rpc-url endpoints: first (first healthy), fastest (lowest latency, reused for 5 minutes) or round-robin"
+
+// checkRPCSelect validates an --rpc-select mode
+func checkRPCSelect(mode string) error {
+	switch mode {
+	case rpcSelectFirst, rpcSelectFastest, rpcSelectRoundRobin:
+		return nil
+	}
+	return fmt.Errorf("unsupported --rpc-select: %s (supported: %s, %s, %s)",
+		mode, rpcSelectFirst, rpcSelectFastest, rpcSelectRoundRobin)
+}
+
+// endpointProbe is the result of a health check of one endpoint
+type endpointProbe struct {
+	chainID uint64
+	latency time.Duration
+	err     error
+}
+
+// probeEndpoint connects to an endpoint and times a chain ID request
+func probeEndpoint(rpcURL string) endpointProbe {
+	ctx, cancel := context.WithTimeout(context.Background(), rpcProbeTimeout)
+	defer cancel()
+
+	start := time.Now()
+	client, err := network.CreateClient(ctx, rpcURL)
+	if err != nil {
+		return endpointProbe{err: err}
+	}
+	defer client.Close()
+	chainID, err := network.GetChainID(ctx, client)
+	return endpointProbe{chainID: chainID, latency: time.Since(start), err: err}
+}
+
+// selectRPCURL chooses one of several endpoints. An endpoint is healthy when
+// it reports its chain ID in time; unhealthy ones are reported and skipped.
+// Healthy endpoints that report different chains are a misconfiguration
+// that could send a transaction to the wrong network, so selection fails.
+func selectRPCURL(urls []string, mode string) (string, error) {
+	cache, err := store.LoadRPCSelectionCache()
+	if err != nil {
+		log.Debug("Failed to load RPC selection cache", "error", err)
+		cache = &store.RPCSelectionCache{Lists: make(map[string]store.RPCSelectionEntry)}
+	}
+	if mode == rpcSelectFastest {
+		if i, chainID, ok := cache.Fastest(urls); ok {
+			// The cached endpoint may have failed or been repointed since
+			endpoint := redactRPCURL(urls[i])
+			switch p := probeEndpoint(urls[i]); {
+			case p.err != nil:
+				log.Warn("⚠ Cached fastest RPC endpoint is unhealthy; measuring all endpoints again", "endpoint", endpoint, "error", p.err)
+			case p.chainID != chainID:
+				log.Warn("⚠ Cached fastest RPC endpoint now reports a different chain; measuring all endpoints again",
+					"endpoint", endpoint, "chain_id", p.chainID, "expected", chainID)
+			default:
+				log.Info("Selected RPC endpoint", "endpoint", endpoint, "reason", "fastest when measured in the last 5 minutes",
+					"latency", p.latency.Round(time.Millisecond))
+				return urls[i], nil
+			}
+		}
+	}
+
+	// Probe the endpoints in parallel, then judge them in list order
+	probes := make([]endpointProbe, len(urls))
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		wg.Add(1)
+		go func(i int, u string) {
+			defer wg.Done()
+			probes[i] = probeEndpoint(u)
+		}(i, u)
+	}
+	wg.Wait()
+
+	var chainID uint64
+	first := -1
+	healthy := make([]bool, len(urls))
+	for i, p := range probes {
+		endpoint := redactRPCURL(urls[i])
+		switch {
+		case p.err != nil:
+			log.Warn("⚠ RPC endpoint is unhealthy; skipping it", "endpoint", endpoint, "error", p.err)
+		case first >= 0 && p.chainID != chainID:
+			return "", types.Errorf(types.CodeChainMismatch, "RPC endpoints report different chains: %s is on chain %d, %s on chain %d; fix the endpoint list",
+				redactRPCURL(urls[first]), chainID, endpoint, p.chainID)
+		default:
+			if first < 0 {
+				first, chainID = i, p.chainID
+			}
+			healthy[i] = true
+			log.Debug("RPC endpoint is healthy", "endpoint", endpoint, "chain_id", p.chainID, "latency", p.latency.Round(time.Millisecond))
+		}
+	}
+
+	chosen, reason := -1, ""
+	switch mode {
+	case rpcSelectFirst:
+		for i := range urls {
+			if healthy[i] {
+				chosen, reason = i, "first healthy endpoint"
+				break
+			}
+		}
+	case rpcSelectFastest:
+		for i := range urls {
+			if healthy[i] && (chosen < 0 || probes[i].latency < probes[chosen].latency) {
+				chosen = i
+			}
+		}
+		if chosen >= 0 {
+			reason = fmt.Sprintf("lowest latency (%s)", probes[chosen].latency.Round(time.Millisecond))
+			cache.RecordFastest(urls, chosen, chainID)
+		}
+	case rpcSelectRoundRobin:
+		start := cache.Next(urls)
+		for n := 0; n < len(urls); n++ {
+			if i := (start + n) % len(urls); healthy[i] {
+				chosen, reason = i, fmt.Sprintf("round-robin (endpoint %d of %d)", i+1, len(urls))
+				break
+			}
+		}
+		if chosen >= 0 {
+			cache.RecordUsed(urls, chosen)
+		}
+	}
+	if chosen < 0 {
+		return "", fmt.Errorf("none of the %d RPC endpoints is healthy", len(urls))
+	}
+
+	if err := cache.Save(); err != nil {
+		log.Debug("Failed to save RPC selection cache", "error", err)
+	}
+	log.Info("Selected RPC endpoint", "endpoint", redactRPCURL(urls[chosen]), "reason", reason,
+		"healthy", fmt.Sprintf("%d/%d", countTrue(healthy), len(urls)))
+	return urls[chosen], nil
+}
+
+// countTrue counts the set entries of a slice
+func countTrue(values []bool) int {
+	n := 0
+	for _, v := range values {
+		if v {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+)
+
+// chainNode is a node answering eth_chainId with the current value of chainID
+func chainNode(t *testing.T, chainID *atomic.Uint64) string {
+	return stubNodeURL(t, func(method string, params []json.RawMessage) interface{} {
+		if method == "eth_chainId" {
+			return fmt.Sprintf("0x%x", chainID.Load())
+		}
+		return nil
+	})
+}
+
+func newChain(id uint64) *atomic.Uint64 {
+	c := new(atomic.Uint64)
+	c.Store(id)
+	return c
+}
+
+func TestSelectRPCURLMixedChains(t *testing.T) {
+	t.Setenv("CRYPTOHEIR_HOME", t.TempDir())
+	urls := []string{chainNode(t, newChain(1)), chainNode(t, newChain(11155111))}
+
+	for _, mode := range []string{rpcSelectFirst, rpcSelectFastest, rpcSelectRoundRobin} {
+		_, err := selectRPCURL(urls, mode)
+		if types.CodeOf(err) != types.CodeChainMismatch || !strings.Contains(err.Error(), "different chains") {
+			t.Errorf("selectRPCURL(%s) error = %v, want a chain mismatch", mode, err)
+		}
+	}
+}
+
+func TestSelectRPCURLSkipsUnhealthy(t *testing.T) {
+	t.Setenv("CRYPTOHEIR_HOME", t.TempDir())
+	urls := []string{"http://127.0.0.1:1", chainNode(t, newChain(1))}
+
+	got, err := selectRPCURL(urls, rpcSelectFirst)
+	if err != nil {
+		t.Fatalf("selectRPCURL() error = %v", err)
+	}
+	if got != urls[1] {
+		t.Errorf("selectRPCURL() = %s, want the healthy endpoint", got)
+	}
+
+	if _, err := selectRPCURL(urls[:1], rpcSelectFirst); err == nil {
+		t.Error("selectRPCURL() with no healthy endpoint: no error")
+	}
+}
+
+func TestSelectRPCURLFastestCacheReprobed(t *testing.T) {
+	t.Setenv("CRYPTOHEIR_HOME", t.TempDir())
+	a, b := newChain(1), newChain(1)
+	urls := []string{chainNode(t, a), chainNode(t, b)}
+
+	first, err := selectRPCURL(urls, rpcSelectFastest)
+	if err != nil {
+		t.Fatalf("selectRPCURL() error = %v", err)
+	}
+	if again, err := selectRPCURL(urls, rpcSelectFastest); err != nil || again != first {
+		t.Fatalf("cached selectRPCURL() = %s, %v; want %s", again, err, first)
+	}
+
+	// The cached endpoint switches chains: all endpoints are measured again
+	// and the mismatch is caught
+	if first == urls[0] {
+		a.Store(10)
+	} else {
+		b.Store(10)
+	}
+	if _, err := selectRPCURL(urls, rpcSelectFastest); types.CodeOf(err) != types.CodeChainMismatch {
+		t.Errorf("selectRPCURL() after the cached endpoint changed chain: error = %v, want a chain mismatch", err)
+	}
+
+	// Both moved: the new chain is measured and cached
+	a.Store(10)
+	b.Store(10)
+	if _, err := selectRPCURL(urls, rpcSelectFastest); err != nil {
+		t.Errorf("selectRPCURL() after both endpoints changed chain: error = %v", err)
+	}
+}
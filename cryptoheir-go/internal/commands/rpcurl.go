@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// customRPCURL returns the RPC URL given with --rpc-url or read with
+// --rpc-url-file, or "" if neither was set. Either may list several
+// endpoints (comma-separated in --rpc-url, one per line in the file), in which
+// case one is chosen according to --rpc-select.
+func customRPCURL(urlFlag, fileFlag string) (string, error) {
+	if urlFlag != "" && fileFlag != "" {
+		return "", fmt.Errorf("--rpc-url and --rpc-url-file cannot be used together")
+	}
+	if err := checkRPCSelect(rpcSelectFlag); err != nil {
+		return "", err
+	}
+
+	var urls []string
+	if fileFlag != "" {
+		var err error
+		if urls, err = readRPCURLFile(fileFlag); err != nil {
+			return "", err
+		}
+	} else {
+		for _, u := range strings.Split(urlFlag, ",") {
+			if u = strings.TrimSpace(u); u != "" {
+				urls = append(urls, u)
+			}
+		}
+	}
+
+	switch len(urls) {
+	case 0:
+		return "", nil
+	case 1:
+		return urls[0], nil
+	}
+	return selectRPCURL(urls, rpcSelectFlag)
+}
+
+// readRPCURLFile reads RPC URLs from a file, one per line, or from stdin if
+// path is "-", so that URLs with embedded API keys stay out of shell history
+// and ps output
+func readRPCURLFile(path string) ([]string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(io.LimitReader(os.Stdin, 4096))
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read RPC URL file: %w", err)
+	}
+
+	var urls []string
+	for _, line := range strings.Split(string(data), "\n") {
+		content := strings.TrimSpace(line)
+		if content == "" {
+			continue
+		}
+		if strings.ContainsAny(content, " \t") {
+			return nil, fmt.Errorf("RPC URL file must contain one URL per line")
+		}
+		if err := checkRPCURL(content); err != nil {
+			return nil, fmt.Errorf("invalid RPC URL in file: %w", err)
+		}
+		urls = append(urls, content)
+	}
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("RPC URL file is empty")
+	}
+
+	return urls, nil
+}
+
+// checkRPCURL validates the scheme and host of an RPC URL
+func checkRPCURL(rpcURL string) error {
+	u, err := url.Parse(rpcURL)
+	if err != nil {
+		return err
+	}
+	switch u.Scheme {
+	case "http", "https", "ws", "wss":
+	default:
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("missing host")
+	}
+	return nil
+}
+
+// redactRPCURL strips credentials, path and query (where API keys are
+// usually embedded) from an RPC URL before it is recorded in output files
+func redactRPCURL(rpcURL string) string {
+	u, err := url.Parse(rpcURL)
+	if err != nil {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
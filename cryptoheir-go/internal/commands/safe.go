@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/network"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// safeBatchVersion is the Safe{Wallet} Transaction Builder file version
+const safeBatchVersion = "1.0"
+
+// safeOperationCall is the Safe operation for a plain call (1 would be a
+// delegatecall, which prepare never produces)
+const safeOperationCall = 0
+
+// safeBatch is a Safe{Wallet} Transaction Builder batch file. Owners import
+// it into the Safe UI, which proposes, signs and executes the transactions
+// from the Safe; gas, fees and nonces are chosen there.
+type safeBatch struct {
+	Version      string            `json:"version"`
+	ChainID      string            `json:"chainId"`
+	CreatedAt    int64             `json:"createdAt"`
+	Meta         safeBatchMeta     `json:"meta"`
+	Transactions []safeTransaction `json:"transactions"`
+}
+
+type safeBatchMeta struct {
+	Name                   string `json:"name"`
+	Description            string `json:"description"`
+	CreatedFromSafeAddress string `json:"createdFromSafeAddress"`
+}
+
+// safeTransaction is one call of a batch. Value is in wei as a decimal
+// string and Data is 0x-prefixed hex.
+type safeTransaction struct {
+	To        string `json:"to"`
+	Value     string `json:"value"`
+	Data      string `json:"data"`
+	Operation int    `json:"operation"`
+}
+
+// buildSafeBatch converts prepared transactions into a Safe batch executed
+// from safe, in order
+func buildSafeBatch(txs []*types.TxParams, safe common.Address, name string) (*safeBatch, error) {
+	batch := &safeBatch{
+		Version:   safeBatchVersion,
+		ChainID:   strconv.FormatUint(txs[0].Transaction.ChainID, 10),
+		CreatedAt: time.Now().UnixMilli(),
+		Meta: safeBatchMeta{
+			Name:                   name,
+			Description:            "Prepared by cryptoheir",
+			CreatedFromSafeAddress: safe.Hex(),
+		},
+	}
+	for _, txParams := range txs {
+		tx := txParams.Transaction
+		if tx.To == nil {
+			return nil, fmt.Errorf("contract deployments cannot be exported as a Safe transaction")
+		}
+		batch.Transactions = append(batch.Transactions, safeTransaction{
+			To:        tx.To.Hex(),
+			Value:     tx.Value.ToBigInt().String(),
+			Data:      hexutil.Encode(tx.Data),
+			Operation: safeOperationCall,
+		})
+	}
+
+	if err := checkSafeBatch(batch); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// checkSafeBatch verifies the fields the Safe Transaction Builder requires,
+// so a malformed file fails here rather than on import
+func checkSafeBatch(batch *safeBatch) error {
+	if batch.Version != safeBatchVersion {
+		return fmt.Errorf("invalid Safe batch: unsupported version %q", batch.Version)
+	}
+	if id, err := strconv.ParseUint(batch.ChainID, 10, 64); err != nil || id == 0 {
+		return fmt.Errorf("invalid Safe batch: chainId must be a positive decimal, got %q", batch.ChainID)
+	}
+	if batch.CreatedAt <= 0 {
+		return fmt.Errorf("invalid Safe batch: createdAt must be a timestamp in milliseconds")
+	}
+	if batch.Meta.Name == "" || !common.IsHexAddress(batch.Meta.CreatedFromSafeAddress) {
+		return fmt.Errorf("invalid Safe batch: meta requires a name and the Safe address")
+	}
+	if len(batch.Transactions) == 0 {
+		return fmt.Errorf("invalid Safe batch: no transactions")
+	}
+	for i, tx := range batch.Transactions {
+		if !common.IsHexAddress(tx.To) || common.HexToAddress(tx.To) == (common.Address{}) {
+			return fmt.Errorf("invalid Safe batch: transaction %d has invalid to %q", i+1, tx.To)
+		}
+		if !isDecimal(tx.Value) {
+			return fmt.Errorf("invalid Safe batch: transaction %d value must be a decimal wei amount, got %q", i+1, tx.Value)
+		}
+		if _, err := hexutil.Decode(tx.Data); err != nil {
+			return fmt.Errorf("invalid Safe batch: transaction %d has invalid data: %w", i+1, err)
+		}
+		if tx.Operation != safeOperationCall {
+			return fmt.Errorf("invalid Safe batch: transaction %d must be a call (operation 0), got %d", i+1, tx.Operation)
+		}
+	}
+	return nil
+}
+
+// isDecimal reports whether s is a non-empty string of decimal digits
+func isDecimal(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// writeSafeBatch writes prepared transactions as a Safe Transaction Builder
+// batch. The file is not signed by cryptoheir: the Safe owners sign it in the
+// Safe flow.
+func writeSafeBatch(path string, txs []*types.TxParams, safe common.Address, name string) error {
+	if err := checkOverwrite(path, forceFlag); err != nil {
+		return err
+	}
+	batch, err := buildSafeBatch(txs, safe, name)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(batch, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize Safe batch: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	log.Info("✓ Safe transaction batch prepared successfully", "transactions", len(batch.Transactions))
+	log.Info("  Output", "file", path)
+	log.Info("  Next", "instruction", "Import the file in the Safe{Wallet} Transaction Builder; the Safe owners sign and execute it there, not with 'cryptoheir sign'")
+	return nil
+}
+
+// checkSafeAddress warns when SIGNER_ADDRESS has no code, since Safe
+// transactions execute from the Safe contract rather than from an owner key
+func checkSafeAddress(ctx context.Context, client *ethclient.Client, safe common.Address) {
+	code, err := network.GetCode(ctx, client, safe)
+	if err != nil {
+		log.Debug("Could not check the Safe address", "error", err)
+		return
+	}
+	if len(code) == 0 {
+		log.Warn("⚠ SIGNER_ADDRESS has no code; with --safe it should be the Safe address, which executes the transactions", "address", safe.Hex())
+	}
+}
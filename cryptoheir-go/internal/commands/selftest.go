@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/crypto"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/version"
+	"github.com/spf13/cobra"
+)
+
+// SelfTestCmd represents the selftest command
+var SelfTestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Check that this binary signs canonical transactions",
+	Long: `Sign fixed legacy and EIP-1559 transactions with a fixed test key and
+compare the raw bytes against committed golden values.
+
+Run it on the signing machine after installing or upgrading the binary. A
+failure means transactions are encoded or signed differently on this build or
+platform (e.g. a go-ethereum change in RLP field ordering), and nothing it
+signs should be broadcast. The test key is public and holds no funds.`,
+	Args: cobra.NoArgs,
+	RunE: runSelfTest,
+}
+
+func runSelfTest(cmd *cobra.Command, args []string) error {
+	fmt.Printf("%s (go-ethereum %s, %s)\n", version.ToolVersion(), version.GoEthereumVersion(), version.GoVersion())
+
+	failed := 0
+	for _, r := range crypto.SelfTest() {
+		if r.Err != nil {
+			failed++
+			fmt.Printf("  ✗ %s: %v\n", r.Name, r.Err)
+			continue
+		}
+		fmt.Printf("  ✓ %s\n", r.Name)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("self-test failed: %d golden vector(s) did not match; do not sign with this binary", failed)
+	}
+	fmt.Println("All golden vectors match")
+	return nil
+}
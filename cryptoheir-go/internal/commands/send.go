@@ -0,0 +1,233 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/crypto"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/network"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// sweepL1FeeMultiplier is how many times the estimated L1 data fee a sweep
+// reserves on OP Stack L2s, since the fee follows the L1 gas price until the
+// transaction is mined
+const sweepL1FeeMultiplier = 2
+
+// prepareSend prepares a plain native ETH transfer of --amount, or of the
+// whole balance minus the worst-case gas cost with --sweep
+func prepareSend(ctx context.Context, client *ethclient.Client, signerAddress common.Address, nonce uint64, chainID uint64, networkName, rpcURL string) (*types.TxParams, error) {
+	log.Info("Preparing send transaction...")
+
+	// Validate required flags
+	if toFlag == "" {
+		return nil, fmt.Errorf("--to is required")
+	}
+	if tokenFlag != "" {
+		return nil, fmt.Errorf("send transfers native ETH only; --token is not supported")
+	}
+	if sweepFlag == (amountFlag != "") {
+		return nil, fmt.Errorf("exactly one of --amount or --sweep is required")
+	}
+	if sweepFlag && delegateFlag != "" {
+		return nil, fmt.Errorf("--sweep cannot be used with --delegate (the authorization gas is not accounted for)")
+	}
+
+	// Parse the recipient (or resolve its ENS name)
+	var to common.Address
+	var toENS, toLabel string
+	var err error
+	if isAddressBookLabel(toFlag) {
+		to, toLabel, err = resolveAddressBook(toFlag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient: %w", err)
+		}
+	} else if isENSName(toFlag) {
+		to, toENS, err = resolveENS(ctx, client, toFlag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient: %w", err)
+		}
+		log.Info("Resolved ENS name", "name", toENS, "address", to.Hex())
+	} else {
+		to, err = types.ParseAddress(toFlag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient: %w", err)
+		}
+	}
+	if to == (common.Address{}) {
+		return nil, fmt.Errorf("recipient must not be the zero address")
+	}
+	if to == signerAddress {
+		log.Warn("⚠ Recipient is the signer address itself; the transaction only pays gas", "address", to.Hex())
+	}
+
+	var value, balance *big.Int
+	if sweepFlag {
+		balance, err = network.GetBalance(ctx, client, signerAddress)
+		if err != nil {
+			return nil, err
+		}
+		log.Info("Signer balance", "balance", types.FormatEth(balance))
+		warnPendingBeforeSweep(ctx, client, signerAddress, nonce)
+	} else {
+		value, err = parseEther(amountFlag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount: %w", err)
+		}
+		if value.Sign() <= 0 {
+			return nil, fmt.Errorf("--amount must be greater than zero")
+		}
+	}
+
+	// A transfer to an account without code always uses exactly the
+	// intrinsic gas, which lets a sweep reserve no more than it needs
+	var gasLimit *big.Int
+	code, err := network.GetCode(ctx, client, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(code) == 0 && delegateFlag == "" {
+		gasLimit = new(big.Int).SetUint64(params.TxGas)
+		log.Info("Gas limit (plain transfer)", "gas", gasLimit.String())
+	} else {
+		gasLimit, err = network.EstimateGas(ctx, client, signerAddress, &to, nil, value)
+		if err != nil {
+			return nil, fmt.Errorf("gas estimation failed: %w", err)
+		}
+		log.Info("Estimated gas (recipient has code)", "gas", gasLimit.String())
+	}
+
+	// Get gas prices
+	gasPrices, err := getGasPrices(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build transaction data
+	txData := types.TransactionData{
+		From:     signerAddress,
+		To:       &to,
+		Nonce:    nonce,
+		ChainID:  chainID,
+		GasLimit: types.NewBigInt(gasLimit),
+	}
+	applyGasPrices(&txData, gasPrices)
+
+	// Attach EIP-7702 delegation if requested
+	if delegateFlag != "" {
+		if err := applyDelegation(&txData); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkFeeFields(&txData); err != nil {
+		return nil, err
+	}
+
+	var reserved *big.Int
+	if sweepFlag {
+		// Size the L1 data fee with the full balance as the value, which
+		// encodes at least as long as the final value
+		txData.Value = types.NewBigInt(balance)
+		l1Reserve := new(big.Int)
+		if _, unsigned, err := crypto.SerializedSize(&txData); err == nil {
+			if l1Fee := estimateL1Fee(ctx, client, unsigned); l1Fee != nil {
+				l1Reserve.Mul(l1Fee, big.NewInt(sweepL1FeeMultiplier))
+				log.Info("Reserving for the L1 data fee (OP Stack)",
+					"estimated", types.FormatEth(l1Fee),
+					"reserved", types.FormatEth(l1Reserve))
+			}
+		}
+
+		value, reserved, err = sweepValue(balance, gasLimit, feeCap(&txData), l1Reserve)
+		if err != nil {
+			return nil, err
+		}
+		log.Info("Sweep amount", "value", types.FormatAmount(value, 18, "ETH"), "reserved_for_gas", types.FormatAmount(reserved, 18, "ETH"))
+		if txData.TxType != 0 {
+			log.Warn("⚠ The gas cost is reserved at the max fee per gas. The account keeps gas_limit × (max fee − effective gas price) after mining, so it ends at zero only if the effective price reaches the max fee")
+		} else if len(code) > 0 {
+			log.Warn("⚠ The account keeps any gas the recipient contract does not use")
+		}
+	}
+	if dumpCalldataFlag {
+		return nil, dumpCalldata(&to, nil, value)
+	}
+
+	txData.Value = types.NewBigInt(value)
+	log.Info("Send value", "value", types.FormatAmount(value, 18, "ETH"))
+
+	// Build parameters JSON
+	sendParams := map[string]interface{}{
+		"to":     to.Hex(),
+		"amount": value.String(),
+	}
+	if toENS != "" {
+		sendParams["to_ens"] = toENS
+	}
+	if sweepFlag {
+		sendParams["sweep"] = true
+	}
+	paramsJSON, _ := json.Marshal(sendParams)
+
+	// Build TxParams
+	txParams := &types.TxParams{
+		Mode:        types.TransactionModeCall,
+		Params:      paramsJSON,
+		Transaction: txData,
+		Metadata:    newMetadata(networkName, chainID, rpcURL),
+	}
+	if toENS != "" {
+		recordENSName(txParams, toENS, to)
+	}
+	if toLabel != "" {
+		recordAddressLabel(txParams, toLabel, to)
+	}
+	if sweepFlag {
+		if txParams.Metadata.AdditionalInfo == nil {
+			txParams.Metadata.AdditionalInfo = make(map[string]interface{})
+		}
+		txParams.Metadata.AdditionalInfo["sweep_balance_wei"] = balance.String()
+		txParams.Metadata.AdditionalInfo["sweep_reserved_wei"] = reserved.String()
+	}
+
+	log.Info("Send prepared for recipient", "recipient", to.Hex())
+	return txParams, nil
+}
+
+// sweepValue returns the value that leaves nothing but the reserved gas in an
+// account: the balance minus gas limit × fee cap and any extra reserve (the
+// L1 data fee on rollups). The reserved total is returned as well.
+func sweepValue(balance, gasLimit, feeCap, extra *big.Int) (*big.Int, *big.Int, error) {
+	reserved := new(big.Int).Mul(gasLimit, feeCap)
+	reserved.Add(reserved, extra)
+	if balance.Cmp(reserved) <= 0 {
+		return nil, nil, types.Errorf(types.CodeInsufficientFunds, "balance %s does not cover the worst-case gas cost %s; nothing to sweep",
+			types.FormatAmount(balance, 18, "ETH"), types.FormatAmount(reserved, 18, "ETH"))
+	}
+	return new(big.Int).Sub(balance, reserved), reserved, nil
+}
+
+// feeCap returns the highest price per gas a transaction can pay: the max fee
+// for EIP-1559 and EIP-7702 transactions, the gas price for legacy ones
+func feeCap(txData *types.TransactionData) *big.Int {
+	if txData.TxType == 0 {
+		return txData.GasPrice.ToBigInt()
+	}
+	return txData.MaxFeePerGas.ToBigInt()
+}
+
+// warnPendingBeforeSweep warns when earlier transactions of the signer are
+// still pending, since they spend from the balance a sweep is computed from
+func warnPendingBeforeSweep(ctx context.Context, client *ethclient.Client, signerAddress common.Address, nonce uint64) {
+	confirmed, err := network.GetConfirmedNonce(ctx, client, signerAddress)
+	if err != nil || nonce <= confirmed {
+		return
+	}
+	log.Warn("⚠ The signer has pending transactions before this one; they spend from the swept balance and the sweep may fail",
+		"confirmed_nonce", confirmed, "nonce", nonce)
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+)
+
+func TestSweepValue(t *testing.T) {
+	gwei := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e9)) }
+	eth := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+
+	tests := []struct {
+		name         string
+		balance      *big.Int
+		gasLimit     int64
+		feeCap       *big.Int
+		extra        *big.Int
+		wantValue    *big.Int
+		wantReserved *big.Int
+	}{
+		{
+			name:         "plain transfer at 30 gwei",
+			balance:      eth(1),
+			gasLimit:     21000,
+			feeCap:       gwei(30),
+			extra:        new(big.Int),
+			wantValue:    new(big.Int).Sub(eth(1), big.NewInt(21000*30e9)),
+			wantReserved: big.NewInt(21000 * 30e9),
+		},
+		{
+			name:         "rollup reserves the L1 data fee",
+			balance:      eth(2),
+			gasLimit:     21000,
+			feeCap:       gwei(1),
+			extra:        big.NewInt(5e12),
+			wantValue:    new(big.Int).Sub(eth(2), big.NewInt(21000*1e9+5e12)),
+			wantReserved: big.NewInt(21000*1e9 + 5e12),
+		},
+		{
+			name:         "balance one wei above the reserve",
+			balance:      big.NewInt(21000*10 + 1),
+			gasLimit:     21000,
+			feeCap:       big.NewInt(10),
+			extra:        new(big.Int),
+			wantValue:    big.NewInt(1),
+			wantReserved: big.NewInt(21000 * 10),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, reserved, err := sweepValue(tt.balance, big.NewInt(tt.gasLimit), tt.feeCap, tt.extra)
+			if err != nil {
+				t.Fatalf("sweepValue() error = %v", err)
+			}
+			if value.Cmp(tt.wantValue) != 0 || reserved.Cmp(tt.wantReserved) != 0 {
+				t.Errorf("sweepValue() = %s, %s; want %s, %s", value, reserved, tt.wantValue, tt.wantReserved)
+			}
+			// The sweep is affordable at the fee cap, and any lower effective
+			// price leaves the difference behind rather than failing
+			if total := new(big.Int).Add(value, reserved); total.Cmp(tt.balance) != 0 {
+				t.Errorf("value + reserved = %s, want the balance %s", total, tt.balance)
+			}
+		})
+	}
+}
+
+func TestSweepValueInsufficient(t *testing.T) {
+	for _, balance := range []int64{0, 21000*10 - 1, 21000 * 10} {
+		_, _, err := sweepValue(big.NewInt(balance), big.NewInt(21000), big.NewInt(10), new(big.Int))
+		if types.CodeOf(err) != types.CodeInsufficientFunds {
+			t.Errorf("sweepValue(balance %d) error = %v, want %s", balance, err, types.CodeInsufficientFunds)
+		}
+	}
+}
+
+func TestFeeCap(t *testing.T) {
+	legacy := &types.TransactionData{TxType: 0, GasPrice: types.NewBigInt(big.NewInt(7)), MaxFeePerGas: types.NewBigInt(big.NewInt(9))}
+	if got := feeCap(legacy); got.Int64() != 7 {
+		t.Errorf("feeCap(legacy) = %s, want the gas price 7", got)
+	}
+	for _, txType := range []uint8{2, 4} {
+		tx := &types.TransactionData{TxType: txType, MaxFeePerGas: types.NewBigInt(big.NewInt(9)), MaxPriorityFeePerGas: types.NewBigInt(big.NewInt(1))}
+		if got := feeCap(tx); got.Int64() != 9 {
+			t.Errorf("feeCap(type %d) = %s, want the max fee 9", txType, got)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// ServeCmd represents the serve command
+var ServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve prepare and broadcast as a local HTTP API",
+	Long: `Start a local HTTP server exposing prepare and broadcast to other processes,
+such as a local web UI.
+
+Endpoints (all require "Authorization: Bearer <token>"):
+  POST /prepare    {"operation": "deposit", "flags": {"beneficiary": "0x...", ...}}
+                   Returns the prepared transaction parameters JSON.
+  POST /broadcast  Signed transaction JSON as the body; optional query
+                   parameters network, rpc_url and no_wait=true.
+                   Returns the receipt (or submission) JSON.
+
+Signing is never exposed: transactions are still signed offline with
+'cryptoheir sign'. The server listens on loopback only unless
+--allow-remote is given.`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+var (
+	serveListenFlag      string
+	serveTokenFileFlag   string
+	serveAllowRemoteFlag bool
+)
+
+// serveTokenEnv supplies the API token when --token-file is not given. The
+// token is never taken as a flag, which would show it in ps output.
+const serveTokenEnv = "CRYPTOHEIR_SERVE_TOKEN"
+
+// maxRequestBytes limits request bodies
+const maxRequestBytes = 1 << 20
+
+// servePrepareFlags are the prepare flags accepted by POST /prepare. Flags
+// that read local files, write output paths or prompt are not exposed.
+var servePrepareFlags = map[string]bool{
+	"network":         true,
+	"rpc-url":         true,
+	"beneficiary":     true,
+	"amount":          true,
+	"deadline":        true,
+	"token":           true,
+	"nonce":           true,
+	"delegate":        true,
+	"price-usd":       true,
+	"broadcast-after": true,
+	"no-history":      true,
+}
+
+func init() {
+	ServeCmd.Flags().StringVar(&serveListenFlag, "listen", "127.0.0.1:8787", "Address to listen on")
+	ServeCmd.Flags().StringVar(&serveTokenFileFlag, "token-file", "", "Read the API token clients must send as a bearer token from a file ('-' for stdin) (default: $"+serveTokenEnv+", or a random token printed at startup)")
+	ServeCmd.Flags().BoolVar(&serveAllowRemoteFlag, "allow-remote", false, "Allow listening on a non-loopback address")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	host, _, err := net.SplitHostPort(serveListenFlag)
+	if err != nil {
+		return fmt.Errorf("invalid --listen address: %w", err)
+	}
+	if !serveAllowRemoteFlag && !isLoopback(host) {
+		return fmt.Errorf("refusing to listen on non-loopback address %s without --allow-remote", serveListenFlag)
+	}
+
+	token, err := serveToken()
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return fmt.Errorf("failed to generate API token: %w", err)
+		}
+		token = hex.EncodeToString(buf)
+		// Printed even with --quiet: without it the server cannot be used
+		fmt.Fprintf(os.Stderr, "Generated API token: %s\n", token)
+	}
+
+	// Requests run the CLI itself, so each one behaves exactly like the
+	// corresponding command
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate cryptoheir executable: %w", err)
+	}
+	s := &apiServer{executable: executable, token: token}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/prepare", s.handlePrepare)
+	mux.HandleFunc("/broadcast", s.handleBroadcast)
+
+	server := &http.Server{
+		Addr:              serveListenFlag,
+		Handler:           s.authenticate(mux),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Info("Serving local API", "address", "http://"+serveListenFlag, "endpoints", "POST /prepare, POST /broadcast")
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("server failed: %w", err)
+	}
+	return nil
+}
+
+// isLoopback reports whether a listen host only accepts local connections
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// apiServer serves the local API by running the CLI as a subprocess
+type apiServer struct {
+	executable string
+	token      string
+}
+
+// prepareRequest is the body of POST /prepare
+type prepareRequest struct {
+	Operation string            `json:"operation"`
+	Flags     map[string]string `json:"flags"`
+}
+
+// serveToken returns the API token read with --token-file, or from
+// $CRYPTOHEIR_SERVE_TOKEN, or "" if neither is set
+func serveToken() (string, error) {
+	if serveTokenFileFlag == "" {
+		return strings.TrimSpace(os.Getenv(serveTokenEnv)), nil
+	}
+
+	var data []byte
+	var err error
+	if serveTokenFileFlag == "-" {
+		data, err = io.ReadAll(io.LimitReader(os.Stdin, 4096))
+	} else {
+		data, err = os.ReadFile(serveTokenFileFlag)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read API token file: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("API token file is empty")
+	}
+	if strings.ContainsAny(token, " \t\r\n") {
+		return "", fmt.Errorf("API token file must contain a single token without whitespace")
+	}
+	return token, nil
+}
+
+// authenticate rejects requests without the token in an
+// "Authorization: Bearer <token>" header. The scheme is required (and, as in
+// RFC 7235, case-insensitive), so a bare token is refused.
+func (s *apiServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme, got, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") ||
+			subtle.ConstantTimeCompare([]byte(strings.TrimSpace(got)), []byte(s.token)) != 1 {
+			writeAPIError(w, http.StatusUnauthorized, fmt.Errorf("missing or invalid API token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *apiServer) handlePrepare(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, fmt.Errorf("use POST"))
+		return
+	}
+
+	var req prepareRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if req.Operation == "" || strings.HasPrefix(req.Operation, "-") {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid or missing operation: %q", req.Operation))
+		return
+	}
+
+	dir, err := os.MkdirTemp("", "cryptoheir-serve-")
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	output := filepath.Join(dir, "tx-params.json")
+
+	args := []string{"prepare", req.Operation, "--output", output, "--quiet"}
+	for name, value := range req.Flags {
+		if !servePrepareFlags[name] {
+			writeAPIError(w, http.StatusBadRequest, fmt.Errorf("flag not allowed: %s", name))
+			return
+		}
+		args = append(args, "--"+name+"="+value)
+	}
+
+	log.Info("API prepare", "operation", req.Operation)
+	if err := s.run(r.Context(), args); err != nil {
+		writeAPIError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeAPIFile(w, output)
+}
+
+func (s *apiServer) handleBroadcast(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, fmt.Errorf("use POST"))
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("failed to read request body: %w", err))
+		return
+	}
+	if !json.Valid(body) {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("request body is not valid JSON"))
+		return
+	}
+
+	dir, err := os.MkdirTemp("", "cryptoheir-serve-")
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	input := filepath.Join(dir, "signed-tx.json")
+	if err := os.WriteFile(input, body, 0600); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	args := []string{"broadcast", "--input", input, "--quiet"}
+	query := r.URL.Query()
+	if v := query.Get("network"); v != "" {
+		args = append(args, "--network="+v)
+	}
+	if v := query.Get("rpc_url"); v != "" {
+		args = append(args, "--rpc-url="+v)
+	}
+	if v := query.Get("no_wait"); v != "" {
+		noWait, err := strconv.ParseBool(v)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid no_wait: %w", err))
+			return
+		}
+		args = append(args, "--no-wait="+strconv.FormatBool(noWait))
+	}
+
+	log.Info("API broadcast")
+	if err := s.run(r.Context(), args); err != nil {
+		writeAPIError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeAPIFile(w, receiptPath(input))
+}
+
+// run executes the CLI with args, returning its error output on failure
+func (s *apiServer) run(ctx context.Context, args []string) error {
+	var output bytes.Buffer
+	c := exec.CommandContext(ctx, s.executable, args...)
+	c.Stdout = &output
+	c.Stderr = &output
+	if err := c.Run(); err != nil {
+		return commandError(output.String(), err)
+	}
+	return nil
+}
+
+// commandError extracts the final "Error: ..." line printed by a failed
+// command, with the error code printed after it, falling back to its exit
+// status
+func commandError(output string, err error) error {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	var code types.ErrorCode
+	for i := len(lines) - 1; i >= 0; i-- {
+		if c, ok := strings.CutPrefix(lines[i], "Error code: "); ok {
+			code = types.ErrorCode(c)
+			continue
+		}
+		if msg, ok := strings.CutPrefix(lines[i], "Error: "); ok {
+			return types.WithCode(code, errors.New(msg))
+		}
+	}
+	return fmt.Errorf("command failed: %w", err)
+}
+
+// writeAPIFile responds with the JSON file written by a command
+func writeAPIFile(w http.ResponseWriter, path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, fmt.Errorf("command produced no output: %w", err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// writeAPIError responds with {"error": "..."}, adding "code" when the error
+// carries one
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+	body := map[string]string{"error": err.Error()}
+	if code := types.CodeOf(err); code != "" {
+		body["code"] = string(code)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
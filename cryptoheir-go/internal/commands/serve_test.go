@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServeAuthenticate(t *testing.T) {
+	const token = "0123456789abcdef"
+	s := &apiServer{token: token}
+	handler := s.authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"bearer token", "Bearer " + token, http.StatusNoContent},
+		{"scheme is case-insensitive", "bearer " + token, http.StatusNoContent},
+		{"bare token", token, http.StatusUnauthorized},
+		{"other scheme", "Basic " + token, http.StatusUnauthorized},
+		{"wrong token", "Bearer fedcba9876543210", http.StatusUnauthorized},
+		{"token prefix", "Bearer " + token[:8], http.StatusUnauthorized},
+		{"scheme only", "Bearer", http.StatusUnauthorized},
+		{"missing", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/prepare", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestServeToken(t *testing.T) {
+	t.Cleanup(func() { serveTokenFileFlag = "" })
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		env     string
+		want    string
+		wantErr string
+	}{
+		{name: "neither", want: ""},
+		{name: "environment", env: " from-env\n", want: "from-env"},
+		{name: "file", file: write("token", "from-file\n"), want: "from-file"},
+		{name: "file takes precedence", file: write("token2", "from-file"), env: "from-env", want: "from-file"},
+		{name: "empty file", file: write("empty", "\n"), wantErr: "empty"},
+		{name: "several tokens", file: write("two", "a b\n"), wantErr: "single token"},
+		{name: "missing file", file: filepath.Join(dir, "missing"), wantErr: "failed to read"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(serveTokenEnv, tt.env)
+			serveTokenFileFlag = tt.file
+			got, err := serveToken()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("serveToken() = %q, %v; want an error mentioning %q", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("serveToken() = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -1,14 +1,20 @@
 package commands
 
 import (
+	"crypto/ecdsa"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/contract"
 	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/crypto"
 	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/tui"
 	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/version"
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/spf13/cobra"
 )
 
@@ -27,24 +33,97 @@ var (
 	signInputFlag      string
 	signOutputFlag     string
 	signSkipReviewFlag bool
+
+	signVerifyDeterministicFlag bool
+
+	signEncryptOutFlag      bool
+	signRecipientPubkeyFlag string
+
+	signForceFlag bool
+	signCBORFlag  bool
+
+	signPrintPayloadFlag bool
+
+	signScanPathsFlag      bool
+	signScanCountFlag      int
+	signDerivationPathFlag string
 )
 
+// maxScanCount bounds --scan-count
+const maxScanCount = 1000
+
 func init() {
 	SignCmd.Flags().StringVarP(&signInputFlag, "input", "i", "tx-params.json", "Input transaction parameters file")
 	SignCmd.Flags().StringVarP(&signOutputFlag, "output", "o", "signed-tx.json", "Output signed transaction file")
+	SignCmd.Flags().BoolVar(&signCBORFlag, "cbor", false, "Write the signed transaction in the compact CBOR format for size-limited transfers such as QR codes (default output signed-tx.cbor)")
+	SignCmd.Flags().BoolVar(&signForceFlag, "force", false, "Overwrite an existing output file")
 	SignCmd.Flags().BoolVar(&signSkipReviewFlag, "skip-review", false, "Skip interactive TUI review (not recommended)")
+	SignCmd.Flags().BoolVar(&signVerifyDeterministicFlag, "verify-deterministic", false, "Sign twice and fail unless both signatures are byte-identical (RFC 6979)")
+	SignCmd.Flags().BoolVar(&signEncryptOutFlag, "encrypt-out", false, "Encrypt the signed transaction file to --recipient-pubkey (ECIES) for transfer over an untrusted medium")
+	SignCmd.Flags().StringVar(&signRecipientPubkeyFlag, "recipient-pubkey", "", "Hex secp256k1 public key of the online machine to encrypt to (with --encrypt-out)")
+	SignCmd.Flags().BoolVar(&signPrintPayloadFlag, "print-signing-payload", false, "Print the exact bytes to be signed (the RLP pre-image) and their signing hash before signing")
+	SignCmd.Flags().BoolVar(&signScanPathsFlag, "scan-paths", false, "Derive accounts from MNEMONIC and sign with the one matching the transaction sender, instead of using PRIVATE_KEY")
+	SignCmd.Flags().IntVar(&signScanCountFlag, "scan-count", 20, "Number of derivation indices to check with --scan-paths")
+	SignCmd.Flags().StringVar(&signDerivationPathFlag, "derivation-path", accounts.DefaultBaseDerivationPath.String(), "First derivation path checked with --scan-paths; its last component is incremented")
 }
 
 func runSign(cmd *cobra.Command, args []string) error {
+	if signCBORFlag && !cmd.Flags().Changed("output") {
+		signOutputFlag = "signed-tx.cbor"
+	}
+
+	// Check the encryption options before the review, not after signing
+	var recipient *ecdsa.PublicKey
+	if signEncryptOutFlag {
+		if signRecipientPubkeyFlag == "" {
+			return fmt.Errorf("--encrypt-out requires --recipient-pubkey")
+		}
+		var err error
+		recipient, err = crypto.ParsePublicKey(signRecipientPubkeyFlag)
+		if err != nil {
+			return fmt.Errorf("invalid --recipient-pubkey: %w", err)
+		}
+	} else if signRecipientPubkeyFlag != "" {
+		return fmt.Errorf("--recipient-pubkey requires --encrypt-out")
+	}
+
+	// Check the scan options before the review, not after it
+	var scanBase accounts.DerivationPath
+	if signScanPathsFlag {
+		if signScanCountFlag <= 0 || signScanCountFlag > maxScanCount {
+			return fmt.Errorf("--scan-count must be between 1 and %d", maxScanCount)
+		}
+		var err error
+		scanBase, err = accounts.ParseDerivationPath(signDerivationPathFlag)
+		if err != nil {
+			return fmt.Errorf("invalid --derivation-path: %w", err)
+		}
+	} else if cmd.Flags().Changed("scan-count") || cmd.Flags().Changed("derivation-path") {
+		return fmt.Errorf("--scan-count and --derivation-path require --scan-paths")
+	}
+
 	// Load transaction parameters
-	txParamsData, err := os.ReadFile(signInputFlag)
+	loaded, err := types.LoadTxParams(signInputFlag)
 	if err != nil {
-		return fmt.Errorf("failed to read input file: %w", err)
+		return fmt.Errorf("failed to load transaction parameters: %w", err)
+	}
+	txParams := *loaded
+	if changes := types.MigrateTxParams(&txParams); len(changes) > 0 {
+		log.Info("Migrated transaction parameters from an older format", "changes", strings.Join(changes, "; "))
 	}
 
-	var txParams types.TxParams
-	if err := json.Unmarshal(txParamsData, &txParams); err != nil {
-		return fmt.Errorf("failed to parse transaction parameters: %w", err)
+	// A labelled operation keeps its label in the signed file's name. The
+	// label is sanitized again, as the file may not come from prepare.
+	if txParams.Metadata.Label != "" && !cmd.Flags().Changed("output") {
+		label, err := sanitizeLabel(txParams.Metadata.Label)
+		if err != nil {
+			return fmt.Errorf("transaction parameters: %w", err)
+		}
+		signOutputFlag = labeledPath(signOutputFlag, label)
+	}
+	// Fail before the review rather than after it
+	if err := checkOverwrite(signOutputFlag, signForceFlag); err != nil {
+		return err
 	}
 
 	log.Info("Transaction parameters loaded")
@@ -55,11 +134,43 @@ func runSign(cmd *cobra.Command, args []string) error {
 	if txParams.FunctionName != "" {
 		log.Info("  Function", "function", txParams.FunctionName)
 	}
+	if txParams.Metadata.BroadcastAfter != "" {
+		log.Info("  Scheduled for broadcast after", "time", txParams.Metadata.BroadcastAfter)
+	}
 
 	// Validate transaction parameters
 	if err := validateTxParams(&txParams); err != nil {
 		return fmt.Errorf("invalid transaction parameters: %w", err)
 	}
+	if err := checkSelfContained(&txParams); err != nil {
+		return fmt.Errorf("invalid transaction parameters: %w", err)
+	}
+
+	// The embedded ABI lets the review decode the calldata locally
+	if err := contract.Initialize(); err != nil {
+		log.Warn("Could not load the contract ABI; calldata will not be decoded locally", "error", err)
+	}
+
+	if signPrintPayloadFlag {
+		payload, err := crypto.ComputeSigningPayload(&txParams.Transaction)
+		if err != nil {
+			return fmt.Errorf("failed to compute signing payload: %w", err)
+		}
+		log.Info("Signing payload", "signer", payload.Signer, "size", len(payload.Preimage))
+		log.Info("  Pre-image", "bytes", "0x"+hex.EncodeToString(payload.Preimage))
+		log.Info("  Signing hash", "hash", payload.Hash.Hex())
+		if payload.UnsignedAuthorizations > 0 {
+			log.Warn("⚠ Authorizations are signed first; the final pre-image includes their signatures in place of the zeros shown",
+				"unsigned_authorizations", payload.UnsignedAuthorizations)
+		}
+	}
+
+	// The signature is checked against the parameters as loaded, not the
+	// in-memory copy the review renders and edits
+	loadedTx, err := txParams.Transaction.Clone()
+	if err != nil {
+		return err
+	}
 
 	// Interactive TUI review (unless skipped)
 	if !signSkipReviewFlag {
@@ -77,6 +188,9 @@ func runSign(cmd *cobra.Command, args []string) error {
 		}
 
 		log.Info("✓ Transaction approved by user")
+		for _, edit := range txParams.Metadata.ReviewEdits {
+			log.Warn("⚠ Edited during review", "field", edit.Field, "prepared", edit.Prepared, "signed", edit.Signed)
+		}
 	} else {
 		log.Warn("⚠ WARNING: Skipping transaction review (use with caution!)")
 	}
@@ -87,19 +201,57 @@ func runSign(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	if config.PrivateKey == "" {
-		return fmt.Errorf("PRIVATE_KEY not set in environment")
+	privateKey := config.PrivateKey
+	if signScanPathsFlag {
+		if err := config.RequireMnemonic(); err != nil {
+			return err
+		}
+		log.Info("Scanning derivation paths for the transaction sender...",
+			"from", signDerivationPathFlag, "count", signScanCountFlag)
+		account, err := crypto.ScanMnemonic(config.Mnemonic, config.MnemonicPassphrase, scanBase, signScanCountFlag, txParams.Transaction.From)
+		if err != nil {
+			return err
+		}
+		log.Info("✓ Found the transaction sender", "path", account.Path.String(), "address", account.Address.Hex())
+		privateKey = account.PrivateKeyHex()
+	} else if err := config.RequireSigning(); err != nil {
+		return err
 	}
 
 	// Sign transaction
 	log.Info("Signing transaction...")
-	signedTx, err := crypto.SignTransaction(&txParams, config.PrivateKey)
+	signedTx, err := crypto.SignTransaction(&txParams, privateKey)
 	if err != nil {
 		return fmt.Errorf("failed to sign transaction: %w", err)
 	}
 
+	// Make sure the signed bytes are exactly what was loaded, changed only
+	// by the edits recorded during review
+	if err := loadedTx.ApplyReviewEdits(txParams.Metadata.ReviewEdits); err != nil {
+		return fmt.Errorf("refusing to write signed transaction: %w", err)
+	}
+	if err := crypto.VerifySignedTransaction(signedTx.SignedTransaction, loadedTx); err != nil {
+		return fmt.Errorf("refusing to write signed transaction: %w", err)
+	}
+	log.Info("✓ Signed transaction matches reviewed parameters")
+
+	if signVerifyDeterministicFlag {
+		if err := crypto.VerifyDeterministic(&txParams, privateKey, signedTx.SignedTransaction); err != nil {
+			return fmt.Errorf("refusing to write signed transaction: %w", err)
+		}
+		log.Info("✓ Signature is deterministic (identical when signed twice)")
+	}
+
 	// Update metadata
+	signedTx.SchemaVersion = types.SchemaVersion
 	signedTx.Metadata.SignedAt = time.Now().UTC().Format(time.RFC3339)
+	signedTx.Metadata.TxTypeName = types.TxTypeName(txParams.Transaction.TxType)
+	signedTx.Metadata.SignedWith = &types.SignerSoftware{
+		ToolVersion:       version.ToolVersion(),
+		GitCommit:         version.GitCommit,
+		GoEthereumVersion: version.GoEthereumVersion(),
+		GoVersion:         version.GoVersion(),
+	}
 
 	log.Info("✓ Transaction signed successfully")
 	log.Info("  TX Hash", "hash", signedTx.TxHash.Hex())
@@ -108,20 +260,57 @@ func runSign(cmd *cobra.Command, args []string) error {
 	}
 
 	// Save signed transaction
-	signedData, err := json.MarshalIndent(signedTx, "", "  ")
+	var signedData []byte
+	if signCBORFlag {
+		signedData, err = types.EncodeCBOR(signedTx)
+	} else {
+		signedData, err = json.MarshalIndent(signedTx, "", "  ")
+	}
 	if err != nil {
 		return fmt.Errorf("failed to serialize signed transaction: %w", err)
 	}
+	if recipient != nil {
+		encrypted, err := crypto.EncryptSignedTx(signedData, recipient)
+		if err != nil {
+			return err
+		}
+		signedData, err = json.MarshalIndent(encrypted, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to serialize encrypted signed transaction: %w", err)
+		}
+		log.Info("✓ Signed transaction encrypted", "recipient", encrypted.Recipient.Hex(), "encryption", encrypted.Encryption)
+	}
 
 	if err := os.WriteFile(signOutputFlag, signedData, 0644); err != nil {
 		return fmt.Errorf("failed to write output file: %w", err)
 	}
 
 	log.Info("✓ Signed transaction saved", "file", signOutputFlag)
+	journalSignedTx("sign", "signed", signOutputFlag, signedTx, nil)
 	log.Info("  Next",
-		"instruction", fmt.Sprintf("Transfer to online machine and run 'cryptoheir broadcast -i %s --network %s'",
-			signOutputFlag, txParams.Metadata.Network.Name))
+		"instruction", fmt.Sprintf("Transfer to online machine and run 'cryptoheir broadcast -i %s --network %s%s'",
+			signOutputFlag, txParams.Metadata.Network.Name, decryptHint(recipient != nil)))
+
+	return nil
+}
 
+// checkSelfContained verifies what a self-contained file claims that the
+// signer can recompute offline: the address a deployment will create
+func checkSelfContained(txParams *types.TxParams) error {
+	sc := txParams.SelfContained
+	if sc == nil {
+		return nil
+	}
+	tx := txParams.Transaction
+	if predicted := sc.PredictedContractAddress; predicted != nil {
+		if tx.To != nil {
+			return fmt.Errorf("the file records a predicted contract address but the transaction is not a deployment")
+		}
+		if computed := crypto.PredictContractAddress(tx.From, tx.Nonce); *predicted != computed {
+			return fmt.Errorf("the file's predicted contract address %s does not match %s, computed from the sender and nonce", predicted.Hex(), computed.Hex())
+		}
+	}
+	log.Info("Self-contained transaction file: the review uses the decoding and ABI fragment it carries")
 	return nil
 }
 
@@ -133,6 +322,17 @@ func validateTxParams(txParams *types.TxParams) error {
 		if txParams.Transaction.MaxFeePerGas == nil || txParams.Transaction.MaxPriorityFeePerGas == nil {
 			return fmt.Errorf("EIP-1559 transaction requires max_fee_per_gas and max_priority_fee_per_gas")
 		}
+	} else if txParams.Transaction.TxType == 4 {
+		// EIP-7702
+		if txParams.Transaction.MaxFeePerGas == nil || txParams.Transaction.MaxPriorityFeePerGas == nil {
+			return fmt.Errorf("EIP-7702 transaction requires max_fee_per_gas and max_priority_fee_per_gas")
+		}
+		if txParams.Transaction.To == nil {
+			return fmt.Errorf("EIP-7702 transaction cannot be a contract deployment")
+		}
+		if len(txParams.Transaction.AuthorizationList) == 0 {
+			return fmt.Errorf("EIP-7702 transaction requires an authorization_list")
+		}
 	} else if txParams.Transaction.TxType == 0 {
 		// Legacy
 		if txParams.Transaction.GasPrice == nil {
@@ -154,3 +354,11 @@ func validateTxParams(txParams *types.TxParams) error {
 
 	return nil
 }
+
+// decryptHint returns the broadcast flags needed for an encrypted output file
+func decryptHint(encrypted bool) string {
+	if !encrypted {
+		return ""
+	}
+	return " --decrypt --key <key-file>"
+}
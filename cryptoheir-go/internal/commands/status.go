@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/network"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/spf13/cobra"
+)
+
+// StatusCmd represents the status command
+var StatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Check the status of a broadcast transaction",
+	Long: `Check whether a broadcast transaction has been mined.
+
+Reads the signed transaction file, looks up its hash on the network and, once
+the transaction is mined, saves the receipt next to the input file (replacing
+the result written by 'broadcast --no-wait').`,
+	RunE: runStatus,
+}
+
+var (
+	statusInputFlag   string
+	statusNetworkFlag string
+	statusRPCURLFlag  string
+	statusRPCURLFile  string
+
+	statusBeneficiaryCard bool
+)
+
+func init() {
+	StatusCmd.Flags().StringVarP(&statusInputFlag, "input", "i", "signed-tx.json", "Input signed transaction file")
+	StatusCmd.Flags().StringVar(&statusNetworkFlag, "network", "", "Network name (defaults to the signed transaction's network)")
+	StatusCmd.Flags().StringVar(&statusRPCURLFlag, "rpc-url", "", "Custom RPC URL (overrides network)")
+	StatusCmd.Flags().StringVar(&statusRPCURLFile, "rpc-url-file", "", "Read the custom RPC URL from a file ('-' for stdin)")
+	StatusCmd.Flags().StringVar(&rpcSelectFlag, "rpc-select", rpcSelectFirst, rpcSelectUsage)
+	StatusCmd.Flags().BoolVar(&statusBeneficiaryCard, "beneficiary-card", false, "For a confirmed deposit, write a card for the beneficiary (<input>-beneficiary.txt)")
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	signedTx, err := loadSignedTx(statusInputFlag)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	client, err := connectForSignedTx(ctx, signedTx, statusNetworkFlag, statusRPCURLFlag, statusRPCURLFile)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	status, receipt, err := GetTransactionStatus(ctx, client, signedTx.TxHash)
+	if err != nil {
+		return err
+	}
+
+	log.Info("Transaction status", "hash", signedTx.TxHash.Hex(), "status", status)
+	if receipt == nil {
+		return nil
+	}
+	journalSignedTx("status", status, statusInputFlag, signedTx, nil)
+
+	log.Info("  Block", "block", receipt.BlockNumber)
+	log.Info("  Gas Used", "gas_used", receipt.GasUsed)
+	if receipt.ContractAddress != nil {
+		log.Info("  Contract Address", "address", receipt.ContractAddress.Hex())
+	}
+
+	receipt.Metadata["network"] = signedTx.Metadata.Network.Name
+	receipt.Metadata["chain_id"] = signedTx.Metadata.Network.ChainID
+	if signedTx.Metadata.Bundle != nil {
+		receipt.Metadata["bundle"] = signedTx.Metadata.Bundle
+	}
+	if signedTx.Metadata.Label != "" {
+		receipt.Metadata["label"] = signedTx.Metadata.Label
+	}
+
+	recordReceiptParties(receipt, signedTx)
+	recordReceiptEvents(receipt)
+
+	receiptFilename := receiptPath(statusInputFlag)
+	receiptData, err := json.MarshalIndent(receipt, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize receipt: %w", err)
+	}
+	if err := os.WriteFile(receiptFilename, receiptData, 0644); err != nil {
+		return fmt.Errorf("failed to write receipt file: %w", err)
+	}
+	log.Info("  Receipt saved", "file", receiptFilename)
+	if statusBeneficiaryCard {
+		writeBeneficiaryCards(receipt, signedTx.Metadata.Network.ChainID, signedTx.Metadata.Network.Name, statusInputFlag)
+	}
+
+	if receipt.Status == 0 {
+		return types.Errorf(types.CodeTxFailed, "transaction failed on-chain")
+	}
+	return nil
+}
+
+// GetTransactionStatus checks the status of a transaction by hash. The status
+// is "not found", "pending", "success" or "failed"; the receipt is returned
+// once the transaction is mined.
+func GetTransactionStatus(ctx context.Context, client *ethclient.Client, txHash common.Hash) (string, *types.TxReceipt, error) {
+	_, isPending, err := network.GetTransaction(ctx, client, txHash)
+	if errors.Is(err, ethereum.NotFound) {
+		return "not found", nil, nil
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if isPending {
+		return "pending", nil, nil
+	}
+
+	receipt, err := client.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get receipt: %w", err)
+	}
+
+	result := network.ToTxReceipt(receipt)
+	return receiptStatus(result), result, nil
+}
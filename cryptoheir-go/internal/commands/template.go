@@ -0,0 +1,264 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/contract"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/spf13/cobra"
+)
+
+// loadTemplate reads a prepared transaction parameters file, or a receipt,
+// for --from-params and checks that it fits the operation, chain and signer
+func loadTemplate(path, operation string, chainID uint64, signerAddress common.Address) (*types.TxParams, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load template %s: %w", path, err)
+	}
+	var template *types.TxParams
+	fromReceipt := isReceiptFile(data)
+	if fromReceipt {
+		if !refetchGasFlag {
+			return nil, fmt.Errorf("template %s is a receipt, which carries no gas settings; add --refetch-gas", path)
+		}
+		if template, err = templateFromReceipt(path); err != nil {
+			return nil, err
+		}
+	} else {
+		if template, err = types.LoadTxParams(path); err != nil {
+			return nil, fmt.Errorf("failed to load template %s: %w", path, err)
+		}
+		types.MigrateTxParams(template)
+	}
+
+	switch operation {
+	case "deploy":
+		if template.Mode != types.TransactionModeDeploy {
+			return nil, fmt.Errorf("template %s is not a deployment (mode %s)", path, template.Mode)
+		}
+	default:
+		if template.Mode != types.TransactionModeCall || template.FunctionName != operation {
+			return nil, fmt.Errorf("template %s is not a %s transaction (%s)", path, operation, operationName(template.Mode, template.FunctionName))
+		}
+		if template.Transaction.To == nil {
+			return nil, fmt.Errorf("template %s has no recipient", path)
+		}
+	}
+	tx := template.Transaction
+	switch {
+	case fromReceipt:
+	case tx.GasLimit == nil:
+		return nil, fmt.Errorf("template %s has no gas limit", path)
+	case tx.TxType == 0 && tx.GasPrice == nil:
+		return nil, fmt.Errorf("template %s is a legacy transaction without a gas price", path)
+	case tx.TxType != 0 && (tx.MaxFeePerGas == nil || tx.MaxPriorityFeePerGas == nil):
+		return nil, fmt.Errorf("template %s is missing its EIP-1559 fees", path)
+	}
+	if fromReceipt && template.Transaction.ChainID == 0 {
+		log.Warn("The receipt does not record its chain ID; make sure --network is the chain it was mined on", "file", path)
+	} else if template.Transaction.ChainID != chainID {
+		return nil, types.Errorf(types.CodeChainMismatch, "template %s is for chain ID %d, but the network is chain ID %d", path, template.Transaction.ChainID, chainID)
+	}
+	if template.Transaction.From != signerAddress {
+		log.Warn("Template was prepared for a different signer", "template_from", template.Transaction.From.Hex(), "signer", signerAddress.Hex())
+	}
+
+	log.Info("Using template", "file", path, "operation", operationName(template.Mode, template.FunctionName), "template_nonce", template.Transaction.Nonce)
+	return template, nil
+}
+
+// applyTemplateContract takes the contract address from the template. An
+// explicit CONTRACT_ADDRESS must agree with it.
+func applyTemplateContract(config *types.Config, template *types.TxParams) error {
+	to := *template.Transaction.To
+	if config.ContractAddress != nil && *config.ContractAddress != to {
+		return fmt.Errorf("template targets contract %s, but CONTRACT_ADDRESS is %s", to.Hex(), config.ContractAddress.Hex())
+	}
+	config.ContractAddress = &to
+	log.Info("Template field", "field", "to", "source", "inherited", "value", to.Hex())
+	return nil
+}
+
+// applyDepositTemplate fills deposit flags that were not given on the command
+// line from the template's parameters, logging which were inherited and
+// which were overridden. Amounts are recorded in base units and converted
+// back to the units the --amount flag expects.
+func applyDepositTemplate(ctx context.Context, cmd *cobra.Command, client *ethclient.Client, template *types.TxParams) error {
+	var params map[string]string
+	if err := json.Unmarshal(template.Params, &params); err != nil {
+		return fmt.Errorf("failed to parse template parameters: %w", err)
+	}
+
+	// Record which flags were given before any are filled in
+	order := []string{"beneficiary", "amount", "deadline", "token"}
+	given := make(map[string]bool, len(order))
+	for _, name := range order {
+		given[name] = cmd.Flags().Changed(name)
+	}
+
+	// The token decides the units of the inherited amount, so resolve it first
+	if !given["token"] && params["token"] != "" {
+		if err := cmd.Flags().Set("token", params["token"]); err != nil {
+			return fmt.Errorf("invalid template token: %w", err)
+		}
+	}
+	var token common.Address
+	if tokenFlag != "" {
+		var err error
+		if token, _, err = parseAddressArg(tokenFlag); err != nil {
+			return fmt.Errorf("invalid token: %w", err)
+		}
+	}
+
+	// The template amount is in base units of the template's token; another
+	// token's decimals would scale it by orders of magnitude
+	var templateToken common.Address
+	if params["token"] != "" {
+		templateToken = common.HexToAddress(params["token"])
+	}
+	if token != templateToken && !given["amount"] && params["amount"] != "" {
+		return fmt.Errorf("--token %s differs from the template token %s; pass --amount in units of the new token",
+			tokenName(token), tokenName(templateToken))
+	}
+
+	decimals := uint8(18)
+	if tokenFlag != "" {
+		info, err := prepareTokenInfo(ctx, client, token)
+		if err != nil {
+			return err
+		}
+		decimals = info.Decimals
+	}
+
+	inherited := map[string]string{
+		"beneficiary": params["beneficiary"],
+		"amount":      templateUnits(params["amount"], decimals),
+		"deadline":    params["deadline"],
+		"token":       params["token"],
+	}
+	for _, name := range order {
+		value := inherited[name]
+		switch {
+		case given[name]:
+			log.Info("Template field", "field", name, "source", "overridden", "value", cmd.Flags().Lookup(name).Value.String())
+		case value == "":
+			continue
+		case name == "token":
+			log.Info("Template field", "field", name, "source", "inherited", "value", value)
+		default:
+			if err := cmd.Flags().Set(name, value); err != nil {
+				return fmt.Errorf("invalid template %s: %w", name, err)
+			}
+			log.Info("Template field", "field", name, "source", "inherited", "value", value)
+		}
+	}
+	if !given["deadline"] && deadlineFlag != 0 && deadlineFlag <= time.Now().Unix() {
+		return fmt.Errorf("the template deadline %s has passed; pass a new --deadline", time.Unix(deadlineFlag, 0).UTC().Format(time.RFC3339))
+	}
+	if name := params["beneficiary_ens"]; name != "" && !given["beneficiary"] {
+		log.Info("Template beneficiary was resolved from an ENS name; reusing the resolved address", "name", name)
+	}
+	return nil
+}
+
+// tokenName names a deposit token for messages, "native ETH" for the zero
+// address
+func tokenName(token common.Address) string {
+	if token == (common.Address{}) {
+		return "native ETH"
+	}
+	return token.Hex()
+}
+
+// templateUnits converts a base-unit amount recorded in a template back to
+// a decimal string. Empty or malformed amounts yield an empty string.
+func templateUnits(amount string, decimals uint8) string {
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return ""
+	}
+	return types.FormatUnits(value, decimals)
+}
+
+// checkTemplateDeploy warns when the template deployed different bytecode
+// than the embedded contract, which is always what gets deployed
+func checkTemplateDeploy(template *types.TxParams) {
+	if len(template.Transaction.Data) == 0 {
+		// Receipts do not record the deployed bytecode
+		return
+	}
+	bytecode, err := contract.LoadBytecode()
+	if err == nil && !bytes.Equal(template.Transaction.Data, bytecode) {
+		log.Warn("Template deployed different bytecode; using the embedded contract bytecode")
+	}
+}
+
+// applyTemplateGas copies the template's fees over the freshly fetched ones
+// unless --refetch-gas is given. Its gas limit is only reused for the same
+// call: when flags change the recipient, calldata, value or delegation (e.g.
+// --token, --amount or --delegate), the fresh estimate is kept. The
+// transaction type follows the template's fee market; type-4 transactions
+// stay type 4.
+func applyTemplateGas(txData *types.TransactionData, template *types.TxParams) {
+	if refetchGasFlag {
+		log.Info("Template field", "field", "gas", "source", "refetched", "gas_limit", txData.GasLimit.ToBigInt().String())
+		return
+	}
+
+	src := template.Transaction
+	if reason := templateCallDiff(txData, &src); reason != "" {
+		log.Info("Template field", "field", "gas_limit", "source", "estimated", "reason", reason, "value", txData.GasLimit.ToBigInt().String())
+	} else {
+		txData.GasLimit = src.GasLimit
+		log.Info("Template field", "field", "gas_limit", "source", "inherited", "value", src.GasLimit.ToBigInt().String())
+	}
+
+	if src.TxType == 0 {
+		if txData.TxType == 4 {
+			log.Warn("Template uses legacy gas pricing, which EIP-7702 transactions cannot use; keeping the refetched fees")
+			return
+		}
+		txData.TxType = 0
+		txData.GasPrice = src.GasPrice
+		txData.MaxFeePerGas = nil
+		txData.MaxPriorityFeePerGas = nil
+		log.Info("Template field", "field", "fees", "source", "inherited",
+			"gas_price_gwei", types.FormatGwei(src.GasPrice.ToBigInt()))
+		return
+	}
+
+	if txData.TxType != 4 {
+		txData.TxType = 2
+	}
+	txData.GasPrice = nil
+	txData.MaxFeePerGas = src.MaxFeePerGas
+	txData.MaxPriorityFeePerGas = src.MaxPriorityFeePerGas
+	log.Info("Template field", "field", "fees", "source", "inherited",
+		"max_fee_gwei", types.FormatGwei(src.MaxFeePerGas.ToBigInt()),
+		"priority_fee_gwei", types.FormatGwei(src.MaxPriorityFeePerGas.ToBigInt()))
+}
+
+// templateCallDiff describes how the new transaction's call differs from the
+// template's, or returns "" when it is the same call and the template's gas
+// limit applies
+func templateCallDiff(txData, src *types.TransactionData) string {
+	switch {
+	case (txData.To == nil) != (src.To == nil) || (txData.To != nil && *txData.To != *src.To):
+		return "the recipient differs from the template"
+	case !bytes.Equal(txData.Data, src.Data):
+		return "the calldata differs from the template"
+	case txData.Value.ToBigInt().Cmp(src.Value.ToBigInt()) != 0:
+		return "the value differs from the template"
+	case len(txData.AuthorizationList) != len(src.AuthorizationList):
+		return "the delegation differs from the template"
+	}
+	return ""
+}
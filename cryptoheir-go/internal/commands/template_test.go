@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// templateCmd returns a command with the deposit flags bound to the prepare
+// flag variables, which are restored when the test ends
+func templateCmd(t *testing.T) *cobra.Command {
+	t.Helper()
+	saved := []interface{}{beneficiaryFlag, amountFlag, deadlineFlag, tokenFlag, tokenDecimalsFlag}
+	t.Cleanup(func() {
+		beneficiaryFlag = saved[0].(string)
+		amountFlag = saved[1].(string)
+		deadlineFlag = saved[2].(int64)
+		tokenFlag = saved[3].(string)
+		tokenDecimalsFlag = saved[4].(int)
+	})
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringVar(&beneficiaryFlag, "beneficiary", "", "")
+	cmd.Flags().StringVar(&amountFlag, "amount", "", "")
+	cmd.Flags().Int64Var(&deadlineFlag, "deadline", 0, "")
+	cmd.Flags().StringVar(&tokenFlag, "token", "", "")
+	cmd.Flags().IntVar(&tokenDecimalsFlag, "token-decimals", -1, "")
+	return cmd
+}
+
+func TestApplyDepositTemplateTokenChange(t *testing.T) {
+	const (
+		usdc = "0x2222222222222222222222222222222222222222" // 6 decimals
+		dai  = "0x3333333333333333333333333333333333333333" // 18 decimals
+	)
+	decimals := map[string]int{usdc: 6, dai: 18}
+
+	tests := []struct {
+		name          string
+		templateToken string
+		flags         map[string]string
+		wantErr       string
+		wantAmount    string
+		wantToken     string
+	}{
+		{
+			name:          "inherited token converts with its own decimals",
+			templateToken: usdc,
+			wantAmount:    "1.5",
+			wantToken:     usdc,
+		},
+		{
+			name:          "another token without --amount is refused",
+			templateToken: usdc,
+			flags:         map[string]string{"token": dai},
+			wantErr:       "pass --amount",
+		},
+		{
+			name:          "another token with --amount",
+			templateToken: usdc,
+			flags:         map[string]string{"token": dai, "amount": "2"},
+			wantAmount:    "2",
+			wantToken:     dai,
+		},
+		{
+			name:          "a token instead of a native template is refused",
+			templateToken: "",
+			flags:         map[string]string{"token": dai},
+			wantErr:       "template token native ETH",
+		},
+		{
+			name:          "the same token given again keeps the amount",
+			templateToken: usdc,
+			flags:         map[string]string{"token": strings.ToUpper(usdc[:2]) + usdc[2:]},
+			wantAmount:    "1.5",
+			wantToken:     usdc,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := stubNode(t, func(method string, params []json.RawMessage) interface{} {
+				if method != "eth_call" {
+					return nil
+				}
+				var call struct {
+					To string `json:"to"`
+				}
+				_ = json.Unmarshal(params[0], &call)
+				// A bare uint answers decimals(); as a symbol() string it
+				// fails to decode, which leaves the symbol empty
+				return fmt.Sprintf("0x%064x", decimals[strings.ToLower(call.To)])
+			})
+
+			templateParams, _ := json.Marshal(map[string]string{
+				"beneficiary": "0x4444444444444444444444444444444444444444",
+				"amount":      "1500000",
+				"deadline":    "4102444800",
+				"token":       tt.templateToken,
+			})
+			template := &types.TxParams{Params: templateParams}
+
+			cmd := templateCmd(t)
+			for name, value := range tt.flags {
+				if err := cmd.Flags().Set(name, value); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := applyDepositTemplate(context.Background(), cmd, client, template)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("applyDepositTemplate() error = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyDepositTemplate() error = %v", err)
+			}
+			if amountFlag != tt.wantAmount {
+				t.Errorf("amount = %q, want %q", amountFlag, tt.wantAmount)
+			}
+			if !strings.EqualFold(tokenFlag, tt.wantToken) {
+				t.Errorf("token = %q, want %q", tokenFlag, tt.wantToken)
+			}
+		})
+	}
+}
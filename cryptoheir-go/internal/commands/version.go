@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/contract"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/version"
+	"github.com/spf13/cobra"
+)
+
+// VersionCmd represents the version command
+var VersionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show tool, contract and library versions",
+	Long: `Show the tool version and build information, the keccak256 hash of the
+embedded CryptoHeir contract bytecode, and the linked go-ethereum version.
+
+Compare the contract hash against a trusted build to confirm which bytecode
+this binary will deploy. The release line names the official CryptoHeir
+release the bytecode matches, if any.`,
+	Args: cobra.NoArgs,
+	RunE: runVersion,
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	if err := contract.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize contract: %w", err)
+	}
+
+	bytecodeHash, err := contract.BytecodeHash()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", version.ToolVersion())
+	fmt.Printf("  Git commit:      %s\n", version.GitCommit)
+	fmt.Printf("  Build date:      %s\n", version.BuildDate)
+	fmt.Printf("  Go version:      %s\n", version.GoVersion())
+	fmt.Printf("  go-ethereum:     %s\n", version.GoEthereumVersion())
+	fmt.Printf("  Contract hash:   %s\n", bytecodeHash.Hex())
+	release := contract.MatchingRelease()
+	if release == "" {
+		release = "none (the embedded artifact is not an official release)"
+	}
+	fmt.Printf("  Release:         %s\n", release)
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/crypto"
+	"github.com/spf13/cobra"
+)
+
+// WhoamiCmd represents the whoami command
+var WhoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show which account signed a signed transaction file",
+	Long: `Recover the sender from the signature of each signed transaction file and
+print it next to the "from" address stored in the file, flagging any mismatch.
+
+This answers "who signed this?" and works entirely offline. It does not check
+the rest of the transaction.`,
+	Args: cobra.NoArgs,
+	RunE: runWhoami,
+}
+
+var whoamiFromSignedFlag []string
+
+func init() {
+	WhoamiCmd.Flags().StringSliceVar(&whoamiFromSignedFlag, "from-signed", nil, "Signed transaction file(s) to inspect (comma-separated or repeated)")
+	WhoamiCmd.MarkFlagRequired("from-signed")
+}
+
+func runWhoami(cmd *cobra.Command, args []string) error {
+	mismatches := 0
+	for _, path := range whoamiFromSignedFlag {
+		signedTx, err := loadSignedTx(path)
+		if err != nil {
+			return err
+		}
+
+		signer, tx, err := crypto.RecoverSigner(signedTx.SignedTransaction)
+		if err != nil {
+			return fmt.Errorf("failed to recover signer of %s: %w", path, err)
+		}
+
+		fmt.Printf("%s\n", path)
+		fmt.Printf("  Signer (recovered): %s\n", signer.Hex())
+		fmt.Printf("  From (stored):      %s\n", signedTx.From.Hex())
+		fmt.Printf("  Chain ID: %d  Nonce: %d  Hash: %s\n", tx.ChainId(), tx.Nonce(), tx.Hash().Hex())
+		if signer != signedTx.From {
+			fmt.Printf("  ⚠ MISMATCH: the file claims %s but was signed by %s\n", signedTx.From.Hex(), signer.Hex())
+			mismatches++
+		}
+	}
+
+	if mismatches > 0 {
+		return fmt.Errorf("%d of %d file(s) were signed by a different account than their stored from address", mismatches, len(whoamiFromSignedFlag))
+	}
+	return nil
+}
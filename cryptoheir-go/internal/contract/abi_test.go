@@ -0,0 +1,114 @@
+package contract
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// mutatedABI parses the embedded artifact's ABI after applying mutate to its
+// function entries, keyed by name
+func mutatedABI(t *testing.T, mutate func(functions map[string]map[string]interface{})) abi.ABI {
+	t.Helper()
+	var artifact struct {
+		ABI []map[string]interface{} `json:"abi"`
+	}
+	if err := json.Unmarshal(contractArtifactJSON, &artifact); err != nil {
+		t.Fatal(err)
+	}
+	functions := make(map[string]map[string]interface{})
+	for _, entry := range artifact.ABI {
+		if entry["type"] == "function" {
+			functions[entry["name"].(string)] = entry
+		}
+	}
+	mutate(functions)
+
+	var entries []map[string]interface{}
+	for _, entry := range artifact.ABI {
+		if entry["type"] != "function" || functions[entry["name"].(string)] != nil {
+			entries = append(entries, entry)
+		}
+	}
+	encoded, err := json.Marshal(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := abi.JSON(strings.NewReader(string(encoded)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+// input returns argument i of a function entry
+func input(entry map[string]interface{}, i int) map[string]interface{} {
+	return entry["inputs"].([]interface{})[i].(map[string]interface{})
+}
+
+// swapNames exchanges the names of two arguments, keeping their types
+func swapNames(entry map[string]interface{}, i, j int) {
+	a, b := input(entry, i), input(entry, j)
+	a["name"], b["name"] = b["name"], a["name"]
+}
+
+func TestCheckExpectedMethods(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(functions map[string]map[string]interface{})
+		wantErr string // "" for a valid ABI
+	}{
+		{"embedded artifact", func(map[string]map[string]interface{}) {}, ""},
+		{
+			"deposit token and beneficiary swapped",
+			func(f map[string]map[string]interface{}) { swapNames(f["deposit"], 0, 1) },
+			"takes its arguments as (_beneficiary, _token, _amount, _deadline)",
+		},
+		{
+			"deposit amount and deadline swapped",
+			func(f map[string]map[string]interface{}) { swapNames(f["deposit"], 2, 3) },
+			"takes its arguments as (_token, _beneficiary, _deadline, _amount)",
+		},
+		{
+			"extendDeadline arguments swapped",
+			func(f map[string]map[string]interface{}) { swapNames(f["extendDeadline"], 0, 1) },
+			"extendDeadline takes its arguments as (_newDeadline, _inheritanceId)",
+		},
+		{
+			"deposit argument renamed",
+			func(f map[string]map[string]interface{}) { input(f["deposit"], 1)["name"] = "_heir" },
+			"takes its arguments as (_token, _heir, _amount, _deadline)",
+		},
+		{
+			"deposit argument type changed",
+			func(f map[string]map[string]interface{}) { input(f["deposit"], 3)["type"] = "uint64" },
+			"expected deposit(address,address,uint256,uint256) (_token, _beneficiary, _amount, _deadline), found deposit(address,address,uint256,uint64)",
+		},
+		{
+			"claim missing",
+			func(f map[string]map[string]interface{}) { delete(f, "claim") },
+			"has no claim method",
+		},
+		{
+			"deposit not payable",
+			func(f map[string]map[string]interface{}) { f["deposit"]["stateMutability"] = "nonpayable" },
+			"is not payable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkExpectedMethods(mutatedABI(t, tt.mutate))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkExpectedMethods() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkExpectedMethods() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,15 +1,20 @@
 package contract
 
 import (
+	"bytes"
 	_ "embed"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 // ContractArtifact represents the Foundry contract artifact structure
@@ -18,6 +23,9 @@ type ContractArtifact struct {
 	Bytecode struct {
 		Object string `json:"object"`
 	} `json:"bytecode"`
+	DeployedBytecode struct {
+		Object string `json:"object"`
+	} `json:"deployedBytecode"`
 }
 
 //go:embed CryptoHeir.json
@@ -26,6 +34,7 @@ var contractArtifactJSON []byte
 var (
 	contractABI      abi.ABI
 	contractBytecode []byte
+	contractRuntime  []byte
 )
 
 // Initialize loads the contract artifact and parses ABI
@@ -41,6 +50,9 @@ func Initialize() error {
 	if err != nil {
 		return fmt.Errorf("failed to parse contract ABI: %w", err)
 	}
+	if err := checkExpectedMethods(parsedABI); err != nil {
+		return err
+	}
 	contractABI = parsedABI
 
 	// Parse bytecode (remove 0x prefix if present)
@@ -51,6 +63,81 @@ func Initialize() error {
 	}
 	contractBytecode = bytecode
 
+	// Runtime bytecode, as stored on chain after deployment
+	runtime, err := hex.DecodeString(strings.TrimPrefix(artifact.DeployedBytecode.Object, "0x"))
+	if err != nil {
+		return fmt.Errorf("failed to decode contract runtime bytecode: %w", err)
+	}
+	contractRuntime = runtime
+
+	return nil
+}
+
+// expectedMethods are the CryptoHeir functions the CLI encodes calls to,
+// with their canonical signatures
+var expectedMethods = map[string]string{
+	"deposit":              "deposit(address,address,uint256,uint256)",
+	"claim":                "claim(uint256)",
+	"reclaim":              "reclaim(uint256)",
+	"extendDeadline":       "extendDeadline(uint256,uint256)",
+	"transferFeeCollector": "transferFeeCollector(address)",
+	"acceptFeeCollector":   "acceptFeeCollector()",
+	"getInheritance":       "getInheritance(uint256)",
+}
+
+// expectedArguments are the ABI names of the arguments the CLI passes, in
+// order, for methods with several arguments of the same type. The signature
+// cannot tell them apart: a deposit with token and beneficiary, or amount and
+// deadline, swapped still packs, but sends the values to the wrong place.
+var expectedArguments = map[string][]string{
+	"deposit":        {"_token", "_beneficiary", "_amount", "_deadline"},
+	"extendDeadline": {"_inheritanceId", "_newDeadline"},
+}
+
+// payableMethods must accept value: a native deposit sends its amount
+var payableMethods = []string{"deposit"}
+
+// checkExpectedMethods confirms the ABI is a CryptoHeir ABI whose functions
+// take the arguments the CLI encodes, so a wrong or outdated artifact fails
+// here rather than with a packing error or malformed calldata in a command
+func checkExpectedMethods(parsedABI abi.ABI) error {
+	names := make([]string, 0, len(expectedMethods))
+	for name := range expectedMethods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		want := expectedMethods[name]
+		args, checkArgs := expectedArguments[name]
+		if checkArgs {
+			want = fmt.Sprintf("%s (%s)", want, strings.Join(args, ", "))
+		}
+		method, ok := parsedABI.Methods[name]
+		if !ok {
+			return fmt.Errorf("contract ABI has no %s method; expected %s", name, want)
+		}
+		if method.Sig != expectedMethods[name] {
+			return fmt.Errorf("contract ABI method %s does not match this CLI: expected %s, found %s; the artifact is from an incompatible contract version",
+				name, want, method.Sig)
+		}
+		if !checkArgs {
+			continue
+		}
+		found := make([]string, len(method.Inputs))
+		for i, input := range method.Inputs {
+			found[i] = input.Name
+		}
+		if !slices.Equal(found, args) {
+			return fmt.Errorf("contract ABI method %s takes its arguments as (%s), but this CLI passes (%s); the artifact is from an incompatible contract version",
+				name, strings.Join(found, ", "), strings.Join(args, ", "))
+		}
+	}
+	for _, name := range payableMethods {
+		if !parsedABI.Methods[name].IsPayable() {
+			return fmt.Errorf("contract ABI method %s is not payable; native deposits could not send their amount", expectedMethods[name])
+		}
+	}
 	return nil
 }
 
@@ -62,6 +149,41 @@ func LoadBytecode() ([]byte, error) {
 	return contractBytecode, nil
 }
 
+// MethodInfo describes a function of the loaded contract ABI
+type MethodInfo struct {
+	Name            string
+	Signature       string // Canonical signature, e.g. claim(uint256)
+	Declaration     string // Full declaration with parameter names and outputs
+	StateMutability string // pure, view, nonpayable or payable
+	Selector        []byte
+}
+
+// IsView reports whether calling the method cannot change state
+func (m MethodInfo) IsView() bool {
+	return m.StateMutability == "view" || m.StateMutability == "pure"
+}
+
+// Methods returns the functions of the contract ABI sorted by name
+func Methods() ([]MethodInfo, error) {
+	if contractABI.Methods == nil {
+		return nil, fmt.Errorf("contract not initialized, call Initialize() first")
+	}
+
+	methods := make([]MethodInfo, 0, len(contractABI.Methods))
+	for _, m := range contractABI.Methods {
+		methods = append(methods, MethodInfo{
+			Name:            m.Name,
+			Signature:       m.Sig,
+			Declaration:     m.String(),
+			StateMutability: m.StateMutability,
+			Selector:        m.ID,
+		})
+	}
+	sort.Slice(methods, func(i, j int) bool { return methods[i].Name < methods[j].Name })
+
+	return methods, nil
+}
+
 // EncodeDeposit encodes the deposit function call
 // deposit(address _token, address _beneficiary, uint256 _amount, uint256 _deadline)
 func EncodeDeposit(beneficiary common.Address, amount *big.Int, deadline *big.Int, token *common.Address) ([]byte, *big.Int, error) {
@@ -90,6 +212,109 @@ func EncodeDeposit(beneficiary common.Address, amount *big.Int, deadline *big.In
 	return data, value, nil
 }
 
+// DepositFee returns the protocol fee the contract keeps from a deposit
+// (0.1%, rounded down). The rest is held for the beneficiary.
+func DepositFee(amount *big.Int) *big.Int {
+	return new(big.Int).Div(amount, big.NewInt(1000))
+}
+
+// ClaimFee returns the protocol fee the contract keeps from a claim of the
+// held amount (1%, rounded down)
+func ClaimFee(held *big.Int) *big.Int {
+	return new(big.Int).Div(held, big.NewInt(100))
+}
+
+// ClaimWindow is when each party can settle an inheritance, by the
+// contract's rules: claim reverts before the deadline (DeadlineNotReached)
+// and reclaim from the deadline on (DeadlineAlreadyPassed). Until the
+// inheritance is claimed, the depositor can still move the deadline with
+// extendDeadline, so the window is the one in force when the deposit is made.
+type ClaimWindow struct {
+	Deadline *big.Int // Unix seconds, as passed to deposit
+}
+
+// NewClaimWindow returns the window of an inheritance with the given deadline
+func NewClaimWindow(deadline *big.Int) ClaimWindow {
+	return ClaimWindow{Deadline: deadline}
+}
+
+// DeadlineTime returns the deadline as a time, or false if it does not fit
+func (w ClaimWindow) DeadlineTime() (time.Time, bool) {
+	if w.Deadline == nil || !w.Deadline.IsInt64() {
+		return time.Time{}, false
+	}
+	return time.Unix(w.Deadline.Int64(), 0).UTC(), true
+}
+
+// formatDeadline renders the deadline as RFC3339 with its Unix timestamp
+func (w ClaimWindow) formatDeadline() string {
+	t, ok := w.DeadlineTime()
+	if !ok {
+		return fmt.Sprintf("timestamp %s", w.Deadline)
+	}
+	return fmt.Sprintf("%s (%s)", t.Format(time.RFC3339), w.Deadline)
+}
+
+// Summary states the window in one sentence
+func (w ClaimWindow) Summary() string {
+	return fmt.Sprintf("beneficiary can claim from %s on; depositor can reclaim before then", w.formatDeadline())
+}
+
+// Rules lists who can do what and when, one statement per line
+func (w ClaimWindow) Rules() []string {
+	deadline := w.formatDeadline()
+	return []string{
+		"Before " + deadline + ": the depositor can reclaim the deposit; the beneficiary cannot claim yet",
+		"From " + deadline + " on: the beneficiary can claim; the depositor can no longer reclaim",
+		"Until it is claimed, the depositor can move the deadline (extendDeadline), and this window with it",
+	}
+}
+
+// Metadata returns the window as recorded in transaction metadata
+func (w ClaimWindow) Metadata() map[string]interface{} {
+	info := map[string]interface{}{
+		"deadline": w.Deadline.String(),
+		"summary":  w.Summary(),
+	}
+	if t, ok := w.DeadlineTime(); ok {
+		info["claimable_from"] = t.Format(time.RFC3339)
+		info["reclaimable_before"] = t.Format(time.RFC3339)
+	}
+	return info
+}
+
+// DepositArgs are the decoded arguments of a deposit call
+type DepositArgs struct {
+	Token       common.Address // Zero address for native ETH
+	Beneficiary common.Address
+	Amount      *big.Int
+	Deadline    *big.Int
+}
+
+// DecodeDeposit decodes deposit calldata, failing if the calldata is not a
+// deposit call
+func DecodeDeposit(data []byte) (*DepositArgs, error) {
+	if contractABI.Methods == nil {
+		return nil, fmt.Errorf("contract not initialized, call Initialize() first")
+	}
+
+	method := contractABI.Methods["deposit"]
+	if len(data) < 4 || !bytes.Equal(data[:4], method.ID) {
+		return nil, fmt.Errorf("calldata is not a deposit call")
+	}
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode deposit: %w", err)
+	}
+
+	return &DepositArgs{
+		Token:       values[0].(common.Address),
+		Beneficiary: values[1].(common.Address),
+		Amount:      values[2].(*big.Int),
+		Deadline:    values[3].(*big.Int),
+	}, nil
+}
+
 // EncodeClaim encodes the claim function call
 // claim(uint256 _inheritanceId)
 func EncodeClaim(inheritanceID *big.Int) ([]byte, error) {
@@ -165,6 +390,112 @@ func EncodeAcceptFeeCollector() ([]byte, error) {
 	return data, nil
 }
 
+// Inheritance is the stored state of an inheritance as returned by
+// getInheritance. Claimed is set by both claim and reclaim; a zero depositor
+// means the inheritance does not exist.
+type Inheritance struct {
+	Depositor   common.Address
+	Beneficiary common.Address
+	Token       common.Address
+	Amount      *big.Int
+	Deadline    *big.Int
+	Claimed     bool
+}
+
+// EncodeGetInheritance encodes the getInheritance view call
+// getInheritance(uint256 _inheritanceId)
+func EncodeGetInheritance(inheritanceID *big.Int) ([]byte, error) {
+	if contractABI.Methods == nil {
+		return nil, fmt.Errorf("contract not initialized, call Initialize() first")
+	}
+
+	data, err := contractABI.Pack("getInheritance", inheritanceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode getInheritance: %w", err)
+	}
+
+	return data, nil
+}
+
+// DecodeInheritance decodes the result of a getInheritance call
+func DecodeInheritance(result []byte) (*Inheritance, error) {
+	if contractABI.Methods == nil {
+		return nil, fmt.Errorf("contract not initialized, call Initialize() first")
+	}
+
+	values, err := contractABI.Unpack("getInheritance", result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode getInheritance result: %w", err)
+	}
+
+	return &Inheritance{
+		Depositor:   values[0].(common.Address),
+		Beneficiary: values[1].(common.Address),
+		Token:       values[2].(common.Address),
+		Amount:      values[3].(*big.Int),
+		Deadline:    values[4].(*big.Int),
+		Claimed:     values[5].(bool),
+	}, nil
+}
+
+// EncodeNextInheritanceID encodes the nextInheritanceId view call
+func EncodeNextInheritanceID() ([]byte, error) {
+	if contractABI.Methods == nil {
+		return nil, fmt.Errorf("contract not initialized, call Initialize() first")
+	}
+
+	data, err := contractABI.Pack("nextInheritanceId")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode nextInheritanceId: %w", err)
+	}
+
+	return data, nil
+}
+
+// DecodeNextInheritanceID decodes the result of a nextInheritanceId call
+func DecodeNextInheritanceID(result []byte) (*big.Int, error) {
+	if contractABI.Methods == nil {
+		return nil, fmt.Errorf("contract not initialized, call Initialize() first")
+	}
+
+	values, err := contractABI.Unpack("nextInheritanceId", result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode nextInheritanceId result: %w", err)
+	}
+
+	return values[0].(*big.Int), nil
+}
+
+// DecodeInheritanceCall returns the method name and inheritance ID of a
+// claim, reclaim or extendDeadline call, failing for any other calldata
+func DecodeInheritanceCall(data []byte) (string, *big.Int, error) {
+	if contractABI.Methods == nil {
+		return "", nil, fmt.Errorf("contract not initialized, call Initialize() first")
+	}
+
+	for _, name := range []string{"claim", "reclaim", "extendDeadline"} {
+		method := contractABI.Methods[name]
+		if len(data) < 4 || !bytes.Equal(data[:4], method.ID) {
+			continue
+		}
+		values, err := method.Inputs.Unpack(data[4:])
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to decode %s: %w", name, err)
+		}
+		return name, values[0].(*big.Int), nil
+	}
+	return "", nil, fmt.Errorf("calldata is not a call on an inheritance")
+}
+
+// SettledEventIDs returns the topics of the InheritanceClaimed and
+// InheritanceReclaimed events, which mark an inheritance as settled
+func SettledEventIDs() (claimed, reclaimed common.Hash, err error) {
+	if contractABI.Events == nil {
+		return common.Hash{}, common.Hash{}, fmt.Errorf("contract not initialized, call Initialize() first")
+	}
+	return contractABI.Events["InheritanceClaimed"].ID, contractABI.Events["InheritanceReclaimed"].ID, nil
+}
+
 // DecodeContractError attempts to decode a contract revert error
 func DecodeContractError(revertData []byte) string {
 	if len(revertData) < 4 {
@@ -194,15 +525,47 @@ func DecodeContractError(revertData []byte) string {
 		revertData[1] == errorSig[1] &&
 		revertData[2] == errorSig[2] &&
 		revertData[3] == errorSig[3] {
-		// Try to decode the string (skip selector, decode ABI-encoded string)
-		if len(revertData) > 4 {
-			return fmt.Sprintf("revert: %s", string(revertData[4:]))
+		if reason, err := abi.UnpackRevert(revertData); err == nil {
+			return fmt.Sprintf("revert: %s", reason)
 		}
 	}
 
+	// Check for compiler-inserted Panic(uint256)
+	if bytes.HasPrefix(revertData, panicSelector) && len(revertData) >= 36 {
+		code := new(big.Int).SetBytes(revertData[4:36])
+		return fmt.Sprintf("panic 0x%02x: %s", code, panicDescription(code))
+	}
+
 	return fmt.Sprintf("unknown error: 0x%x", revertData[:min(len(revertData), 32)])
 }
 
+// panicSelector is the selector of Solidity's Panic(uint256) error
+var panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+
+// panicCodes maps Panic(uint256) codes to their documented meaning
+var panicCodes = map[uint64]string{
+	0x00: "generic compiler-inserted panic",
+	0x01: "assertion failed",
+	0x11: "arithmetic overflow or underflow",
+	0x12: "division or modulo by zero",
+	0x21: "invalid enum value",
+	0x22: "incorrectly encoded storage byte array",
+	0x31: "pop() on an empty array",
+	0x32: "array index out of bounds",
+	0x41: "too much memory allocated",
+	0x51: "call to an uninitialized internal function",
+}
+
+// panicDescription returns the meaning of a Panic(uint256) code
+func panicDescription(code *big.Int) string {
+	if code.IsUint64() {
+		if desc, ok := panicCodes[code.Uint64()]; ok {
+			return desc
+		}
+	}
+	return "unknown panic code"
+}
+
 // min returns the smaller of two ints
 func min(a, b int) int {
 	if a < b {
@@ -210,3 +573,11 @@ func min(a, b int) int {
 	}
 	return b
 }
+
+// BytecodeHash returns the keccak256 hash of the embedded deployment bytecode
+func BytecodeHash() (common.Hash, error) {
+	if len(contractBytecode) == 0 {
+		return common.Hash{}, fmt.Errorf("contract not initialized, call Initialize() first")
+	}
+	return crypto.Keccak256Hash(contractBytecode), nil
+}
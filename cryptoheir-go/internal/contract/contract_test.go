@@ -0,0 +1,39 @@
+package contract
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// panicData encodes Panic(uint256) revert data with the given code
+func panicData(code int64) []byte {
+	return append(append([]byte{}, panicSelector...), common.LeftPadBytes(big.NewInt(code).Bytes(), 32)...)
+}
+
+func TestDecodeContractErrorPanic(t *testing.T) {
+	tests := []struct {
+		code int64
+		want string
+	}{
+		{0x01, "panic 0x01: assertion failed"},
+		{0x11, "panic 0x11: arithmetic overflow or underflow"},
+		{0x12, "panic 0x12: division or modulo by zero"},
+		{0x32, "panic 0x32: array index out of bounds"},
+		{0x99, "panic 0x99: unknown panic code"},
+	}
+	for _, tt := range tests {
+		if got := DecodeContractError(panicData(tt.code)); got != tt.want {
+			t.Errorf("DecodeContractError(Panic(0x%02x)) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeContractErrorTruncatedPanic(t *testing.T) {
+	got := DecodeContractError(panicData(0x11)[:20])
+	if !strings.HasPrefix(got, "unknown error: 0x4e487b71") {
+		t.Errorf("DecodeContractError(truncated panic) = %q, want the unknown error fallback", got)
+	}
+}
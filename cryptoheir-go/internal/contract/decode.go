@@ -0,0 +1,199 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DecodeCall decodes a transaction's calldata against the CryptoHeir and
+// ERC20 ABIs into a human-readable form. to is nil for deployments.
+func DecodeCall(to *common.Address, data []byte, value *big.Int) (*types.DecodedCall, error) {
+	if contractABI.Methods == nil {
+		return nil, fmt.Errorf("contract not initialized, call Initialize() first")
+	}
+
+	if to == nil {
+		summary := fmt.Sprintf("Deploy an unknown contract (%d bytes of init code)", len(data))
+		if bytes.Equal(data, contractBytecode) {
+			summary = "Deploy the CryptoHeir contract"
+		}
+		return &types.DecodedCall{Function: "constructor", Summary: summary}, nil
+	}
+
+	if len(data) == 0 {
+		return &types.DecodedCall{
+			Summary: fmt.Sprintf("Send %s ETH to %s (no calldata)", types.FormatEther(value), to.Hex()),
+		}, nil
+	}
+	if len(data) < 4 {
+		return nil, fmt.Errorf("calldata too short for a function selector")
+	}
+
+	method, err := contractABI.MethodById(data[:4])
+	if err != nil {
+		if method, err = erc20ABI.MethodById(data[:4]); err != nil {
+			return nil, fmt.Errorf("unknown function selector 0x%x", data[:4])
+		}
+	}
+
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s arguments: %w", method.Sig, err)
+	}
+
+	call := &types.DecodedCall{Function: method.Sig}
+	for i, input := range method.Inputs {
+		call.Args = append(call.Args, types.DecodedArg{
+			Name:  input.Name,
+			Type:  input.Type.String(),
+			Value: formatArg(values[i]),
+		})
+	}
+	call.Summary = summarize(method, values, *to, value)
+
+	return call, nil
+}
+
+// summarize describes a decoded call in plain words
+func summarize(method *abi.Method, values []interface{}, to common.Address, value *big.Int) string {
+	switch method.Sig {
+	case "deposit(address,address,uint256,uint256)":
+		// A native deposit deposits msg.value; _amount is ignored
+		deposited := values[2].(*big.Int)
+		if values[0].(common.Address) == (common.Address{}) {
+			deposited = value
+			if deposited == nil {
+				deposited = new(big.Int)
+			}
+		}
+		amount := formatTokenAmount(values[0].(common.Address), deposited)
+		return fmt.Sprintf("Deposit %s for beneficiary %s, claimable after %s",
+			amount, values[1].(common.Address).Hex(), formatTimestamp(values[3].(*big.Int)))
+	case "claim(uint256)":
+		return fmt.Sprintf("Claim inheritance #%s", values[0].(*big.Int))
+	case "reclaim(uint256)":
+		return fmt.Sprintf("Reclaim inheritance #%s before its deadline", values[0].(*big.Int))
+	case "extendDeadline(uint256,uint256)":
+		return fmt.Sprintf("Extend the deadline of inheritance #%s to %s", values[0].(*big.Int), formatTimestamp(values[1].(*big.Int)))
+	case "transferFeeCollector(address)":
+		return fmt.Sprintf("Propose %s as the new fee collector", values[0].(common.Address).Hex())
+	case "acceptFeeCollector()":
+		return "Accept the fee collector role"
+	case "approve(address,uint256)":
+		return fmt.Sprintf("Approve %s to spend %s base units of token %s",
+			values[0].(common.Address).Hex(), values[1].(*big.Int), to.Hex())
+	}
+
+	args := make([]string, len(values))
+	for i, v := range values {
+		args[i] = formatArg(v)
+	}
+	summary := fmt.Sprintf("Call %s(%s) on %s", method.Name, strings.Join(args, ", "), to.Hex())
+	if value != nil && value.Sign() > 0 {
+		summary += fmt.Sprintf(" with %s ETH", types.FormatEther(value))
+	}
+	return summary
+}
+
+// formatArg formats a decoded ABI value for display
+func formatArg(v interface{}) string {
+	switch v := v.(type) {
+	case common.Address:
+		return v.Hex()
+	case *big.Int:
+		return v.String()
+	case []byte:
+		return fmt.Sprintf("0x%x", v)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// formatTimestamp formats a Unix timestamp argument with its UTC time
+func formatTimestamp(ts *big.Int) string {
+	if !ts.IsInt64() {
+		return ts.String()
+	}
+	return fmt.Sprintf("%s (%s)", ts, time.Unix(ts.Int64(), 0).UTC().Format("2006-01-02 15:04 MST"))
+}
+
+// ABIFragment returns the ABI entry of the function a call's selector names,
+// from the CryptoHeir or ERC20 ABI, as a one-entry JSON ABI. It lets a
+// signer without the contract artifact decode the call.
+func ABIFragment(data []byte) (json.RawMessage, error) {
+	if contractABI.Methods == nil {
+		return nil, fmt.Errorf("contract not initialized, call Initialize() first")
+	}
+	if len(data) < 4 {
+		return nil, fmt.Errorf("calldata too short for a function selector")
+	}
+
+	var artifact ContractArtifact
+	if err := json.Unmarshal(contractArtifactJSON, &artifact); err != nil {
+		return nil, fmt.Errorf("failed to parse contract artifact: %w", err)
+	}
+	for _, source := range []json.RawMessage{artifact.ABI, json.RawMessage(erc20ABIJSON)} {
+		var entries []json.RawMessage
+		if err := json.Unmarshal(source, &entries); err != nil {
+			return nil, fmt.Errorf("failed to parse ABI: %w", err)
+		}
+		for _, entry := range entries {
+			fragment := json.RawMessage("[" + string(entry) + "]")
+			parsed, err := abi.JSON(bytes.NewReader(fragment))
+			if err != nil {
+				continue
+			}
+			if _, err := parsed.MethodById(data[:4]); err == nil {
+				var compact bytes.Buffer
+				if err := json.Compact(&compact, fragment); err != nil {
+					return nil, err
+				}
+				return compact.Bytes(), nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unknown function selector 0x%x", data[:4])
+}
+
+// DecodeCallWithFragment decodes a call with the ABI fragment of a
+// self-contained transaction file. The fragment is not trusted: its
+// selector must match the calldata and the arguments must decode exactly,
+// but its parameter names come from the file.
+func DecodeCallWithFragment(fragment json.RawMessage, to *common.Address, data []byte, value *big.Int) (*types.DecodedCall, error) {
+	if to == nil || len(data) < 4 {
+		return nil, fmt.Errorf("not a function call")
+	}
+	parsed, err := abi.JSON(bytes.NewReader(fragment))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ABI fragment: %w", err)
+	}
+	method, err := parsed.MethodById(data[:4])
+	if err != nil {
+		return nil, fmt.Errorf("the ABI fragment does not match selector 0x%x", data[:4])
+	}
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s arguments: %w", method.Sig, err)
+	}
+	if packed, err := method.Inputs.Pack(values...); err != nil || !bytes.Equal(packed, data[4:]) {
+		return nil, fmt.Errorf("the calldata does not re-encode identically with %s", method.Sig)
+	}
+
+	call := &types.DecodedCall{Function: method.Sig}
+	for i, input := range method.Inputs {
+		call.Args = append(call.Args, types.DecodedArg{
+			Name:  input.Name,
+			Type:  input.Type.String(),
+			Value: formatArg(values[i]),
+		})
+	}
+	call.Summary = summarize(method, values, *to, value)
+	return call, nil
+}
@@ -0,0 +1,83 @@
+package contract
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ENSRegistryAddress is the ENS registry, deployed at the same address on
+// mainnet and the public testnets
+var ENSRegistryAddress = common.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e")
+
+// ensABIJSON covers the registry resolver lookup and the resolver addr record
+const ensABIJSON = `[
+	{"type":"function","name":"resolver","stateMutability":"view","inputs":[{"name":"node","type":"bytes32"}],"outputs":[{"name":"","type":"address"}]},
+	{"type":"function","name":"addr","stateMutability":"view","inputs":[{"name":"node","type":"bytes32"}],"outputs":[{"name":"","type":"address"}]}
+]`
+
+var ensABI abi.ABI
+
+func init() {
+	parsed, err := abi.JSON(strings.NewReader(ensABIJSON))
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded ENS ABI: %v", err))
+	}
+	ensABI = parsed
+}
+
+// NormalizeENSName lowercases an ENS name and rejects characters outside
+// a-z, 0-9 and '-', so visually confusable Unicode names are never resolved
+func NormalizeENSName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	labels := strings.Split(name, ".")
+	if len(labels) < 2 {
+		return "", fmt.Errorf("invalid ENS name %q", name)
+	}
+	for _, label := range labels {
+		if label == "" {
+			return "", fmt.Errorf("invalid ENS name %q: empty label", name)
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-') {
+				return "", fmt.Errorf("invalid ENS name %q: only a-z, 0-9 and '-' are supported", name)
+			}
+		}
+	}
+	return name, nil
+}
+
+// Namehash computes the ENS node of a normalized name (EIP-137)
+func Namehash(name string) common.Hash {
+	var node common.Hash
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		labelHash := crypto.Keccak256([]byte(labels[i]))
+		node = crypto.Keccak256Hash(node.Bytes(), labelHash)
+	}
+	return node
+}
+
+// EncodeENSCall encodes a registry resolver(node) or resolver addr(node) call
+func EncodeENSCall(method string, node common.Hash) ([]byte, error) {
+	data, err := ensABI.Pack(method, node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ENS %s: %w", method, err)
+	}
+	return data, nil
+}
+
+// DecodeENSAddress decodes the address returned by resolver() or addr()
+func DecodeENSAddress(method string, data []byte) (common.Address, error) {
+	values, err := ensABI.Unpack(method, data)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to decode ENS %s result: %w", method, err)
+	}
+	return values[0].(common.Address), nil
+}
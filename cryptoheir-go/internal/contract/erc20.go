@@ -0,0 +1,81 @@
+package contract
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// erc20ABIJSON is the subset of the ERC20 interface used by the CLI
+const erc20ABIJSON = `[
+	{"type":"function","name":"approve","stateMutability":"nonpayable","inputs":[{"name":"spender","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"allowance","stateMutability":"view","inputs":[{"name":"owner","type":"address"},{"name":"spender","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"account","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"decimals","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint8"}]},
+	{"type":"function","name":"symbol","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+	{"type":"event","name":"Transfer","anonymous":false,"inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]},
+	{"type":"event","name":"Approval","anonymous":false,"inputs":[{"name":"owner","type":"address","indexed":true},{"name":"spender","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]}
+]`
+
+var erc20ABI abi.ABI
+
+func init() {
+	parsed, err := abi.JSON(strings.NewReader(erc20ABIJSON))
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded ERC20 ABI: %v", err))
+	}
+	erc20ABI = parsed
+}
+
+// EncodeApprove encodes the ERC20 approve function call
+// approve(address spender, uint256 amount)
+func EncodeApprove(spender common.Address, amount *big.Int) ([]byte, error) {
+	data, err := erc20ABI.Pack("approve", spender, amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode approve: %w", err)
+	}
+
+	return data, nil
+}
+
+// DecodeApprove decodes approve calldata, failing if the calldata is not an
+// approve call
+func DecodeApprove(data []byte) (common.Address, *big.Int, error) {
+	method := erc20ABI.Methods["approve"]
+	if len(data) < 4 || !bytes.Equal(data[:4], method.ID) {
+		return common.Address{}, nil, fmt.Errorf("calldata is not an approve call")
+	}
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("failed to decode approve: %w", err)
+	}
+
+	return values[0].(common.Address), values[1].(*big.Int), nil
+}
+
+// EncodeERC20Call encodes a call to one of the supported ERC20 functions
+func EncodeERC20Call(method string, args ...interface{}) ([]byte, error) {
+	data, err := erc20ABI.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", method, err)
+	}
+
+	return data, nil
+}
+
+// DecodeERC20Result decodes the single return value of an ERC20 call
+func DecodeERC20Result(method string, data []byte) (interface{}, error) {
+	values, err := erc20ABI.Unpack(method, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s result: %w", method, err)
+	}
+	if len(values) != 1 {
+		return nil, fmt.Errorf("unexpected %s result: %d values", method, len(values))
+	}
+
+	return values[0], nil
+}
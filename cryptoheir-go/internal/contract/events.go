@@ -0,0 +1,135 @@
+package contract
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// DecodeLogs decodes every log of a receipt against the CryptoHeir and ERC20
+// ABIs, in log order. A single transaction may emit several events, e.g. a
+// deposit emits FeeCollected and InheritanceCreated, plus a Transfer for a
+// token deposit. Logs that match no known event are kept undecoded.
+func DecodeLogs(logs []*coretypes.Log) []types.DecodedEvent {
+	events := make([]types.DecodedEvent, 0, len(logs))
+	for _, l := range logs {
+		event, err := decodeLog(l)
+		if err != nil {
+			event = &types.DecodedEvent{
+				Topics:  l.Topics,
+				Data:    fmt.Sprintf("0x%x", l.Data),
+				Summary: fmt.Sprintf("Unknown event from %s", l.Address.Hex()),
+			}
+		}
+		event.LogIndex = l.Index
+		event.Address = l.Address
+		events = append(events, *event)
+	}
+	return events
+}
+
+// decodeLog decodes a single log against the known event ABIs
+func decodeLog(l *coretypes.Log) (*types.DecodedEvent, error) {
+	if len(l.Topics) == 0 {
+		return nil, fmt.Errorf("anonymous event")
+	}
+
+	event, err := contractABI.EventByID(l.Topics[0])
+	if err != nil {
+		if event, err = erc20ABI.EventByID(l.Topics[0]); err != nil {
+			return nil, fmt.Errorf("unknown event topic %s", l.Topics[0].Hex())
+		}
+	}
+
+	values := make(map[string]interface{}, len(event.Inputs))
+	var indexed abi.Arguments
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+	// The same signature may be emitted with a different set of indexed
+	// arguments (ERC721 Transfer), which does not decode as this event
+	if len(l.Topics) != len(indexed)+1 {
+		return nil, fmt.Errorf("%s: expected %d topics, got %d", event.Sig, len(indexed)+1, len(l.Topics))
+	}
+	if err := abi.ParseTopicsIntoMap(values, indexed, l.Topics[1:]); err != nil {
+		return nil, fmt.Errorf("failed to decode %s topics: %w", event.Sig, err)
+	}
+	if err := event.Inputs.NonIndexed().UnpackIntoMap(values, l.Data); err != nil {
+		return nil, fmt.Errorf("failed to decode %s data: %w", event.Sig, err)
+	}
+
+	decoded := &types.DecodedEvent{Name: event.Name, Signature: event.Sig}
+	for _, input := range event.Inputs {
+		decoded.Args = append(decoded.Args, types.DecodedArg{
+			Name:  input.Name,
+			Type:  input.Type.String(),
+			Value: formatArg(values[input.Name]),
+		})
+	}
+	decoded.Summary = summarizeEvent(event, values, l.Address)
+	return decoded, nil
+}
+
+// summarizeEvent describes a decoded event in plain words
+func summarizeEvent(event *abi.Event, values map[string]interface{}, emitter common.Address) string {
+	addr := func(name string) string {
+		a, _ := values[name].(common.Address)
+		return a.Hex()
+	}
+	num := func(name string) *big.Int {
+		n, _ := values[name].(*big.Int)
+		if n == nil {
+			n = new(big.Int)
+		}
+		return n
+	}
+	amount := func(tokenArg, amountArg string) string {
+		token, _ := values[tokenArg].(common.Address)
+		return formatTokenAmount(token, num(amountArg))
+	}
+
+	switch event.Sig {
+	case "InheritanceCreated(uint256,address,address,address,uint256,uint256)":
+		return fmt.Sprintf("Inheritance #%s created: %s for beneficiary %s, claimable after %s",
+			num("inheritanceId"), amount("token", "amount"), addr("beneficiary"), formatTimestamp(num("deadline")))
+	case "FeeCollected(address,address,uint256,string)":
+		return fmt.Sprintf("Fee of %s collected by %s (%v)", amount("token", "amount"), addr("collector"), values["feeType"])
+	case "InheritanceClaimed(uint256,address,address,uint256)":
+		return fmt.Sprintf("Inheritance #%s claimed: %s to %s", num("inheritanceId"), amount("token", "amount"), addr("beneficiary"))
+	case "InheritanceReclaimed(uint256,address,address,uint256)":
+		return fmt.Sprintf("Inheritance #%s reclaimed: %s to %s", num("inheritanceId"), amount("token", "amount"), addr("depositor"))
+	case "DeadlineExtended(uint256,uint256,uint256)":
+		return fmt.Sprintf("Deadline of inheritance #%s extended from %s to %s",
+			num("inheritanceId"), formatTimestamp(num("oldDeadline")), formatTimestamp(num("newDeadline")))
+	case "FeeCollectorTransferStarted(address,address)":
+		return fmt.Sprintf("Fee collector transfer proposed from %s to %s", addr("currentCollector"), addr("pendingCollector"))
+	case "FeeCollectorTransferred(address,address)":
+		return fmt.Sprintf("Fee collector changed from %s to %s", addr("previousCollector"), addr("newCollector"))
+	case "Transfer(address,address,uint256)":
+		return fmt.Sprintf("Transfer of %s base units of token %s from %s to %s", num("value"), emitter.Hex(), addr("from"), addr("to"))
+	case "Approval(address,address,uint256)":
+		return fmt.Sprintf("Approval for %s to spend %s base units of token %s owned by %s", addr("spender"), num("value"), emitter.Hex(), addr("owner"))
+	}
+
+	args := make([]string, len(event.Inputs))
+	for i, input := range event.Inputs {
+		args[i] = formatArg(values[input.Name])
+	}
+	return fmt.Sprintf("%s(%s)", event.Name, strings.Join(args, ", "))
+}
+
+// formatTokenAmount formats an amount of the given token, where the zero
+// address stands for native ETH
+func formatTokenAmount(token common.Address, amount *big.Int) string {
+	if token == (common.Address{}) {
+		return types.FormatEther(amount) + " ETH"
+	}
+	return fmt.Sprintf("%s base units of token %s", amount, token.Hex())
+}
@@ -0,0 +1,50 @@
+package contract
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// GasPriceOracleAddress is the OP Stack predeploy that prices the L1 data
+// portion of L2 transactions (Optimism, Base and other OP Stack chains)
+var GasPriceOracleAddress = common.HexToAddress("0x420000000000000000000000000000000000000F")
+
+const gasPriceOracleABIJSON = `[
+	{"type":"function","name":"getL1Fee","stateMutability":"view","inputs":[{"name":"_data","type":"bytes"}],"outputs":[{"name":"","type":"uint256"}]}
+]`
+
+var gasPriceOracleABI abi.ABI
+
+func init() {
+	parsed, err := abi.JSON(strings.NewReader(gasPriceOracleABIJSON))
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded gas price oracle ABI: %v", err))
+	}
+	gasPriceOracleABI = parsed
+}
+
+// EncodeGetL1Fee encodes getL1Fee(bytes) for an unsigned, EIP-2718 encoded transaction
+func EncodeGetL1Fee(unsignedTx []byte) ([]byte, error) {
+	data, err := gasPriceOracleABI.Pack("getL1Fee", unsignedTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode getL1Fee: %w", err)
+	}
+	return data, nil
+}
+
+// DecodeGetL1Fee decodes the L1 data fee in wei returned by getL1Fee
+func DecodeGetL1Fee(result []byte) (*big.Int, error) {
+	values, err := gasPriceOracleABI.Unpack("getL1Fee", result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode getL1Fee result: %w", err)
+	}
+	fee, ok := values[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("unexpected getL1Fee result type %T", values[0])
+	}
+	return fee, nil
+}
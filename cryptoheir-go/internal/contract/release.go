@@ -0,0 +1,110 @@
+package contract
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Release holds the bytecode hashes of an official CryptoHeir release
+type Release struct {
+	// BytecodeHash is the keccak256 of the creation bytecode (the initcode a
+	// deployment sends)
+	BytecodeHash common.Hash
+	// RuntimeHash is the keccak256 of the code stored on chain after
+	// deployment. The contract has no immutables, so it is the same for
+	// every deployment.
+	RuntimeHash common.Hash
+}
+
+// releases lists the official releases by version. The table is compiled in
+// so that it is checked offline and cannot be swapped together with the
+// artifact it vouches for.
+//
+// No version has been tagged yet, so the table is empty. Each entry is added
+// when a release is tagged, with the hashes of the artifact built from the
+// tagged sources (foundry/out/CryptoHeir.sol/CryptoHeir.json: keccak256 of
+// bytecode.object and of deployedBytecode.object), and the same hashes are
+// published in the release notes so they can be checked independently.
+var releases = map[string]Release{}
+
+// LookupRelease returns the official release with the given version. A
+// missing "v" prefix is accepted.
+func LookupRelease(version string) (Release, error) {
+	v := strings.TrimSpace(version)
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	r, ok := releases[v]
+	if !ok {
+		if len(releases) == 0 {
+			return Release{}, fmt.Errorf("unknown CryptoHeir release %q: this build records no official releases", version)
+		}
+		return Release{}, fmt.Errorf("unknown CryptoHeir release %q (known: %s)", version, strings.Join(ReleaseVersions(), ", "))
+	}
+	return r, nil
+}
+
+// ReleaseVersions returns the known release versions in sorted order
+func ReleaseVersions() []string {
+	versions := make([]string, 0, len(releases))
+	for v := range releases {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// MatchingRelease returns the version of the official release whose creation
+// bytecode is the embedded artifact's, or "" if there is none
+func MatchingRelease() string {
+	hash := crypto.Keccak256Hash(contractBytecode)
+	for _, v := range ReleaseVersions() {
+		if releases[v].BytecodeHash == hash {
+			return v
+		}
+	}
+	return ""
+}
+
+// VerifyArtifactRelease checks that the embedded artifact's creation and
+// runtime bytecode are exactly those of an official release
+func VerifyArtifactRelease(version string) error {
+	r, err := LookupRelease(version)
+	if err != nil {
+		return err
+	}
+	hash, err := BytecodeHash()
+	if err != nil {
+		return err
+	}
+	if hash != r.BytecodeHash {
+		return fmt.Errorf("embedded contract bytecode %s does not match release %s (%s); the artifact is modified or from another version",
+			hash.Hex(), version, r.BytecodeHash.Hex())
+	}
+	if runtime := crypto.Keccak256Hash(contractRuntime); runtime != r.RuntimeHash {
+		return fmt.Errorf("embedded contract runtime bytecode %s does not match release %s (%s)",
+			runtime.Hex(), version, r.RuntimeHash.Hex())
+	}
+	return nil
+}
+
+// VerifyRuntimeRelease checks that code read from chain is exactly the
+// runtime bytecode of an official release
+func VerifyRuntimeRelease(version string, code []byte) error {
+	r, err := LookupRelease(version)
+	if err != nil {
+		return err
+	}
+	if len(code) == 0 {
+		return fmt.Errorf("no contract code at the address to compare against release %s", version)
+	}
+	if hash := crypto.Keccak256Hash(code); hash != r.RuntimeHash {
+		return fmt.Errorf("on-chain code %s does not match release %s (%s); the contract is not an official CryptoHeir %s deployment",
+			hash.Hex(), version, r.RuntimeHash.Hex(), version)
+	}
+	return nil
+}
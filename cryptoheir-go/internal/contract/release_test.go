@@ -0,0 +1,64 @@
+package contract
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestNoReleasesRecorded(t *testing.T) {
+	if len(releases) != 0 {
+		t.Skip("releases are recorded")
+	}
+	if _, err := LookupRelease("v0.1.0"); err == nil || !strings.Contains(err.Error(), "no official releases") {
+		t.Errorf("LookupRelease() error = %v, want an error saying no releases are recorded", err)
+	}
+	if err := Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	if v := MatchingRelease(); v != "" {
+		t.Errorf("MatchingRelease() = %q, want none", v)
+	}
+}
+
+func TestVerifyRelease(t *testing.T) {
+	if err := Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	saved := releases
+	t.Cleanup(func() { releases = saved })
+	releases = map[string]Release{
+		"v9.9.9": {
+			BytecodeHash: crypto.Keccak256Hash(contractBytecode),
+			RuntimeHash:  crypto.Keccak256Hash(contractRuntime),
+		},
+	}
+
+	if err := VerifyArtifactRelease("9.9.9"); err != nil {
+		t.Errorf("VerifyArtifactRelease() error = %v", err)
+	}
+	if v := MatchingRelease(); v != "v9.9.9" {
+		t.Errorf("MatchingRelease() = %q, want v9.9.9", v)
+	}
+	if err := VerifyRuntimeRelease("v9.9.9", contractRuntime); err != nil {
+		t.Errorf("VerifyRuntimeRelease() error = %v", err)
+	}
+
+	tampered := append([]byte{}, contractRuntime...)
+	tampered[len(tampered)-1] ^= 0xff
+	if err := VerifyRuntimeRelease("v9.9.9", tampered); err == nil || !strings.Contains(err.Error(), "does not match release") {
+		t.Errorf("VerifyRuntimeRelease(tampered) error = %v, want a mismatch", err)
+	}
+	if err := VerifyRuntimeRelease("v9.9.9", nil); err == nil || !strings.Contains(err.Error(), "no contract code") {
+		t.Errorf("VerifyRuntimeRelease(no code) error = %v, want a missing-code error", err)
+	}
+	if _, err := LookupRelease("v1.2.3"); err == nil || !strings.Contains(err.Error(), "known: v9.9.9") {
+		t.Errorf("LookupRelease(unknown) error = %v, want the known versions listed", err)
+	}
+
+	releases["v9.9.9"] = Release{BytecodeHash: crypto.Keccak256Hash([]byte("other")), RuntimeHash: releases["v9.9.9"].RuntimeHash}
+	if err := VerifyArtifactRelease("v9.9.9"); err == nil || !strings.Contains(err.Error(), "does not match release") {
+		t.Errorf("VerifyArtifactRelease(other bytecode) error = %v, want a mismatch", err)
+	}
+}
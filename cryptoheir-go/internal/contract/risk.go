@@ -0,0 +1,98 @@
+package contract
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Severity ranks how much scrutiny a transaction deserves during review
+type Severity string
+
+const (
+	SeverityRoutine   Severity = "routine"
+	SeverityCaution   Severity = "caution"
+	SeverityAdmin     Severity = "admin"
+	SeverityDangerous Severity = "dangerous"
+)
+
+// Risk tags a transaction with a severity and the reason for it
+type Risk struct {
+	Severity Severity
+	Reason   string
+}
+
+// operationRisks tags functions by canonical signature. Besides the
+// CryptoHeir admin functions, it covers common selectors of other contracts
+// that destroy code, swap implementations or hand over control.
+var operationRisks = map[string]Risk{
+	"deposit(address,address,uint256,uint256)": {SeverityRoutine, "creates an inheritance"},
+	"claim(uint256)":                  {SeverityRoutine, "claims an inheritance"},
+	"reclaim(uint256)":                {SeverityCaution, "withdraws an inheritance before its deadline"},
+	"extendDeadline(uint256,uint256)": {SeverityRoutine, "extends an inheritance deadline"},
+	"transferFeeCollector(address)":   {SeverityAdmin, "changes fee collector (proposes a new one)"},
+	"acceptFeeCollector()":            {SeverityAdmin, "changes fee collector (accepts the role)"},
+	"approve(address,uint256)":        {SeverityCaution, "grants a token allowance"},
+
+	"kill()":                                {SeverityDangerous, "may self-destruct the target contract"},
+	"destroy()":                             {SeverityDangerous, "may self-destruct the target contract"},
+	"selfDestruct()":                        {SeverityDangerous, "may self-destruct the target contract"},
+	"upgradeTo(address)":                    {SeverityDangerous, "replaces the target's implementation (delegatecall target)"},
+	"upgradeToAndCall(address,bytes)":       {SeverityDangerous, "replaces the target's implementation and delegatecalls it"},
+	"transferOwnership(address)":            {SeverityAdmin, "transfers ownership of the target contract"},
+	"renounceOwnership()":                   {SeverityDangerous, "permanently renounces ownership of the target contract"},
+	"setApprovalForAll(address,bool)":       {SeverityCaution, "grants or revokes control of all tokens of a collection"},
+	"execute(address,uint256,bytes)":        {SeverityDangerous, "makes the target execute an arbitrary call"},
+	"multicall(bytes[])":                    {SeverityCaution, "batches several calls; each inner call needs review"},
+	"delegatecall(address,bytes)":           {SeverityDangerous, "delegatecalls arbitrary code in the target's context"},
+	"changeAdmin(address)":                  {SeverityAdmin, "changes the proxy admin of the target contract"},
+	"setImplementation(address)":            {SeverityDangerous, "replaces the target's implementation (delegatecall target)"},
+	"transferFrom(address,address,uint256)": {SeverityCaution, "moves tokens using an allowance"},
+}
+
+// riskBySelector indexes operationRisks by 4-byte selector
+var riskBySelector = func() map[[4]byte]Risk {
+	m := make(map[[4]byte]Risk, len(operationRisks))
+	for sig, risk := range operationRisks {
+		var selector [4]byte
+		copy(selector[:], crypto.Keccak256([]byte(sig))[:4])
+		m[selector] = risk
+	}
+	return m
+}()
+
+// Tag formats the risk for display, e.g. "⚠ admin operation: changes fee
+// collector". Routine operations are tagged without the ⚠ marker, e.g.
+// "routine operation: creates an inheritance".
+func (r Risk) Tag() string {
+	if r.Severity == SeverityRoutine {
+		return fmt.Sprintf("%s operation: %s", r.Severity, r.Reason)
+	}
+	return fmt.Sprintf("⚠ %s operation: %s", r.Severity, r.Reason)
+}
+
+// ClassifyCall tags a transaction by its calldata. This is a selector match
+// only; the target's code is not analyzed, so unknown selectors are flagged
+// for caution. deploy marks a contract creation and hasDelegation an EIP-7702
+// authorization list.
+func ClassifyCall(deploy bool, data []byte, hasDelegation bool) Risk {
+	if hasDelegation {
+		return Risk{SeverityDangerous, "delegates the signer account to contract code (EIP-7702)"}
+	}
+	if deploy {
+		return Risk{SeverityCaution, "deploys a contract"}
+	}
+	if len(data) == 0 {
+		return Risk{SeverityRoutine, "plain transfer"}
+	}
+	if len(data) < 4 {
+		return Risk{SeverityCaution, "malformed calldata"}
+	}
+
+	var selector [4]byte
+	copy(selector[:], data[:4])
+	if risk, ok := riskBySelector[selector]; ok {
+		return risk
+	}
+	return Risk{SeverityCaution, "unknown function; calldata cannot be decoded"}
+}
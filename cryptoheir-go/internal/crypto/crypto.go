@@ -1,6 +1,7 @@
 package crypto
 
 import (
+	"bytes"
 	"crypto/ecdsa"
 	"fmt"
 	"math/big"
@@ -11,6 +12,7 @@ import (
 	coretypes "github.com/ethereum/go-ethereum/core/types"
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/holiman/uint256"
 )
 
 // SignTransaction signs a transaction with a private key
@@ -36,23 +38,9 @@ func SignTransaction(txParams *types.TxParams, privateKeyHex string) (*types.Sig
 	}
 
 	// Sign based on transaction type
-	var signedTxBytes []byte
-	var txHash common.Hash
-
-	if txParams.Transaction.TxType == 2 {
-		// EIP-1559 transaction
-		signedTxBytes, txHash, err = signEIP1559(&txParams.Transaction, privateKey)
-		if err != nil {
-			return nil, fmt.Errorf("failed to sign EIP-1559 transaction: %w", err)
-		}
-	} else if txParams.Transaction.TxType == 0 {
-		// Legacy transaction
-		signedTxBytes, txHash, err = signLegacy(&txParams.Transaction, privateKey)
-		if err != nil {
-			return nil, fmt.Errorf("failed to sign legacy transaction: %w", err)
-		}
-	} else {
-		return nil, fmt.Errorf("unsupported transaction type: %d", txParams.Transaction.TxType)
+	signedTxBytes, txHash, err := signTxData(&txParams.Transaction, privateKey)
+	if err != nil {
+		return nil, err
 	}
 
 	// Predict contract address for deployments
@@ -67,6 +55,7 @@ func SignTransaction(txParams *types.TxParams, privateKeyHex string) (*types.Sig
 		SignedTransaction:        signedTxBytes,
 		TxHash:                   txHash,
 		Mode:                     txParams.Mode,
+		FunctionName:             txParams.FunctionName,
 		From:                     txParams.Transaction.From,
 		PredictedContractAddress: predictedAddr,
 		Metadata:                 txParams.Metadata,
@@ -75,78 +64,206 @@ func SignTransaction(txParams *types.TxParams, privateKeyHex string) (*types.Sig
 	return signedTx, nil
 }
 
-// signEIP1559 signs an EIP-1559 transaction
-func signEIP1559(txData *types.TransactionData, privateKey *ecdsa.PrivateKey) ([]byte, common.Hash, error) {
-	// Validate EIP-1559 fields
-	if txData.MaxFeePerGas == nil || txData.MaxPriorityFeePerGas == nil {
-		return nil, common.Hash{}, fmt.Errorf("EIP-1559 transaction requires max_fee_per_gas and max_priority_fee_per_gas")
+// signTxData signs the transaction with the signer matching its type
+func signTxData(txData *types.TransactionData, privateKey *ecdsa.PrivateKey) ([]byte, common.Hash, error) {
+	switch txData.TxType {
+	case 2:
+		// EIP-1559 transaction
+		signedTxBytes, txHash, err := signEIP1559(txData, privateKey)
+		if err != nil {
+			return nil, common.Hash{}, fmt.Errorf("failed to sign EIP-1559 transaction: %w", err)
+		}
+		return signedTxBytes, txHash, nil
+	case 4:
+		// EIP-7702 set-code transaction
+		signedTxBytes, txHash, err := signSetCode(txData, privateKey)
+		if err != nil {
+			return nil, common.Hash{}, fmt.Errorf("failed to sign EIP-7702 transaction: %w", err)
+		}
+		return signedTxBytes, txHash, nil
+	case 0:
+		// Legacy transaction
+		signedTxBytes, txHash, err := signLegacy(txData, privateKey)
+		if err != nil {
+			return nil, common.Hash{}, fmt.Errorf("failed to sign legacy transaction: %w", err)
+		}
+		return signedTxBytes, txHash, nil
 	}
 
+	return nil, common.Hash{}, fmt.Errorf("unsupported transaction type: %d", txData.TxType)
+}
+
+// BuildTransaction constructs the unsigned transaction described by txData.
+// EIP-7702 authorizations are included as given (signed or not).
+func BuildTransaction(txData *types.TransactionData) (*coretypes.Transaction, error) {
 	// Build transaction value (default to 0)
 	value := big.NewInt(0)
 	if txData.Value != nil {
 		value = txData.Value.ToBigInt()
 	}
 
-	// Create EIP-1559 transaction
-	tx := coretypes.NewTx(&coretypes.DynamicFeeTx{
-		ChainID:   big.NewInt(int64(txData.ChainID)),
-		Nonce:     txData.Nonce,
-		GasTipCap: txData.MaxPriorityFeePerGas.ToBigInt(),
-		GasFeeCap: txData.MaxFeePerGas.ToBigInt(),
-		Gas:       txData.GasLimit.ToBigInt().Uint64(),
-		To:        txData.To,
-		Value:     value,
-		Data:      txData.Data,
-	})
-
-	// Sign the transaction
-	signer := coretypes.NewLondonSigner(big.NewInt(int64(txData.ChainID)))
-	signedTx, err := coretypes.SignTx(tx, signer, privateKey)
+	chainID, gas, err := checkedChainAndGas(txData)
 	if err != nil {
-		return nil, common.Hash{}, fmt.Errorf("failed to sign transaction: %w", err)
+		return nil, err
 	}
 
-	// Encode to binary (EIP-2718 format)
-	signedTxBytes, err := signedTx.MarshalBinary()
+	switch txData.TxType {
+	case 0:
+		// Legacy transaction
+		if txData.GasPrice == nil {
+			return nil, fmt.Errorf("legacy transaction requires gas_price")
+		}
+		return coretypes.NewTx(&coretypes.LegacyTx{
+			Nonce:    txData.Nonce,
+			GasPrice: txData.GasPrice.ToBigInt(),
+			Gas:      gas,
+			To:       txData.To,
+			Value:    value,
+			Data:     txData.Data,
+		}), nil
+
+	case 2:
+		// EIP-1559 transaction
+		if txData.MaxFeePerGas == nil || txData.MaxPriorityFeePerGas == nil {
+			return nil, fmt.Errorf("EIP-1559 transaction requires max_fee_per_gas and max_priority_fee_per_gas")
+		}
+		return coretypes.NewTx(&coretypes.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     txData.Nonce,
+			GasTipCap: txData.MaxPriorityFeePerGas.ToBigInt(),
+			GasFeeCap: txData.MaxFeePerGas.ToBigInt(),
+			Gas:       gas,
+			To:        txData.To,
+			Value:     value,
+			Data:      txData.Data,
+		}), nil
+
+	case 4:
+		// EIP-7702 set-code transaction
+		if txData.MaxFeePerGas == nil || txData.MaxPriorityFeePerGas == nil {
+			return nil, fmt.Errorf("EIP-7702 transaction requires max_fee_per_gas and max_priority_fee_per_gas")
+		}
+		if txData.To == nil {
+			return nil, fmt.Errorf("EIP-7702 transaction cannot be a contract deployment")
+		}
+		if len(txData.AuthorizationList) == 0 {
+			return nil, fmt.Errorf("EIP-7702 transaction requires at least one authorization")
+		}
+
+		authList := make([]coretypes.SetCodeAuthorization, 0, len(txData.AuthorizationList))
+		for _, a := range txData.AuthorizationList {
+			auth := coretypes.SetCodeAuthorization{
+				ChainID: *uint256.NewInt(a.ChainID),
+				Address: a.Address,
+				Nonce:   a.Nonce,
+			}
+			if a.IsSigned() {
+				r, err := toUint256("authorization r", a.R.ToBigInt())
+				if err != nil {
+					return nil, err
+				}
+				sv, err := toUint256("authorization s", a.S.ToBigInt())
+				if err != nil {
+					return nil, err
+				}
+				auth.V = a.YParity
+				auth.R = *r
+				auth.S = *sv
+			}
+			authList = append(authList, auth)
+		}
+
+		tipCap, err := toUint256("max_priority_fee_per_gas", txData.MaxPriorityFeePerGas.ToBigInt())
+		if err != nil {
+			return nil, err
+		}
+		feeCap, err := toUint256("max_fee_per_gas", txData.MaxFeePerGas.ToBigInt())
+		if err != nil {
+			return nil, err
+		}
+		value256, err := toUint256("value", value)
+		if err != nil {
+			return nil, err
+		}
+
+		return coretypes.NewTx(&coretypes.SetCodeTx{
+			ChainID:   uint256.NewInt(txData.ChainID),
+			Nonce:     txData.Nonce,
+			GasTipCap: tipCap,
+			GasFeeCap: feeCap,
+			Gas:       gas,
+			To:        *txData.To,
+			Value:     value256,
+			Data:      txData.Data,
+			AuthList:  authList,
+		}), nil
+	}
+
+	return nil, fmt.Errorf("unsupported transaction type: %d", txData.TxType)
+}
+
+// signEIP1559 signs an EIP-1559 transaction
+func signEIP1559(txData *types.TransactionData, privateKey *ecdsa.PrivateKey) ([]byte, common.Hash, error) {
+	tx, err := BuildTransaction(txData)
 	if err != nil {
-		return nil, common.Hash{}, fmt.Errorf("failed to encode signed transaction: %w", err)
+		return nil, common.Hash{}, err
 	}
 
-	return signedTxBytes, signedTx.Hash(), nil
+	chainID := new(big.Int).SetUint64(txData.ChainID)
+	return signAndEncode(tx, coretypes.NewLondonSigner(chainID), privateKey)
 }
 
-// signLegacy signs a legacy (pre-EIP-1559) transaction
-func signLegacy(txData *types.TransactionData, privateKey *ecdsa.PrivateKey) ([]byte, common.Hash, error) {
-	// Validate legacy fields
-	if txData.GasPrice == nil {
-		return nil, common.Hash{}, fmt.Errorf("legacy transaction requires gas_price")
+// signSetCode signs an EIP-7702 (type 4) transaction, signing any unsigned
+// authorizations with the same key first
+func signSetCode(txData *types.TransactionData, privateKey *ecdsa.PrivateKey) ([]byte, common.Hash, error) {
+	// Sign authorizations on a copy, leaving the reviewed parameters untouched
+	signed := *txData
+	signed.AuthorizationList = make([]types.Authorization, len(txData.AuthorizationList))
+	for i, a := range txData.AuthorizationList {
+		if !a.IsSigned() {
+			auth, err := coretypes.SignSetCode(privateKey, coretypes.SetCodeAuthorization{
+				ChainID: *uint256.NewInt(a.ChainID),
+				Address: a.Address,
+				Nonce:   a.Nonce,
+			})
+			if err != nil {
+				return nil, common.Hash{}, fmt.Errorf("failed to sign authorization %d: %w", i, err)
+			}
+			a.YParity = auth.V
+			a.R = types.NewBigInt(auth.R.ToBig())
+			a.S = types.NewBigInt(auth.S.ToBig())
+		}
+		signed.AuthorizationList[i] = a
 	}
 
-	// Build transaction value (default to 0)
-	value := big.NewInt(0)
-	if txData.Value != nil {
-		value = txData.Value.ToBigInt()
+	tx, err := BuildTransaction(&signed)
+	if err != nil {
+		return nil, common.Hash{}, err
 	}
 
-	// Create legacy transaction
-	tx := coretypes.NewTx(&coretypes.LegacyTx{
-		Nonce:    txData.Nonce,
-		GasPrice: txData.GasPrice.ToBigInt(),
-		Gas:      txData.GasLimit.ToBigInt().Uint64(),
-		To:       txData.To,
-		Value:    value,
-		Data:     txData.Data,
-	})
+	chainID := new(big.Int).SetUint64(txData.ChainID)
+	return signAndEncode(tx, coretypes.NewPragueSigner(chainID), privateKey)
+}
+
+// signLegacy signs a legacy (pre-EIP-1559) transaction
+func signLegacy(txData *types.TransactionData, privateKey *ecdsa.PrivateKey) ([]byte, common.Hash, error) {
+	tx, err := BuildTransaction(txData)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
 
 	// Sign with EIP-155 (chain ID for replay protection)
-	signer := coretypes.NewEIP155Signer(big.NewInt(int64(txData.ChainID)))
+	chainID := new(big.Int).SetUint64(txData.ChainID)
+	return signAndEncode(tx, coretypes.NewEIP155Signer(chainID), privateKey)
+}
+
+// signAndEncode signs a transaction and encodes it in EIP-2718 format
+func signAndEncode(tx *coretypes.Transaction, signer coretypes.Signer, privateKey *ecdsa.PrivateKey) ([]byte, common.Hash, error) {
 	signedTx, err := coretypes.SignTx(tx, signer, privateKey)
 	if err != nil {
 		return nil, common.Hash{}, fmt.Errorf("failed to sign transaction: %w", err)
 	}
 
-	// Encode to binary
 	signedTxBytes, err := signedTx.MarshalBinary()
 	if err != nil {
 		return nil, common.Hash{}, fmt.Errorf("failed to encode signed transaction: %w", err)
@@ -155,25 +272,99 @@ func signLegacy(txData *types.TransactionData, privateKey *ecdsa.PrivateKey) ([]
 	return signedTxBytes, signedTx.Hash(), nil
 }
 
-// VerifySignature verifies a signed transaction matches expected parameters
-func VerifySignature(signedTxBytes []byte, expectedFrom common.Address) error {
+// checkedChainAndGas converts the chain ID and gas limit with overflow checks,
+// so a corrupted value fails loudly instead of producing a different transaction
+func checkedChainAndGas(txData *types.TransactionData) (*big.Int, uint64, error) {
+	chainID, err := types.ChainIDToBig(txData.ChainID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid chain ID: %w", err)
+	}
+	gas, err := txData.GasLimit.ToUint64()
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid gas limit: %w", err)
+	}
+	return chainID, gas, nil
+}
+
+// toUint256 converts a big.Int to a uint256, rejecting negative or oversized values
+func toUint256(name string, b *big.Int) (*uint256.Int, error) {
+	if b.Sign() < 0 {
+		return nil, fmt.Errorf("%s must not be negative", name)
+	}
+	v, overflow := uint256.FromBig(b)
+	if overflow {
+		return nil, fmt.Errorf("%s exceeds 256 bits", name)
+	}
+	return v, nil
+}
+
+// SerializedSize returns the size in bytes the transaction will have once
+// signed, by signing it with a throwaway key, along with its unsigned
+// EIP-2718 encoding (the input expected by L2 data fee oracles)
+func SerializedSize(txData *types.TransactionData) (int, []byte, error) {
+	tx, err := BuildTransaction(txData)
+	if err != nil {
+		return 0, nil, err
+	}
+	unsigned, err := tx.MarshalBinary()
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to encode transaction: %w", err)
+	}
+
+	throwaway, err := ethcrypto.GenerateKey()
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	signed, _, err := signTxData(txData, throwaway)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return len(signed), unsigned, nil
+}
+
+// VerifyDeterministic signs the transaction parameters again and checks that
+// the result is byte-identical to an earlier signature. Signing uses RFC 6979
+// deterministic nonces, so a difference means a broken signer.
+func VerifyDeterministic(txParams *types.TxParams, privateKeyHex string, signedTxBytes []byte) error {
+	again, err := SignTransaction(txParams, privateKeyHex)
+	if err != nil {
+		return fmt.Errorf("failed to re-sign transaction: %w", err)
+	}
+
+	if !bytes.Equal(again.SignedTransaction, signedTxBytes) {
+		return fmt.Errorf("signing the same transaction twice produced different signatures (keccak %x vs %x)",
+			ethcrypto.Keccak256(signedTxBytes), ethcrypto.Keccak256(again.SignedTransaction))
+	}
+
+	return nil
+}
+
+// RecoverSigner decodes a signed transaction and recovers the address that
+// signed it
+func RecoverSigner(signedTxBytes []byte) (common.Address, *coretypes.Transaction, error) {
 	tx := new(coretypes.Transaction)
 	if err := tx.UnmarshalBinary(signedTxBytes); err != nil {
-		return fmt.Errorf("failed to decode transaction: %w", err)
+		return common.Address{}, nil, fmt.Errorf("failed to decode transaction: %w", err)
 	}
 
-	// Extract signer address from signature
-	chainID := tx.ChainId()
-	var signer coretypes.Signer
-	if tx.Type() == coretypes.DynamicFeeTxType {
-		signer = coretypes.NewLondonSigner(chainID)
-	} else {
-		signer = coretypes.NewEIP155Signer(chainID)
-	}
+	// Extract signer address from signature (latest signer accepts all
+	// supported transaction types)
+	signer := coretypes.LatestSignerForChainID(tx.ChainId())
 
 	from, err := coretypes.Sender(signer, tx)
 	if err != nil {
-		return fmt.Errorf("failed to recover signer: %w", err)
+		return common.Address{}, nil, fmt.Errorf("failed to recover signer: %w", err)
+	}
+
+	return from, tx, nil
+}
+
+// VerifySignature verifies a signed transaction matches expected parameters
+func VerifySignature(signedTxBytes []byte, expectedFrom common.Address) error {
+	from, _, err := RecoverSigner(signedTxBytes)
+	if err != nil {
+		return err
 	}
 
 	if from != expectedFrom {
@@ -183,6 +374,69 @@ func VerifySignature(signedTxBytes []byte, expectedFrom common.Address) error {
 	return nil
 }
 
+// VerifySignedTransaction decodes a signed transaction and checks that every
+// field matches the reviewed transaction parameters, so a fault in the
+// signing path cannot produce a transaction other than the one approved
+func VerifySignedTransaction(signedTxBytes []byte, txData *types.TransactionData) error {
+	tx := new(coretypes.Transaction)
+	if err := tx.UnmarshalBinary(signedTxBytes); err != nil {
+		return fmt.Errorf("failed to decode transaction: %w", err)
+	}
+
+	var mismatches []string
+	check := func(field string, ok bool, got, want interface{}) {
+		if !ok {
+			mismatches = append(mismatches, fmt.Sprintf("%s: signed %v, reviewed %v", field, got, want))
+		}
+	}
+	bigEq := func(a *big.Int, b *types.BigInt) bool {
+		want := big.NewInt(0)
+		if b != nil {
+			want = b.ToBigInt()
+		}
+		return a != nil && a.Cmp(want) == 0
+	}
+
+	check("tx_type", tx.Type() == txData.TxType, tx.Type(), txData.TxType)
+	check("chain_id", tx.ChainId().Cmp(new(big.Int).SetUint64(txData.ChainID)) == 0, tx.ChainId(), txData.ChainID)
+	check("nonce", tx.Nonce() == txData.Nonce, tx.Nonce(), txData.Nonce)
+	check("gas_limit", bigEq(new(big.Int).SetUint64(tx.Gas()), txData.GasLimit), tx.Gas(), txData.GasLimit)
+	check("value", bigEq(tx.Value(), txData.Value), tx.Value(), txData.Value)
+	check("data", string(tx.Data()) == string(txData.Data), fmt.Sprintf("%d bytes", len(tx.Data())), fmt.Sprintf("%d bytes", len(txData.Data)))
+
+	switch {
+	case tx.To() == nil || txData.To == nil:
+		check("to", tx.To() == nil && txData.To == nil, tx.To(), txData.To)
+	default:
+		check("to", *tx.To() == *txData.To, tx.To().Hex(), txData.To.Hex())
+	}
+
+	if txData.TxType == 0 {
+		check("gas_price", bigEq(tx.GasPrice(), txData.GasPrice), tx.GasPrice(), txData.GasPrice)
+	} else {
+		check("max_fee_per_gas", bigEq(tx.GasFeeCap(), txData.MaxFeePerGas), tx.GasFeeCap(), txData.MaxFeePerGas)
+		check("max_priority_fee_per_gas", bigEq(tx.GasTipCap(), txData.MaxPriorityFeePerGas), tx.GasTipCap(), txData.MaxPriorityFeePerGas)
+	}
+
+	authList := tx.SetCodeAuthorizations()
+	check("authorization_list", len(authList) == len(txData.AuthorizationList), len(authList), len(txData.AuthorizationList))
+	if len(authList) == len(txData.AuthorizationList) {
+		for i, a := range authList {
+			want := txData.AuthorizationList[i]
+			check(fmt.Sprintf("authorization %d", i),
+				a.Address == want.Address && a.Nonce == want.Nonce && a.ChainID.Uint64() == want.ChainID && a.ChainID.IsUint64(),
+				fmt.Sprintf("%s/%d/%s", a.Address.Hex(), a.Nonce, a.ChainID.Dec()),
+				fmt.Sprintf("%s/%d/%d", want.Address.Hex(), want.Nonce, want.ChainID))
+		}
+	}
+
+	if len(mismatches) > 0 {
+		return fmt.Errorf("signed transaction does not match reviewed parameters: %s", strings.Join(mismatches, "; "))
+	}
+
+	return VerifySignature(signedTxBytes, txData.From)
+}
+
 // PredictContractAddress predicts the address of a contract deployment
 func PredictContractAddress(deployer common.Address, nonce uint64) common.Address {
 	return ethcrypto.CreateAddress(deployer, nonce)
@@ -0,0 +1,199 @@
+package crypto
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// testKey is the well-known key 0x4646...46 of the EIP-155 examples
+const testKey = "4646464646464646464646464646464646464646464646464646464646464646"
+
+var testSigner = common.HexToAddress("0x9d8A62f656a8d1615C1294fd71e9CFb3E4855A4F")
+
+func testTxParams() *types.TxParams {
+	to := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	return &types.TxParams{
+		Mode: types.TransactionModeCall,
+		Transaction: types.TransactionData{
+			TxType:               2,
+			From:                 testSigner,
+			To:                   &to,
+			Data:                 []byte{0xde, 0xad, 0xbe, 0xef},
+			Nonce:                7,
+			ChainID:              11155111,
+			GasLimit:             types.NewBigInt(big.NewInt(60000)),
+			MaxFeePerGas:         types.NewBigInt(big.NewInt(30_000_000_000)),
+			MaxPriorityFeePerGas: types.NewBigInt(big.NewInt(1_000_000_000)),
+			Value:                types.NewBigInt(big.NewInt(1000)),
+		},
+	}
+}
+
+func TestVerifySignedTransactionRoundTrip(t *testing.T) {
+	txParams := testTxParams()
+	signedTx, err := SignTransaction(txParams, testKey)
+	if err != nil {
+		t.Fatalf("SignTransaction() error = %v", err)
+	}
+	if err := VerifySignedTransaction(signedTx.SignedTransaction, &txParams.Transaction); err != nil {
+		t.Fatalf("VerifySignedTransaction() error = %v", err)
+	}
+}
+
+func TestVerifySignedTransactionCatchesMismatch(t *testing.T) {
+	tests := []struct {
+		field  string
+		mutate func(tx *types.TransactionData)
+	}{
+		{"nonce", func(tx *types.TransactionData) { tx.Nonce++ }},
+		{"to", func(tx *types.TransactionData) {
+			addr := common.HexToAddress("0x2222222222222222222222222222222222222222")
+			tx.To = &addr
+		}},
+		{"value", func(tx *types.TransactionData) { tx.Value = types.NewBigInt(big.NewInt(1001)) }},
+		{"data", func(tx *types.TransactionData) { tx.Data = []byte{0xde, 0xad} }},
+		{"gas_limit", func(tx *types.TransactionData) { tx.GasLimit = types.NewBigInt(big.NewInt(60001)) }},
+		{"max_fee_per_gas", func(tx *types.TransactionData) { tx.MaxFeePerGas = types.NewBigInt(big.NewInt(31_000_000_000)) }},
+		{"chain_id", func(tx *types.TransactionData) { tx.ChainID = 1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			txParams := testTxParams()
+			reviewed, err := txParams.Transaction.Clone()
+			if err != nil {
+				t.Fatal(err)
+			}
+			// A faulty signing path signs something other than what was reviewed
+			tt.mutate(&txParams.Transaction)
+			signedTx, err := SignTransaction(txParams, testKey)
+			if err != nil {
+				t.Fatalf("SignTransaction() error = %v", err)
+			}
+			err = VerifySignedTransaction(signedTx.SignedTransaction, reviewed)
+			if err == nil || !strings.Contains(err.Error(), tt.field) {
+				t.Fatalf("VerifySignedTransaction() error = %v, want a %s mismatch", err, tt.field)
+			}
+		})
+	}
+}
+
+// A fee changed in place through the shared big.Int is caught when verifying
+// against a copy taken before the review
+func TestVerifySignedTransactionCatchesAliasedFee(t *testing.T) {
+	txParams := testTxParams()
+	reviewed, err := txParams.Transaction.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fee := txParams.Transaction.MaxFeePerGas.ToBigInt()
+	fee.Mul(fee, txParams.Transaction.GasLimit.ToBigInt())
+
+	signedTx, err := SignTransaction(txParams, testKey)
+	if err != nil {
+		t.Fatalf("SignTransaction() error = %v", err)
+	}
+	if err := VerifySignedTransaction(signedTx.SignedTransaction, reviewed); err == nil {
+		t.Fatal("VerifySignedTransaction() accepted a fee changed after review")
+	}
+}
+
+func TestVerifySignedTransactionWithReviewEdits(t *testing.T) {
+	txParams := testTxParams()
+	loaded, err := txParams.Transaction.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	edits := []types.ReviewEdit{{Field: "max_fee_per_gas", Prepared: "30000000000", Signed: "35000000000"}}
+	txParams.Transaction.MaxFeePerGas = types.NewBigInt(big.NewInt(35_000_000_000))
+
+	signedTx, err := SignTransaction(txParams, testKey)
+	if err != nil {
+		t.Fatalf("SignTransaction() error = %v", err)
+	}
+	if err := loaded.ApplyReviewEdits(edits); err != nil {
+		t.Fatalf("ApplyReviewEdits() error = %v", err)
+	}
+	if err := VerifySignedTransaction(signedTx.SignedTransaction, loaded); err != nil {
+		t.Fatalf("VerifySignedTransaction() error = %v", err)
+	}
+}
+
+func TestVerifyDeterministic(t *testing.T) {
+	legacy := func() *types.TxParams {
+		p := testTxParams()
+		p.Transaction.TxType = 0
+		p.Transaction.MaxFeePerGas = nil
+		p.Transaction.MaxPriorityFeePerGas = nil
+		p.Transaction.GasPrice = types.NewBigInt(big.NewInt(20_000_000_000))
+		return p
+	}
+	deploy := func(p *types.TxParams) *types.TxParams {
+		p.Mode = types.TransactionModeDeploy
+		p.Transaction.To = nil
+		p.Transaction.Value = types.NewBigInt(big.NewInt(0))
+		return p
+	}
+	setCode := func() *types.TxParams {
+		p := testTxParams()
+		p.Transaction.TxType = 4
+		p.Transaction.AuthorizationList = []types.Authorization{{
+			ChainID: 11155111,
+			Address: common.HexToAddress("0x2222222222222222222222222222222222222222"),
+			Nonce:   8,
+		}}
+		return p
+	}
+	tests := []struct {
+		name   string
+		params *types.TxParams
+	}{
+		{"legacy call", legacy()},
+		{"legacy deployment", deploy(legacy())},
+		{"EIP-1559 call", testTxParams()},
+		{"EIP-1559 deployment", deploy(testTxParams())},
+		{"EIP-7702 with an unsigned authorization", setCode()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signedTx, err := SignTransaction(tt.params, testKey)
+			if err != nil {
+				t.Fatalf("SignTransaction() error = %v", err)
+			}
+			again, err := SignTransaction(tt.params, testKey)
+			if err != nil {
+				t.Fatalf("SignTransaction() error = %v", err)
+			}
+			if string(again.SignedTransaction) != string(signedTx.SignedTransaction) {
+				t.Fatal("signing the same transaction twice gave different bytes")
+			}
+			if err := VerifyDeterministic(tt.params, testKey, signedTx.SignedTransaction); err != nil {
+				t.Fatalf("VerifyDeterministic() error = %v", err)
+			}
+
+			// A signature that differs in its last byte (part of s) must fail
+			tampered := append([]byte(nil), signedTx.SignedTransaction...)
+			tampered[len(tampered)-1] ^= 0x01
+			err = VerifyDeterministic(tt.params, testKey, tampered)
+			if err == nil || !strings.Contains(err.Error(), "different signatures") {
+				t.Fatalf("VerifyDeterministic() with a tampered signature error = %v, want a mismatch", err)
+			}
+		})
+	}
+
+	t.Run("signature of another transaction", func(t *testing.T) {
+		params := testTxParams()
+		other := testTxParams()
+		other.Transaction.Nonce++
+		otherTx, err := SignTransaction(other, testKey)
+		if err != nil {
+			t.Fatalf("SignTransaction() error = %v", err)
+		}
+		if err := VerifyDeterministic(params, testKey, otherTx.SignedTransaction); err == nil {
+			t.Fatal("VerifyDeterministic() accepted the signature of a different transaction")
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"fmt"
+	"strings"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+)
+
+// ParsePublicKey parses a hex secp256k1 public key in uncompressed (65 bytes,
+// 0x04 prefix), compressed (33 bytes) or raw (64 bytes, no prefix) form
+func ParsePublicKey(publicKeyHex string) (*ecdsa.PublicKey, error) {
+	publicKeyHex = strings.TrimSpace(publicKeyHex)
+	if !strings.HasPrefix(publicKeyHex, "0x") {
+		publicKeyHex = "0x" + publicKeyHex
+	}
+	raw, err := hexutil.Decode(publicKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	var publicKey *ecdsa.PublicKey
+	switch len(raw) {
+	case 33:
+		publicKey, err = ethcrypto.DecompressPubkey(raw)
+	case 64:
+		publicKey, err = ethcrypto.UnmarshalPubkey(append([]byte{4}, raw...))
+	case 65:
+		publicKey, err = ethcrypto.UnmarshalPubkey(raw)
+	default:
+		return nil, fmt.Errorf("invalid public key: expected 33, 64 or 65 bytes, got %d", len(raw))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	return publicKey, nil
+}
+
+// EncryptSignedTx encrypts a serialized signed transaction file to the
+// recipient's public key. The recipient's address is recorded so the wrong
+// decryption key can be reported clearly.
+func EncryptSignedTx(signedTxJSON []byte, recipient *ecdsa.PublicKey) (*types.EncryptedSignedTx, error) {
+	ciphertext, err := ecies.Encrypt(rand.Reader, ecies.ImportECDSAPublic(recipient), signedTxJSON, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt signed transaction: %w", err)
+	}
+
+	return &types.EncryptedSignedTx{
+		SchemaVersion: types.SchemaVersion,
+		Encryption:    types.EncryptionECIES,
+		Recipient:     ethcrypto.PubkeyToAddress(*recipient),
+		Ciphertext:    ciphertext,
+	}, nil
+}
+
+// DecryptSignedTx decrypts an encrypted signed transaction file, returning
+// the serialized signed transaction. The ECIES MAC rejects a ciphertext
+// altered in transit, but it does not authenticate the sender: anyone with
+// the recipient's public key can produce a file that decrypts. Trust in the
+// contents comes from the transaction's own signature.
+func DecryptSignedTx(encrypted *types.EncryptedSignedTx, privateKey *ecdsa.PrivateKey) ([]byte, error) {
+	if encrypted.Encryption != types.EncryptionECIES {
+		return nil, fmt.Errorf("unsupported encryption: %q (supported: %s)", encrypted.Encryption, types.EncryptionECIES)
+	}
+
+	keyAddress := ethcrypto.PubkeyToAddress(privateKey.PublicKey)
+	if encrypted.Recipient != (common.Address{}) && encrypted.Recipient != keyAddress {
+		return nil, fmt.Errorf("file is encrypted to the key of %s, but the decryption key is for %s", encrypted.Recipient.Hex(), keyAddress.Hex())
+	}
+
+	plaintext, err := ecies.ImportECDSA(privateKey).Decrypt(encrypted.Ciphertext, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt signed transaction (corrupted or altered file?): %w", err)
+	}
+	return plaintext, nil
+}
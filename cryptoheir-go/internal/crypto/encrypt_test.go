@@ -0,0 +1,139 @@
+package crypto
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+var testPlaintext = []byte(`{"schema_version":"1","signed_transaction":"0x02f8"}`)
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	key, err := ethcrypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := EncryptSignedTx(testPlaintext, &key.PublicKey)
+	if err != nil {
+		t.Fatalf("EncryptSignedTx() error = %v", err)
+	}
+	if encrypted.Recipient != ethcrypto.PubkeyToAddress(key.PublicKey) {
+		t.Errorf("Recipient = %s, want the key's address", encrypted.Recipient.Hex())
+	}
+	if bytes.Contains(encrypted.Ciphertext, testPlaintext) {
+		t.Error("ciphertext contains the plaintext")
+	}
+
+	plaintext, err := DecryptSignedTx(encrypted, key)
+	if err != nil {
+		t.Fatalf("DecryptSignedTx() error = %v", err)
+	}
+	if !bytes.Equal(plaintext, testPlaintext) {
+		t.Errorf("DecryptSignedTx() = %s, want %s", plaintext, testPlaintext)
+	}
+}
+
+func TestDecryptRejectsTampering(t *testing.T) {
+	key, err := ethcrypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := EncryptSignedTx(testPlaintext, &key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Flip one bit in each part of the ciphertext: the ephemeral key, the
+	// encrypted body and the MAC
+	n := len(encrypted.Ciphertext)
+	for _, i := range []int{1, 65 + 16 + 3, n - 1} {
+		tampered := *encrypted
+		tampered.Ciphertext = append([]byte{}, encrypted.Ciphertext...)
+		tampered.Ciphertext[i] ^= 0x01
+		if _, err := DecryptSignedTx(&tampered, key); err == nil {
+			t.Errorf("DecryptSignedTx() accepted a ciphertext altered at byte %d", i)
+		}
+	}
+
+	truncated := *encrypted
+	truncated.Ciphertext = encrypted.Ciphertext[:n-1]
+	if _, err := DecryptSignedTx(&truncated, key); err == nil {
+		t.Error("DecryptSignedTx() accepted a truncated ciphertext")
+	}
+}
+
+func TestDecryptWrongKey(t *testing.T) {
+	key, err := ethcrypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ethcrypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := EncryptSignedTx(testPlaintext, &key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := DecryptSignedTx(encrypted, other); err == nil || !strings.Contains(err.Error(), "encrypted to the key of") {
+		t.Errorf("DecryptSignedTx() with the wrong key: error = %v, want a recipient mismatch", err)
+	}
+
+	// Without the recorded recipient, the MAC still fails
+	anonymous := *encrypted
+	anonymous.Recipient = common.Address{}
+	if _, err := DecryptSignedTx(&anonymous, other); err == nil {
+		t.Error("DecryptSignedTx() decrypted with the wrong key")
+	}
+}
+
+func TestDecryptUnsupportedEncryption(t *testing.T) {
+	key, err := ethcrypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := EncryptSignedTx(testPlaintext, &key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted.Encryption = "age"
+	if _, err := DecryptSignedTx(encrypted, key); err == nil {
+		t.Error("DecryptSignedTx() accepted an unsupported encryption scheme")
+	}
+}
+
+func TestParsePublicKeyForms(t *testing.T) {
+	key, err := ethcrypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	uncompressed := ethcrypto.FromECDSAPub(&key.PublicKey)
+	forms := map[string]string{
+		"uncompressed":       hexutil.Encode(uncompressed),
+		"compressed":         hexutil.Encode(ethcrypto.CompressPubkey(&key.PublicKey)),
+		"raw":                hexutil.Encode(uncompressed[1:]),
+		"without 0x prefix":  hexutil.Encode(uncompressed)[2:],
+		"surrounding spaces": " " + hexutil.Encode(uncompressed) + "\n",
+	}
+	for name, form := range forms {
+		got, err := ParsePublicKey(form)
+		if err != nil {
+			t.Errorf("ParsePublicKey(%s) error = %v", name, err)
+			continue
+		}
+		if !got.Equal(&key.PublicKey) {
+			t.Errorf("ParsePublicKey(%s) returned a different key", name)
+		}
+	}
+
+	for _, bad := range []string{"", "0x04", "0xzz", hexutil.Encode(uncompressed[:40])} {
+		if _, err := ParsePublicKey(bad); err == nil {
+			t.Errorf("ParsePublicKey(%q) succeeded, want an error", bad)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DerivedAccount is an account derived from a mnemonic
+type DerivedAccount struct {
+	Path       accounts.DerivationPath
+	Address    common.Address
+	PrivateKey *ecdsa.PrivateKey
+}
+
+// PrivateKeyHex returns the private key in the hex form SignTransaction takes
+func (a *DerivedAccount) PrivateKeyHex() string {
+	return hex.EncodeToString(ethcrypto.FromECDSA(a.PrivateKey))
+}
+
+// MnemonicSeed computes the BIP-39 seed of a mnemonic and optional
+// passphrase. Both are NFKD-normalized as BIP-39 requires, so accented or
+// Japanese words and passphrases give the same seed as other wallets however
+// they were typed. The words are not checked against the BIP-39 word list, so
+// a mistyped mnemonic derives unrelated accounts rather than failing.
+func MnemonicSeed(mnemonic, passphrase string) ([]byte, error) {
+	// Splitting on any Unicode space and joining with an ASCII space matches
+	// NFKD, which maps the ideographic space of Japanese mnemonics to U+0020
+	words := strings.Fields(norm.NFKD.String(strings.ToLower(mnemonic)))
+	switch len(words) {
+	case 12, 15, 18, 21, 24:
+	default:
+		return nil, fmt.Errorf("invalid mnemonic: expected 12, 15, 18, 21 or 24 words, got %d", len(words))
+	}
+	salt := norm.NFKD.String("mnemonic" + passphrase)
+	seed, err := pbkdf2.Key(sha512.New, strings.Join(words, " "), []byte(salt), 2048, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute mnemonic seed: %w", err)
+	}
+	return seed, nil
+}
+
+// DeriveKey derives the BIP-32 private key at path from a seed
+func DeriveKey(seed []byte, path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	n := ethcrypto.S256().Params().N
+
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	key, chainCode := new(big.Int).SetBytes(sum[:32]), sum[32:]
+	if key.Sign() == 0 || key.Cmp(n) >= 0 {
+		return nil, fmt.Errorf("seed yields an invalid master key")
+	}
+
+	for _, index := range path {
+		var data []byte
+		if index >= 0x80000000 {
+			// Hardened child: derived from the private key
+			data = append([]byte{0}, common.LeftPadBytes(key.Bytes(), 32)...)
+		} else {
+			// Normal child: derived from the compressed public key
+			parent, err := ethcrypto.ToECDSA(common.LeftPadBytes(key.Bytes(), 32))
+			if err != nil {
+				return nil, err
+			}
+			data = ethcrypto.CompressPubkey(&parent.PublicKey)
+		}
+		data = binary.BigEndian.AppendUint32(data, index)
+
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		sum := mac.Sum(nil)
+		tweak := new(big.Int).SetBytes(sum[:32])
+		if tweak.Cmp(n) >= 0 {
+			return nil, fmt.Errorf("path %s yields an invalid key", path)
+		}
+		key = tweak.Add(tweak, key).Mod(tweak, n)
+		if key.Sign() == 0 {
+			return nil, fmt.Errorf("path %s yields an invalid key", path)
+		}
+		chainCode = sum[32:]
+	}
+
+	return ethcrypto.ToECDSA(common.LeftPadBytes(key.Bytes(), 32))
+}
+
+// ScanMnemonic derives count accounts starting at base, incrementing its last
+// component, and returns the one whose address is want. When none matches it
+// fails listing every address checked.
+func ScanMnemonic(mnemonic, passphrase string, base accounts.DerivationPath, count int, want common.Address) (*DerivedAccount, error) {
+	if count <= 0 {
+		return nil, fmt.Errorf("scan count must be positive")
+	}
+	seed, err := MnemonicSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	next := accounts.DefaultIterator(base)
+	checked := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		path := next()
+		key, err := DeriveKey(seed, path)
+		if err != nil {
+			return nil, err
+		}
+		address := ethcrypto.PubkeyToAddress(key.PublicKey)
+		if address == want {
+			return &DerivedAccount{Path: path, Address: address, PrivateKey: key}, nil
+		}
+		checked = append(checked, fmt.Sprintf("%s %s", path, address.Hex()))
+	}
+	return nil, fmt.Errorf("none of the %d derived accounts is the transaction sender %s; checked:\n  %s",
+		count, want.Hex(), strings.Join(checked, "\n  "))
+}
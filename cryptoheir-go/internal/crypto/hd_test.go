@@ -0,0 +1,150 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// TestMnemonicSeedBIP39Vectors uses vectors from the BIP-39 reference test
+// set (trezor/python-mnemonic vectors.json, passphrase "TREZOR") and the
+// Japanese set linked from BIP-39, which exercises NFKD normalization of the
+// words, the ideographic space between them and the passphrase
+func TestMnemonicSeedBIP39Vectors(t *testing.T) {
+	tests := []struct {
+		mnemonic   string
+		passphrase string
+		seed       string
+	}{
+		{
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+			"TREZOR",
+			"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		},
+		{
+			"legal winner thank year wave sausage worth useful legal winner thank yellow",
+			"TREZOR",
+			"2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607",
+		},
+		{
+			"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+			"TREZOR",
+			"ac27495480225222079d7be181583751e86f571027b0497b5b5d11218e0a8a13332572917f0f8e5a589620c6f15b11c61dee327651a14c34e18231052e48c069",
+		},
+		{
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art",
+			"TREZOR",
+			"bda85446c68413707090a52022edd26a1c9462295029f2e60cd7c4f2bbd3097170af7a4d73245cafa9c3cca8d561a7c3de6f5d4a10be8ed2a5e608d68f92fcc8",
+		},
+		{
+			"あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あおぞら",
+			"㍍ガバヴァぱばぐゞちぢ十人十色",
+			"a262d6fb6122ecf45be09c50492b31f92e9beb7d9a845987a02cefda57a15f9c467a17872029a9e92299b5cbdf306e3a0ee620245cbd508959b6cb7ca637bd55",
+		},
+	}
+	for _, tt := range tests {
+		seed, err := MnemonicSeed(tt.mnemonic, tt.passphrase)
+		if err != nil {
+			t.Errorf("MnemonicSeed(%q) error = %v", tt.mnemonic, err)
+			continue
+		}
+		if got := hex.EncodeToString(seed); got != tt.seed {
+			t.Errorf("MnemonicSeed(%q) = %s, want %s", tt.mnemonic, got, tt.seed)
+		}
+	}
+}
+
+func TestMnemonicSeedNormalization(t *testing.T) {
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	composed, err := MnemonicSeed(mnemonic, "caf\u00e9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	decomposed, err := MnemonicSeed(mnemonic, "cafe\u0301")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(composed) != hex.EncodeToString(decomposed) {
+		t.Error("composed and decomposed passphrases give different seeds")
+	}
+
+	spaced, err := MnemonicSeed("  "+strings.ToUpper(strings.ReplaceAll(mnemonic, " ", "\n\t"))+" ", "caf\u00e9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(composed) != hex.EncodeToString(spaced) {
+		t.Error("extra whitespace or capitals in the mnemonic change the seed")
+	}
+
+	if _, err := MnemonicSeed("abandon abandon about", ""); err == nil {
+		t.Error("MnemonicSeed() accepted 3 words")
+	}
+}
+
+// TestDeriveKeyBIP32Vectors uses test vectors 1 to 3 of BIP-32. Vector 3
+// has a master key with a leading zero byte, which must be kept.
+func TestDeriveKeyBIP32Vectors(t *testing.T) {
+	const h = 0x80000000
+	tests := []struct {
+		seed string
+		path []uint32
+		key  string
+	}{
+		{"000102030405060708090a0b0c0d0e0f", nil, "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"},
+		{"000102030405060708090a0b0c0d0e0f", []uint32{h}, "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{"000102030405060708090a0b0c0d0e0f", []uint32{h, 1}, "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{"000102030405060708090a0b0c0d0e0f", []uint32{h, 1, h + 2}, "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca"},
+		{"000102030405060708090a0b0c0d0e0f", []uint32{h, 1, h + 2, 2}, "0f479245fb19a38a1954c5c7c0ebab2f9bdfd96a17563ef28a6a4b1a2a764ef4"},
+		{"000102030405060708090a0b0c0d0e0f", []uint32{h, 1, h + 2, 2, 1000000000}, "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+
+		{vector2Seed, nil, "4b03d6fc340455b363f51020ad3ecca4f0850280cf436c70c727923f6db46c3e"},
+		{vector2Seed, []uint32{0}, "abe74a98f6c7eabee0428f53798f0ab8aa1bd37873999041703c742f15ac7e1e"},
+		{vector2Seed, []uint32{0, h + 2147483647}, "877c779ad9687164e9c2f4f0f4ff0340814392330693ce95a58fe18fd52e6e93"},
+		{vector2Seed, []uint32{0, h + 2147483647, 1}, "704addf544a06e5ee4bea37098463c23613da32020d604506da8c0518e1da4b7"},
+		{vector2Seed, []uint32{0, h + 2147483647, 1, h + 2147483646}, "f1c7c871a54a804afe328b4c83a1c33b8e5ff48f5087273f04efa83b247d6a2d"},
+		{vector2Seed, []uint32{0, h + 2147483647, 1, h + 2147483646, 2}, "bb7d39bdb83ecf58f2fd82b6d918341cbef428661ef01ab97c28a4842125ac23"},
+
+		{vector3Seed, nil, "00ddb80b067e0d4993197fe10f2657a844a384589847602d56f0c629c81aae32"},
+		{vector3Seed, []uint32{h}, "491f7a2eebc7b57028e0d3faa0acda02e75c33b03c48fb288c41e2ea44e1daef"},
+	}
+	for _, tt := range tests {
+		seed, _ := hex.DecodeString(tt.seed)
+		key, err := DeriveKey(seed, accounts.DerivationPath(tt.path))
+		if err != nil {
+			t.Errorf("DeriveKey(%s) error = %v", accounts.DerivationPath(tt.path), err)
+			continue
+		}
+		if got := hex.EncodeToString(ethcrypto.FromECDSA(key)); got != tt.key {
+			t.Errorf("DeriveKey(%.8s…, %s) = %s, want %s", tt.seed, accounts.DerivationPath(tt.path), got, tt.key)
+		}
+	}
+}
+
+const (
+	vector2Seed = "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542"
+	vector3Seed = "4b381541583be4423346c643850da4b320e46a87ae3d2a4e6da11eba819cd4acba45d239319ac14f863b8d5ab5a0d0c64d2e8a1e7d1457df2e5a3c51c73235be"
+)
+
+// TestScanMnemonic finds the second account of the well-known development
+// mnemonic used by Hardhat and Foundry
+func TestScanMnemonic(t *testing.T) {
+	const mnemonic = "test test test test test test test test test test test junk"
+	want := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+
+	account, err := ScanMnemonic(mnemonic, "", accounts.DefaultBaseDerivationPath, 5, want)
+	if err != nil {
+		t.Fatalf("ScanMnemonic() error = %v", err)
+	}
+	if account.Address != want || account.Path.String() != "m/44'/60'/0'/0/1" {
+		t.Errorf("ScanMnemonic() = %s at %s, want %s at m/44'/60'/0'/0/1", account.Address.Hex(), account.Path, want.Hex())
+	}
+
+	_, err = ScanMnemonic(mnemonic, "", accounts.DefaultBaseDerivationPath, 1, want)
+	if err == nil || !strings.Contains(err.Error(), "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266") {
+		t.Errorf("ScanMnemonic() past the scan count: error = %v, want the checked address listed", err)
+	}
+}
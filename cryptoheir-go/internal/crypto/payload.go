@@ -0,0 +1,81 @@
+package crypto
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// SigningPayload is what the private key signs: the pre-image of the
+// signing hash and the hash itself
+type SigningPayload struct {
+	// Signer names the signing scheme
+	Signer   string
+	Preimage []byte
+	Hash     common.Hash
+
+	// UnsignedAuthorizations counts EIP-7702 authorizations that are signed
+	// with the same key just before the transaction. Their signatures are
+	// part of the pre-image, so the bytes shown have them zeroed and the
+	// final payload differs.
+	UnsignedAuthorizations int
+}
+
+// ComputeSigningPayload builds the transaction exactly as signing does and
+// returns the bytes its signing hash is computed over. The pre-image is
+// encoded here independently and checked against the signer's own Hash, so a
+// mismatch between the two fails instead of showing the wrong bytes.
+func ComputeSigningPayload(txData *types.TransactionData) (*SigningPayload, error) {
+	tx, err := BuildTransaction(txData)
+	if err != nil {
+		return nil, err
+	}
+	chainID := new(big.Int).SetUint64(txData.ChainID)
+
+	var (
+		signer  coretypes.Signer
+		name    string
+		prefix  []byte
+		payload []interface{}
+	)
+	switch tx.Type() {
+	case coretypes.LegacyTxType:
+		signer, name = coretypes.NewEIP155Signer(chainID), "EIP-155 (legacy)"
+		payload = []interface{}{tx.Nonce(), tx.GasPrice(), tx.Gas(), tx.To(), tx.Value(), tx.Data(), chainID, uint(0), uint(0)}
+	case coretypes.DynamicFeeTxType:
+		signer, name = coretypes.NewLondonSigner(chainID), "EIP-1559 (type 2)"
+		prefix = []byte{coretypes.DynamicFeeTxType}
+		payload = []interface{}{chainID, tx.Nonce(), tx.GasTipCap(), tx.GasFeeCap(), tx.Gas(), tx.To(), tx.Value(), tx.Data(), tx.AccessList()}
+	case coretypes.SetCodeTxType:
+		signer, name = coretypes.NewPragueSigner(chainID), "EIP-7702 (type 4)"
+		prefix = []byte{coretypes.SetCodeTxType}
+		payload = []interface{}{chainID, tx.Nonce(), tx.GasTipCap(), tx.GasFeeCap(), tx.Gas(), tx.To(), tx.Value(), tx.Data(), tx.AccessList(), tx.SetCodeAuthorizations()}
+	default:
+		return nil, fmt.Errorf("unsupported transaction type: %d", tx.Type())
+	}
+
+	encoded, err := rlp.EncodeToBytes(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signing payload: %w", err)
+	}
+	preimage := append(prefix, encoded...)
+
+	hash := signer.Hash(tx)
+	if got := common.BytesToHash(ethcrypto.Keccak256(preimage)); got != hash {
+		return nil, fmt.Errorf("signing payload does not hash to the signer's hash (%s vs %s)", got.Hex(), hash.Hex())
+	}
+
+	unsigned := 0
+	for _, a := range txData.AuthorizationList {
+		if !a.IsSigned() {
+			unsigned++
+		}
+	}
+
+	return &SigningPayload{Signer: name, Preimage: preimage, Hash: hash, UnsignedAuthorizations: unsigned}, nil
+}
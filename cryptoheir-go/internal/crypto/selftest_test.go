@@ -0,0 +1,53 @@
+package crypto
+
+import (
+	"strings"
+	"testing"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSelfTest(t *testing.T) {
+	results := SelfTest()
+	if len(results) != len(goldenVectors) {
+		t.Fatalf("SelfTest() returned %d results, want one per vector (%d)", len(results), len(goldenVectors))
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			t.Errorf("%s: %v", r.Name, r.Err)
+		}
+	}
+}
+
+func TestSelfTestKeyIsEIP155Example(t *testing.T) {
+	key, err := ethcrypto.HexToECDSA(selfTestKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from := ethcrypto.PubkeyToAddress(key.PublicKey); from != testSigner {
+		t.Errorf("self-test key address = %s, want %s", from.Hex(), testSigner.Hex())
+	}
+}
+
+func TestCheckVectorDetectsChanges(t *testing.T) {
+	for _, v := range goldenVectors {
+		tx := v.tx
+		tx.From = testSigner
+		tx.Nonce++
+		if err := checkVector(&tx, v.raw); err == nil || !strings.Contains(err.Error(), "differ from the golden value") {
+			t.Errorf("%s with a changed nonce: error = %v, want a golden value mismatch", v.name, err)
+		}
+
+		tx = v.tx
+		tx.From = testSigner
+		flipped := []byte(v.raw)
+		if flipped[len(flipped)-1] == '0' {
+			flipped[len(flipped)-1] = '1'
+		} else {
+			flipped[len(flipped)-1] = '0'
+		}
+		if err := checkVector(&tx, string(flipped)); err == nil {
+			t.Errorf("%s against an altered golden value: no error", v.name)
+		}
+	}
+}
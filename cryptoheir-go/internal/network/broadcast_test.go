@@ -0,0 +1,83 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// signedTestTx returns a signed EIP-1559 transaction, encoded for broadcast
+func signedTestTx(t *testing.T) (*coretypes.Transaction, []byte) {
+	t.Helper()
+	key, err := crypto.HexToECDSA("4646464646464646464646464646464646464646464646464646464646464646")
+	if err != nil {
+		t.Fatal(err)
+	}
+	to := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	chainID := big.NewInt(11155111)
+	tx, err := coretypes.SignNewTx(key, coretypes.LatestSignerForChainID(chainID), &coretypes.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     3,
+		GasTipCap: big.NewInt(1e9),
+		GasFeeCap: big.NewInt(3e9),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx, raw
+}
+
+func TestBroadcastAlreadyKnown(t *testing.T) {
+	tx, raw := signedTestTx(t)
+	tests := []struct {
+		name      string
+		response  interface{}
+		wantKnown bool
+	}{
+		{"accepted", tx.Hash().Hex(), false},
+		{"geth", &rpcError{Code: -32000, Message: "already known"}, true},
+		{"nethermind", &rpcError{Code: -32010, Message: "AlreadyKnown"}, true},
+		{"openethereum", &rpcError{Code: -32010, Message: "Transaction with the same hash was already imported."}, true},
+		{"short imported wording", &rpcError{Code: -32010, Message: "Transaction already imported"}, true},
+		{"nonce too low", &rpcError{Code: -32000, Message: "nonce too low: next nonce 4, tx nonce 3"}, false},
+		{"underpriced", &rpcError{Code: -32000, Message: "replacement transaction underpriced"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := newMockRPC(t, map[string]interface{}{"eth_sendRawTransaction": tt.response})
+			hash, err := BroadcastTransaction(context.Background(), node.client(), raw)
+			if node.count("eth_sendRawTransaction") != 1 {
+				t.Fatalf("eth_sendRawTransaction called %d times, want once", node.count("eth_sendRawTransaction"))
+			}
+			if _, isErr := tt.response.(*rpcError); !isErr {
+				if err != nil || hash != tx.Hash() {
+					t.Fatalf("BroadcastTransaction() = %s, %v; want %s", hash.Hex(), err, tx.Hash().Hex())
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("BroadcastTransaction() succeeded, want the node's error")
+			}
+			if got := IsAlreadyKnown(err); got != tt.wantKnown {
+				t.Errorf("IsAlreadyKnown(%v) = %v, want %v", err, got, tt.wantKnown)
+			}
+			// An already known transaction is not a definitive rejection
+			// whose nonce could be reused
+			if tt.wantKnown && errors.Is(err, ErrReplacementUnderpriced) {
+				t.Errorf("already known reported as underpriced: %v", err)
+			}
+		})
+	}
+}
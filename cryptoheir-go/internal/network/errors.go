@@ -0,0 +1,319 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"strings"
+	"syscall"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// NetworkError wraps a failed RPC operation and records whether retrying the
+// same request may succeed
+type NetworkError struct {
+	Op        string // Operation that failed, e.g. "failed to get nonce"
+	Err       error
+	retryable bool
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Op, e.Err)
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// IsRetryable reports whether the failure is transient (timeouts, rate
+// limits, server errors, dropped connections) rather than a rejection of the
+// request itself (reverts, invalid parameters, nonce errors)
+func (e *NetworkError) IsRetryable() bool {
+	return e.retryable
+}
+
+// codedMessages map node responses to error codes, checked in order
+var codedMessages = []struct {
+	message string
+	code    types.ErrorCode
+}{
+	{"execution reverted", types.CodeRevert},
+	{"insufficient funds", types.CodeInsufficientFunds},
+	{"nonce too low", types.CodeNonceTooLow},
+	{"nonce too high", types.CodeNonceTooHigh},
+	{"replacement transaction underpriced", types.CodeReplacementUnderpriced},
+	{"replacement fee too low", types.CodeReplacementUnderpriced},
+}
+
+// ErrorCode classifies the failure: a revert, a funds or nonce rejection, or
+// types.CodeNetwork for anything else the node or the connection reported
+func (e *NetworkError) ErrorCode() types.ErrorCode {
+	var dataErr rpc.DataError
+	if errors.As(e.Err, &dataErr) && dataErr.ErrorData() != nil {
+		return types.CodeRevert
+	}
+	msg := strings.ToLower(e.Err.Error())
+	for _, m := range codedMessages {
+		if strings.Contains(msg, m.message) {
+			return m.code
+		}
+	}
+	return types.CodeNetwork
+}
+
+// Is matches the types sentinel error of the failure's code, e.g.
+// errors.Is(err, types.ErrNonceTooLow)
+func (e *NetworkError) Is(target error) bool {
+	t, ok := target.(*types.CodedError)
+	return ok && t.Err == nil && t.Code == e.ErrorCode()
+}
+
+// IsRetryable reports whether err contains a retryable NetworkError
+func IsRetryable(err error) bool {
+	var netErr *NetworkError
+	if errors.As(err, &netErr) {
+		return netErr.IsRetryable()
+	}
+	return false
+}
+
+// wrapError wraps err from the named operation in a classified NetworkError
+func wrapError(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &NetworkError{Op: op, Err: err, retryable: classifyRetryable(err)}
+}
+
+// fatalMessages mark node responses that will not change on retry
+var fatalMessages = []string{
+	"execution reverted",
+	"nonce too low",
+	"nonce too high",
+	"insufficient funds",
+	"invalid",
+	"intrinsic gas too low",
+	"exceeds block gas limit",
+	"replacement transaction underpriced",
+	"already known",
+}
+
+// retryableMessages mark transient node responses
+var retryableMessages = []string{
+	"rate limit",
+	"too many requests",
+	"timeout",
+	"timed out",
+	"temporarily unavailable",
+	"header not found",
+	"connection reset",
+	"connection refused",
+	"broken pipe",
+}
+
+// classifyRetryable decides whether a go-ethereum or RPC error is transient
+func classifyRetryable(err error) bool {
+	// Reverts carry revert data and never succeed on retry
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) && dataErr.ErrorData() != nil {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == 429 || httpErr.StatusCode >= 500
+	}
+
+	// JSON-RPC "limit exceeded" (EIP-1474)
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == -32005 {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, m := range fatalMessages {
+		if strings.Contains(msg, m) {
+			return false
+		}
+	}
+	for _, m := range retryableMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ErrReplacementUnderpriced is matched (with errors.Is) by node rejections of
+// a replacement transaction whose fee increase is too small
+var ErrReplacementUnderpriced = errors.New("replacement transaction underpriced")
+
+// ReplacementBumpPercent is the minimum fee increase over the pending
+// transaction that geth-based nodes accept for a replacement
+const ReplacementBumpPercent = 10
+
+// ReplacementUnderpricedError describes a rejected replacement and the fees
+// it would have needed
+type ReplacementUnderpricedError struct {
+	Err         error
+	Replacement *coretypes.Transaction
+	Original    *coretypes.Transaction // Pending transaction being replaced; nil if unknown
+}
+
+func (e *ReplacementUnderpricedError) Error() string {
+	nonce := e.Replacement.Nonce()
+	if e.Original == nil {
+		return fmt.Sprintf("replacement transaction underpriced: nonce %d is held by a pending transaction; raise both fees by at least %d%% over it",
+			nonce, ReplacementBumpPercent)
+	}
+
+	minFeeCap, minTipCap := e.MinFees()
+	advice := "re-prepare it with at least those fees"
+	if percent, ok := e.RequiredBumpPercent(); ok {
+		advice = fmt.Sprintf("re-prepare it with fees at least %d%% higher", percent)
+	}
+	return fmt.Sprintf("replacement transaction underpriced: nonce %d is held by %s (max fee %s gwei, tip %s gwei); "+
+		"a replacement needs at least max fee %s gwei and tip %s gwei, this one has %s gwei and %s gwei; %s",
+		nonce, e.Original.Hash().Hex(),
+		types.FormatUnits(e.Original.GasFeeCap(), 9), types.FormatUnits(e.Original.GasTipCap(), 9),
+		types.FormatUnits(minFeeCap, 9), types.FormatUnits(minTipCap, 9),
+		types.FormatUnits(e.Replacement.GasFeeCap(), 9), types.FormatUnits(e.Replacement.GasTipCap(), 9),
+		advice)
+}
+
+func (e *ReplacementUnderpricedError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches ErrReplacementUnderpriced and types.ErrReplacementUnderpriced
+func (e *ReplacementUnderpricedError) Is(target error) bool {
+	return target == ErrReplacementUnderpriced || target == types.ErrReplacementUnderpriced
+}
+
+// ErrorCode returns types.CodeReplacementUnderpriced
+func (e *ReplacementUnderpricedError) ErrorCode() types.ErrorCode {
+	return types.CodeReplacementUnderpriced
+}
+
+// MinFees returns the lowest max fee and tip (the gas price for legacy
+// transactions) a replacement of the original transaction is accepted with
+func (e *ReplacementUnderpricedError) MinFees() (feeCap, tipCap *big.Int) {
+	if e.Original == nil {
+		return nil, nil
+	}
+	return bumpFee(e.Original.GasFeeCap(), ReplacementBumpPercent), bumpFee(e.Original.GasTipCap(), ReplacementBumpPercent)
+}
+
+// RequiredBumpPercent returns the smallest whole percentage by which both of
+// the replacement's fees must be raised to reach MinFees. It reports false
+// if the original is unknown, or if a zero fee of the replacement must be
+// raised, which no percentage does.
+func (e *ReplacementUnderpricedError) RequiredBumpPercent() (int, bool) {
+	minFeeCap, minTipCap := e.MinFees()
+	if minFeeCap == nil {
+		return 0, false
+	}
+	feePercent, feeOK := percentNeeded(e.Replacement.GasFeeCap(), minFeeCap)
+	tipPercent, tipOK := percentNeeded(e.Replacement.GasTipCap(), minTipCap)
+	return max(feePercent, tipPercent), feeOK && tipOK
+}
+
+// IsReplacementUnderpriced reports whether a broadcast failed because the
+// node rejected the fee increase of a replacement transaction
+func IsReplacementUnderpriced(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrReplacementUnderpriced) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "replacement transaction underpriced") ||
+		strings.Contains(msg, "replacement fee too low")
+}
+
+// IsRejected reports whether the node answered with a JSON-RPC error
+// response, as opposed to the request failing in transit. A rejected
+// broadcast definitely did not enter the node's pool.
+func IsRejected(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr)
+}
+
+// alreadyKnownMessages mark a node rejecting a transaction it already has in
+// its pool (geth, Erigon and Besu; Nethermind; OpenEthereum, which says
+// "Transaction with the same hash was already imported.")
+var alreadyKnownMessages = []string{
+	"already known",
+	"alreadyknown",
+	"already imported",
+}
+
+// IsAlreadyKnown reports whether a broadcast was rejected because the node
+// already has the transaction, i.e. it was submitted before
+func IsAlreadyKnown(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, m := range alreadyKnownMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// bumpFee raises a fee by percent, rounding up so the result is accepted
+// whichever way a node rounds its threshold. Nodes also require each fee to
+// be strictly higher than the original's, so the result is at least one wei
+// above it; this is what a zero tip must be raised to.
+func bumpFee(fee *big.Int, percent int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(int64(100+percent)))
+	bumped.Add(bumped, big.NewInt(99))
+	bumped.Div(bumped, big.NewInt(100))
+	if floor := new(big.Int).Add(fee, big.NewInt(1)); bumped.Cmp(floor) < 0 {
+		return floor
+	}
+	return bumped
+}
+
+// percentNeeded returns the smallest whole percentage p with
+// current * (100+p) / 100 >= target. It reports false when current is zero
+// and target is not, since no percentage raises a zero fee.
+func percentNeeded(current, target *big.Int) (int, bool) {
+	if current.Cmp(target) >= 0 {
+		return 0, true
+	}
+	if current.Sign() == 0 {
+		return 0, false
+	}
+	// p = ceil((target - current) * 100 / current)
+	diff := new(big.Int).Sub(target, current)
+	diff.Mul(diff, big.NewInt(100))
+	diff.Add(diff, new(big.Int).Sub(current, big.NewInt(1)))
+	return int(diff.Div(diff, current).Int64()), true
+}
@@ -0,0 +1,181 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"strings"
+	"syscall"
+	"testing"
+
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestBumpFee(t *testing.T) {
+	tests := []struct {
+		fee     int64
+		percent int
+		want    int64
+	}{
+		{1_000_000_000, 10, 1_100_000_000},
+		{1_000_000_001, 10, 1_100_000_002}, // rounded up
+		{0, 10, 1},                         // strictly above a zero fee
+		{1, 10, 2},                         // 1.1 wei rounds up to 2
+		{5, 10, 6},
+		{100, 0, 101}, // no bump still needs one wei more
+	}
+	for _, tt := range tests {
+		if got := bumpFee(big.NewInt(tt.fee), tt.percent); got.Int64() != tt.want {
+			t.Errorf("bumpFee(%d, %d) = %s, want %d", tt.fee, tt.percent, got, tt.want)
+		}
+	}
+}
+
+func TestPercentNeeded(t *testing.T) {
+	tests := []struct {
+		current, target int64
+		want            int
+		wantOK          bool
+	}{
+		{1_100_000_000, 1_100_000_000, 0, true},
+		{2_000_000_000, 1_100_000_000, 0, true},
+		{1_000_000_000, 1_100_000_000, 10, true},
+		{1_000_000_000, 1_100_000_001, 11, true}, // rounded up
+		{1_000_000_000, 2_000_000_000, 100, true},
+		{0, 0, 0, true},
+		{0, 1, 0, false}, // no percentage raises a zero fee
+	}
+	for _, tt := range tests {
+		got, ok := percentNeeded(big.NewInt(tt.current), big.NewInt(tt.target))
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("percentNeeded(%d, %d) = %d, %v; want %d, %v", tt.current, tt.target, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestReplacementUnderpricedFees(t *testing.T) {
+	dynamicTx := func(feeCap, tipCap int64) *coretypes.Transaction {
+		return coretypes.NewTx(&coretypes.DynamicFeeTx{Nonce: 3, GasFeeCap: big.NewInt(feeCap), GasTipCap: big.NewInt(tipCap), Gas: 21000})
+	}
+	tests := []struct {
+		name                 string
+		original, replaced   *coretypes.Transaction
+		wantFeeCap, wantTip  int64
+		wantPercent          int
+		wantPercentOK        bool
+		wantMessageSubstring string
+	}{
+		{
+			name:                 "fees raised too little",
+			original:             dynamicTx(30e9, 2e9),
+			replaced:             dynamicTx(31e9, 2e9),
+			wantFeeCap:           33e9,
+			wantTip:              2.2e9,
+			wantPercent:          10,
+			wantPercentOK:        true,
+			wantMessageSubstring: "at least 10% higher",
+		},
+		{
+			name:                 "zero tip original",
+			original:             dynamicTx(30e9, 0),
+			replaced:             dynamicTx(40e9, 0),
+			wantFeeCap:           33e9,
+			wantTip:              1,
+			wantPercentOK:        false,
+			wantMessageSubstring: "at least those fees",
+		},
+		{
+			name:                 "zero tip replacement of a tipped original",
+			original:             dynamicTx(30e9, 1e9),
+			replaced:             dynamicTx(33e9, 0),
+			wantFeeCap:           33e9,
+			wantTip:              1.1e9,
+			wantPercentOK:        false,
+			wantMessageSubstring: "tip 1.1 gwei",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &ReplacementUnderpricedError{Replacement: tt.replaced, Original: tt.original}
+			feeCap, tipCap := e.MinFees()
+			if feeCap.Int64() != tt.wantFeeCap || tipCap.Int64() != tt.wantTip {
+				t.Errorf("MinFees() = %s, %s; want %d, %d", feeCap, tipCap, tt.wantFeeCap, tt.wantTip)
+			}
+			percent, ok := e.RequiredBumpPercent()
+			if ok != tt.wantPercentOK || (ok && percent != tt.wantPercent) {
+				t.Errorf("RequiredBumpPercent() = %d, %v; want %d, %v", percent, ok, tt.wantPercent, tt.wantPercentOK)
+			}
+			if msg := e.Error(); !strings.Contains(msg, tt.wantMessageSubstring) {
+				t.Errorf("Error() = %q, want it to contain %q", msg, tt.wantMessageSubstring)
+			}
+		})
+	}
+
+	unknown := &ReplacementUnderpricedError{Replacement: dynamicTx(30e9, 1e9)}
+	if feeCap, _ := unknown.MinFees(); feeCap != nil {
+		t.Errorf("MinFees() without the original = %s, want nil", feeCap)
+	}
+	if _, ok := unknown.RequiredBumpPercent(); ok {
+		t.Error("RequiredBumpPercent() without the original reported a percentage")
+	}
+}
+
+// jsonRPCError is a JSON-RPC error as the rpc client returns it, with a code
+// and optional revert data
+type jsonRPCError struct {
+	code int
+	msg  string
+	data interface{}
+}
+
+func (e *jsonRPCError) Error() string          { return e.msg }
+func (e *jsonRPCError) ErrorCode() int         { return e.code }
+func (e *jsonRPCError) ErrorData() interface{} { return e.data }
+
+func TestClassifyRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"rate limit message", errors.New("Rate limit exceeded, retry later"), true},
+		{"too many requests", errors.New("429 Too Many Requests"), true},
+		{"HTTP 429", rpc.HTTPError{StatusCode: 429, Status: "429 Too Many Requests"}, true},
+		{"HTTP 502", rpc.HTTPError{StatusCode: 502, Status: "502 Bad Gateway"}, true},
+		{"HTTP 401", rpc.HTTPError{StatusCode: 401, Status: "401 Unauthorized"}, false},
+		{"limit exceeded code", &jsonRPCError{code: -32005, msg: "request limit reached"}, true},
+		{"deadline exceeded", fmt.Errorf("failed to call: %w", context.DeadlineExceeded), true},
+		{"net timeout", &net.OpError{Op: "dial", Err: timeoutError{}}, true},
+		{"timeout message", errors.New("request timed out"), true},
+		{"connection reset", fmt.Errorf("post: %w", syscall.ECONNRESET), true},
+		{"unexpected EOF", io.ErrUnexpectedEOF, true},
+		{"header not found", errors.New("header not found"), true},
+		{"cancelled", context.Canceled, false},
+		{"nonce too low", &jsonRPCError{code: -32000, msg: "nonce too low: next nonce 5, tx nonce 4"}, false},
+		{"nonce too high", errors.New("nonce too high"), false},
+		{"insufficient funds", errors.New("insufficient funds for gas * price + value"), false},
+		{"revert with data", &jsonRPCError{code: 3, msg: "execution reverted", data: "0x08c379a0"}, false},
+		{"revert with data and a retryable message", &jsonRPCError{code: 3, msg: "execution reverted: rate limit", data: "0x08c379a0"}, false},
+		{"revert message", errors.New("execution reverted: DeadlineNotReached"), false},
+		{"underpriced", errors.New("replacement transaction underpriced"), false},
+		{"unknown", errors.New("something else"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyRetryable(tt.err); got != tt.want {
+				t.Errorf("classifyRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// timeoutError is a net.Error that timed out
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
@@ -0,0 +1,96 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// GasReport compares the plain gas estimate with one using an EIP-2930
+// access list generated by eth_createAccessList
+type GasReport struct {
+	Estimate uint64 // Raw eth_estimateGas result
+	Buffered uint64 // Estimate with the 20% buffer
+
+	// Access-list results are only set when the node supports
+	// eth_createAccessList; AccessListErr explains why they are missing
+	AccessListEstimate uint64
+	AccessListBuffered uint64
+	AccessListEntries  int
+	AccessListErr      error
+}
+
+// GetGasReport estimates gas with and without an access list. Only the plain
+// estimate is required; access-list failures are recorded in the report.
+func GetGasReport(ctx context.Context, client *ethclient.Client, from common.Address, to *common.Address, data []byte, value *big.Int) (*GasReport, error) {
+	msg := ethereum.CallMsg{
+		From:  from,
+		To:    to,
+		Data:  data,
+		Value: value,
+	}
+
+	estimate, err := client.EstimateGas(ctx, msg)
+	if err != nil {
+		return nil, wrapError("gas estimation failed", err)
+	}
+	report := &GasReport{Estimate: estimate, Buffered: withGasBuffer(estimate)}
+
+	accessList, vmErr, err := createAccessList(ctx, client, msg)
+	switch {
+	case err != nil:
+		report.AccessListErr = fmt.Errorf("eth_createAccessList not available: %w", err)
+		return report, nil
+	case vmErr != "":
+		report.AccessListErr = fmt.Errorf("eth_createAccessList execution failed: %s", vmErr)
+		return report, nil
+	case accessList == nil:
+		report.AccessListErr = fmt.Errorf("eth_createAccessList returned no access list")
+		return report, nil
+	}
+
+	msg.AccessList = *accessList
+	withList, err := client.EstimateGas(ctx, msg)
+	if err != nil {
+		report.AccessListErr = fmt.Errorf("gas estimation with access list failed: %w", err)
+		return report, nil
+	}
+	report.AccessListEstimate = withList
+	report.AccessListBuffered = withGasBuffer(withList)
+	report.AccessListEntries = len(*accessList)
+
+	return report, nil
+}
+
+// accessListResult is the response of eth_createAccessList
+type accessListResult struct {
+	AccessList *coretypes.AccessList `json:"accessList"`
+	Error      string                `json:"error,omitempty"`
+}
+
+// createAccessList calls eth_createAccessList directly rather than through
+// gethclient, which would pull geth's node dependencies into the binary
+func createAccessList(ctx context.Context, client *ethclient.Client, msg ethereum.CallMsg) (*coretypes.AccessList, string, error) {
+	arg := map[string]interface{}{
+		"from":  msg.From,
+		"input": hexutil.Bytes(msg.Data),
+	}
+	if msg.To != nil {
+		arg["to"] = msg.To
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+
+	var result accessListResult
+	if err := client.Client().CallContext(ctx, &result, "eth_createAccessList", arg, "latest"); err != nil {
+		return nil, "", err
+	}
+	return result.AccessList, result.Error, nil
+}
@@ -0,0 +1,159 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+func TestMain(m *testing.M) {
+	SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+// rpcError is a JSON-RPC error response returned by a mock handler
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+// mockRPC is a JSON-RPC server answering each method with a fixed result, a
+// *rpcError, or the result of a func(params []json.RawMessage) interface{}
+type mockRPC struct {
+	t       *testing.T
+	server  *httptest.Server
+	mu      sync.Mutex
+	methods map[string]interface{}
+	calls   map[string]int
+}
+
+// newMockRPC starts a mock node, closed when the test ends
+func newMockRPC(t *testing.T, methods map[string]interface{}) *mockRPC {
+	t.Helper()
+	m := &mockRPC{t: t, methods: methods, calls: make(map[string]int)}
+	m.server = httptest.NewServer(http.HandlerFunc(m.serve))
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+// client dials the mock node
+func (m *mockRPC) client() *ethclient.Client {
+	m.t.Helper()
+	client, err := ethclient.Dial(m.server.URL)
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	m.t.Cleanup(client.Close)
+	return client
+}
+
+// set replaces the answer to a method
+func (m *mockRPC) set(method string, answer interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.methods[method] = answer
+}
+
+// count returns how often a method was called
+func (m *mockRPC) count(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[method]
+}
+
+type rpcRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+func (m *mockRPC) serve(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var batch []rpcRequest
+	if err := json.Unmarshal(body, &batch); err == nil {
+		responses := make([]rpcResponse, len(batch))
+		for i, req := range batch {
+			responses[i] = m.answer(req)
+		}
+		json.NewEncoder(w).Encode(responses)
+		return
+	}
+	var req rpcRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(m.answer(req))
+}
+
+func (m *mockRPC) answer(req rpcRequest) rpcResponse {
+	m.mu.Lock()
+	m.calls[req.Method]++
+	answer, ok := m.methods[req.Method]
+	m.mu.Unlock()
+
+	resp := rpcResponse{JSONRPC: "2.0", ID: req.ID}
+	if f, isFunc := answer.(func([]json.RawMessage) interface{}); isFunc {
+		answer = f(req.Params)
+	}
+	switch a := answer.(type) {
+	case nil:
+		if !ok {
+			resp.Error = &rpcError{Code: -32601, Message: fmt.Sprintf("the method %s does not exist/is not available", req.Method)}
+		}
+	case *rpcError:
+		resp.Error = a
+	default:
+		resp.Result = a
+	}
+	return resp
+}
+
+// mockHeader returns a block header with the given number, timestamp and base
+// fee (nil for a pre-London block), all as hex quantities
+func mockHeader(number, timestamp string, baseFee interface{}) map[string]interface{} {
+	zero := "0x" + fmt.Sprintf("%064x", 0)
+	header := map[string]interface{}{
+		"parentHash":       zero,
+		"sha3Uncles":       zero,
+		"miner":            "0x0000000000000000000000000000000000000000",
+		"stateRoot":        zero,
+		"transactionsRoot": zero,
+		"receiptsRoot":     zero,
+		"logsBloom":        "0x" + fmt.Sprintf("%0512x", 0),
+		"difficulty":       "0x0",
+		"number":           number,
+		"gasLimit":         "0x1c9c380",
+		"gasUsed":          "0x0",
+		"timestamp":        timestamp,
+		"extraData":        "0x",
+		"mixHash":          zero,
+		"nonce":            "0x0000000000000000",
+		"hash":             zero,
+	}
+	if baseFee != nil {
+		header["baseFeePerGas"] = baseFee
+	}
+	return header
+}
@@ -2,16 +2,23 @@ package network
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math/big"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	coretypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 var log *slog.Logger
@@ -21,77 +28,327 @@ func SetLogger(logger *slog.Logger) {
 	log = logger
 }
 
-// GetRPCURL returns the RPC URL for a given network name
-func GetRPCURL(network string, infuraAPIKey string) (string, error) {
-	// Check for custom RPC URL first (localhost, custom endpoints)
-	if network == "localhost" || network == "anvil" || network == "hardhat" {
-		return "http://127.0.0.1:8545", nil
+// knownNetwork describes a pre-configured network
+type knownNetwork struct {
+	chainID  uint64
+	explorer string // block explorer base URL
+
+	// confirmations is how many blocks, counting the inclusion block,
+	// broadcast waits for by default
+	confirmations uint64
+}
+
+// knownNetworks maps the pre-configured network names, reached through
+// Infura endpoints of the same name.
+//
+// Confirmation defaults follow each chain's reorg risk. Ethereum
+// proof-of-stake reorgs are rare and shallow, so a few blocks suffice.
+// Polygon PoS has seen much deeper reorgs and produces blocks every two
+// seconds, so it waits longer. The rollups order transactions through a
+// single sequencer and do not reorg their own blocks in normal operation, so
+// inclusion is enough.
+var knownNetworks = map[string]knownNetwork{
+	// Ethereum
+	"mainnet": {1, "https://etherscan.io", 3},
+	"sepolia": {11155111, "https://sepolia.etherscan.io", 2},
+	"holesky": {17000, "https://holesky.etherscan.io", 2},
+
+	// Polygon
+	"polygon-mainnet": {137, "https://polygonscan.com", 32},
+	"polygon-amoy":    {80002, "https://amoy.polygonscan.com", 8},
+
+	// Arbitrum
+	"arbitrum-mainnet": {42161, "https://arbiscan.io", 1},
+	"arbitrum-sepolia": {421614, "https://sepolia.arbiscan.io", 1},
+
+	// Optimism
+	"optimism-mainnet": {10, "https://optimistic.etherscan.io", 1},
+	"optimism-sepolia": {11155420, "https://sepolia-optimism.etherscan.io", 1},
+
+	// Base
+	"base-mainnet": {8453, "https://basescan.org", 1},
+	"base-sepolia": {84532, "https://sepolia.basescan.org", 1},
+
+	// Linea
+	"linea-mainnet": {59144, "https://lineascan.build", 1},
+	"linea-sepolia": {59141, "https://sepolia.lineascan.build", 1},
+}
+
+// lookupChain returns the pre-configured network with a chain ID. Lookups
+// are by chain ID so a known chain reached through a custom RPC URL is
+// recognized too.
+func lookupChain(chainID uint64) (knownNetwork, bool) {
+	for _, n := range knownNetworks {
+		if n.chainID == chainID {
+			return n, true
+		}
 	}
+	return knownNetwork{}, false
+}
 
-	// If Infura key not provided, can't use predefined networks
-	if infuraAPIKey == "" {
-		return "", fmt.Errorf("INFURA_API_KEY required for network %s", network)
+// DefaultConfirmations returns how many blocks broadcast waits for on a
+// chain: the network's default, or 1 (inclusion only) for chains not in the
+// registry, such as local development networks
+func DefaultConfirmations(chainID uint64) uint64 {
+	if n, ok := lookupChain(chainID); ok {
+		return n.confirmations
 	}
+	return 1
+}
+
+// localNetworks are the network names that resolve to a local development
+// node
+var localNetworks = []string{"localhost", "anvil", "hardhat"}
 
-	// Map network names to Infura endpoints
-	networkMap := map[string]string{
-		// Ethereum
-		"mainnet": fmt.Sprintf("https://mainnet.infura.io/v3/%s", infuraAPIKey),
-		"sepolia": fmt.Sprintf("https://sepolia.infura.io/v3/%s", infuraAPIKey),
-		"holesky": fmt.Sprintf("https://holesky.infura.io/v3/%s", infuraAPIKey),
+// isLocalNetwork reports whether a network name resolves to a local
+// development node
+func isLocalNetwork(network string) bool {
+	return slices.Contains(localNetworks, network)
+}
+
+// RequireRPC checks that the named network can be reached without a custom
+// RPC URL: the name must be known, and all but the local development networks
+// need the Infura key. The name is checked first, so a typo is reported with
+// a suggestion rather than as a missing key.
+func RequireRPC(network string, infuraAPIKey string) error {
+	if isLocalNetwork(network) {
+		return nil
+	}
+	if _, exists := knownNetworks[network]; !exists {
+		return unknownNetworkError(network)
+	}
+	if infuraAPIKey == "" {
+		return types.Errorf(types.CodeConfigMissing, "INFURA_API_KEY not set in environment (required for network %s unless --rpc-url is given)", network)
+	}
+	return nil
+}
 
-		// Polygon
-		"polygon-mainnet": fmt.Sprintf("https://polygon-mainnet.infura.io/v3/%s", infuraAPIKey),
-		"polygon-amoy":    fmt.Sprintf("https://polygon-amoy.infura.io/v3/%s", infuraAPIKey),
+// GetRPCURL returns the RPC URL for a given network name, after checking it
+// with RequireRPC
+func GetRPCURL(network string, infuraAPIKey string) (string, error) {
+	if err := RequireRPC(network, infuraAPIKey); err != nil {
+		return "", err
+	}
+	if isLocalNetwork(network) {
+		return "http://127.0.0.1:8545", nil
+	}
+	return fmt.Sprintf("https://%s.infura.io/v3/%s", network, infuraAPIKey), nil
+}
 
-		// Arbitrum
-		"arbitrum-mainnet": fmt.Sprintf("https://arbitrum-mainnet.infura.io/v3/%s", infuraAPIKey),
-		"arbitrum-sepolia": fmt.Sprintf("https://arbitrum-sepolia.infura.io/v3/%s", infuraAPIKey),
+// NetworkNames returns the known network names, including the local ones,
+// in sorted order
+func NetworkNames() []string {
+	names := append([]string(nil), localNetworks...)
+	for name := range knownNetworks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
 
-		// Optimism
-		"optimism-mainnet": fmt.Sprintf("https://optimism-mainnet.infura.io/v3/%s", infuraAPIKey),
-		"optimism-sepolia": fmt.Sprintf("https://optimism-sepolia.infura.io/v3/%s", infuraAPIKey),
+// SuggestNetwork returns the known network name closest to name by edit
+// distance, or "" if none is close enough to be a likely typo. Ties go to
+// the alphabetically first name.
+func SuggestNetwork(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	maxDistance := max(2, len(name)/4)
+	best, bestDistance := "", maxDistance+1
+	for _, candidate := range NetworkNames() {
+		if d := levenshtein(name, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
 
-		// Base
-		"base-mainnet": fmt.Sprintf("https://base-mainnet.infura.io/v3/%s", infuraAPIKey),
-		"base-sepolia": fmt.Sprintf("https://base-sepolia.infura.io/v3/%s", infuraAPIKey),
+// unknownNetworkError reports an unknown network name, suggesting the
+// closest known one when it looks like a typo
+func unknownNetworkError(name string) error {
+	if suggestion := SuggestNetwork(name); suggestion != "" {
+		return types.Errorf(types.CodeConfigInvalid, "unknown network '%s'; did you mean '%s'?", name, suggestion)
+	}
+	return types.Errorf(types.CodeConfigInvalid, "unknown network '%s' (known: %s; or use --rpc-url)", name, strings.Join(NetworkNames(), ", "))
+}
 
-		// Linea
-		"linea-mainnet": fmt.Sprintf("https://linea-mainnet.infura.io/v3/%s", infuraAPIKey),
-		"linea-sepolia": fmt.Sprintf("https://linea-sepolia.infura.io/v3/%s", infuraAPIKey),
+// levenshtein returns the number of single-character insertions, deletions
+// and substitutions that turn a into b
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
 	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
 
-	url, exists := networkMap[network]
-	if !exists {
-		return "", fmt.Errorf("unknown network: %s", network)
+// explorerURL returns the block explorer of a chain, or "" if none is known
+func explorerURL(chainID uint64) string {
+	n, _ := lookupChain(chainID)
+	return n.explorer
+}
+
+// ExplorerTxURL returns the block explorer page of a transaction, or "" for
+// chains without a known explorer (such as local development networks)
+func ExplorerTxURL(chainID uint64, txHash common.Hash) string {
+	if base := explorerURL(chainID); base != "" {
+		return base + "/tx/" + txHash.Hex()
 	}
+	return ""
+}
 
-	return url, nil
+// ExplorerAddressURL returns the block explorer page of an address, or "" for
+// chains without a known explorer
+func ExplorerAddressURL(chainID uint64, address common.Address) string {
+	if base := explorerURL(chainID); base != "" {
+		return base + "/address/" + address.Hex()
+	}
+	return ""
 }
 
-// CreateClient creates an Ethereum RPC client
+// CreateClient creates an Ethereum RPC client. HTTP endpoints go through
+// the rate limit set with SetRateLimit and retry requests rejected with
+// HTTP 429.
 func CreateClient(ctx context.Context, rpcURL string) (*ethclient.Client, error) {
-	client, err := ethclient.DialContext(ctx, rpcURL)
+	var options []rpc.ClientOption
+	if strings.HasPrefix(rpcURL, "http://") || strings.HasPrefix(rpcURL, "https://") {
+		options = append(options, rpc.WithHTTPClient(&http.Client{
+			Transport: &throttledTransport{base: http.DefaultTransport},
+		}))
+	}
+	client, err := rpc.DialOptions(ctx, rpcURL, options...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to RPC: %w", err)
+		return nil, wrapError("failed to connect to RPC", err)
 	}
-	return client, nil
+	return ethclient.NewClient(client), nil
 }
 
 // GetChainID returns the chain ID from the RPC endpoint
 func GetChainID(ctx context.Context, client *ethclient.Client) (uint64, error) {
 	chainID, err := client.ChainID(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get chain ID: %w", err)
+		return 0, wrapError("failed to get chain ID", err)
 	}
 	return chainID.Uint64(), nil
 }
 
+// GetClientVersion returns the node's client version string (web3_clientVersion)
+func GetClientVersion(ctx context.Context, client *ethclient.Client) (string, error) {
+	var version string
+	if err := client.Client().CallContext(ctx, &version, "web3_clientVersion"); err != nil {
+		return "", wrapError("failed to get client version", err)
+	}
+	return version, nil
+}
+
+// DevNodeRPCPrefix returns the RPC namespace of a local development node
+// ("anvil" or "hardhat") detected from its client version, or "" otherwise
+func DevNodeRPCPrefix(clientVersion string) string {
+	v := strings.ToLower(clientVersion)
+	switch {
+	case strings.HasPrefix(v, "anvil"):
+		return "anvil"
+	case strings.HasPrefix(v, "hardhatnetwork"):
+		return "hardhat"
+	}
+	return ""
+}
+
+// GetBalance returns the latest balance of an address
+func GetBalance(ctx context.Context, client *ethclient.Client, address common.Address) (*big.Int, error) {
+	balance, err := client.BalanceAt(ctx, address, nil)
+	if err != nil {
+		return nil, wrapError("failed to get balance", err)
+	}
+	return balance, nil
+}
+
+// GetCode returns the latest deployed code at an address (empty for accounts
+// without code)
+func GetCode(ctx context.Context, client *ethclient.Client, address common.Address) ([]byte, error) {
+	code, err := client.CodeAt(ctx, address, nil)
+	if err != nil {
+		return nil, wrapError("failed to get code", err)
+	}
+	return code, nil
+}
+
+// GetBlockTime returns the timestamp of the latest block
+func GetBlockTime(ctx context.Context, client *ethclient.Client) (time.Time, error) {
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return time.Time{}, wrapError("failed to get latest block", err)
+	}
+	return time.Unix(int64(header.Time), 0), nil
+}
+
+// GetBlockGasLimit returns the gas limit of the latest block
+func GetBlockGasLimit(ctx context.Context, client *ethclient.Client) (uint64, error) {
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, wrapError("failed to get latest block", err)
+	}
+	return header.GasLimit, nil
+}
+
+// GetBaseFee returns the base fee and number of the latest block. The base
+// fee is nil on chains without EIP-1559.
+func GetBaseFee(ctx context.Context, client *ethclient.Client) (*big.Int, uint64, error) {
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, 0, wrapError("failed to get latest block", err)
+	}
+	return header.BaseFee, header.Number.Uint64(), nil
+}
+
+// EnforcesInitcodeLimit reports whether a chain rejects creation
+// transactions whose initcode exceeds the EIP-3860 limit. The pre-configured
+// networks all enforce it. Other chains are judged by their latest block: a
+// withdrawals root means Shanghai, which introduced the limit, is active.
+func EnforcesInitcodeLimit(ctx context.Context, client *ethclient.Client, chainID uint64) (bool, error) {
+	if _, ok := lookupChain(chainID); ok {
+		return true, nil
+	}
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return false, wrapError("failed to get latest block", err)
+	}
+	return header.WithdrawalsHash != nil, nil
+}
+
+// GetBlockNumber returns the number of the latest block
+func GetBlockNumber(ctx context.Context, client *ethclient.Client) (uint64, error) {
+	number, err := client.BlockNumber(ctx)
+	if err != nil {
+		return 0, wrapError("failed to get block number", err)
+	}
+	return number, nil
+}
+
 // GetNonce returns the transaction count (nonce) for an address
 func GetNonce(ctx context.Context, client *ethclient.Client, address common.Address) (uint64, error) {
 	nonce, err := client.PendingNonceAt(ctx, address)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get nonce: %w", err)
+		return 0, wrapError("failed to get nonce", err)
+	}
+	return nonce, nil
+}
+
+// GetConfirmedNonce returns the number of mined transactions of an address
+func GetConfirmedNonce(ctx context.Context, client *ethclient.Client, address common.Address) (uint64, error) {
+	nonce, err := client.NonceAt(ctx, address, nil)
+	if err != nil {
+		return 0, wrapError("failed to get confirmed nonce", err)
 	}
 	return nonce, nil
 }
@@ -104,31 +361,36 @@ type GasPrices struct {
 	IsEIP1559            bool
 }
 
-// GetGasPrices fetches gas prices, preferring EIP-1559 if available
+// MinGasPrice is used when the node suggests a gas price of zero, as some
+// development chains do (1 gwei)
+var MinGasPrice = big.NewInt(1e9)
+
+// GetGasPrices fetches gas prices, preferring EIP-1559 if available. The base
+// fee is read from the latest block header, falling back to fee history for
+// nodes that omit it, and to the legacy gas price when neither has one.
 func GetGasPrices(ctx context.Context, client *ethclient.Client) (*GasPrices, error) {
-	// Try EIP-1559 fee history first
+	// The latest header carries the exact base fee on EIP-1559 chains
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err == nil && header.BaseFee != nil {
+		log.Debug("Using base fee from latest block header", "block", header.Number, "base_fee", header.BaseFee)
+		return baseFeeGasPrices(ctx, client, header.BaseFee)
+	}
+	if err != nil {
+		log.Debug("Failed to get latest block header, trying fee history", "error", err)
+	}
+
+	// Try EIP-1559 fee history next
 	feeHistory, err := client.FeeHistory(ctx, 1, nil, []float64{50})
 	if err == nil && len(feeHistory.BaseFee) > 0 {
 		baseFee := feeHistory.BaseFee[len(feeHistory.BaseFee)-1]
-
-		// Priority fee: 1.5 gwei
-		priorityFee := new(big.Int).Mul(big.NewInt(15), big.NewInt(1e8)) // 1.5 gwei
-
-		// Max fee: 2 * base_fee + priority_fee
-		maxFee := new(big.Int).Mul(baseFee, big.NewInt(2))
-		maxFee.Add(maxFee, priorityFee)
-
-		return &GasPrices{
-			MaxFeePerGas:         maxFee,
-			MaxPriorityFeePerGas: priorityFee,
-			IsEIP1559:            true,
-		}, nil
+		log.Debug("Using base fee from fee history", "base_fee", baseFee)
+		return baseFeeGasPrices(ctx, client, baseFee)
 	}
 
 	// Fallback to legacy gas price
-	gasPrice, err := client.SuggestGasPrice(ctx)
+	gasPrice, err := suggestGasPrice(ctx, client)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get gas price: %w", err)
+		return nil, err
 	}
 
 	return &GasPrices{
@@ -137,6 +399,52 @@ func GetGasPrices(ctx context.Context, client *ethclient.Client) (*GasPrices, er
 	}, nil
 }
 
+// baseFeeGasPrices derives EIP-1559 fees from a reported base fee. Some chains
+// and L2s report a zero base fee, which would cap the max fee at the priority
+// fee alone; the node's suggested gas price then stands in for the base fee.
+func baseFeeGasPrices(ctx context.Context, client *ethclient.Client, baseFee *big.Int) (*GasPrices, error) {
+	if baseFee.Sign() > 0 {
+		return eip1559GasPrices(baseFee), nil
+	}
+
+	gasPrice, err := suggestGasPrice(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	log.Warn("Node reported a zero base fee; using the suggested gas price in its place", "gas_price", gasPrice)
+	return eip1559GasPrices(gasPrice), nil
+}
+
+// suggestGasPrice returns the node's suggested gas price, raised to
+// MinGasPrice when the node suggests zero
+func suggestGasPrice(ctx context.Context, client *ethclient.Client) (*big.Int, error) {
+	gasPrice, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, wrapError("failed to get gas price", err)
+	}
+	if gasPrice.Sign() <= 0 {
+		log.Warn("Node suggested a zero gas price; using the minimum", "min_gas_price", MinGasPrice)
+		return new(big.Int).Set(MinGasPrice), nil
+	}
+	return gasPrice, nil
+}
+
+// eip1559GasPrices derives EIP-1559 fees from a base fee
+func eip1559GasPrices(baseFee *big.Int) *GasPrices {
+	// Priority fee: 1.5 gwei
+	priorityFee := new(big.Int).Mul(big.NewInt(15), big.NewInt(1e8)) // 1.5 gwei
+
+	// Max fee: 2 * base_fee + priority_fee
+	maxFee := new(big.Int).Mul(baseFee, big.NewInt(2))
+	maxFee.Add(maxFee, priorityFee)
+
+	return &GasPrices{
+		MaxFeePerGas:         maxFee,
+		MaxPriorityFeePerGas: priorityFee,
+		IsEIP1559:            true,
+	}
+}
+
 // EstimateGas estimates gas for a transaction with 20% buffer
 func EstimateGas(ctx context.Context, client *ethclient.Client, from common.Address, to *common.Address, data []byte, value *big.Int) (*big.Int, error) {
 	msg := ethereum.CallMsg{
@@ -153,17 +461,64 @@ func EstimateGas(ctx context.Context, client *ethclient.Client, from common.Addr
 		if callErr != nil {
 			// Try to decode the error
 			if len(callErr.Error()) > 0 {
-				return nil, fmt.Errorf("gas estimation failed: %s", callErr.Error())
+				return nil, wrapError("gas estimation failed", callErr)
 			}
 		}
-		return nil, fmt.Errorf("gas estimation failed: %w", err)
+		return nil, wrapError("gas estimation failed", err)
 	}
 
-	// Add 20% buffer for safety
-	buffer := new(big.Int).Div(big.NewInt(int64(gasLimit)), big.NewInt(5))
-	gasWithBuffer := new(big.Int).Add(big.NewInt(int64(gasLimit)), buffer)
+	return new(big.Int).SetUint64(withGasBuffer(gasLimit)), nil
+}
+
+// withGasBuffer adds the 20% safety buffer to a gas estimate
+func withGasBuffer(gas uint64) uint64 {
+	return gas + gas/5
+}
+
+// CallContract executes a read-only call against the latest block
+func CallContract(ctx context.Context, client *ethclient.Client, to common.Address, data []byte) ([]byte, error) {
+	return CallContractAt(ctx, client, to, data, nil)
+}
+
+// CallContractAt is CallContract against the state at a past block (nil for
+// the latest). Nodes without archive state may refuse old blocks.
+func CallContractAt(ctx context.Context, client *ethclient.Client, to common.Address, data []byte, block *big.Int) ([]byte, error) {
+	msg := ethereum.CallMsg{
+		To:   &to,
+		Data: data,
+	}
 
-	return gasWithBuffer, nil
+	result, err := client.CallContract(ctx, msg, block)
+	if err != nil {
+		return nil, wrapError("contract call failed", err)
+	}
+	return result, nil
+}
+
+// SimulateTransaction runs a transaction's call with eth_call against the
+// latest block, from the given sender, without submitting it. On a revert the
+// error is returned together with the revert data, if the node provides it.
+func SimulateTransaction(ctx context.Context, client *ethclient.Client, from common.Address, tx *coretypes.Transaction) ([]byte, error) {
+	msg := ethereum.CallMsg{
+		From:       from,
+		To:         tx.To(),
+		Gas:        tx.Gas(),
+		Value:      tx.Value(),
+		Data:       tx.Data(),
+		AccessList: tx.AccessList(),
+	}
+
+	if _, err := client.CallContract(ctx, msg, nil); err != nil {
+		var revertData []byte
+		var dataErr rpc.DataError
+		if errors.As(err, &dataErr) {
+			if s, ok := dataErr.ErrorData().(string); ok {
+				revertData, _ = hexutil.Decode(s)
+			}
+		}
+		return revertData, wrapError("simulation failed", err)
+	}
+	return nil, nil
 }
 
 // BroadcastTransaction broadcasts a signed raw transaction
@@ -174,7 +529,7 @@ func BroadcastTransaction(ctx context.Context, client *ethclient.Client, signedT
 	}
 
 	if err := client.SendTransaction(ctx, tx); err != nil {
-		return common.Hash{}, fmt.Errorf("failed to broadcast transaction: %w", err)
+		return common.Hash{}, wrapError("failed to broadcast transaction", err)
 	}
 
 	return tx.Hash(), nil
@@ -184,44 +539,45 @@ func BroadcastTransaction(ctx context.Context, client *ethclient.Client, signedT
 func GetTransaction(ctx context.Context, client *ethclient.Client, txHash common.Hash) (*coretypes.Transaction, bool, error) {
 	tx, isPending, err := client.TransactionByHash(ctx, txHash)
 	if err != nil {
-		return nil, false, err
+		return nil, false, wrapError("failed to get transaction", err)
 	}
 	return tx, isPending, nil
 }
 
-// WaitForReceipt polls for a transaction receipt with timeout
-func WaitForReceipt(ctx context.Context, client *ethclient.Client, txHash common.Hash) (*types.TxReceipt, error) {
+// WaitForReceipt polls for a transaction receipt with timeout. When
+// watchBlocks is set, the receipt is only requested again after the block
+// number has advanced past the last block checked, which avoids redundant
+// receipt lookups on HTTP endpoints.
+func WaitForReceipt(ctx context.Context, client *ethclient.Client, txHash common.Hash, watchBlocks bool) (*types.TxReceipt, error) {
 	timeout := 5 * time.Minute
 	interval := 5 * time.Second
 	deadline := time.Now().Add(timeout)
 
 	log.Info("Waiting for transaction to be mined", "hash", txHash.Hex())
 
+	// Block number at broadcast time; later checks only run for newer heads
+	var lastBlock uint64
+	if watchBlocks {
+		startBlock, err := client.BlockNumber(ctx)
+		if err != nil {
+			log.Warn("Failed to get block number, polling receipt every interval", "error", err)
+			watchBlocks = false
+		} else {
+			lastBlock = startBlock
+			log.Debug("Watching for new blocks", "from_block", startBlock)
+		}
+	}
+
 	lastLog := time.Now()
+	checkReceipt := true // Always check once, the transaction may already be mined
 	for time.Now().Before(deadline) {
-		receipt, err := client.TransactionReceipt(ctx, txHash)
-		if err == nil {
-			// Receipt found
-			log.Info("Transaction mined", "block", receipt.BlockNumber.Uint64())
-
-			var contractAddr *common.Address
-			if receipt.ContractAddress != (common.Address{}) {
-				contractAddr = &receipt.ContractAddress
-			}
-
-			txReceipt := &types.TxReceipt{
-				TransactionHash: receipt.TxHash,
-				BlockNumber:     receipt.BlockNumber.Uint64(),
-				BlockHash:       receipt.BlockHash.Hex(),
-				From:            common.Address{}, // Not available in receipt
-				To:              nil,              // Not available in receipt
-				GasUsed:         fmt.Sprintf("%d", receipt.GasUsed),
-				Status:          receipt.Status,
-				ContractAddress: contractAddr,
-				Metadata:        make(map[string]interface{}),
+		if checkReceipt {
+			receipt, err := client.TransactionReceipt(ctx, txHash)
+			if err == nil {
+				// Receipt found
+				log.Info("Transaction mined", "block", receipt.BlockNumber.Uint64())
+				return ToTxReceipt(receipt), nil
 			}
-
-			return txReceipt, nil
 		}
 
 		// Log progress every 30 seconds
@@ -232,20 +588,139 @@ func WaitForReceipt(ctx context.Context, client *ethclient.Client, txHash common
 
 		// Wait before next poll
 		time.Sleep(interval)
+
+		checkReceipt = true
+		if watchBlocks {
+			head, err := client.BlockNumber(ctx)
+			if err == nil {
+				checkReceipt = head > lastBlock
+				if checkReceipt {
+					log.Debug("New block", "block", head)
+					lastBlock = head
+				}
+			}
+		}
 	}
 
 	return nil, fmt.Errorf("timeout waiting for transaction receipt after %v", timeout)
 }
 
-// FormatEth converts wei to ETH string with 6 decimal places
-func FormatEth(wei *big.Int) string {
-	if wei == nil {
-		return "0"
+// Polling cadence and time limit of WaitForConfirmations; variables so tests
+// can shorten them
+var (
+	confirmationPollInterval = 5 * time.Second
+	confirmationTimeout      = func(confirmations uint64) time.Duration {
+		return 5*time.Minute + time.Duration(confirmations)*30*time.Second
 	}
+)
 
-	// Convert to float for display (wei to ETH = / 1e18)
-	weiFloat := new(big.Float).SetInt(wei)
-	ethFloat := new(big.Float).Quo(weiFloat, big.NewFloat(1e18))
+// InsufficientConfirmationsError is returned, together with the receipt, when
+// a mined transaction did not reach the requested depth before
+// WaitForConfirmations gave up
+type InsufficientConfirmationsError struct {
+	TxHash  common.Hash
+	Block   uint64
+	Wanted  uint64
+	Reached uint64
+	Err     error // why the wait stopped: a timeout or the context's error
+}
+
+func (e *InsufficientConfirmationsError) Error() string {
+	return fmt.Sprintf("transaction %s was mined in block %d but reached only %d of %d confirmations: %v",
+		e.TxHash.Hex(), e.Block, e.Reached, e.Wanted, e.Err)
+}
+
+func (e *InsufficientConfirmationsError) Unwrap() error {
+	return e.Err
+}
 
-	return fmt.Sprintf("%.6f ETH", ethFloat)
+// WaitForConfirmations waits until the block that included a transaction is
+// confirmations blocks deep (1 is the inclusion block itself), then reads the
+// receipt again. A receipt from a different block means the transaction was
+// reorganized into another block; it is reported and the wait starts over
+// from that block. A missing receipt means it was dropped from the chain.
+//
+// If the wait times out or ctx is cancelled first, the receipt is returned
+// along with an *InsufficientConfirmationsError, so the caller can still
+// record the mined transaction.
+func WaitForConfirmations(ctx context.Context, client *ethclient.Client, receipt *types.TxReceipt, confirmations uint64) (*types.TxReceipt, error) {
+	if confirmations <= 1 {
+		return receipt, nil
+	}
+	target := receipt.BlockNumber + confirmations - 1
+	timeout := confirmationTimeout(confirmations)
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(confirmationPollInterval)
+	defer ticker.Stop()
+
+	reached := uint64(1)
+	insufficient := func(err error) (*types.TxReceipt, error) {
+		return receipt, &InsufficientConfirmationsError{
+			TxHash:  receipt.TransactionHash,
+			Block:   receipt.BlockNumber,
+			Wanted:  confirmations,
+			Reached: reached,
+			Err:     err,
+		}
+	}
+
+	log.Info("Waiting for confirmations", "confirmations", confirmations, "target_block", target)
+	for {
+		head, err := client.BlockNumber(ctx)
+		if err != nil {
+			log.Debug("Failed to get block number", "error", err)
+		} else {
+			if head >= receipt.BlockNumber {
+				reached = min(head-receipt.BlockNumber+1, confirmations)
+			}
+			if head >= target {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return insufficient(ctx.Err())
+		case <-deadline.C:
+			return insufficient(fmt.Errorf("timed out after %v", timeout))
+		case <-ticker.C:
+		}
+	}
+
+	fresh, err := client.TransactionReceipt(ctx, receipt.TransactionHash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, fmt.Errorf("transaction %s was reorganized out of block %d before %d confirmations", receipt.TransactionHash.Hex(), receipt.BlockNumber, confirmations)
+	}
+	if err != nil {
+		return nil, wrapError("failed to get transaction receipt", err)
+	}
+	confirmed := ToTxReceipt(fresh)
+	if confirmed.BlockHash != receipt.BlockHash {
+		log.Warn("⚠ Transaction was reorganized into a different block; waiting again",
+			"old_block", receipt.BlockNumber, "new_block", confirmed.BlockNumber)
+		return WaitForConfirmations(ctx, client, confirmed, confirmations)
+	}
+	log.Info("✓ Transaction confirmed", "confirmations", confirmations, "block", confirmed.BlockNumber)
+	return confirmed, nil
+}
+
+// ToTxReceipt converts a go-ethereum receipt into the tool's receipt format
+func ToTxReceipt(receipt *coretypes.Receipt) *types.TxReceipt {
+	var contractAddr *common.Address
+	if receipt.ContractAddress != (common.Address{}) {
+		contractAddr = &receipt.ContractAddress
+	}
+
+	return &types.TxReceipt{
+		TransactionHash: receipt.TxHash,
+		BlockNumber:     receipt.BlockNumber.Uint64(),
+		BlockHash:       receipt.BlockHash.Hex(),
+		From:            common.Address{}, // Not available in receipt
+		To:              nil,              // Not available in receipt
+		GasUsed:         fmt.Sprintf("%d", receipt.GasUsed),
+		Status:          receipt.Status,
+		ContractAddress: contractAddr,
+		Metadata:        make(map[string]interface{}),
+		Logs:            receipt.Logs,
+	}
 }
@@ -0,0 +1,384 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestGetGasPricesZeroBaseFee(t *testing.T) {
+	tests := []struct {
+		name        string
+		methods     map[string]interface{}
+		wantEIP1559 bool
+		wantMaxFee  int64 // EIP-1559 max fee, or the legacy gas price
+	}{
+		{
+			name: "base fee from the header",
+			methods: map[string]interface{}{
+				"eth_getBlockByNumber": mockHeader("0x10", "0x1", "0x3b9aca00"), // 1 gwei
+			},
+			wantEIP1559: true,
+			wantMaxFee:  2*1e9 + 15e8,
+		},
+		{
+			name: "zero base fee in the header falls back to the suggested gas price",
+			methods: map[string]interface{}{
+				"eth_getBlockByNumber": mockHeader("0x10", "0x1", "0x0"),
+				"eth_gasPrice":         "0x77359400", // 2 gwei
+			},
+			wantEIP1559: true,
+			wantMaxFee:  2*2e9 + 15e8,
+		},
+		{
+			name: "zero base fee in the fee history falls back to the suggested gas price",
+			methods: map[string]interface{}{
+				"eth_getBlockByNumber": mockHeader("0x10", "0x1", nil),
+				"eth_feeHistory": map[string]interface{}{
+					"oldestBlock":   "0x10",
+					"baseFeePerGas": []string{"0x0", "0x0"},
+					"gasUsedRatio":  []float64{0},
+					"reward":        [][]string{{"0x0"}},
+				},
+				"eth_gasPrice": "0x3b9aca00",
+			},
+			wantEIP1559: true,
+			wantMaxFee:  2*1e9 + 15e8,
+		},
+		{
+			name: "zero base fee and a zero suggested gas price use the minimum",
+			methods: map[string]interface{}{
+				"eth_getBlockByNumber": mockHeader("0x10", "0x1", "0x0"),
+				"eth_gasPrice":         "0x0",
+			},
+			wantEIP1559: true,
+			wantMaxFee:  2*MinGasPrice.Int64() + 15e8,
+		},
+		{
+			name: "legacy chain with a zero suggested gas price uses the minimum",
+			methods: map[string]interface{}{
+				"eth_getBlockByNumber": mockHeader("0x10", "0x1", nil),
+				"eth_gasPrice":         "0x0",
+			},
+			wantMaxFee: MinGasPrice.Int64(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newMockRPC(t, tt.methods).client()
+			prices, err := GetGasPrices(context.Background(), client)
+			if err != nil {
+				t.Fatalf("GetGasPrices() error = %v", err)
+			}
+			if prices.IsEIP1559 != tt.wantEIP1559 {
+				t.Fatalf("IsEIP1559 = %v, want %v", prices.IsEIP1559, tt.wantEIP1559)
+			}
+			got := prices.GasPrice
+			if prices.IsEIP1559 {
+				got = prices.MaxFeePerGas
+			}
+			if got.Cmp(big.NewInt(tt.wantMaxFee)) != 0 {
+				t.Errorf("fee = %s, want %d", got, tt.wantMaxFee)
+			}
+			if got.Sign() <= 0 {
+				t.Errorf("fee must be positive, got %s", got)
+			}
+		})
+	}
+}
+
+func TestGetGasPricesSource(t *testing.T) {
+	feeHistory := func(baseFee string) map[string]interface{} {
+		return map[string]interface{}{
+			"oldestBlock":   "0x10",
+			"baseFeePerGas": []string{baseFee, baseFee},
+			"gasUsedRatio":  []float64{0.5},
+			"reward":        [][]string{{"0x0"}},
+		}
+	}
+	tests := []struct {
+		name           string
+		methods        map[string]interface{}
+		wantEIP1559    bool
+		wantFee        int64 // EIP-1559 max fee, or the legacy gas price
+		wantFeeHistory bool  // whether eth_feeHistory is consulted
+	}{
+		{
+			name: "header base fee is preferred over the fee history",
+			methods: map[string]interface{}{
+				"eth_getBlockByNumber": mockHeader("0x10", "0x1", "0x3b9aca00"), // 1 gwei
+				"eth_feeHistory":       feeHistory("0x12a05f200"),               // 5 gwei
+			},
+			wantEIP1559: true,
+			wantFee:     2*1e9 + 15e8,
+		},
+		{
+			name: "header base fee is used when the fee history RPC fails",
+			methods: map[string]interface{}{
+				"eth_getBlockByNumber": mockHeader("0x10", "0x1", "0x3b9aca00"),
+				"eth_feeHistory":       &rpcError{Code: -32000, Message: "fee history not supported"},
+			},
+			wantEIP1559: true,
+			wantFee:     2*1e9 + 15e8,
+		},
+		{
+			name: "fee history is used when the header request fails",
+			methods: map[string]interface{}{
+				"eth_getBlockByNumber": &rpcError{Code: -32000, Message: "header not available"},
+				"eth_feeHistory":       feeHistory("0x12a05f200"),
+			},
+			wantEIP1559:    true,
+			wantFee:        2*5e9 + 15e8,
+			wantFeeHistory: true,
+		},
+		{
+			name: "fee history is used when the header has no base fee",
+			methods: map[string]interface{}{
+				"eth_getBlockByNumber": mockHeader("0x10", "0x1", nil),
+				"eth_feeHistory":       feeHistory("0x12a05f200"),
+			},
+			wantEIP1559:    true,
+			wantFee:        2*5e9 + 15e8,
+			wantFeeHistory: true,
+		},
+		{
+			name: "legacy gas price without a base fee anywhere",
+			methods: map[string]interface{}{
+				"eth_getBlockByNumber": mockHeader("0x10", "0x1", nil),
+				"eth_feeHistory":       &rpcError{Code: -32601, Message: "method not found"},
+				"eth_gasPrice":         "0x4a817c800", // 20 gwei
+			},
+			wantFee:        20e9,
+			wantFeeHistory: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := newMockRPC(t, tt.methods)
+			prices, err := GetGasPrices(context.Background(), node.client())
+			if err != nil {
+				t.Fatalf("GetGasPrices() error = %v", err)
+			}
+			if prices.IsEIP1559 != tt.wantEIP1559 {
+				t.Fatalf("IsEIP1559 = %v, want %v", prices.IsEIP1559, tt.wantEIP1559)
+			}
+			got := prices.GasPrice
+			if prices.IsEIP1559 {
+				got = prices.MaxFeePerGas
+			}
+			if got.Cmp(big.NewInt(tt.wantFee)) != 0 {
+				t.Errorf("fee = %s, want %d", got, tt.wantFee)
+			}
+			if called := node.count("eth_feeHistory") > 0; called != tt.wantFeeHistory {
+				t.Errorf("eth_feeHistory called = %v, want %v", called, tt.wantFeeHistory)
+			}
+		})
+	}
+}
+
+func TestDefaultConfirmations(t *testing.T) {
+	tests := []struct {
+		chainID uint64
+		want    uint64
+	}{
+		{1, 3},         // Ethereum mainnet
+		{11155111, 2},  // Sepolia
+		{137, 32},      // Polygon PoS
+		{80002, 8},     // Polygon Amoy
+		{42161, 1},     // Arbitrum One
+		{10, 1},        // Optimism
+		{8453, 1},      // Base
+		{31337, 1},     // local development chain, not in the registry
+		{999999999, 1}, // unknown chain
+	}
+	for _, tt := range tests {
+		if got := DefaultConfirmations(tt.chainID); got != tt.want {
+			t.Errorf("DefaultConfirmations(%d) = %d, want %d", tt.chainID, got, tt.want)
+		}
+	}
+}
+
+// shortConfirmationWait makes WaitForConfirmations poll every millisecond and
+// give up after timeout, for the duration of the test
+func shortConfirmationWait(t *testing.T, timeout time.Duration) {
+	t.Helper()
+	interval, limit := confirmationPollInterval, confirmationTimeout
+	confirmationPollInterval = time.Millisecond
+	confirmationTimeout = func(uint64) time.Duration { return timeout }
+	t.Cleanup(func() {
+		confirmationPollInterval, confirmationTimeout = interval, limit
+	})
+}
+
+// mockReceipt returns a successful transaction receipt as a node encodes it
+func mockReceipt(txHash common.Hash, block uint64, blockHash common.Hash) map[string]interface{} {
+	return map[string]interface{}{
+		"transactionHash":   txHash.Hex(),
+		"transactionIndex":  "0x0",
+		"blockHash":         blockHash.Hex(),
+		"blockNumber":       fmt.Sprintf("0x%x", block),
+		"cumulativeGasUsed": "0x5208",
+		"gasUsed":           "0x5208",
+		"effectiveGasPrice": "0x1",
+		"logs":              []interface{}{},
+		"logsBloom":         "0x" + fmt.Sprintf("%0512x", 0),
+		"status":            "0x1",
+		"type":              "0x2",
+	}
+}
+
+func TestWaitForConfirmations(t *testing.T) {
+	txHash := common.HexToHash("0xaa")
+	blockHash := common.HexToHash("0xbb")
+	mined := &types.TxReceipt{TransactionHash: txHash, BlockNumber: 100, BlockHash: blockHash.Hex()}
+
+	t.Run("inclusion only does not poll", func(t *testing.T) {
+		node := newMockRPC(t, map[string]interface{}{})
+		got, err := WaitForConfirmations(context.Background(), node.client(), mined, 1)
+		if err != nil || got != mined {
+			t.Fatalf("WaitForConfirmations() = %v, %v; want the receipt unchanged", got, err)
+		}
+		if n := node.count("eth_blockNumber"); n != 0 {
+			t.Errorf("eth_blockNumber called %d times, want 0", n)
+		}
+	})
+
+	t.Run("confirmed once the head reaches the target", func(t *testing.T) {
+		shortConfirmationWait(t, time.Minute)
+		var head atomic.Uint64
+		head.Store(100)
+		node := newMockRPC(t, map[string]interface{}{
+			"eth_blockNumber": func([]json.RawMessage) interface{} {
+				return fmt.Sprintf("0x%x", head.Add(1)-1)
+			},
+			"eth_getTransactionReceipt": mockReceipt(txHash, 100, blockHash),
+		})
+		got, err := WaitForConfirmations(context.Background(), node.client(), mined, 3)
+		if err != nil {
+			t.Fatalf("WaitForConfirmations() error = %v", err)
+		}
+		if got.BlockNumber != 100 || got.BlockHash != blockHash.Hex() {
+			t.Errorf("receipt from block %d (%s), want block 100 (%s)", got.BlockNumber, got.BlockHash, blockHash.Hex())
+		}
+		if n := node.count("eth_blockNumber"); n != 3 {
+			t.Errorf("eth_blockNumber called %d times, want 3 (heads 100, 101, 102)", n)
+		}
+	})
+
+	t.Run("timeout after mining returns the receipt and the shortfall", func(t *testing.T) {
+		shortConfirmationWait(t, 20*time.Millisecond)
+		node := newMockRPC(t, map[string]interface{}{
+			"eth_blockNumber": "0x65", // 101: two of three confirmations
+		})
+		got, err := WaitForConfirmations(context.Background(), node.client(), mined, 3)
+		if got != mined {
+			t.Errorf("receipt = %v, want the mined receipt", got)
+		}
+		var insufficient *InsufficientConfirmationsError
+		if !errors.As(err, &insufficient) {
+			t.Fatalf("error = %v, want an InsufficientConfirmationsError", err)
+		}
+		if insufficient.Reached != 2 || insufficient.Wanted != 3 || insufficient.Block != 100 {
+			t.Errorf("reached %d of %d in block %d, want 2 of 3 in block 100",
+				insufficient.Reached, insufficient.Wanted, insufficient.Block)
+		}
+		if node.count("eth_getTransactionReceipt") != 0 {
+			t.Error("the receipt was re-read before the target depth")
+		}
+	})
+
+	t.Run("cancellation stops the wait", func(t *testing.T) {
+		shortConfirmationWait(t, time.Minute)
+		ctx, cancel := context.WithCancel(context.Background())
+		node := newMockRPC(t, map[string]interface{}{
+			"eth_blockNumber": func([]json.RawMessage) interface{} {
+				cancel()
+				return "0x64"
+			},
+		})
+		done := make(chan error, 1)
+		go func() {
+			_, err := WaitForConfirmations(ctx, node.client(), mined, 3)
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			var insufficient *InsufficientConfirmationsError
+			if !errors.As(err, &insufficient) || !errors.Is(err, context.Canceled) {
+				t.Errorf("error = %v, want an InsufficientConfirmationsError wrapping context.Canceled", err)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("WaitForConfirmations did not return after cancellation")
+		}
+	})
+
+	t.Run("a vanished receipt is a reorg", func(t *testing.T) {
+		shortConfirmationWait(t, time.Minute)
+		node := newMockRPC(t, map[string]interface{}{
+			"eth_blockNumber":           "0x66",
+			"eth_getTransactionReceipt": nil,
+		})
+		got, err := WaitForConfirmations(context.Background(), node.client(), mined, 3)
+		if err == nil || got != nil {
+			t.Fatalf("WaitForConfirmations() = %v, %v; want a reorg error", got, err)
+		}
+		var insufficient *InsufficientConfirmationsError
+		if errors.As(err, &insufficient) {
+			t.Errorf("error = %v, want a reorg error rather than a shortfall", err)
+		}
+	})
+}
+
+func TestRequireRPC(t *testing.T) {
+	tests := []struct {
+		network  string
+		key      string
+		wantCode types.ErrorCode // "" for no error
+		wantErr  string
+		wantURL  string
+	}{
+		{"localhost", "", "", "", "http://127.0.0.1:8545"},
+		{"anvil", "", "", "", "http://127.0.0.1:8545"},
+		{"hardhat", "", "", "", "http://127.0.0.1:8545"},
+		{"mainnet", "key", "", "", "https://mainnet.infura.io/v3/key"},
+		{"mainnet", "", types.CodeConfigMissing, "INFURA_API_KEY not set", ""},
+		// A typo is reported as such even without a key
+		{"mainet", "", types.CodeConfigInvalid, "did you mean 'mainnet'?", ""},
+		{"locahost", "", types.CodeConfigInvalid, "did you mean 'localhost'?", ""},
+		{"ropsten-classic", "key", types.CodeConfigInvalid, "unknown network 'ropsten-classic' (known:", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.network+"/"+tt.key, func(t *testing.T) {
+			err := RequireRPC(tt.network, tt.key)
+			url, urlErr := GetRPCURL(tt.network, tt.key)
+			if tt.wantCode == "" {
+				if err != nil || urlErr != nil {
+					t.Fatalf("RequireRPC() = %v, GetRPCURL() error = %v; want no errors", err, urlErr)
+				}
+				if url != tt.wantURL {
+					t.Errorf("GetRPCURL() = %s, want %s", url, tt.wantURL)
+				}
+				return
+			}
+			if code := types.CodeOf(err); code != tt.wantCode {
+				t.Errorf("RequireRPC() code = %s, want %s (error %v)", code, tt.wantCode, err)
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("RequireRPC() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+			if urlErr == nil || urlErr.Error() != err.Error() {
+				t.Errorf("GetRPCURL() error = %v, want the RequireRPC error", urlErr)
+			}
+		})
+	}
+}
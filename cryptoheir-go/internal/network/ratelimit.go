@@ -0,0 +1,150 @@
+package network
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultRPS is the default RPC request rate for commands that make many
+// calls in a row, such as a batch broadcast. It stays well below the
+// per-second limits of the free tiers of common providers.
+const DefaultRPS = 5
+
+// rateLimitRetries is how often a request rejected with HTTP 429 is retried
+// before the error is returned
+const rateLimitRetries = 4
+
+// rateLimitMaxDelay caps the wait before retrying a rate-limited request,
+// including a Retry-After the provider asked for
+const rateLimitMaxDelay = 30 * time.Second
+
+var (
+	limiterMu sync.Mutex
+	limiter   *rateLimiter
+)
+
+// SetRateLimit limits the HTTP RPC requests of clients created afterwards to
+// rps per second, shared across all of them. Zero or less disables it.
+func SetRateLimit(rps float64) {
+	limiterMu.Lock()
+	defer limiterMu.Unlock()
+	if rps <= 0 {
+		limiter = nil
+		return
+	}
+	limiter = newRateLimiter(rps)
+}
+
+func currentLimiter() *rateLimiter {
+	limiterMu.Lock()
+	defer limiterMu.Unlock()
+	return limiter
+}
+
+// rateLimiter is a token bucket: it holds up to burst tokens, refilled at
+// rate per second, and each request takes one. Requests beyond the bucket
+// reserve future tokens, so waiting requests are spaced 1/rate apart.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter returns a full bucket allowing bursts of up to one second's
+// worth of requests
+func newRateLimiter(rps float64) *rateLimiter {
+	burst := math.Max(1, math.Floor(rps))
+	return &rateLimiter{rate: rps, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// reserve takes a token and returns how long to wait until it is available
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// Wait blocks until the request may be sent or ctx is done
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	return sleepContext(ctx, l.reserve())
+}
+
+// sleepContext sleeps for d, returning early with the context's error
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// throttledTransport spaces out HTTP RPC requests with the shared limiter (if
+// set) and retries requests the provider rejected with HTTP 429, backing off
+// exponentially or as long as its Retry-After asks. A rejected request was
+// not processed, so sending it again is safe.
+type throttledTransport struct {
+	base http.RoundTripper
+}
+
+func (t *throttledTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		if l := currentLimiter(); l != nil {
+			if err := l.Wait(req.Context()); err != nil {
+				return nil, err
+			}
+		}
+
+		r := req
+		if attempt > 0 {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(req.Context())
+			r.Body = body
+		}
+		resp, err := t.base.RoundTrip(r)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests ||
+			attempt == rateLimitRetries || req.GetBody == nil {
+			return resp, err
+		}
+
+		delay := retryAfter(resp, backoff)
+		resp.Body.Close()
+		log.Warn("⚠ RPC rate limit hit (HTTP 429); backing off", "retry_in", delay, "retry", attempt+1, "of", rateLimitRetries)
+		if err := sleepContext(req.Context(), delay); err != nil {
+			return nil, err
+		}
+		backoff *= 2
+	}
+}
+
+// retryAfter returns the delay a 429 response asks for in its Retry-After
+// header (in seconds), or fallback, capped at rateLimitMaxDelay
+func retryAfter(resp *http.Response, fallback time.Duration) time.Duration {
+	delay := fallback
+	if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s > 0 {
+		delay = time.Duration(s) * time.Second
+	}
+	return min(delay, rateLimitMaxDelay)
+}
@@ -0,0 +1,99 @@
+package network
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterSpacing(t *testing.T) {
+	tests := []struct {
+		name      string
+		rps       float64
+		immediate int           // requests served from the full bucket
+		spacing   time.Duration // between the requests after that
+	}{
+		{"whole rate", 10, 10, 100 * time.Millisecond},
+		{"fractional rate", 2.5, 2, 400 * time.Millisecond},
+		{"below one per second", 0.5, 1, 2 * time.Second},
+	}
+	const tolerance = 20 * time.Millisecond
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newRateLimiter(tt.rps)
+			for i := 0; i < tt.immediate; i++ {
+				if d := l.reserve(); d != 0 {
+					t.Fatalf("request %d waits %v, want none within the burst", i+1, d)
+				}
+			}
+			// Requests beyond the burst queue up, each one spacing later
+			for i := 1; i <= 3; i++ {
+				want := time.Duration(i) * tt.spacing
+				if d := l.reserve(); d < want-tolerance || d > want+tolerance {
+					t.Errorf("request %d after the burst waits %v, want %v", i, d, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	l := newRateLimiter(20)
+	start := time.Now()
+	for i := 0; i < 30; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 20 from the bucket, then 10 spaced 50ms apart
+	if elapsed := time.Since(start); elapsed < 450*time.Millisecond {
+		t.Errorf("30 requests at 20/s took %v, want at least 500ms", elapsed)
+	}
+
+	// A long-idle bucket refills to its burst, not beyond
+	l.last = time.Now().Add(-time.Hour)
+	for i := 0; i < 20; i++ {
+		if d := l.reserve(); d != 0 {
+			t.Fatalf("request %d after idling waits %v, want none", i+1, d)
+		}
+	}
+	if d := l.reserve(); d <= 0 {
+		t.Error("the bucket refilled beyond its burst")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx); err != context.Canceled {
+		t.Errorf("Wait() with a cancelled context = %v, want context.Canceled", err)
+	}
+}
+
+func TestThrottledTransportRetries429(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &throttledTransport{base: http.DefaultTransport}}
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200 after the retry", resp.StatusCode)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("server called %d times, want 2", n)
+	}
+}
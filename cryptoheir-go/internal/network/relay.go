@@ -0,0 +1,58 @@
+package network
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Relay methods accepted by BroadcastPrivate
+const (
+	// RelayMethodPrivate submits through eth_sendPrivateTransaction, as
+	// offered by block builders and the Flashbots relay
+	RelayMethodPrivate = "eth_sendPrivateTransaction"
+
+	// RelayMethodRaw submits through eth_sendRawTransaction to a protected
+	// RPC endpoint (Flashbots Protect, MEV Blocker) that keeps it private
+	RelayMethodRaw = "eth_sendRawTransaction"
+)
+
+// BroadcastPrivate submits a signed raw transaction to a private relay
+// instead of the public mempool, so it cannot be front-run before inclusion
+func BroadcastPrivate(ctx context.Context, relayURL, method string, signedTx []byte) (common.Hash, error) {
+	tx := new(coretypes.Transaction)
+	if err := tx.UnmarshalBinary(signedTx); err != nil {
+		return common.Hash{}, fmt.Errorf("failed to decode signed transaction: %w", err)
+	}
+
+	var param interface{}
+	switch method {
+	case RelayMethodPrivate:
+		param = map[string]interface{}{"tx": hexutil.Encode(signedTx)}
+	case RelayMethodRaw:
+		param = hexutil.Encode(signedTx)
+	default:
+		return common.Hash{}, fmt.Errorf("unsupported relay method: %s (supported: %s, %s)", method, RelayMethodPrivate, RelayMethodRaw)
+	}
+
+	client, err := rpc.DialContext(ctx, relayURL)
+	if err != nil {
+		return common.Hash{}, wrapError("failed to connect to private relay", err)
+	}
+	defer client.Close()
+
+	var result common.Hash
+	if err := client.CallContext(ctx, &result, method, param); err != nil {
+		return common.Hash{}, wrapError("private relay rejected transaction", err)
+	}
+
+	if result != tx.Hash() {
+		return common.Hash{}, fmt.Errorf("private relay returned hash %s, expected %s", result.Hex(), tx.Hash().Hex())
+	}
+
+	return result, nil
+}
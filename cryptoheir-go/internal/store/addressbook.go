@@ -0,0 +1,124 @@
+package store
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const addressBookFile = "addressbook.json"
+
+// labelPattern restricts labels to characters that are unambiguous on a
+// terminal, so two labels cannot look alike
+var labelPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// AddressBookEntry is a labelled address
+type AddressBookEntry struct {
+	Label   string         `json:"label"`
+	Address common.Address `json:"address"`
+	Added   string         `json:"added"`
+}
+
+// AddressBook maps labels to addresses. It is opt-in: the file only exists
+// once an entry has been added.
+type AddressBook struct {
+	Entries map[string]AddressBookEntry `json:"entries"`
+}
+
+// NormalizeLabel lowercases a label, strips a leading @ and checks that it
+// uses only lowercase letters, digits, - and _
+func NormalizeLabel(label string) (string, error) {
+	normalized := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(label), "@"))
+	if !labelPattern.MatchString(normalized) {
+		return "", fmt.Errorf("invalid label %q: use up to 32 letters, digits, - and _, starting with a letter or digit", label)
+	}
+	return normalized, nil
+}
+
+// LoadAddressBook loads the local address book
+func LoadAddressBook() (*AddressBook, error) {
+	book := &AddressBook{}
+	if err := ReadJSON(addressBookFile, book); err != nil {
+		return nil, err
+	}
+	if book.Entries == nil {
+		book.Entries = make(map[string]AddressBookEntry)
+	}
+	return book, nil
+}
+
+// Save writes the address book
+func (b *AddressBook) Save() error {
+	return WriteJSON(addressBookFile, b)
+}
+
+// Lookup returns the entry for a label
+func (b *AddressBook) Lookup(label string) (AddressBookEntry, bool) {
+	normalized, err := NormalizeLabel(label)
+	if err != nil {
+		return AddressBookEntry{}, false
+	}
+	e, ok := b.Entries[normalized]
+	return e, ok
+}
+
+// Add stores an address under a new label. Existing labels are never
+// silently repointed; they must be removed first.
+func (b *AddressBook) Add(label string, addr common.Address) (AddressBookEntry, error) {
+	normalized, err := NormalizeLabel(label)
+	if err != nil {
+		return AddressBookEntry{}, err
+	}
+	if addr == (common.Address{}) {
+		return AddressBookEntry{}, fmt.Errorf("refusing to store the zero address")
+	}
+	if e, ok := b.Entries[normalized]; ok {
+		return AddressBookEntry{}, fmt.Errorf("label %s already points to %s; remove it first to change it", normalized, e.Address.Hex())
+	}
+	e := AddressBookEntry{Label: normalized, Address: addr, Added: time.Now().UTC().Format(time.RFC3339)}
+	b.Entries[normalized] = e
+	return e, nil
+}
+
+// Remove deletes a label, reporting whether it existed
+func (b *AddressBook) Remove(label string) bool {
+	normalized, err := NormalizeLabel(label)
+	if err != nil {
+		return false
+	}
+	if _, ok := b.Entries[normalized]; !ok {
+		return false
+	}
+	delete(b.Entries, normalized)
+	return true
+}
+
+// Sorted returns the entries ordered by label
+func (b *AddressBook) Sorted() []AddressBookEntry {
+	entries := make([]AddressBookEntry, 0, len(b.Entries))
+	for _, e := range b.Entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Label < entries[j].Label })
+	return entries
+}
+
+// Lookalikes returns entries whose address resembles addr without being it
+func (b *AddressBook) Lookalikes(addr common.Address) []AddressBookEntry {
+	var matches []AddressBookEntry
+	for _, e := range b.Sorted() {
+		if IsLookalike(e.Address, addr) {
+			matches = append(matches, e)
+		}
+	}
+	return matches
+}
+
+// AddressBookPath returns the path of the address book file
+func AddressBookPath() (string, error) {
+	return Path(addressBookFile)
+}
@@ -0,0 +1,98 @@
+package store
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const beneficiariesFile = "beneficiaries.json"
+
+// lookalikeAffix is the number of leading and trailing hex characters two
+// addresses must share to be considered look-alikes. Address-poisoning attacks
+// generate vanity addresses matching what wallets usually display.
+const lookalikeAffix = 4
+
+// BeneficiaryEntry records a beneficiary address previously used on a chain
+type BeneficiaryEntry struct {
+	Address   common.Address `json:"address"`
+	FirstUsed string         `json:"first_used"`
+	LastUsed  string         `json:"last_used"`
+}
+
+// BeneficiaryHistory maps chain IDs to the beneficiaries used on them
+type BeneficiaryHistory struct {
+	Chains map[string][]BeneficiaryEntry `json:"chains"`
+}
+
+// LoadBeneficiaries loads the local beneficiary history
+func LoadBeneficiaries() (*BeneficiaryHistory, error) {
+	history := &BeneficiaryHistory{}
+	if err := ReadJSON(beneficiariesFile, history); err != nil {
+		return nil, err
+	}
+	if history.Chains == nil {
+		history.Chains = make(map[string][]BeneficiaryEntry)
+	}
+	return history, nil
+}
+
+// Save writes the beneficiary history
+func (h *BeneficiaryHistory) Save() error {
+	return WriteJSON(beneficiariesFile, h)
+}
+
+// Known reports whether the address has been used on the chain before
+func (h *BeneficiaryHistory) Known(chainID uint64, addr common.Address) bool {
+	for _, e := range h.Chains[chainKey(chainID)] {
+		if e.Address == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// Record adds or refreshes an address in the chain's history
+func (h *BeneficiaryHistory) Record(chainID uint64, addr common.Address) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	key := chainKey(chainID)
+
+	for i, e := range h.Chains[key] {
+		if e.Address == addr {
+			h.Chains[key][i].LastUsed = now
+			return
+		}
+	}
+
+	h.Chains[key] = append(h.Chains[key], BeneficiaryEntry{Address: addr, FirstUsed: now, LastUsed: now})
+}
+
+// Lookalikes returns previously-used addresses on the chain that share a
+// prefix and suffix with addr but are not identical to it
+func (h *BeneficiaryHistory) Lookalikes(chainID uint64, addr common.Address) []BeneficiaryEntry {
+	var matches []BeneficiaryEntry
+	for _, e := range h.Chains[chainKey(chainID)] {
+		if e.Address != addr && IsLookalike(e.Address, addr) {
+			matches = append(matches, e)
+		}
+	}
+	return matches
+}
+
+// IsLookalike reports whether two different addresses share the leading and
+// trailing hex characters typically shown in truncated address displays
+func IsLookalike(a, b common.Address) bool {
+	if a == b {
+		return false
+	}
+	ha := strings.ToLower(a.Hex()[2:])
+	hb := strings.ToLower(b.Hex()[2:])
+	return ha[:lookalikeAffix] == hb[:lookalikeAffix] &&
+		ha[len(ha)-lookalikeAffix:] == hb[len(hb)-lookalikeAffix:]
+}
+
+func chainKey(chainID uint64) string {
+	return strconv.FormatUint(chainID, 10)
+}
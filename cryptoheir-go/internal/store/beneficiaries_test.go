@@ -0,0 +1,80 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestIsLookalike(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{"identical", "0x1234aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa5678", "0x1234aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa5678", false},
+		{"same prefix and suffix", "0x1234aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa5678", "0x1234bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb5678", true},
+		{"case does not matter", "0xABCDaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaEF01", "0xabcdbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbef01", true},
+		{"prefix differs", "0x1234aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa5678", "0x1235aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa5678", false},
+		{"suffix differs", "0x1234aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa5678", "0x1234aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa5679", false},
+		{"only three characters shared", "0x123aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa5678", "0x123bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb5678", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := common.HexToAddress(tt.a), common.HexToAddress(tt.b)
+			if got := IsLookalike(a, b); got != tt.want {
+				t.Errorf("IsLookalike(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+			if got := IsLookalike(b, a); got != tt.want {
+				t.Errorf("IsLookalike is not symmetric for %s, %s", tt.a, tt.b)
+			}
+		})
+	}
+}
+
+func TestBeneficiaryHistoryRecord(t *testing.T) {
+	t.Setenv(homeEnv, t.TempDir())
+
+	original := common.HexToAddress("0x1234aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa5678")
+	lookalike := common.HexToAddress("0x1234bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb5678")
+
+	history, err := LoadBeneficiaries()
+	if err != nil {
+		t.Fatalf("LoadBeneficiaries() error = %v", err)
+	}
+	if history.Known(1, original) {
+		t.Fatal("empty history knows an address")
+	}
+
+	history.Record(1, original)
+	if !history.Known(1, original) {
+		t.Fatal("recorded address is not known")
+	}
+	if history.Known(11155111, original) {
+		t.Fatal("address recorded on mainnet is known on another chain")
+	}
+	if got := history.Lookalikes(1, lookalike); len(got) != 1 || got[0].Address != original {
+		t.Fatalf("Lookalikes() = %+v, want the recorded address", got)
+	}
+	if got := history.Lookalikes(1, original); len(got) != 0 {
+		t.Fatalf("Lookalikes() of the recorded address itself = %+v, want none", got)
+	}
+
+	// Recording again refreshes the entry instead of adding another
+	first := history.Chains["1"][0].FirstUsed
+	history.Record(1, original)
+	if entries := history.Chains["1"]; len(entries) != 1 || entries[0].FirstUsed != first {
+		t.Fatalf("re-recording changed the entries: %+v", entries)
+	}
+
+	if err := history.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := LoadBeneficiaries()
+	if err != nil {
+		t.Fatalf("LoadBeneficiaries() error = %v", err)
+	}
+	if !loaded.Known(1, original) || loaded.Known(1, lookalike) {
+		t.Fatalf("saved history = %+v, want only the recorded address", loaded.Chains)
+	}
+}
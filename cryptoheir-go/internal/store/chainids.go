@@ -0,0 +1,74 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+const chainIDsFile = "chain-ids.json"
+
+// ChainIDCacheTTL is how long a cached chain ID is trusted
+const ChainIDCacheTTL = 24 * time.Hour
+
+// ChainIDEntry records the chain ID an RPC endpoint reported
+type ChainIDEntry struct {
+	ChainID   uint64 `json:"chain_id"`
+	FetchedAt string `json:"fetched_at"`
+}
+
+// ChainIDCache maps RPC endpoints to their chain IDs. Endpoints are keyed by
+// the SHA-256 of their URL, so API keys embedded in URLs are never written to
+// disk.
+type ChainIDCache struct {
+	Endpoints map[string]ChainIDEntry `json:"endpoints"`
+}
+
+// LoadChainIDCache loads the local chain ID cache
+func LoadChainIDCache() (*ChainIDCache, error) {
+	cache := &ChainIDCache{}
+	if err := ReadJSON(chainIDsFile, cache); err != nil {
+		return nil, err
+	}
+	if cache.Endpoints == nil {
+		cache.Endpoints = make(map[string]ChainIDEntry)
+	}
+	return cache, nil
+}
+
+// Save writes the chain ID cache
+func (c *ChainIDCache) Save() error {
+	return WriteJSON(chainIDsFile, c)
+}
+
+// Lookup returns the cached chain ID of an endpoint if it was fetched within
+// ChainIDCacheTTL
+func (c *ChainIDCache) Lookup(rpcURL string) (uint64, bool) {
+	entry, ok := c.Endpoints[endpointKey(rpcURL)]
+	if !ok {
+		return 0, false
+	}
+	fetched, err := time.Parse(time.RFC3339, entry.FetchedAt)
+	if err != nil || time.Since(fetched) > ChainIDCacheTTL {
+		return 0, false
+	}
+	return entry.ChainID, true
+}
+
+// Record stores a freshly fetched chain ID for an endpoint. It reports
+// whether a different chain ID was cached for it, which callers treat as a
+// sign the endpoint was repointed.
+func (c *ChainIDCache) Record(rpcURL string, chainID uint64) (mismatch bool) {
+	key := endpointKey(rpcURL)
+	if entry, ok := c.Endpoints[key]; ok && entry.ChainID != chainID {
+		mismatch = true
+	}
+	c.Endpoints[key] = ChainIDEntry{ChainID: chainID, FetchedAt: time.Now().UTC().Format(time.RFC3339)}
+	return mismatch
+}
+
+// endpointKey hashes an RPC URL for use as a cache key
+func endpointKey(rpcURL string) string {
+	sum := sha256.Sum256([]byte(rpcURL))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,114 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const journalFile = "cryptoheir-journal.jsonl"
+
+// journalEnv overrides the journal location (default: in the state directory)
+const journalEnv = "CRYPTOHEIR_JOURNAL"
+
+// JournalEntry records one prepare, sign, broadcast or status event
+type JournalEntry struct {
+	Time      string         `json:"time"`
+	Event     string         `json:"event"`               // prepare, sign, broadcast, status
+	Operation string         `json:"operation,omitempty"` // deploy, deposit, approve, ...
+	Network   string         `json:"network,omitempty"`
+	ChainID   uint64         `json:"chain_id,omitempty"`
+	From      common.Address `json:"from"`
+	Nonce     uint64         `json:"nonce"`
+	TxHash    *common.Hash   `json:"tx_hash,omitempty"` // Unknown until signed
+	File      string         `json:"file,omitempty"`
+	Label     string         `json:"label,omitempty"` // prepare --label
+	Status    string         `json:"status"`          // prepared, signed, submitted, success, failed, error
+	Error     string         `json:"error,omitempty"`
+}
+
+// JournalPath returns the journal file path, which can be overridden with
+// CRYPTOHEIR_JOURNAL
+func JournalPath() (string, error) {
+	if path := os.Getenv(journalEnv); path != "" {
+		return path, nil
+	}
+	return Path(journalFile)
+}
+
+// AppendJournal appends an entry to the journal, stamping it with the
+// current time if unset. The journal is append-only; entries are never
+// rewritten.
+func AppendJournal(entry JournalEntry) error {
+	path, err := JournalPath()
+	if err != nil {
+		return err
+	}
+	if entry.Time == "" {
+		entry.Time = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to serialize journal entry: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create journal directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open journal %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// ReadJournal loads all journal entries in the order they were recorded. A
+// missing journal is not an error.
+func ReadJournal() ([]JournalEntry, error) {
+	path, err := JournalPath()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse journal %s line %d: %w", path, line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal %s: %w", path, err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,89 @@
+package store
+
+import (
+	"strings"
+	"time"
+)
+
+const rpcSelectionFile = "rpc-selection.json"
+
+// RPCSelectionTTL is how long the fastest endpoint of a list is reused
+// before the endpoints are measured again
+const RPCSelectionTTL = 5 * time.Minute
+
+// RPCSelectionEntry records the endpoint chosen from a list, by its position
+// in the list
+type RPCSelectionEntry struct {
+	FastestIndex int    `json:"fastest_index"`
+	ChainID      uint64 `json:"chain_id,omitempty"` // Chain the endpoints reported when measured
+	MeasuredAt   string `json:"measured_at,omitempty"`
+	NextIndex    int    `json:"next_index"`
+}
+
+// RPCSelectionCache maps endpoint lists to their last selection. Lists are
+// keyed by the SHA-256 of their URLs, and only positions are stored, so API
+// keys embedded in URLs are never written to disk.
+type RPCSelectionCache struct {
+	Lists map[string]RPCSelectionEntry `json:"lists"`
+}
+
+// LoadRPCSelectionCache loads the local endpoint selection cache
+func LoadRPCSelectionCache() (*RPCSelectionCache, error) {
+	cache := &RPCSelectionCache{}
+	if err := ReadJSON(rpcSelectionFile, cache); err != nil {
+		return nil, err
+	}
+	if cache.Lists == nil {
+		cache.Lists = make(map[string]RPCSelectionEntry)
+	}
+	return cache, nil
+}
+
+// Save writes the endpoint selection cache
+func (c *RPCSelectionCache) Save() error {
+	return WriteJSON(rpcSelectionFile, c)
+}
+
+// Fastest returns the position of the fastest endpoint of a list and the
+// chain it reported, if it was measured within RPCSelectionTTL
+func (c *RPCSelectionCache) Fastest(urls []string) (int, uint64, bool) {
+	entry, ok := c.Lists[listKey(urls)]
+	if !ok || entry.MeasuredAt == "" || entry.ChainID == 0 {
+		return 0, 0, false
+	}
+	measured, err := time.Parse(time.RFC3339, entry.MeasuredAt)
+	if err != nil || time.Since(measured) > RPCSelectionTTL || entry.FastestIndex >= len(urls) {
+		return 0, 0, false
+	}
+	return entry.FastestIndex, entry.ChainID, true
+}
+
+// RecordFastest stores the position of the fastest endpoint of a list and
+// the chain the endpoints reported
+func (c *RPCSelectionCache) RecordFastest(urls []string, index int, chainID uint64) {
+	key := listKey(urls)
+	entry := c.Lists[key]
+	entry.FastestIndex = index
+	entry.ChainID = chainID
+	entry.MeasuredAt = time.Now().UTC().Format(time.RFC3339)
+	c.Lists[key] = entry
+}
+
+// Next returns the position of the endpoint a round-robin selection starts
+// from
+func (c *RPCSelectionCache) Next(urls []string) int {
+	return c.Lists[listKey(urls)].NextIndex % len(urls)
+}
+
+// RecordUsed advances the round-robin position past the endpoint used
+func (c *RPCSelectionCache) RecordUsed(urls []string, index int) {
+	key := listKey(urls)
+	entry := c.Lists[key]
+	entry.NextIndex = (index + 1) % len(urls)
+	c.Lists[key] = entry
+}
+
+// listKey hashes an endpoint list for use as a cache key
+func listKey(urls []string) string {
+	return endpointKey(strings.Join(urls, "\n"))
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// homeEnv overrides the default state directory
+const homeEnv = "CRYPTOHEIR_HOME"
+
+// Dir returns the directory holding local state files, creating it if needed.
+// Defaults to ~/.cryptoheir unless CRYPTOHEIR_HOME is set.
+func Dir() (string, error) {
+	dir := os.Getenv(homeEnv)
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to determine home directory: %w", err)
+		}
+		dir = filepath.Join(home, ".cryptoheir")
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	return dir, nil
+}
+
+// Path returns the full path of a named state file
+func Path(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// ReadJSON loads a named state file into v. A missing file is not an error
+// and leaves v unchanged.
+func ReadJSON(name string, v interface{}) error {
+	path, err := Path(name)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// WriteJSON atomically replaces a named state file with v
+func WriteJSON(name string, v interface{}) error {
+	path, err := Path(name)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize %s: %w", name, err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const submissionsFile = "submissions.json"
+
+// SubmissionEntry records a transaction submitted during a batch broadcast
+type SubmissionEntry struct {
+	ChainID     uint64         `json:"chain_id"`
+	From        common.Address `json:"from"`
+	Nonce       uint64         `json:"nonce"`
+	TxHash      common.Hash    `json:"tx_hash"`
+	File        string         `json:"file"`
+	SubmittedAt string         `json:"submitted_at"`
+}
+
+// SubmissionLedger tracks submitted-but-unconfirmed transactions by chain,
+// sender and nonce, so a re-run batch never sends a different transaction
+// for a nonce that was already attempted
+type SubmissionLedger struct {
+	Entries map[string]SubmissionEntry `json:"entries"`
+}
+
+// LoadSubmissions loads the local submission ledger
+func LoadSubmissions() (*SubmissionLedger, error) {
+	ledger := &SubmissionLedger{}
+	if err := ReadJSON(submissionsFile, ledger); err != nil {
+		return nil, err
+	}
+	if ledger.Entries == nil {
+		ledger.Entries = make(map[string]SubmissionEntry)
+	}
+	return ledger, nil
+}
+
+// Save writes the submission ledger
+func (l *SubmissionLedger) Save() error {
+	return WriteJSON(submissionsFile, l)
+}
+
+// Lookup returns the recorded submission for a chain, sender and nonce
+func (l *SubmissionLedger) Lookup(chainID uint64, from common.Address, nonce uint64) (SubmissionEntry, bool) {
+	e, ok := l.Entries[submissionKey(chainID, from, nonce)]
+	return e, ok
+}
+
+// Record stores a submission attempt
+func (l *SubmissionLedger) Record(chainID uint64, from common.Address, nonce uint64, txHash common.Hash, file string) {
+	l.Entries[submissionKey(chainID, from, nonce)] = SubmissionEntry{
+		ChainID:     chainID,
+		From:        from,
+		Nonce:       nonce,
+		TxHash:      txHash,
+		File:        file,
+		SubmittedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// Remove deletes the entry for a chain, sender and nonce
+func (l *SubmissionLedger) Remove(chainID uint64, from common.Address, nonce uint64) {
+	delete(l.Entries, submissionKey(chainID, from, nonce))
+}
+
+// Prune removes the sender's entries with nonces below confirmedNonce, which
+// can no longer be mined again. Returns the number of entries removed.
+func (l *SubmissionLedger) Prune(chainID uint64, from common.Address, confirmedNonce uint64) int {
+	removed := 0
+	for key, e := range l.Entries {
+		if e.ChainID == chainID && e.From == from && e.Nonce < confirmedNonce {
+			delete(l.Entries, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+func submissionKey(chainID uint64, from common.Address, nonce uint64) string {
+	return fmt.Sprintf("%d:%s:%d", chainID, from.Hex(), nonce)
+}
@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+)
+
+// recordedInt reads a base-10 integer recorded in the metadata at prepare time
+func (m *model) recordedInt(key string) (*big.Int, bool) {
+	s, ok := m.txParams.Metadata.AdditionalInfo[key].(string)
+	if !ok {
+		return nil, false
+	}
+	return new(big.Int).SetString(s, 10)
+}
+
+// recordedUint reads a small non-negative integer recorded at prepare time.
+// The value's Go type depends on how the parameters reached the TUI: float64
+// from JSON, uint64 or int64 from CBOR, or the original type (such as uint8)
+// when prepare hands its parameters over directly, so any integer kind is
+// accepted.
+func (m *model) recordedUint(key string) (uint64, bool) {
+	v := reflect.ValueOf(m.txParams.Metadata.AdditionalInfo[key])
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() < 0 {
+			return 0, false
+		}
+		return uint64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint(), true
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f < 0 || f != math.Trunc(f) || f >= 1<<64 {
+			return 0, false
+		}
+		return uint64(f), true
+	}
+	return 0, false
+}
+
+// worstCaseCost returns the most the transaction can cost the signer in ETH:
+// its value, the gas limit at the max fee (or gas price) and any L1 data fee
+func (m *model) worstCaseCost() *big.Int {
+	tx := m.txParams.Transaction
+	cost := new(big.Int)
+	if tx.GasLimit != nil {
+		price := tx.MaxFeePerGas
+		if tx.TxType == 0 {
+			price = tx.GasPrice
+		}
+		if price != nil {
+			cost.Mul(tx.GasLimit.ToBigInt(), price.ToBigInt())
+		}
+	}
+	if tx.Value != nil {
+		cost.Add(cost, tx.Value.ToBigInt())
+	}
+	if l1Fee, ok := m.recordedInt("l1_data_fee_wei"); ok {
+		cost.Add(cost, l1Fee)
+	}
+	return cost
+}
+
+// balanceLines shows the balances snapshotted at prepare time and what
+// remains after this transaction, flagging anything the signer cannot afford
+func (m *model) balanceLines(lines *[]string, mark func(string)) {
+	balance, ok := m.recordedInt("signer_balance_wei")
+	if !ok {
+		return
+	}
+	info := m.txParams.Metadata.AdditionalInfo
+
+	*lines = append(*lines, "")
+	mark("Balance")
+	at, _ := info["balance_snapshot_at"].(string)
+	if block, ok := m.recordedUint("balance_snapshot_block"); ok {
+		at = fmt.Sprintf("%s, block %d", at, block)
+	}
+	*lines = append(*lines, labelStyle.Render(fmt.Sprintf("Balance (snapshot at prepare time, %s):", at)))
+
+	remaining := new(big.Int).Sub(balance, m.worstCaseCost())
+	*lines = append(*lines, "  ETH: "+valueStyle.Render(types.FormatAmount(balance, 18, "ETH"))+m.usdEstimate(balance))
+	if remaining.Sign() < 0 {
+		*lines = append(*lines, costStyle.Render(fmt.Sprintf("  ⚠ INSUFFICIENT: value plus max gas cost exceeds the balance by %s",
+			types.FormatAmount(new(big.Int).Neg(remaining), 18, "ETH"))))
+	} else {
+		*lines = append(*lines, "  After this transaction: "+valueStyle.Render(types.FormatAmount(remaining, 18, "ETH"))+" remaining (at the max gas cost)")
+	}
+
+	var params map[string]interface{}
+	_ = json.Unmarshal(m.txParams.Params, &params)
+	amount, ok := new(big.Int).SetString(fmt.Sprint(params["amount"]), 10)
+	if !ok {
+		amount = new(big.Int)
+	}
+	if tokenBalance, ok := m.recordedInt("token_balance"); ok {
+		remaining := new(big.Int).Sub(tokenBalance, amount)
+		*lines = append(*lines, "  Token: "+valueStyle.Render(m.tokenAmount(tokenBalance)))
+		if remaining.Sign() < 0 {
+			*lines = append(*lines, costStyle.Render("  ⚠ INSUFFICIENT: the deposit exceeds the token balance by "+m.tokenAmount(new(big.Int).Neg(remaining))))
+		} else {
+			*lines = append(*lines, "  After this transaction: "+valueStyle.Render(m.tokenAmount(remaining))+" remaining")
+		}
+	}
+	if allowance, ok := m.recordedInt("token_allowance"); ok {
+		*lines = append(*lines, "  Allowance for the contract: "+valueStyle.Render(m.tokenAmount(allowance)))
+		if allowance.Cmp(amount) < 0 {
+			*lines = append(*lines, costStyle.Render("  ⚠ INSUFFICIENT: approve the contract for the deposit amount first (prepare --with-approve)"))
+		}
+	}
+
+	*lines = append(*lines, controlsStyle.Render("Balances were captured online and may have changed since."))
+}
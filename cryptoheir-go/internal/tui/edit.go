@@ -0,0 +1,258 @@
+package tui
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Edit bounds, relative to the prepared values. They catch slips such as an
+// extra zero while leaving room to react to the market. The priority fee is
+// bounded by the max fee instead. The gas limit cannot go below the estimate
+// it was prepared from, and a sweep's fees and gas cannot be raised at all.
+const (
+	maxFeeEditFactor = 10 // max fee or gas price: between 1/10 and 10x the prepared value
+	maxGasEditFactor = 3  // gas limit: at most 3x the prepared value
+)
+
+// editField is one fee or gas field that can be changed during review. Only
+// these fields are editable; the recipient, value and data never are.
+type editField struct {
+	name  string // transaction field, as recorded in the metadata
+	label string
+	gwei  bool // entered in gwei rather than as an integer
+	value **types.BigInt
+}
+
+// editState holds an edit in progress. Values are staged and only applied
+// once all of them validate together.
+type editState struct {
+	fields []editField
+	values []*big.Int
+	step   int
+	input  textinput.Model
+	err    error
+}
+
+// editFields lists the editable fields of the transaction type
+func (m *model) editFields() []editField {
+	tx := &m.txParams.Transaction
+	gasLimit := editField{name: "gas_limit", label: "Gas limit", value: &tx.GasLimit}
+	if tx.TxType == 0 {
+		return []editField{
+			{name: "gas_price", label: "Gas price (gwei)", gwei: true, value: &tx.GasPrice},
+			gasLimit,
+		}
+	}
+	return []editField{
+		{name: "max_priority_fee_per_gas", label: "Max priority fee (gwei)", gwei: true, value: &tx.MaxPriorityFeePerGas},
+		{name: "max_fee_per_gas", label: "Max fee (gwei)", gwei: true, value: &tx.MaxFeePerGas},
+		gasLimit,
+	}
+}
+
+// startEdit enters edit mode with the current values
+func (m *model) startEdit() tea.Cmd {
+	fields := m.editFields()
+	m.edit = &editState{fields: fields, values: make([]*big.Int, len(fields)), input: textinput.New()}
+	for i, f := range fields {
+		m.edit.values[i] = (*f.value).ToBigInt()
+	}
+	m.loadEditStep()
+	return textinput.Blink
+}
+
+// loadEditStep shows the staged value of the current field in the input
+func (m *model) loadEditStep() {
+	e := m.edit
+	f := e.fields[e.step]
+	e.input.Reset()
+	e.input.SetValue(formatEditValue(e.values[e.step], f.gwei))
+	e.input.CursorEnd()
+	e.input.Focus()
+	e.err = nil
+}
+
+// updateEdit handles keys while editing. Enter accepts the current field and
+// moves on; after the last field all values are checked and applied. Esc
+// discards the edit.
+func (m *model) updateEdit(msg tea.Msg) tea.Cmd {
+	e := m.edit
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.Type {
+		case tea.KeyEsc, tea.KeyCtrlC:
+			m.edit = nil
+			return nil
+		case tea.KeyEnter:
+			f := e.fields[e.step]
+			value, err := parseEditValue(e.input.Value(), f.gwei)
+			if err != nil {
+				e.err = err
+				return nil
+			}
+			e.values[e.step] = value
+			if e.step < len(e.fields)-1 {
+				e.step++
+				m.loadEditStep()
+				return nil
+			}
+			if err := m.applyEdit(); err != nil {
+				e.err = err
+				return nil
+			}
+			m.edit = nil
+			m.refreshContent()
+			return nil
+		}
+	}
+	var cmd tea.Cmd
+	e.input, cmd = e.input.Update(msg)
+	return cmd
+}
+
+// applyEdit validates the staged values against the prepared ones and writes
+// the changes into the transaction, recording each in the metadata
+func (m *model) applyEdit() error {
+	e := m.edit
+	staged := make(map[string]*big.Int)
+	for i, f := range e.fields {
+		staged[f.name] = e.values[i]
+	}
+
+	_, sweep := m.recordedInt("sweep_balance_wei")
+	for i, f := range e.fields {
+		prepared := m.preparedValue(f)
+		value := e.values[i]
+		switch {
+		case sweep && value.Cmp(prepared) > 0 && f.name != "max_priority_fee_per_gas":
+			return fmt.Errorf("%s cannot be raised above the prepared %s: the sweep sends the whole balance less the prepared gas cost, so a higher one is rejected for insufficient funds",
+				f.name, formatEditValue(prepared, f.gwei))
+		case f.name == "gas_limit":
+			if floor := minGasLimit(prepared); value.Cmp(floor) < 0 {
+				return fmt.Errorf("gas limit must be at least %s, the estimate the prepared %s was buffered from", floor, prepared)
+			}
+			if value.Cmp(new(big.Int).Mul(prepared, big.NewInt(maxGasEditFactor))) > 0 {
+				return fmt.Errorf("gas limit must be at most %dx the prepared %s", maxGasEditFactor, prepared)
+			}
+		case f.name == "max_priority_fee_per_gas":
+			// Checked against the max fee below; a zero tip is valid
+		default:
+			if value.Sign() <= 0 {
+				return fmt.Errorf("%s must be greater than zero", f.name)
+			}
+			if new(big.Int).Mul(value, big.NewInt(maxFeeEditFactor)).Cmp(prepared) < 0 ||
+				value.Cmp(new(big.Int).Mul(prepared, big.NewInt(maxFeeEditFactor))) > 0 {
+				return fmt.Errorf("%s must be between 1/%d and %dx the prepared %s gwei",
+					f.name, maxFeeEditFactor, maxFeeEditFactor, formatEditValue(prepared, true))
+			}
+		}
+	}
+	if tip, fee := staged["max_priority_fee_per_gas"], staged["max_fee_per_gas"]; tip != nil && fee != nil && tip.Cmp(fee) > 0 {
+		return fmt.Errorf("max priority fee must not exceed the max fee")
+	}
+
+	for i, f := range e.fields {
+		if e.values[i].Cmp((*f.value).ToBigInt()) == 0 {
+			continue
+		}
+		m.recordEdit(f, e.values[i])
+		*f.value = types.NewBigInt(e.values[i])
+	}
+	return nil
+}
+
+// minGasLimit returns the lowest gas limit an edit may set: the estimate the
+// prepared limit was derived from by adding the 20% buffer (rounded up, so
+// it never exceeds the estimate), and at least the intrinsic gas of a
+// transfer. A lower limit is certain to run out of gas.
+func minGasLimit(prepared *big.Int) *big.Int {
+	floor := new(big.Int).Mul(prepared, big.NewInt(5))
+	floor.Add(floor, big.NewInt(5))
+	floor.Div(floor, big.NewInt(6))
+	if floor.Cmp(new(big.Int).SetUint64(params.TxGas)) < 0 {
+		floor.SetUint64(params.TxGas)
+	}
+	return floor
+}
+
+// preparedValue returns a field's value as prepared, before any earlier edit
+func (m *model) preparedValue(f editField) *big.Int {
+	for _, edit := range m.txParams.Metadata.ReviewEdits {
+		if edit.Field == f.name {
+			if v, ok := new(big.Int).SetString(edit.Prepared, 10); ok {
+				return v
+			}
+		}
+	}
+	return (*f.value).ToBigInt()
+}
+
+// recordEdit records a changed field before it is written, keeping the
+// prepared value of a field edited more than once and dropping the record of
+// a field set back to its prepared value
+func (m *model) recordEdit(f editField, value *big.Int) {
+	edits := m.txParams.Metadata.ReviewEdits
+	for i := range edits {
+		if edits[i].Field != f.name {
+			continue
+		}
+		if edits[i].Prepared == value.String() {
+			m.txParams.Metadata.ReviewEdits = append(edits[:i], edits[i+1:]...)
+		} else {
+			edits[i].Signed = value.String()
+		}
+		return
+	}
+	m.txParams.Metadata.ReviewEdits = append(edits, types.ReviewEdit{
+		Field:    f.name,
+		Prepared: m.preparedValue(f).String(),
+		Signed:   value.String(),
+	})
+}
+
+// editView renders the edit panel shown in place of the status line
+func (m *model) editView() string {
+	e := m.edit
+	f := e.fields[e.step]
+	var b strings.Builder
+	b.WriteString(labelStyle.Render(fmt.Sprintf("Edit %d/%d: %s", e.step+1, len(e.fields), f.label)))
+	b.WriteString(hintStyle.Render(fmt.Sprintf("  (prepared: %s)", formatEditValue(m.preparedValue(f), f.gwei))))
+	b.WriteString("\n")
+	b.WriteString(e.input.View())
+	if e.err != nil {
+		b.WriteString("\n")
+		b.WriteString(costStyle.Render("✗ " + e.err.Error()))
+	}
+	return b.String()
+}
+
+// formatEditValue formats a value for editing: gwei for fees, an integer for
+// the gas limit
+func formatEditValue(v *big.Int, gwei bool) string {
+	if !gwei {
+		return v.String()
+	}
+	return types.FormatUnits(v, 9)
+}
+
+// parseEditValue parses an entered fee (gwei, up to 9 decimals) or gas limit
+// as a plain decimal
+func parseEditValue(s string, gwei bool) (*big.Int, error) {
+	if !gwei {
+		v, err := types.ParseUnits(s, 0)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gas limit: %q", strings.TrimSpace(s))
+		}
+		return v, nil
+	}
+	v, err := types.ParseUnits(s, 9)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gwei amount: %w (gwei has at most 9 decimals)", err)
+	}
+	return v, nil
+}
@@ -0,0 +1,145 @@
+package tui
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+)
+
+// editModel returns a model reviewing an EIP-1559 call prepared with a
+// 120000 gas limit (a 100000 estimate plus the buffer), a 30 gwei max fee and
+// a 1 gwei priority fee, or a legacy transfer with a 21000 gas limit and a
+// 20 gwei gas price
+func editModel(legacy, sweep bool) *model {
+	tx := types.TransactionData{
+		TxType:               2,
+		GasLimit:             types.NewBigInt(big.NewInt(120000)),
+		MaxFeePerGas:         types.NewBigInt(big.NewInt(30e9)),
+		MaxPriorityFeePerGas: types.NewBigInt(big.NewInt(1e9)),
+	}
+	if legacy {
+		tx = types.TransactionData{
+			TxType:   0,
+			GasLimit: types.NewBigInt(big.NewInt(21000)),
+			GasPrice: types.NewBigInt(big.NewInt(20e9)),
+		}
+	}
+	info := map[string]interface{}{}
+	if sweep {
+		info["sweep_balance_wei"] = "1000000000000000000"
+	}
+	return &model{txParams: &types.TxParams{Transaction: tx, Metadata: types.Metadata{AdditionalInfo: info}}}
+}
+
+func TestApplyEdit(t *testing.T) {
+	tests := []struct {
+		name    string
+		legacy  bool
+		sweep   bool
+		values  map[string]int64 // staged values by field; others stay prepared
+		wantErr string
+	}{
+		{name: "unchanged"},
+		{name: "gas limit at the estimate", values: map[string]int64{"gas_limit": 100000}},
+		{name: "gas limit below the estimate", values: map[string]int64{"gas_limit": 99999}, wantErr: "at least 100000"},
+		{name: "gas limit at 3x", values: map[string]int64{"gas_limit": 360000}},
+		{name: "gas limit above 3x", values: map[string]int64{"gas_limit": 360001}, wantErr: "at most 3x"},
+		{name: "transfer gas limit at the intrinsic gas", legacy: true, values: map[string]int64{"gas_limit": 21000}},
+		{name: "transfer gas limit below the intrinsic gas", legacy: true, values: map[string]int64{"gas_limit": 20999}, wantErr: "at least 21000"},
+		{name: "max fee at 10x", values: map[string]int64{"max_fee_per_gas": 300e9}},
+		{name: "max fee above 10x", values: map[string]int64{"max_fee_per_gas": 300e9 + 1}, wantErr: "between 1/10 and 10x"},
+		{name: "max fee below 1/10", values: map[string]int64{"max_fee_per_gas": 3e9 - 1}, wantErr: "between 1/10 and 10x"},
+		{name: "zero max fee", values: map[string]int64{"max_fee_per_gas": 0}, wantErr: "greater than zero"},
+		{name: "zero priority fee", values: map[string]int64{"max_priority_fee_per_gas": 0}},
+		{name: "priority fee above the max fee", values: map[string]int64{"max_priority_fee_per_gas": 31e9}, wantErr: "must not exceed the max fee"},
+		{name: "sweep with lower fees and gas", sweep: true, values: map[string]int64{"max_fee_per_gas": 20e9, "gas_limit": 110000}},
+		{name: "sweep with a higher priority fee", sweep: true, values: map[string]int64{"max_priority_fee_per_gas": 2e9}},
+		{name: "sweep with a higher max fee", sweep: true, values: map[string]int64{"max_fee_per_gas": 31e9}, wantErr: "cannot be raised"},
+		{name: "sweep with a higher gas limit", sweep: true, values: map[string]int64{"gas_limit": 120001}, wantErr: "cannot be raised"},
+		{name: "legacy sweep with a higher gas price", legacy: true, sweep: true, values: map[string]int64{"gas_price": 21e9}, wantErr: "cannot be raised"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := editModel(tt.legacy, tt.sweep)
+			m.startEdit()
+			for i, f := range m.edit.fields {
+				if v, ok := tt.values[f.name]; ok {
+					m.edit.values[i] = big.NewInt(v)
+				}
+			}
+
+			err := m.applyEdit()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("applyEdit() error = %v, want it to mention %q", err, tt.wantErr)
+				}
+				if len(m.txParams.Metadata.ReviewEdits) != 0 {
+					t.Errorf("a rejected edit was recorded: %+v", m.txParams.Metadata.ReviewEdits)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyEdit() error = %v", err)
+			}
+			for _, f := range m.edit.fields {
+				if v, ok := tt.values[f.name]; ok && (*f.value).ToBigInt().Int64() != v {
+					t.Errorf("%s = %s, want %d", f.name, *f.value, v)
+				}
+			}
+		})
+	}
+}
+
+func TestMinGasLimit(t *testing.T) {
+	// Every estimate buffered the way prepare does it is accepted back
+	for _, estimate := range []int64{21000, 46000, 99999, 100000, 100001, 123457} {
+		prepared := estimate + estimate/5
+		if got := minGasLimit(big.NewInt(prepared)); got.Int64() > estimate {
+			t.Errorf("minGasLimit(%d) = %s, above the estimate %d", prepared, got, estimate)
+		} else if got.Int64() < estimate-1 {
+			t.Errorf("minGasLimit(%d) = %s, want close to the estimate %d", prepared, got, estimate)
+		}
+	}
+	if got := minGasLimit(big.NewInt(21000)); got.Int64() != 21000 {
+		t.Errorf("minGasLimit(21000) = %s, want the intrinsic gas 21000", got)
+	}
+}
+
+func TestParseEditValue(t *testing.T) {
+	tests := []struct {
+		input string
+		gwei  bool
+		want  string // empty when an error is expected
+	}{
+		{"1.5", true, "1500000000"},
+		{" 30 ", true, "30000000000"},
+		{"0.000000001", true, "1"},
+		{"0", true, "0"},
+		{"0.0000000001", true, ""},
+		{"1/2", true, ""},
+		{"1e3", true, ""},
+		{"-1", true, ""},
+		{"0x10", true, ""},
+		{"", true, ""},
+		{"21000", false, "21000"},
+		{"2.1e4", false, ""},
+		{"0x5208", false, ""},
+		{"21000.5", false, ""},
+		{"-21000", false, ""},
+		{"+21000", false, ""},
+	}
+	for _, tt := range tests {
+		got, err := parseEditValue(tt.input, tt.gwei)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("parseEditValue(%q, %v) = %s, want an error", tt.input, tt.gwei, got)
+			}
+			continue
+		}
+		if err != nil || got.String() != tt.want {
+			t.Errorf("parseEditValue(%q, %v) = %v, %v; want %s", tt.input, tt.gwei, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/crypto"
+)
+
+// payloadLines shows the exact bytes the key will sign and their hash, so a
+// reviewer can decode them with another tool and compare them to the fields
+// above. The hex is wrapped to the viewport width.
+func (m *model) payloadLines(lines *[]string, mark func(string)) {
+	*lines = append(*lines, "")
+	mark("Payload")
+	*lines = append(*lines, labelStyle.Render("Signing Payload:"))
+
+	payload, err := crypto.ComputeSigningPayload(&m.txParams.Transaction)
+	if err != nil {
+		*lines = append(*lines, costStyle.Render("Failed to compute the signing payload: "+err.Error()))
+		return
+	}
+
+	wrap := lipgloss.NewStyle()
+	if m.viewport.Width > 0 {
+		wrap = wrap.Width(m.viewport.Width)
+	}
+	*lines = append(*lines, labelStyle.Render("Signer: ")+payload.Signer)
+	*lines = append(*lines, labelStyle.Render("Signing Hash: ")+payload.Hash.Hex())
+	*lines = append(*lines, labelStyle.Render(fmt.Sprintf("Pre-image (%d bytes):", len(payload.Preimage))))
+	*lines = append(*lines, strings.Split(wrap.Render("0x"+hex.EncodeToString(payload.Preimage)), "\n")...)
+	if payload.UnsignedAuthorizations > 0 {
+		*lines = append(*lines, modeStyle.Render(fmt.Sprintf(
+			"⚠ %d authorization(s) are signed first; the final pre-image includes their signatures in place of the zeros shown.",
+			payload.UnsignedAuthorizations)))
+	}
+}
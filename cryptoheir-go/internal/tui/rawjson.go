@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// JSON syntax highlighting styles for the raw view
+var (
+	jsonKeyStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("6"))
+
+	jsonStringStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("2"))
+
+	jsonLiteralStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("3"))
+)
+
+// renderRawJSON renders the complete transaction parameters exactly as they
+// are written to the file, with syntax highlighting. Long lines, such as the
+// calldata, are wrapped to the viewport width so that nothing is cut off.
+func (m *model) renderRawJSON() string {
+	data, err := json.MarshalIndent(m.txParams, "", "  ")
+	if err != nil {
+		return costStyle.Render("Failed to serialize transaction parameters: " + err.Error())
+	}
+
+	wrap := lipgloss.NewStyle()
+	if m.viewport.Width > 0 {
+		wrap = wrap.Width(m.viewport.Width)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		lines[i] = wrap.Render(highlightJSONLine(line))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// highlightJSONLine colors the keys, strings and literals of one line of
+// indented JSON. Punctuation and whitespace are left as they are.
+func highlightJSONLine(line string) string {
+	var b strings.Builder
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case c == '"':
+			end := stringEnd(line, i)
+			token := line[i:end]
+			if strings.HasPrefix(strings.TrimLeft(line[end:], " "), ":") {
+				b.WriteString(jsonKeyStyle.Render(token))
+			} else {
+				b.WriteString(jsonStringStyle.Render(token))
+			}
+			i = end
+		case c == '-' || (c >= '0' && c <= '9') || c == 't' || c == 'f' || c == 'n':
+			end := i
+			for end < len(line) && !strings.ContainsRune(",]} ", rune(line[end])) {
+				end++
+			}
+			b.WriteString(jsonLiteralStyle.Render(line[i:end]))
+			i = end
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// stringEnd returns the index just past the JSON string starting at start,
+// skipping escaped characters
+func stringEnd(line string, start int) int {
+	for i := start + 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(line)
+}
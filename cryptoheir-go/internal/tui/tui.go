@@ -3,13 +3,18 @@ package tui
 import (
 	"encoding/json"
 	"fmt"
+	"math"
+	"math/big"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/network"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/contract"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/crypto"
 	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/version"
 )
 
 // Color styles
@@ -46,13 +51,35 @@ var (
 			Bold(true)
 )
 
+// section marks the first line of a named block in the rendered content
+type section struct {
+	name string
+	line int
+}
+
 // model represents the TUI state
 type model struct {
-	txParams *types.TxParams
-	viewport viewport.Model
-	ready    bool
-	approved bool
-	quitting bool
+	txParams   *types.TxParams
+	viewport   viewport.Model
+	sections   []section
+	paramCount int
+	ready      bool
+	approved   bool
+	quitting   bool
+
+	// rawJSON shows the complete transaction parameters as JSON instead of
+	// the rendered summary
+	rawJSON bool
+
+	// showPayload adds the bytes to be signed and their hash to the summary
+	showPayload bool
+
+	// showVersions adds the signing software versions to the footer
+	showVersions bool
+	height       int
+
+	// edit is the fee and gas edit in progress, if any
+	edit *editState
 }
 
 // ReviewTransaction displays an interactive TUI for transaction review
@@ -82,6 +109,13 @@ func (m model) Init() tea.Cmd {
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// While editing, keys go to the edit input, never to approve or cancel
+	if _, resize := msg.(tea.WindowSizeMsg); !resize && m.edit != nil {
+		cmd := m.updateEdit(msg)
+		m.viewport.Height = m.viewportHeight()
+		return m, cmd
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
@@ -105,18 +139,48 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.viewport.ViewUp()
 		case "pgdown":
 			m.viewport.ViewDown()
+		case "g", "home":
+			m.viewport.GotoTop()
+		case "G", "end":
+			m.viewport.GotoBottom()
+		case "r", "R":
+			// Toggle between the rendered summary and the raw JSON
+			m.rawJSON = !m.rawJSON
+			m.refreshContent()
+			m.viewport.GotoTop()
+		case "e", "E":
+			// Edit the fees and gas limit (summary view only)
+			if !m.rawJSON {
+				cmd := m.startEdit()
+				m.viewport.Height = m.viewportHeight()
+				return m, cmd
+			}
+		case "b", "B":
+			// Toggle the signing payload section
+			m.showPayload = !m.showPayload
+			m.refreshContent()
+		case "v", "V":
+			// Toggle the signing software versions in the footer
+			m.showVersions = !m.showVersions
+			m.viewport.Height = m.viewportHeight()
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			// Jump to the numbered section, if present
+			idx := int(msg.String()[0] - '1')
+			if idx < len(m.sections) {
+				m.viewport.SetYOffset(m.sections[idx].line)
+			}
 		}
 
 	case tea.WindowSizeMsg:
+		m.height = msg.Height
 		if !m.ready {
-			m.viewport = viewport.New(msg.Width, msg.Height-6)
-			m.viewport.SetContent(m.renderTransaction())
+			m.viewport = viewport.New(msg.Width, m.viewportHeight())
 			m.ready = true
 		} else {
 			m.viewport.Width = msg.Width
-			m.viewport.Height = msg.Height - 6
-			m.viewport.SetContent(m.renderTransaction())
+			m.viewport.Height = m.viewportHeight()
 		}
+		m.refreshContent()
 	}
 
 	var cmd tea.Cmd
@@ -137,21 +201,99 @@ func (m model) View() string {
 	// Viewport with transaction details
 	content := m.viewport.View()
 
+	// Section index and position indicator
+	status := fmt.Sprintf("%s  |  Parameters: %d  |  %3.0f%%",
+		m.sectionIndex(), m.paramCount, m.viewport.ScrollPercent()*100)
+	if m.rawJSON {
+		status = fmt.Sprintf("%s  |  %3.0f%%",
+			labelStyle.Render("Raw JSON (complete transaction parameters)"), m.viewport.ScrollPercent()*100)
+	}
+	if m.edit != nil {
+		status = m.editView()
+	}
+
 	// Controls
-	controls := controlsStyle.Render(
-		"Controls: [Y/Enter] Approve  [N/Q/Esc] Cancel  [↑↓/j/k] Scroll  [PgUp/PgDn] Fast Scroll",
-	)
+	footer := "Controls: [Y/Enter] Approve  [N/Q/Esc] Cancel  [↑↓/j/k] Scroll  [PgUp/PgDn] Fast Scroll\n" +
+		"          [1-9] Jump to section  [g/G] Top/Bottom  [r] Raw JSON / summary  [b] Signing bytes  [e] Edit fees  [v] Versions"
+	if m.edit != nil {
+		footer = "Editing: [Enter] Accept value and continue  [Esc] Discard edits"
+	}
+	if m.showVersions {
+		footer += "\nSigning with " + version.Summary()
+	}
+	controls := controlsStyle.Render(footer)
+
+	return fmt.Sprintf("%s\n\n%s\n%s\n%s", title, content, status, controls)
+}
+
+// viewportHeight returns the height left for the viewport below the title and
+// above the status line and footer
+func (m model) viewportHeight() int {
+	height := m.height - 7
+	if m.showVersions {
+		height--
+	}
+	if m.edit != nil {
+		// The edit panel takes up to three lines in place of the status line
+		height -= 2
+	}
+	return height
+}
 
-	return fmt.Sprintf("%s\n\n%s\n\n%s", title, content, controls)
+// refreshContent renders the current view into the viewport. The raw JSON
+// view has no sections to jump to.
+func (m *model) refreshContent() {
+	if m.rawJSON {
+		m.sections = nil
+		m.viewport.SetContent(m.renderRawJSON())
+		return
+	}
+	content, sections := m.renderTransaction()
+	m.sections = sections
+	m.viewport.SetContent(content)
 }
 
-func (m model) renderTransaction() string {
+// sectionIndex renders the numbered section list, highlighting the section
+// currently at the top of the viewport
+func (m model) sectionIndex() string {
+	current := -1
+	for i, s := range m.sections {
+		if s.line <= m.viewport.YOffset {
+			current = i
+		}
+	}
+
+	var parts []string
+	for i, s := range m.sections {
+		label := fmt.Sprintf("%d:%s", i+1, s.name)
+		if i == current {
+			label = labelStyle.Render(label)
+		}
+		parts = append(parts, label)
+	}
+	return strings.Join(parts, " ")
+}
+
+// renderTransaction builds the review content and records the starting line
+// of each section so the reviewer can jump between them
+func (m *model) renderTransaction() (string, []section) {
 	tx := m.txParams.Transaction
 	var lines []string
+	var sections []section
+
+	mark := func(name string) {
+		sections = append(sections, section{name: name, line: len(lines)})
+	}
+
+	mark("Overview")
 
 	// Network information
 	lines = append(lines, labelStyle.Render("Network: ")+
 		networkStyle.Render(fmt.Sprintf("%s (Chain ID: %d)", m.txParams.Metadata.Network.Name, tx.ChainID)))
+	if after := m.txParams.Metadata.BroadcastAfter; after != "" {
+		lines = append(lines, labelStyle.Render("Broadcast: ")+
+			valueStyle.Render("scheduled for after "+after))
+	}
 	lines = append(lines, "")
 
 	// Transaction mode
@@ -166,68 +308,157 @@ func (m model) renderTransaction() string {
 	if m.txParams.FunctionName != "" {
 		lines = append(lines, labelStyle.Render("Function: ")+m.txParams.FunctionName)
 	}
+	lines = append(lines, m.inheritanceIDLines(tx.Data)...)
+
+	// Severity, from the calldata selector rather than the recorded mode
+	risk := contract.ClassifyCall(tx.To == nil, tx.Data, len(tx.AuthorizationList) > 0)
+	riskStyle := networkStyle
+	switch risk.Severity {
+	case contract.SeverityCaution:
+		riskStyle = modeStyle
+	case contract.SeverityAdmin, contract.SeverityDangerous:
+		riskStyle = costStyle
+	}
+	lines = append(lines, labelStyle.Render("Severity: ")+riskStyle.Render(risk.Tag()))
+	if risk.Severity == contract.SeverityAdmin || risk.Severity == contract.SeverityDangerous {
+		lines = append(lines, costStyle.Render("  Privileged operation: apply extra scrutiny before signing."))
+	}
 	lines = append(lines, "")
 
 	// Addresses
+	mark("Addresses")
 	lines = append(lines, labelStyle.Render("From: ")+tx.From.Hex())
 	if tx.To != nil {
-		lines = append(lines, labelStyle.Render("To: ")+tx.To.Hex())
+		to := tx.To.Hex()
+		if label, ok := m.addressLabels()[to]; ok {
+			to = fmt.Sprintf("%s (%s) %s", label, to, preparedLabelNote)
+		}
+		lines = append(lines, labelStyle.Render("To: ")+to)
 	} else {
 		lines = append(lines, labelStyle.Render("To: ")+deploymentStyle.Render("[Contract Deployment]"))
+		lines = append(lines, labelStyle.Render("Creates: ")+crypto.PredictContractAddress(tx.From, tx.Nonce).Hex()+" (from the sender and nonce)")
 	}
 	lines = append(lines, "")
 
-	// Value
-	if tx.Value != nil && tx.Value.ToBigInt().Sign() > 0 {
+	// Value. Token calls move the token amount, shown on its own line.
+	var callParams map[string]interface{}
+	_ = json.Unmarshal(m.txParams.Params, &callParams)
+	token, isToken := callParams["token"].(string)
+	if isToken && callParams["amount"] != nil {
+		lines = append(lines, labelStyle.Render("Token Amount: ")+
+			valueStyle.Render(m.tokenAmount(callParams["amount"]))+
+			fmt.Sprintf(" (%v base units of %s)", callParams["amount"], token))
+	}
+	// A zero value is always shown, so it cannot be mistaken for an omission.
+	// A recorded zero and an absent value field both send nothing, but are
+	// told apart.
+	noFunds := "no funds transferred"
+	if isToken {
+		noFunds = "no ETH transferred"
+	}
+	switch {
+	case tx.Value == nil:
+		lines = append(lines, labelStyle.Render("Value: ")+valueStyle.Render("0 ETH")+" (not set; "+noFunds+")")
+	case tx.Value.ToBigInt().Sign() == 0:
+		lines = append(lines, labelStyle.Render("Value: ")+valueStyle.Render("0 ETH")+" ("+noFunds+")")
+	default:
 		lines = append(lines, labelStyle.Render("Value: ")+
-			valueStyle.Render(network.FormatEth(tx.Value.ToBigInt())))
+			valueStyle.Render(types.FormatAmount(tx.Value.ToBigInt(), 18, "ETH"))+m.usdEstimate(tx.Value.ToBigInt()))
+	}
+	lines = append(lines, "")
+
+	// EIP-7702 authorizations
+	if len(tx.AuthorizationList) > 0 {
+		mark("Delegation")
+		lines = append(lines, labelStyle.Render("EIP-7702 Delegation:"))
+		for i, auth := range tx.AuthorizationList {
+			status := "unsigned (will be signed with this key)"
+			if auth.IsSigned() {
+				status = "pre-signed"
+			}
+			lines = append(lines, fmt.Sprintf("  [%d] Delegate: %s", i, auth.Address.Hex()))
+			lines = append(lines, fmt.Sprintf("      Chain ID: %d  Nonce: %d  Signature: %s", auth.ChainID, auth.Nonce, status))
+		}
+		lines = append(lines, costStyle.Render("⚠ Your account will run the delegate's code until the delegation is replaced."))
 		lines = append(lines, "")
 	}
 
 	// Nonce
+	mark("Gas")
+	lines = append(lines, labelStyle.Render("Type: ")+valueStyle.Render(types.TxTypeName(tx.TxType))+fmt.Sprintf(" (type %d)", tx.TxType))
 	lines = append(lines, labelStyle.Render("Nonce: ")+fmt.Sprintf("%d", tx.Nonce))
 	lines = append(lines, "")
 
 	// Gas parameters
 	lines = append(lines, labelStyle.Render("Gas Limit: ")+tx.GasLimit.ToBigInt().String())
 
-	if tx.TxType == 2 {
-		// EIP-1559
+	if tx.TxType == 2 || tx.TxType == 4 {
+		// EIP-1559 fee market (also used by EIP-7702)
 		lines = append(lines, labelStyle.Render("Max Fee Per Gas: ")+
-			fmt.Sprintf("%s gwei", weiToGwei(tx.MaxFeePerGas.ToBigInt())))
+			fmt.Sprintf("%s gwei", types.FormatGwei(tx.MaxFeePerGas.ToBigInt())))
 		lines = append(lines, labelStyle.Render("Max Priority Fee: ")+
-			fmt.Sprintf("%s gwei", weiToGwei(tx.MaxPriorityFeePerGas.ToBigInt())))
+			fmt.Sprintf("%s gwei", types.FormatGwei(tx.MaxPriorityFeePerGas.ToBigInt())))
 
 		// Estimate cost
-		maxCost := tx.MaxFeePerGas.ToBigInt()
-		maxCost.Mul(maxCost, tx.GasLimit.ToBigInt())
+		maxCost := new(big.Int).Mul(tx.MaxFeePerGas.ToBigInt(), tx.GasLimit.ToBigInt())
 		lines = append(lines, "")
 		lines = append(lines, labelStyle.Render("Estimated Max Cost: ")+
-			costStyle.Render(network.FormatEth(maxCost)))
+			costStyle.Render(types.FormatEth(maxCost))+m.usdEstimate(maxCost))
 	} else {
 		// Legacy
 		lines = append(lines, labelStyle.Render("Gas Price: ")+
-			fmt.Sprintf("%s gwei", weiToGwei(tx.GasPrice.ToBigInt())))
+			fmt.Sprintf("%s gwei", types.FormatGwei(tx.GasPrice.ToBigInt())))
 
 		// Estimate cost
-		cost := tx.GasPrice.ToBigInt()
-		cost.Mul(cost, tx.GasLimit.ToBigInt())
+		cost := new(big.Int).Mul(tx.GasPrice.ToBigInt(), tx.GasLimit.ToBigInt())
 		lines = append(lines, "")
 		lines = append(lines, labelStyle.Render("Estimated Cost: ")+
-			costStyle.Render(network.FormatEth(cost)))
+			costStyle.Render(types.FormatEth(cost))+m.usdEstimate(cost))
+	}
+
+	// Serialized size and L2 data fee (recorded at prepare time)
+	info := m.txParams.Metadata.AdditionalInfo
+	if size, ok := m.recordedUint("serialized_size_bytes"); ok {
+		lines = append(lines, labelStyle.Render("Serialized Size: ")+
+			fmt.Sprintf("%d bytes (%d bytes calldata)", int(size), len(tx.Data)))
+	}
+	if feeStr, ok := info["l1_data_fee_wei"].(string); ok {
+		if fee, ok := new(big.Int).SetString(feeStr, 10); ok {
+			lines = append(lines, labelStyle.Render("Estimated L1 Data Fee: ")+
+				costStyle.Render(types.FormatEth(fee))+m.usdEstimate(fee)+" (charged on top of L2 gas)")
+		}
+	}
+
+	if edits := m.txParams.Metadata.ReviewEdits; len(edits) > 0 {
+		lines = append(lines, modeStyle.Render("⚠ Edited during review (recorded in the signed file):"))
+		for _, edit := range edits {
+			lines = append(lines, modeStyle.Render(fmt.Sprintf("  %s: %s -> %s", edit.Field, edit.Prepared, edit.Signed)))
+		}
+	}
+
+	if note := m.priceNote(); note != "" {
+		lines = append(lines, controlsStyle.Render(note))
 	}
 
+	m.balanceLines(&lines, mark)
+	m.decodedLines(&lines, mark)
+	m.claimWindowLines(&lines, mark)
+
 	// Function parameters (if available)
+	m.paramCount = 0
 	if len(m.txParams.Params) > 0 {
 		lines = append(lines, "")
-		lines = append(lines, labelStyle.Render("Parameters:"))
 
 		// Pretty print JSON params
 		var params map[string]interface{}
 		if err := json.Unmarshal(m.txParams.Params, &params); err == nil {
+			m.paramCount = len(params)
+			m.annotateAmounts(params, isToken)
+			mark("Parameters")
+			lines = append(lines, labelStyle.Render(fmt.Sprintf("Parameters (%d):", len(params))))
 			prettyJSON, err := json.MarshalIndent(params, "", "  ")
 			if err == nil {
-				lines = append(lines, string(prettyJSON))
+				lines = append(lines, strings.Split(string(prettyJSON), "\n")...)
 			}
 		}
 	}
@@ -235,6 +466,7 @@ func (m model) renderTransaction() string {
 	// Data preview (first 32 bytes)
 	if len(tx.Data) > 0 {
 		lines = append(lines, "")
+		mark("Data")
 		lines = append(lines, labelStyle.Render("Data (preview): "))
 		preview := tx.Data
 		if len(preview) > 64 {
@@ -244,29 +476,253 @@ func (m model) renderTransaction() string {
 		lines = append(lines, fmt.Sprintf("(%d bytes total)", len(tx.Data)))
 	}
 
+	if m.showPayload {
+		m.payloadLines(&lines, mark)
+	}
+
 	// Warning message
 	lines = append(lines, "")
 	lines = append(lines, "")
+	mark("Warnings")
 	lines = append(lines, costStyle.Render("⚠ WARNING: Review all details carefully before signing!"))
 	lines = append(lines, costStyle.Render("⚠ This action is irreversible once broadcast."))
 
-	return strings.Join(lines, "\n")
+	return strings.Join(lines, "\n"), sections
 }
 
-// weiToGwei converts wei to gwei for display
-func weiToGwei(wei interface{}) string {
-	// Convert to float for display
-	var weiFloat float64
-	switch v := wei.(type) {
-	case *types.BigInt:
-		if v == nil || v.Int == nil {
-			return "0"
-		}
-		weiFloat = float64(v.Int.Int64())
+// decodedLines shows the decoded call. The local ABI decoding is preferred;
+// the decoding embedded by 'prepare --bundle' is only shown when the call
+// cannot be decoded locally, and any disagreement between the two is flagged.
+func (m *model) decodedLines(lines *[]string, mark func(string)) {
+	tx := m.txParams.Transaction
+	embedded := m.txParams.Decoded
+	local, err := contract.DecodeCall(tx.To, tx.Data, tx.Value.ToBigInt())
+
+	var call *types.DecodedCall
+	var label string
+	switch {
+	case err == nil:
+		call, label = local, "Decoded Call (local ABI):"
+	case m.fragmentCall() != nil:
+		call, label = m.fragmentCall(), "Decoded Call (file's ABI fragment, checked against the calldata):"
+	case embedded != nil:
+		call, label = embedded, "Decoded Call (from bundle, NOT verified by a local ABI):"
 	default:
-		weiFloat, _ = v.(float64)
+		return
+	}
+
+	*lines = append(*lines, "")
+	mark("Decoded Call")
+	*lines = append(*lines, labelStyle.Render(label))
+	*lines = append(*lines, "  "+valueStyle.Render(call.Summary))
+	if call.Function != "" {
+		*lines = append(*lines, "  "+call.Function)
+	}
+	names := m.ensNames()
+	labels := m.addressLabels()
+	for _, arg := range call.Args {
+		value := arg.Value
+		if label, ok := labels[value]; ok {
+			value = fmt.Sprintf("%s (%s) %s", label, value, preparedLabelNote)
+		} else if name, ok := names[value]; ok {
+			value = fmt.Sprintf("%s (%s)", name, value)
+		}
+		*lines = append(*lines, fmt.Sprintf("    %s (%s): %s", arg.Name, arg.Type, value))
+	}
+	if len(names) > 0 {
+		at, _ := m.txParams.Metadata.AdditionalInfo["ens_resolved_at"].(string)
+		*lines = append(*lines, costStyle.Render(fmt.Sprintf("⚠ ENS names were resolved online at prepare time (%s) and cannot be re-checked offline;", at)))
+		*lines = append(*lines, costStyle.Render("  you are trusting that resolution. Verify the address itself."))
+	}
+
+	if fragment := m.fragmentCall(); err == nil && fragment != nil && fragment.Function != local.Function {
+		*lines = append(*lines, costStyle.Render("⚠ The file's ABI fragment describes a different function: "+fragment.Function))
+	}
+	if err != nil && m.fragmentCall() != nil {
+		*lines = append(*lines, costStyle.Render("⚠ The parameter names come from the file; the types and values are decoded from the calldata."))
+	}
+
+	if err == nil && embedded != nil && !embedded.Equal(local) {
+		*lines = append(*lines, costStyle.Render("⚠ The decoding embedded in the bundle does NOT match the calldata:"))
+		*lines = append(*lines, costStyle.Render("  "+embedded.Summary))
+		*lines = append(*lines, costStyle.Render("⚠ Do not sign unless you understand why they differ."))
+	}
+}
+
+// claimWindowLines states who can settle a deposit's inheritance and when.
+// The window is derived from the deadline in the calldata, not from the
+// metadata, so it describes exactly what is signed.
+func (m *model) claimWindowLines(lines *[]string, mark func(string)) {
+	deposit, err := contract.DecodeDeposit(m.txParams.Transaction.Data)
+	if err != nil {
+		return
+	}
+	window := contract.NewClaimWindow(deposit.Deadline)
+
+	*lines = append(*lines, "")
+	mark("Claim Window")
+	*lines = append(*lines, labelStyle.Render("Claim Window:"))
+	for _, rule := range window.Rules() {
+		*lines = append(*lines, "  "+rule)
+	}
+	if t, ok := window.DeadlineTime(); ok && !t.After(time.Now()) {
+		*lines = append(*lines, costStyle.Render("⚠ By this machine's clock the deadline has passed: the contract rejects the deposit (InvalidDeadline), so it would revert and only cost gas."))
+	}
+}
+
+// fragmentCall decodes the call with the ABI fragment of a self-contained
+// file, or returns nil when there is none or it does not fit the calldata
+func (m *model) fragmentCall() *types.DecodedCall {
+	sc := m.txParams.SelfContained
+	if sc == nil || len(sc.ABI) == 0 {
+		return nil
+	}
+	tx := m.txParams.Transaction
+	call, err := contract.DecodeCallWithFragment(sc.ABI, tx.To, tx.Data, tx.Value.ToBigInt())
+	if err != nil {
+		return nil
+	}
+	return call
+}
+
+// tokenAmount formats a token amount parameter in token units, using the
+// symbol and decimals recorded at prepare time. Without them the amount is
+// shown as grouped base units.
+func (m *model) tokenAmount(raw interface{}) string {
+	amount, ok := new(big.Int).SetString(fmt.Sprint(raw), 10)
+	if !ok {
+		return fmt.Sprint(raw)
+	}
+	info := m.txParams.Metadata.AdditionalInfo
+	decimals, ok := m.recordedUint("token_decimals")
+	if !ok || decimals > math.MaxUint8 {
+		return types.FormatAmount(amount, 0, "base units")
+	}
+	symbol, _ := info["token_symbol"].(string)
+	if symbol == "" {
+		symbol = "tokens"
+	}
+	return types.FormatAmount(amount, uint8(decimals), symbol)
+}
+
+// annotateAmounts adds the formatted amount next to raw amount parameters
+// for display
+func (m *model) annotateAmounts(params map[string]interface{}, isToken bool) {
+	if raw, ok := params["amount"]; ok {
+		formatted := m.tokenAmount(raw)
+		if !isToken {
+			if amount, ok := new(big.Int).SetString(fmt.Sprint(raw), 10); ok {
+				formatted = types.FormatAmount(amount, 18, "ETH")
+			}
+		}
+		params["amount"] = fmt.Sprintf("%v (%s)", raw, formatted)
+	}
+}
+
+// ensNames returns the ENS names resolved at prepare time, by checksummed
+// address
+func (m *model) ensNames() map[string]string {
+	return m.recordedNames("ens_names")
+}
+
+// inheritanceIDLines shows the inheritance a claim, reclaim or
+// extendDeadline acts on. The ID is decoded from the calldata, which is what
+// gets signed; the ID in the params file is only checked against it.
+func (m *model) inheritanceIDLines(data []byte) []string {
+	var recorded struct {
+		InheritanceID string `json:"inheritance_id"`
+	}
+	_ = json.Unmarshal(m.txParams.Params, &recorded)
+
+	_, id, err := contract.DecodeInheritanceCall(data)
+	if err != nil {
+		if recorded.InheritanceID == "" {
+			return nil
+		}
+		return []string{
+			labelStyle.Render("Inheritance ID: ") + costStyle.Render(recorded.InheritanceID+" (from the params file only)"),
+			costStyle.Render("⚠ The calldata is not a call on an inheritance, although the params file names one. Do not sign unless you understand why."),
+		}
+	}
+
+	lines := []string{labelStyle.Render("Inheritance ID: ") + valueStyle.Render(id.String())}
+	if recorded.InheritanceID != "" {
+		if want, ok := new(big.Int).SetString(strings.TrimSpace(recorded.InheritanceID), 0); !ok || want.Cmp(id) != 0 {
+			lines = append(lines, costStyle.Render(fmt.Sprintf(
+				"⚠ MISMATCH: the params file says inheritance %s, but the calldata being signed acts on %s. Do not sign.",
+				recorded.InheritanceID, id)))
+		}
+	}
+	return lines
+}
+
+// preparedLabelNote follows an address book label in the review. The label
+// comes from the file being signed, not from an address book on this
+// machine, so it is only as trustworthy as the preparing machine.
+const preparedLabelNote = "(labelled at prepare time)"
+
+// addressLabels returns the address book labels used at prepare time, by
+// checksummed address
+func (m *model) addressLabels() map[string]string {
+	return m.recordedNames("address_labels")
+}
+
+// recordedNames reads an address-to-name map from the metadata, as written
+// (map[string]string) or as decoded from JSON (map[string]interface{})
+func (m *model) recordedNames(key string) map[string]string {
+	names := make(map[string]string)
+	switch recorded := m.txParams.Metadata.AdditionalInfo[key].(type) {
+	case map[string]string:
+		for addr, name := range recorded {
+			names[addr] = name
+		}
+	case map[string]interface{}:
+		for addr, name := range recorded {
+			if s, ok := name.(string); ok {
+				names[addr] = s
+			}
+		}
 	}
+	return names
+}
+
+// usdPrice returns the native token price snapshotted at prepare time, or 0
+func (m *model) usdPrice() float64 {
+	price, _ := m.txParams.Metadata.AdditionalInfo["price_usd"].(float64)
+	return price
+}
 
-	gwei := weiFloat / 1e9
-	return fmt.Sprintf("%.2f", gwei)
+// usdEstimate formats a wei amount as an approximate USD suffix, or returns
+// "" when no price was recorded
+func (m *model) usdEstimate(wei *big.Int) string {
+	price := m.usdPrice()
+	if price <= 0 || wei == nil {
+		return ""
+	}
+	eth, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e18)).Float64()
+	return " (~$" + formatUSD(eth*price) + ")"
+}
+
+// priceNote describes the price snapshot behind the USD estimates
+func (m *model) priceNote() string {
+	price := m.usdPrice()
+	if price <= 0 {
+		return ""
+	}
+	at, _ := m.txParams.Metadata.AdditionalInfo["price_snapshot_at"].(string)
+	return fmt.Sprintf("USD values are estimates at $%s per native token, snapshotted %s", formatUSD(price), at)
+}
+
+// formatUSD formats a dollar amount with two decimals and thousands separators
+func formatUSD(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	intPart, frac, _ := strings.Cut(s, ".")
+	var b strings.Builder
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	return b.String() + "." + frac
 }
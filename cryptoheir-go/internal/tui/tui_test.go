@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/contract"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+)
+
+func TestTokenAmountDecimalsKinds(t *testing.T) {
+	const want = "1,500,000.000000 USDC"
+	tests := []struct {
+		name     string
+		decimals interface{}
+		want     string
+	}{
+		{"float64 from JSON", float64(6), want},
+		{"uint64 from CBOR", uint64(6), want},
+		{"int64 from CBOR", int64(6), want},
+		{"uint8 from prepare", uint8(6), want},
+		{"int", 6, want},
+		{"missing", nil, "1,500,000,000,000 base units"},
+		{"fractional", 6.5, "1,500,000,000,000 base units"},
+		{"negative", -6, "1,500,000,000,000 base units"},
+		{"too large", uint64(256), "1,500,000,000,000 base units"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := map[string]interface{}{"token_symbol": "USDC"}
+			if tt.decimals != nil {
+				info["token_decimals"] = tt.decimals
+			}
+			m := &model{txParams: &types.TxParams{Metadata: types.Metadata{AdditionalInfo: info}}}
+			if got := m.tokenAmount("1500000000000"); got != tt.want {
+				t.Errorf("tokenAmount() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInheritanceIDLines(t *testing.T) {
+	if err := contract.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	claim7, err := contract.EncodeClaim(big.NewInt(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name         string
+		params       string
+		data         []byte
+		wantID       string // "" for no ID line
+		wantMismatch bool
+	}{
+		{"matching", `{"inheritance_id":"7"}`, claim7, "7", false},
+		{"tampered params", `{"inheritance_id":"3"}`, claim7, "7", true},
+		{"no recorded ID", `{}`, claim7, "7", false},
+		{"ID without an inheritance call", `{"inheritance_id":"7"}`, []byte{0xde, 0xad, 0xbe, 0xef}, "7 (from the params file only)", false},
+		{"neither", `{}`, nil, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &model{txParams: &types.TxParams{Params: json.RawMessage(tt.params)}}
+			text := strings.Join(m.inheritanceIDLines(tt.data), "\n")
+			if tt.wantID == "" {
+				if text != "" {
+					t.Errorf("lines = %q, want none", text)
+				}
+				return
+			}
+			if !strings.Contains(text, "Inheritance ID: ") || !strings.Contains(text, tt.wantID) {
+				t.Errorf("lines = %q, want inheritance ID %s", text, tt.wantID)
+			}
+			if mismatch := strings.Contains(text, "MISMATCH"); mismatch != tt.wantMismatch {
+				t.Errorf("mismatch flagged = %v, want %v in %q", mismatch, tt.wantMismatch, text)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// WizardField describes one step of an input wizard
+type WizardField struct {
+	Label       string
+	Help        string
+	Placeholder string
+	Value       string // Initial value
+
+	// Validate checks the entered value and returns a normalized description
+	// shown to the user for confirmation (e.g. a checksummed address)
+	Validate func(string) (string, error)
+}
+
+var hintStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("240"))
+
+// wizardModel represents the wizard TUI state
+type wizardModel struct {
+	title      string
+	fields     []WizardField
+	values     []string
+	step       int
+	input      textinput.Model
+	normalized string // Set while waiting for confirmation of the current step
+	err        error
+	cancelled  bool
+	done       bool
+}
+
+// IsTerminal reports whether stdin is an interactive terminal
+func IsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// RunWizard prompts for each field in turn, asking the user to confirm the
+// normalized value before moving on. Returns the entered values, or false if
+// the user cancelled.
+func RunWizard(title string, fields []WizardField) ([]string, bool, error) {
+	m := newWizardModel(title, fields)
+
+	p := tea.NewProgram(m)
+	finalModel, err := p.Run()
+	if err != nil {
+		return nil, false, fmt.Errorf("TUI error: %w", err)
+	}
+
+	result := finalModel.(wizardModel)
+	if result.cancelled || !result.done {
+		return nil, false, nil
+	}
+	return result.values, true, nil
+}
+
+func newWizardModel(title string, fields []WizardField) wizardModel {
+	m := wizardModel{
+		title:  title,
+		fields: fields,
+		values: make([]string, len(fields)),
+		input:  textinput.New(),
+	}
+	m.loadStep()
+	return m
+}
+
+// loadStep resets the input for the current step
+func (m *wizardModel) loadStep() {
+	field := m.fields[m.step]
+	m.input.Reset()
+	m.input.Placeholder = field.Placeholder
+	m.input.SetValue(field.Value)
+	m.input.CursorEnd()
+	m.input.Focus()
+	m.normalized = ""
+	m.err = nil
+}
+
+func (m wizardModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m wizardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			m.cancelled = true
+			return m, tea.Quit
+		}
+
+		// Awaiting confirmation of a validated value
+		if m.normalized != "" {
+			switch msg.String() {
+			case "y", "Y", "enter":
+				m.values[m.step] = strings.TrimSpace(m.input.Value())
+				m.step++
+				if m.step == len(m.fields) {
+					m.done = true
+					return m, tea.Quit
+				}
+				m.loadStep()
+			case "n", "N":
+				// Edit the value again
+				m.normalized = ""
+				m.input.Focus()
+			}
+			return m, nil
+		}
+
+		if msg.Type == tea.KeyEnter {
+			value := strings.TrimSpace(m.input.Value())
+			normalized, err := m.fields[m.step].Validate(value)
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.err = nil
+			m.normalized = normalized
+			m.input.Blur()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m wizardModel) View() string {
+	if m.done || m.cancelled {
+		return ""
+	}
+
+	field := m.fields[m.step]
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render(m.title))
+	b.WriteString("\n")
+	b.WriteString(labelStyle.Render(fmt.Sprintf("Step %d/%d: %s", m.step+1, len(m.fields), field.Label)))
+	b.WriteString("\n")
+	if field.Help != "" {
+		b.WriteString(hintStyle.Render(field.Help))
+		b.WriteString("\n")
+	}
+	b.WriteString(m.input.View())
+	b.WriteString("\n\n")
+
+	if m.err != nil {
+		b.WriteString(costStyle.Render("✗ " + m.err.Error()))
+		b.WriteString("\n")
+	}
+
+	if m.normalized != "" {
+		b.WriteString(valueStyle.Render("→ " + m.normalized))
+		b.WriteString("\n\n")
+		b.WriteString("Is this correct? [Y/Enter] Yes  [N] Edit  [Esc] Cancel")
+	} else {
+		b.WriteString(controlsStyle.Render("[Enter] Check value  [Esc] Cancel"))
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}
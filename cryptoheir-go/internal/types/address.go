@@ -0,0 +1,41 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ParseAddress strictly parses a hex address. Unlike common.HexToAddress it
+// rejects empty, non-hex and wrong-length input instead of silently returning
+// a truncated, padded or zero address, and it verifies the EIP-55 checksum
+// when the input uses mixed case.
+func ParseAddress(s string) (common.Address, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return common.Address{}, fmt.Errorf("address is empty")
+	}
+
+	hexPart := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	for _, c := range hexPart {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return common.Address{}, fmt.Errorf("address %q is not valid hex", s)
+		}
+	}
+	if len(hexPart) != 2*common.AddressLength {
+		return common.Address{}, fmt.Errorf("address %q has %d hex characters, expected %d",
+			s, len(hexPart), 2*common.AddressLength)
+	}
+
+	addr := common.HexToAddress(hexPart)
+
+	// Mixed case means the input claims to be checksummed
+	if hexPart != strings.ToLower(hexPart) && hexPart != strings.ToUpper(hexPart) {
+		if "0x"+hexPart != addr.Hex() {
+			return common.Address{}, fmt.Errorf("address %q has an invalid EIP-55 checksum (expected %s)", s, addr.Hex())
+		}
+	}
+
+	return addr, nil
+}
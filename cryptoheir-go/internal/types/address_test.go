@@ -0,0 +1,40 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestParseAddress(t *testing.T) {
+	checksummed := "0x9d8A62f656a8d1615C1294fd71e9CFb3E4855A4F"
+	tests := []struct {
+		name    string
+		input   string
+		want    common.Address
+		wantErr bool
+	}{
+		{"checksummed", checksummed, common.HexToAddress(checksummed), false},
+		{"lower case", "0x9d8a62f656a8d1615c1294fd71e9cfb3e4855a4f", common.HexToAddress(checksummed), false},
+		{"upper case", "0x9D8A62F656A8D1615C1294FD71E9CFB3E4855A4F", common.HexToAddress(checksummed), false},
+		{"no prefix", "9d8a62f656a8d1615c1294fd71e9cfb3e4855a4f", common.HexToAddress(checksummed), false},
+		{"surrounding space", " " + checksummed + "\n", common.HexToAddress(checksummed), false},
+		{"explicit zero address", "0x0000000000000000000000000000000000000000", common.Address{}, false},
+		{"empty", "", common.Address{}, true},
+		{"not hex", "0xnothex", common.Address{}, true},
+		{"too short", "0x123", common.Address{}, true},
+		{"too long", checksummed + "00", common.Address{}, true},
+		{"bad checksum", "0x9D8a62f656a8d1615C1294fd71e9CFb3E4855A4F", common.Address{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAddress(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAddress(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAddress(%q) = %s, want %s", tt.input, got.Hex(), tt.want.Hex())
+			}
+		})
+	}
+}
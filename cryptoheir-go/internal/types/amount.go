@@ -0,0 +1,113 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// FormatAmount formats a base-unit amount with all of its decimals and
+// thousands separators in the integer part, followed by the symbol if given,
+// e.g. "1,500,000.000000 USDC". Grouping makes an order-of-magnitude misread
+// much less likely than with raw base units.
+func FormatAmount(raw *big.Int, decimals uint8, symbol string) string {
+	if raw == nil {
+		raw = new(big.Int)
+	}
+
+	digits := new(big.Int).Abs(raw).String()
+	if len(digits) <= int(decimals) {
+		digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+	}
+	intPart, fracPart := digits[:len(digits)-int(decimals)], digits[len(digits)-int(decimals):]
+
+	var b strings.Builder
+	if raw.Sign() < 0 {
+		b.WriteByte('-')
+	}
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	if decimals > 0 {
+		b.WriteByte('.')
+		b.WriteString(fracPart)
+	}
+	if symbol != "" {
+		b.WriteByte(' ')
+		b.WriteString(symbol)
+	}
+	return b.String()
+}
+
+// FormatUnits formats a base-unit amount exactly in whole units with the
+// given number of decimals, without trailing zeros, e.g. 1500000 with 6
+// decimals is "1.5"
+func FormatUnits(amount *big.Int, decimals uint8) string {
+	if amount == nil {
+		return "0"
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	s := new(big.Rat).SetFrac(amount, scale).FloatString(int(decimals))
+	if strings.Contains(s, ".") {
+		s = strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// decimalPattern matches a plain non-negative decimal; big.Rat alone would
+// also accept signs, exponents, fractions, hex and digit separators
+var decimalPattern = regexp.MustCompile(`^([0-9]+(\.[0-9]*)?|\.[0-9]+)$`)
+
+// ParseUnits converts a decimal string (e.g. "1.5") to base units with the
+// given number of decimals, rejecting values with excess precision. It is
+// the inverse of FormatUnits.
+func ParseUnits(s string, decimals uint8) (*big.Int, error) {
+	s = strings.TrimSpace(s)
+	if !decimalPattern.MatchString(s) {
+		return nil, fmt.Errorf("invalid number format: %q", s)
+	}
+
+	amount, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid number format: %q", s)
+	}
+	if _, frac, ok := strings.Cut(s, "."); ok && len(frac) > int(decimals) {
+		return nil, fmt.Errorf("amount has more than %d decimal places", decimals)
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	amount.Mul(amount, new(big.Rat).SetInt(scale))
+	if !amount.IsInt() {
+		return nil, fmt.Errorf("amount has more than %d decimal places", decimals)
+	}
+
+	return amount.Num(), nil
+}
+
+// FormatEther formats a wei amount exactly in ETH, without a unit, e.g. "1.5"
+func FormatEther(wei *big.Int) string {
+	return FormatUnits(wei, 18)
+}
+
+// FormatEth formats a wei amount in ETH rounded to six decimals, with the
+// unit, e.g. "1.500000 ETH"
+func FormatEth(wei *big.Int) string {
+	if wei == nil {
+		wei = new(big.Int)
+	}
+	return new(big.Rat).SetFrac(wei, big.NewInt(1e18)).FloatString(6) + " ETH"
+}
+
+// FormatGwei formats a wei amount in gwei rounded to two decimals, e.g.
+// "1.50". It is computed with big.Rat, so fees beyond the int64 range display
+// correctly; use FormatUnits(wei, 9) where the exact value matters.
+func FormatGwei(wei *big.Int) string {
+	if wei == nil {
+		return "0"
+	}
+	return new(big.Rat).SetFrac(wei, big.NewInt(1e9)).FloatString(2)
+}
@@ -0,0 +1,149 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+)
+
+// bigInt parses a decimal integer for test tables
+func bigInt(t *testing.T, s string) *big.Int {
+	t.Helper()
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		t.Fatalf("invalid integer %q", s)
+	}
+	return v
+}
+
+func TestFormatGwei(t *testing.T) {
+	tests := []struct {
+		wei  string
+		want string
+	}{
+		{"0", "0.00"},
+		{"1500000000", "1.50"},
+		{"1", "0.00"},
+		{"4990000", "0.00"},
+		{"5000000", "0.01"}, // rounds half up
+		{"123456789", "0.12"},
+		// Beyond int64 (9.2e18 wei), where a float64 conversion of Int64() overflows
+		{"9223372036854775808", "9223372036.85"},
+		{"1000000000000000000000000", "1000000000000000.00"},
+		{"123456789012345678901234567890", "123456789012345678901.23"},
+	}
+	for _, tt := range tests {
+		if got := FormatGwei(bigInt(t, tt.wei)); got != tt.want {
+			t.Errorf("FormatGwei(%s) = %q, want %q", tt.wei, got, tt.want)
+		}
+	}
+	if got := FormatGwei(nil); got != "0" {
+		t.Errorf("FormatGwei(nil) = %q, want \"0\"", got)
+	}
+}
+
+func TestFormatUnits(t *testing.T) {
+	tests := []struct {
+		amount   string
+		decimals uint8
+		want     string
+	}{
+		{"0", 18, "0"},
+		{"1", 18, "0.000000000000000001"},
+		{"1000000000000000000", 18, "1"},
+		{"1500000000000000000", 18, "1.5"},
+		{"1000000000000000000000000", 18, "1000000"},
+		{"1500000", 6, "1.5"},
+		{"1", 9, "0.000000001"},
+		{"340282366920938463463374607431768211455", 18, "340282366920938463463.374607431768211455"},
+		{"42", 0, "42"},
+		{"-1500000", 6, "-1.5"},
+	}
+	for _, tt := range tests {
+		if got := FormatUnits(bigInt(t, tt.amount), tt.decimals); got != tt.want {
+			t.Errorf("FormatUnits(%s, %d) = %q, want %q", tt.amount, tt.decimals, got, tt.want)
+		}
+	}
+	if got := FormatUnits(nil, 18); got != "0" {
+		t.Errorf("FormatUnits(nil, 18) = %q, want \"0\"", got)
+	}
+	if got := FormatEther(bigInt(t, "2500000000000000000")); got != "2.5" {
+		t.Errorf("FormatEther(2.5 ETH) = %q, want \"2.5\"", got)
+	}
+}
+
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		input    string
+		decimals uint8
+		want     string // empty when an error is expected
+	}{
+		{"12.345678", 6, "12345678"},
+		{"0.1234567", 6, ""},
+		{"42", 0, "42"},
+		{"42.", 0, "42"},
+		{"4.2", 0, ""},
+		{"1.5", 9, "1500000000"},
+		{"0.000000001", 9, "1"},
+		{"1/2", 9, ""},
+		{"1e3", 9, ""},
+		{"0x10", 0, ""},
+	}
+	for _, tt := range tests {
+		got, err := ParseUnits(tt.input, tt.decimals)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("ParseUnits(%q, %d) = %s, want an error", tt.input, tt.decimals, got)
+			}
+			continue
+		}
+		if err != nil || got.String() != tt.want {
+			t.Errorf("ParseUnits(%q, %d) = %v, %v; want %s", tt.input, tt.decimals, got, err, tt.want)
+		}
+	}
+
+	// ParseUnits inverts FormatUnits
+	for _, amount := range []string{"0", "1", "1500000", "340282366920938463463374607431768211455"} {
+		v := bigInt(t, amount)
+		if got, err := ParseUnits(FormatUnits(v, 18), 18); err != nil || got.Cmp(v) != 0 {
+			t.Errorf("ParseUnits(FormatUnits(%s)) = %v, %v", amount, got, err)
+		}
+	}
+}
+
+func TestFormatEth(t *testing.T) {
+	tests := []struct {
+		wei  string
+		want string
+	}{
+		{"0", "0.000000 ETH"},
+		{"1500000000000000000", "1.500000 ETH"},
+		{"1", "0.000000 ETH"},
+		{"500000000000", "0.000001 ETH"}, // rounds half up
+		{"123456789012345678901234", "123456.789012 ETH"},
+	}
+	for _, tt := range tests {
+		if got := FormatEth(bigInt(t, tt.wei)); got != tt.want {
+			t.Errorf("FormatEth(%s) = %q, want %q", tt.wei, got, tt.want)
+		}
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		raw      string
+		decimals uint8
+		symbol   string
+		want     string
+	}{
+		{"1500000000000", 6, "USDC", "1,500,000.000000 USDC"},
+		{"1", 18, "", "0.000000000000000001"},
+		{"999", 0, "", "999"},
+		{"1000", 0, "", "1,000"},
+		{"-1234567", 2, "EUR", "-12,345.67 EUR"},
+	}
+	for _, tt := range tests {
+		if got := FormatAmount(bigInt(t, tt.raw), tt.decimals, tt.symbol); got != tt.want {
+			t.Errorf("FormatAmount(%s, %d, %q) = %q, want %q", tt.raw, tt.decimals, tt.symbol, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// cborMagic is the self-described CBOR tag (55799) that starts every CBOR
+// file written by this tool, so readers can tell CBOR from JSON
+var cborMagic = []byte{0xd9, 0xd9, 0xf7}
+
+var (
+	cborEnc cbor.EncMode
+	cborDec cbor.DecMode
+)
+
+func init() {
+	var err error
+	// Deterministic encoding, so the same data always yields the same bytes
+	if cborEnc, err = cbor.CoreDetEncOptions().EncMode(); err != nil {
+		panic(err)
+	}
+	// Nested maps decode with string keys, as they do from JSON
+	if cborDec, err = (cbor.DecOptions{DefaultMapType: reflect.TypeOf(map[string]interface{}(nil))}).DecMode(); err != nil {
+		panic(err)
+	}
+}
+
+// IsCBOR reports whether file contents are in the compact CBOR format rather
+// than JSON
+func IsCBOR(data []byte) bool {
+	return bytes.HasPrefix(data, cborMagic)
+}
+
+// EncodeCBOR serializes a transaction parameters or signed transaction file in
+// the compact CBOR format. Field names are the JSON names, but byte fields
+// (calldata, the signed transaction, addresses and hashes) are stored as raw
+// bytes instead of base64 or hex text, which roughly halves the size.
+func EncodeCBOR(v interface{}) ([]byte, error) {
+	data, err := cborEnc.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode CBOR: %w", err)
+	}
+	return append(append([]byte{}, cborMagic...), data...), nil
+}
+
+// cborToJSON converts a CBOR file to its JSON form by decoding it into a new
+// value of v's type. Decoding then continues through the JSON path, so a CBOR
+// file yields exactly what its JSON equivalent would.
+func cborToJSON(data []byte, path string, v interface{}) ([]byte, error) {
+	body := data[len(cborMagic):]
+
+	// Check the version before decoding the rest, as for JSON
+	var header struct {
+		SchemaVersion string `json:"schema_version"`
+	}
+	if err := cborDec.Unmarshal(body, &header); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := CheckSchemaVersion(header.SchemaVersion); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	typed := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+	if err := cborDec.Unmarshal(body, typed); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	converted, err := json.Marshal(typed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s from CBOR: %w", path, err)
+	}
+	return converted, nil
+}
+
+// MarshalCBOR implements cbor.Marshaler. The value is stored as a CBOR
+// integer, or a bignum if it does not fit in 64 bits.
+func (b *BigInt) MarshalCBOR() ([]byte, error) {
+	return cborEnc.Marshal(b.ToBigInt())
+}
+
+// UnmarshalCBOR implements cbor.Unmarshaler
+func (b *BigInt) UnmarshalCBOR(data []byte) error {
+	i := new(big.Int)
+	if err := cborDec.Unmarshal(data, i); err != nil {
+		return fmt.Errorf("invalid big integer: %w", err)
+	}
+	b.Int = i
+	return nil
+}
@@ -0,0 +1,162 @@
+package types_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/crypto"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func cborTestTxParams() *types.TxParams {
+	to := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	huge, _ := new(big.Int).SetString("115792089237316195423570985008687907853269984665640564039457584007913129639935", 10)
+	return &types.TxParams{
+		SchemaVersion: types.SchemaVersion,
+		Mode:          types.TransactionModeCall,
+		FunctionName:  "deposit",
+		Params:        json.RawMessage(`{"beneficiary":"0x2222222222222222222222222222222222222222","amount":"1500000000000000000"}`),
+		Transaction: types.TransactionData{
+			TxType:               2,
+			From:                 testSigner,
+			To:                   &to,
+			Data:                 []byte{0xde, 0xad, 0xbe, 0xef},
+			Nonce:                7,
+			ChainID:              11155111,
+			GasLimit:             types.NewBigInt(big.NewInt(120000)),
+			MaxFeePerGas:         types.NewBigInt(big.NewInt(30_000_000_000)),
+			MaxPriorityFeePerGas: types.NewBigInt(big.NewInt(1_500_000_000)),
+			Value:                types.NewBigInt(huge), // does not fit in 64 bits
+			AuthorizationList: []types.Authorization{
+				{ChainID: 11155111, Address: to, Nonce: 8},
+			},
+		},
+		Metadata: types.Metadata{
+			PreparedAt: "2026-01-02T03:04:05Z",
+			Network:    types.NetworkInfo{Name: "sepolia", ChainID: 11155111},
+			Label:      "alice",
+			AdditionalInfo: map[string]interface{}{
+				"token_symbol":   "USDC",
+				"token_decimals": 6,
+				"nested":         map[string]interface{}{"list": []interface{}{"a", 1}},
+			},
+		},
+	}
+}
+
+// loadBoth writes v as JSON and as CBOR and loads each file with load
+func loadBoth[T any](t *testing.T, v interface{}, load func(string) (T, error)) (fromJSON, fromCBOR T, jsonSize, cborSize int) {
+	t.Helper()
+	dir := t.TempDir()
+
+	jsonData, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cborData, err := types.EncodeCBOR(v)
+	if err != nil {
+		t.Fatalf("EncodeCBOR() error = %v", err)
+	}
+	if !types.IsCBOR(cborData) || types.IsCBOR(jsonData) {
+		t.Fatal("IsCBOR() does not tell the formats apart")
+	}
+
+	jsonPath := filepath.Join(dir, "file.json")
+	cborPath := filepath.Join(dir, "file.cbor")
+	if err := os.WriteFile(jsonPath, jsonData, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cborPath, cborData, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if fromJSON, err = load(jsonPath); err != nil {
+		t.Fatalf("loading JSON: %v", err)
+	}
+	if fromCBOR, err = load(cborPath); err != nil {
+		t.Fatalf("loading CBOR: %v", err)
+	}
+	return fromJSON, fromCBOR, len(jsonData), len(cborData)
+}
+
+func TestCBORTxParamsMatchesJSON(t *testing.T) {
+	txParams := cborTestTxParams()
+	fromJSON, fromCBOR, jsonSize, cborSize := loadBoth(t, txParams, types.LoadTxParams)
+
+	if !reflect.DeepEqual(fromJSON, fromCBOR) {
+		j, _ := json.Marshal(fromJSON)
+		c, _ := json.Marshal(fromCBOR)
+		t.Fatalf("CBOR and JSON files load differently:\njson: %s\ncbor: %s", j, c)
+	}
+	if fromCBOR.Transaction.Value.ToBigInt().Cmp(txParams.Transaction.Value.ToBigInt()) != 0 {
+		t.Errorf("Value = %s, want %s", fromCBOR.Transaction.Value, txParams.Transaction.Value)
+	}
+	if !bytes.Equal(fromCBOR.Transaction.Data, txParams.Transaction.Data) {
+		t.Errorf("Data = %x, want %x", fromCBOR.Transaction.Data, txParams.Transaction.Data)
+	}
+	if cborSize >= jsonSize {
+		t.Errorf("CBOR is %d bytes, JSON %d; want CBOR smaller", cborSize, jsonSize)
+	}
+}
+
+func TestCBORSignedTxMatchesJSON(t *testing.T) {
+	txParams := cborTestTxParams()
+	txParams.Transaction.Value = types.NewBigInt(big.NewInt(1000))
+	txParams.Transaction.AuthorizationList = nil
+	signedTx, err := crypto.SignTransaction(txParams, testKey)
+	if err != nil {
+		t.Fatalf("SignTransaction() error = %v", err)
+	}
+
+	fromJSON, fromCBOR, jsonSize, cborSize := loadBoth(t, signedTx, types.LoadSignedTx)
+	if !reflect.DeepEqual(fromJSON, fromCBOR) {
+		t.Fatal("CBOR and JSON signed transaction files load differently")
+	}
+	if !bytes.Equal(fromCBOR.SignedTransaction, signedTx.SignedTransaction) {
+		t.Fatal("signed transaction bytes changed in the CBOR round trip")
+	}
+	if err := crypto.VerifySignedTransaction(fromCBOR.SignedTransaction, &txParams.Transaction); err != nil {
+		t.Errorf("VerifySignedTransaction() after CBOR round trip error = %v", err)
+	}
+	if cborSize >= jsonSize {
+		t.Errorf("CBOR is %d bytes, JSON %d; want CBOR smaller", cborSize, jsonSize)
+	}
+}
+
+func TestCBORRejectsNewerSchema(t *testing.T) {
+	txParams := cborTestTxParams()
+	txParams.SchemaVersion = "2.0"
+	data, err := types.EncodeCBOR(txParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "future.cbor")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := types.LoadTxParams(path); err == nil {
+		t.Error("LoadTxParams() accepted a CBOR file with a newer major schema version")
+	}
+}
+
+func TestBigIntCBORRoundTrip(t *testing.T) {
+	for _, s := range []string{"0", "1", "18446744073709551615", "18446744073709551616", "-5"} {
+		v, _ := new(big.Int).SetString(s, 10)
+		data, err := types.NewBigInt(v).MarshalCBOR()
+		if err != nil {
+			t.Fatalf("MarshalCBOR(%s) error = %v", s, err)
+		}
+		var got types.BigInt
+		if err := got.UnmarshalCBOR(data); err != nil {
+			t.Fatalf("UnmarshalCBOR(%s) error = %v", s, err)
+		}
+		if got.ToBigInt().Cmp(v) != 0 {
+			t.Errorf("round trip of %s = %s", s, got.ToBigInt())
+		}
+	}
+}
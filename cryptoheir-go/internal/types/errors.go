@@ -0,0 +1,100 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode identifies a class of failure for programs that drive the CLI or
+// use its packages. Codes are stable; the messages they accompany are not.
+type ErrorCode string
+
+const (
+	CodeConfigMissing          ErrorCode = "CONFIG_MISSING"
+	CodeConfigInvalid          ErrorCode = "CONFIG_INVALID"
+	CodeChainMismatch          ErrorCode = "CHAIN_MISMATCH"
+	CodeFileExists             ErrorCode = "FILE_EXISTS"
+	CodeRevert                 ErrorCode = "REVERT"
+	CodeInsufficientFunds      ErrorCode = "INSUFFICIENT_FUNDS"
+	CodeNonceTooLow            ErrorCode = "NONCE_TOO_LOW"
+	CodeNonceTooHigh           ErrorCode = "NONCE_TOO_HIGH"
+	CodeReplacementUnderpriced ErrorCode = "REPLACEMENT_UNDERPRICED"
+	CodeNetwork                ErrorCode = "NETWORK"
+	CodeTxFailed               ErrorCode = "TX_FAILED"
+)
+
+// Sentinel errors, one per code, for use with errors.Is. Any error carrying
+// the code matches, whatever its message.
+var (
+	ErrConfigMissing          = &CodedError{Code: CodeConfigMissing}
+	ErrConfigInvalid          = &CodedError{Code: CodeConfigInvalid}
+	ErrChainMismatch          = &CodedError{Code: CodeChainMismatch}
+	ErrFileExists             = &CodedError{Code: CodeFileExists}
+	ErrRevert                 = &CodedError{Code: CodeRevert}
+	ErrInsufficientFunds      = &CodedError{Code: CodeInsufficientFunds}
+	ErrNonceTooLow            = &CodedError{Code: CodeNonceTooLow}
+	ErrNonceTooHigh           = &CodedError{Code: CodeNonceTooHigh}
+	ErrReplacementUnderpriced = &CodedError{Code: CodeReplacementUnderpriced}
+	ErrNetwork                = &CodedError{Code: CodeNetwork}
+	ErrTxFailed               = &CodedError{Code: CodeTxFailed}
+)
+
+// CodedError attaches an ErrorCode to an error without changing its message
+type CodedError struct {
+	Code ErrorCode
+	Err  error
+}
+
+func (e *CodedError) Error() string {
+	if e.Err == nil {
+		return string(e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches the sentinel error of the same code
+func (e *CodedError) Is(target error) bool {
+	t, ok := target.(*CodedError)
+	return ok && t.Err == nil && t.Code == e.Code
+}
+
+// Errorf formats an error like fmt.Errorf (including %w wrapping) and
+// attaches code to it
+func Errorf(code ErrorCode, format string, args ...interface{}) error {
+	return &CodedError{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// WithCode attaches code to err, returning nil for a nil err
+func WithCode(code ErrorCode, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &CodedError{Code: code, Err: err}
+}
+
+// coder is implemented by errors that carry a code. Besides CodedError,
+// errors that carry one implicitly, such as classified RPC failures, report
+// it this way.
+type coder interface {
+	ErrorCode() ErrorCode
+}
+
+// CodeOf returns the code of the first coded error in err's tree, or "" if
+// there is none. The tree is searched like errors.As, so codes behind
+// errors joined with several %w verbs are found too.
+func CodeOf(err error) ErrorCode {
+	var c coder
+	if errors.As(err, &c) {
+		return c.ErrorCode()
+	}
+	return ""
+}
+
+// ErrorCode returns the attached code
+func (e *CodedError) ErrorCode() ErrorCode {
+	return e.Code
+}
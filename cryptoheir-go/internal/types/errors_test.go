@@ -0,0 +1,57 @@
+package types_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+)
+
+// implicitError carries a code through an ErrorCode method, like the
+// classified RPC failures of the network package
+type implicitError struct{}
+
+func (implicitError) Error() string              { return "nonce too low" }
+func (implicitError) ErrorCode() types.ErrorCode { return types.CodeNonceTooLow }
+
+func TestCodeOf(t *testing.T) {
+	plain := errors.New("boom")
+	coded := types.Errorf(types.CodeConfigInvalid, "bad config")
+
+	tests := []struct {
+		name string
+		err  error
+		want types.ErrorCode
+	}{
+		{"nil", nil, ""},
+		{"uncoded", plain, ""},
+		{"coded", coded, types.CodeConfigInvalid},
+		{"wrapped", fmt.Errorf("failed to load: %w", coded), types.CodeConfigInvalid},
+		{"outermost wins", types.WithCode(types.CodeNetwork, coded), types.CodeNetwork},
+		{"implicit", fmt.Errorf("failed to send: %w", implicitError{}), types.CodeNonceTooLow},
+		{"second of several %w", fmt.Errorf("%w: %w", plain, coded), types.CodeConfigInvalid},
+		{"joined", errors.Join(plain, fmt.Errorf("decrypt: %w", coded)), types.CodeConfigInvalid},
+		{"several %w without code", fmt.Errorf("%w: %w", plain, errors.New("other")), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := types.CodeOf(tt.err); got != tt.want {
+				t.Errorf("CodeOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodedErrorIs(t *testing.T) {
+	err := fmt.Errorf("%w: %w", errors.New("boom"), types.Errorf(types.CodeFileExists, "exists"))
+	if !errors.Is(err, types.ErrFileExists) {
+		t.Error("errors.Is(err, ErrFileExists) = false, want true")
+	}
+	if errors.Is(err, types.ErrNetwork) {
+		t.Error("errors.Is(err, ErrNetwork) = true, want false")
+	}
+	if got := types.ErrFileExists.Error(); got != string(types.CodeFileExists) {
+		t.Errorf("sentinel Error() = %q, want %q", got, types.CodeFileExists)
+	}
+}
@@ -0,0 +1,185 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SchemaVersion is the MAJOR.MINOR version of the tx-params and signed-tx
+// file formats written by this tool. The minor version is bumped for
+// backward-compatible additions, the major version for changes that older
+// tools cannot safely ignore.
+const SchemaVersion = "1.0"
+
+// ErrUnsupportedSchema is returned for files written by a newer tool
+var ErrUnsupportedSchema = errors.New("unsupported schema version")
+
+// CheckSchemaVersion verifies that a file written with the given schema
+// version can be processed. Files without a version predate versioning and
+// are treated as version 0.
+func CheckSchemaVersion(version string) error {
+	if version == "" {
+		return nil
+	}
+
+	major, err := schemaMajor(version)
+	if err != nil {
+		return err
+	}
+	current, _ := schemaMajor(SchemaVersion)
+	if major > current {
+		return fmt.Errorf("%w: file uses %s, but this tool supports up to %s; upgrade cryptoheir to process it", ErrUnsupportedSchema, version, SchemaVersion)
+	}
+
+	return nil
+}
+
+// schemaMajor returns the major component of a MAJOR.MINOR version
+func schemaMajor(version string) (int, error) {
+	majorStr, _, _ := strings.Cut(version, ".")
+	major, err := strconv.Atoi(majorStr)
+	if err != nil || major < 0 {
+		return 0, fmt.Errorf("invalid schema version: %q", version)
+	}
+	return major, nil
+}
+
+// schemaOlder reports whether a file's schema version predates the current
+// one. Files without a version are older; newer minor versions, written by a
+// newer tool, are not, and neither are versions that do not parse.
+func schemaOlder(version string) bool {
+	if version == "" {
+		return true
+	}
+	majorStr, minorStr, _ := strings.Cut(version, ".")
+	currentMajorStr, currentMinorStr, _ := strings.Cut(SchemaVersion, ".")
+	major, err1 := strconv.Atoi(majorStr)
+	minor, err2 := strconv.Atoi(minorStr)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	currentMajor, _ := strconv.Atoi(currentMajorStr)
+	currentMinor, _ := strconv.Atoi(currentMinorStr)
+	return major < currentMajor || (major == currentMajor && minor < currentMinor)
+}
+
+// readVersioned reads a JSON file, checks its schema version and decodes it
+// into v
+func readVersioned(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read input file: %w", err)
+	}
+	return decodeVersioned(data, path, v)
+}
+
+// decodeVersioned checks the schema version of JSON or CBOR data read from
+// the named file and decodes it into v
+func decodeVersioned(data []byte, path string, v interface{}) error {
+	if IsCBOR(data) {
+		var err error
+		if data, err = cborToJSON(data, path, v); err != nil {
+			return err
+		}
+	}
+
+	// Check the version before decoding the rest, which may not match
+	// this tool's structures
+	var header struct {
+		SchemaVersion string `json:"schema_version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := CheckSchemaVersion(header.SchemaVersion); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// LoadTxParams reads a transaction parameters file, rejecting files written
+// with a newer major schema version
+func LoadTxParams(path string) (*TxParams, error) {
+	var txParams TxParams
+	if err := readVersioned(path, &txParams); err != nil {
+		return nil, err
+	}
+	return &txParams, nil
+}
+
+// LoadSignedTx reads a signed transaction file, rejecting files written with
+// a newer major schema version
+func LoadSignedTx(path string) (*SignedTx, error) {
+	var signedTx SignedTx
+	if err := readVersioned(path, &signedTx); err != nil {
+		return nil, err
+	}
+	return &signedTx, nil
+}
+
+// ParseSignedTx decodes a signed transaction file that has already been read,
+// e.g. after decryption, rejecting files written with a newer major schema
+// version
+func ParseSignedTx(data []byte, path string) (*SignedTx, error) {
+	var signedTx SignedTx
+	if err := decodeVersioned(data, path, &signedTx); err != nil {
+		return nil, err
+	}
+	return &signedTx, nil
+}
+
+// MigrateTxParams upgrades transaction parameters read from an older schema
+// version to the current one in place, returning a description of each
+// change. Files without a schema version may also lack the transaction type,
+// which is inferred from the fee fields present. Files of the current or a
+// newer minor version are left as they are, so a newer tool's version is not
+// rewritten to one that claims fewer fields.
+func MigrateTxParams(txParams *TxParams) []string {
+	if !schemaOlder(txParams.SchemaVersion) {
+		return nil
+	}
+
+	var changes []string
+	tx := &txParams.Transaction
+	if tx.TxType == 0 && tx.GasPrice == nil && tx.MaxFeePerGas != nil {
+		tx.TxType = 2
+		if len(tx.AuthorizationList) > 0 {
+			tx.TxType = 4
+		}
+		changes = append(changes, fmt.Sprintf("inferred tx_type %d from fee fields", tx.TxType))
+	}
+
+	changes = append(changes, schemaChange(txParams.SchemaVersion))
+	txParams.SchemaVersion = SchemaVersion
+	return changes
+}
+
+// MigrateSignedTx upgrades a signed transaction read from an older schema
+// version to the current one in place, returning a description of each
+// change. Like MigrateTxParams, it leaves current and newer versions alone.
+func MigrateSignedTx(signedTx *SignedTx) []string {
+	if !schemaOlder(signedTx.SchemaVersion) {
+		return nil
+	}
+
+	changes := []string{schemaChange(signedTx.SchemaVersion)}
+	signedTx.SchemaVersion = SchemaVersion
+	return changes
+}
+
+// schemaChange describes a schema version upgrade
+func schemaChange(from string) string {
+	if from == "" {
+		from = "0 (unversioned)"
+	}
+	return fmt.Sprintf("schema_version %s -> %s", from, SchemaVersion)
+}
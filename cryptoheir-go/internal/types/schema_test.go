@@ -0,0 +1,130 @@
+package types_test
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/crypto"
+	"github.com/cryptoheirdotio/cryptoheir/cryptoheir-go/internal/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// testKey is the well-known key 0x4646...46 of the EIP-155 examples
+const testKey = "4646464646464646464646464646464646464646464646464646464646464646"
+
+var testSigner = common.HexToAddress("0x9d8A62f656a8d1615C1294fd71e9CFb3E4855A4F")
+
+// v0TxParams predates schema versioning: no schema_version, and an EIP-1559
+// transaction without tx_type
+const v0TxParams = `{
+  "mode": "call",
+  "function_name": "deposit",
+  "transaction": {
+    "from": "0x9d8A62f656a8d1615C1294fd71e9CFb3E4855A4F",
+    "to": "0x1111111111111111111111111111111111111111",
+    "data": "",
+    "nonce": 3,
+    "chain_id": 11155111,
+    "gas_limit": "21000",
+    "max_fee_per_gas": "30000000000",
+    "max_priority_fee_per_gas": "1000000000",
+    "value": "1000"
+  },
+  "metadata": {}
+}`
+
+func TestMigrateV0TxParamsAndSign(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tx-params.json")
+	if err := os.WriteFile(path, []byte(v0TxParams), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	txParams, err := types.LoadTxParams(path)
+	if err != nil {
+		t.Fatalf("LoadTxParams() error = %v", err)
+	}
+
+	changes := types.MigrateTxParams(txParams)
+	if len(changes) != 2 {
+		t.Fatalf("MigrateTxParams() changes = %q, want the inferred tx_type and the schema upgrade", changes)
+	}
+	if txParams.SchemaVersion != types.SchemaVersion {
+		t.Errorf("SchemaVersion = %q, want %q", txParams.SchemaVersion, types.SchemaVersion)
+	}
+	if txParams.Transaction.TxType != 2 {
+		t.Errorf("TxType = %d, want 2 (inferred from the EIP-1559 fee fields)", txParams.Transaction.TxType)
+	}
+	if again := types.MigrateTxParams(txParams); again != nil {
+		t.Errorf("second MigrateTxParams() = %q, want no changes", again)
+	}
+
+	signedTx, err := crypto.SignTransaction(txParams, testKey)
+	if err != nil {
+		t.Fatalf("SignTransaction() error = %v", err)
+	}
+	if err := crypto.VerifySignedTransaction(signedTx.SignedTransaction, &txParams.Transaction); err != nil {
+		t.Fatalf("VerifySignedTransaction() error = %v", err)
+	}
+	signer, tx, err := crypto.RecoverSigner(signedTx.SignedTransaction)
+	if err != nil {
+		t.Fatalf("RecoverSigner() error = %v", err)
+	}
+	if signer != testSigner || tx.Type() != 2 || tx.Nonce() != 3 {
+		t.Errorf("signed transaction: signer %s, type %d, nonce %d", signer.Hex(), tx.Type(), tx.Nonce())
+	}
+}
+
+func TestMigrateLegacyKeepsType(t *testing.T) {
+	txParams := &types.TxParams{Transaction: types.TransactionData{GasPrice: types.NewBigInt(big.NewInt(1))}}
+	types.MigrateTxParams(txParams)
+	if txParams.Transaction.TxType != 0 {
+		t.Errorf("TxType = %d, want 0 for a file with a gas price", txParams.Transaction.TxType)
+	}
+}
+
+func TestCheckSchemaVersion(t *testing.T) {
+	for version, wantErr := range map[string]bool{"": false, "0.9": false, "1.0": false, "1.7": false, "2.0": true, "x": true} {
+		if err := types.CheckSchemaVersion(version); (err != nil) != wantErr {
+			t.Errorf("CheckSchemaVersion(%q) error = %v, wantErr %v", version, err, wantErr)
+		}
+	}
+}
+
+func TestMigrateSignedTx(t *testing.T) {
+	signedTx := &types.SignedTx{}
+	if changes := types.MigrateSignedTx(signedTx); len(changes) != 1 || signedTx.SchemaVersion != types.SchemaVersion {
+		t.Errorf("MigrateSignedTx() = %q, schema %q", changes, signedTx.SchemaVersion)
+	}
+}
+
+func TestMigrateOnlyOlderVersions(t *testing.T) {
+	tests := []struct {
+		version     string
+		wantMigrate bool
+	}{
+		{"", true},
+		{"0.9", true},
+		{types.SchemaVersion, false},
+		{"1.7", false}, // newer minor from a newer tool
+		{"1.x", false},
+	}
+	for _, tt := range tests {
+		txParams := &types.TxParams{SchemaVersion: tt.version}
+		changes := types.MigrateTxParams(txParams)
+		if migrated := len(changes) > 0; migrated != tt.wantMigrate {
+			t.Errorf("MigrateTxParams(%q) changes = %q, want migrated %v", tt.version, changes, tt.wantMigrate)
+		}
+		if !tt.wantMigrate && txParams.SchemaVersion != tt.version {
+			t.Errorf("MigrateTxParams(%q) rewrote the version to %q", tt.version, txParams.SchemaVersion)
+		}
+
+		signedTx := &types.SignedTx{SchemaVersion: tt.version}
+		if migrated := len(types.MigrateSignedTx(signedTx)) > 0; migrated != tt.wantMigrate {
+			t.Errorf("MigrateSignedTx(%q) migrated = %v, want %v", tt.version, migrated, tt.wantMigrate)
+		}
+		if !tt.wantMigrate && signedTx.SchemaVersion != tt.version {
+			t.Errorf("MigrateSignedTx(%q) rewrote the version to %q", tt.version, signedTx.SchemaVersion)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"github.com/ethereum/go-ethereum/common"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/joho/godotenv"
 )
 
@@ -17,9 +18,24 @@ type Config struct {
 	InfuraAPIKey    string
 	RPCURL          string
 	ContractAddress *common.Address
+
+	// HD wallet for sign --scan-paths
+	Mnemonic           string
+	MnemonicPassphrase string
+
+	// Deposit guardrails: amounts above these need explicit confirmation
+	MaxAmount      string // ETH
+	MaxTokenAmount string // Comma-separated <token address>=<token units>
+
+	// signerAddressErr holds an invalid SIGNER_ADDRESS, reported only by
+	// commands that need the signer
+	signerAddressErr error
 }
 
-// LoadConfig loads configuration from .env file and environment variables
+// LoadConfig loads configuration from .env file and environment variables.
+// Nothing is required here; each command checks the values it uses with
+// RequireSigning and RequireSignerAddress, and network.RequireRPC for the
+// Infura key, so read-only commands work without any key material.
 func LoadConfig() (*Config, error) {
 	// Try to load .env file (optional)
 	_ = godotenv.Load()
@@ -28,13 +44,21 @@ func LoadConfig() (*Config, error) {
 
 	// Load signer address
 	if addr := os.Getenv("SIGNER_ADDRESS"); addr != "" {
-		address := common.HexToAddress(addr)
-		config.SignerAddress = &address
+		address, err := ParseAddress(addr)
+		if err != nil {
+			config.signerAddressErr = Errorf(CodeConfigInvalid, "invalid SIGNER_ADDRESS: %w", err)
+		} else {
+			config.SignerAddress = &address
+		}
 	}
 
 	// Load private key
 	config.PrivateKey = os.Getenv("PRIVATE_KEY")
 
+	// Load mnemonic
+	config.Mnemonic = os.Getenv("MNEMONIC")
+	config.MnemonicPassphrase = os.Getenv("MNEMONIC_PASSPHRASE")
+
 	// Load Infura API key
 	config.InfuraAPIKey = os.Getenv("INFURA_API_KEY")
 
@@ -43,13 +67,49 @@ func LoadConfig() (*Config, error) {
 
 	// Load contract address
 	if addr := os.Getenv("CONTRACT_ADDRESS"); addr != "" {
-		address := common.HexToAddress(addr)
+		address, err := ParseAddress(addr)
+		if err != nil {
+			return nil, Errorf(CodeConfigInvalid, "invalid CONTRACT_ADDRESS: %w", err)
+		}
 		config.ContractAddress = &address
 	}
 
+	// Load deposit amount limits
+	config.MaxAmount = os.Getenv("MAX_AMOUNT")
+	config.MaxTokenAmount = os.Getenv("MAX_TOKEN_AMOUNT")
+
 	return config, nil
 }
 
+// RequireSigning checks that the private key needed to sign is configured
+func (c *Config) RequireSigning() error {
+	if c.PrivateKey == "" {
+		return Errorf(CodeConfigMissing, "PRIVATE_KEY not set in environment")
+	}
+	return nil
+}
+
+// RequireMnemonic checks that the mnemonic needed to derive signing keys is
+// configured
+func (c *Config) RequireMnemonic() error {
+	if c.Mnemonic == "" {
+		return Errorf(CodeConfigMissing, "MNEMONIC not set in environment")
+	}
+	return nil
+}
+
+// RequireSignerAddress checks that the signer address needed to prepare
+// transactions is configured and valid
+func (c *Config) RequireSignerAddress() error {
+	if c.signerAddressErr != nil {
+		return c.signerAddressErr
+	}
+	if c.SignerAddress == nil {
+		return Errorf(CodeConfigMissing, "SIGNER_ADDRESS not set in environment")
+	}
+	return nil
+}
+
 // TransactionMode represents the type of transaction
 type TransactionMode string
 
@@ -65,50 +125,232 @@ type NetworkInfo struct {
 	RPCURL  string `json:"rpc_url,omitempty"`
 }
 
+// BundleInfo links transactions that were prepared together and must be
+// broadcast in sequence (e.g. an ERC20 approve followed by a deposit)
+type BundleInfo struct {
+	ID       string `json:"id"`
+	Sequence int    `json:"sequence"` // 1-based position within the bundle
+	Size     int    `json:"size"`
+	Step     string `json:"step"` // Operation performed by this transaction
+}
+
 // Metadata contains additional transaction information
 type Metadata struct {
-	PreparedAt    string                 `json:"prepared_at"`
-	SignedAt      string                 `json:"signed_at,omitempty"`
-	BroadcastAt   string                 `json:"broadcast_at,omitempty"`
-	Network       NetworkInfo            `json:"network"`
-	ToolVersion   string                 `json:"tool_version"`
+	PreparedAt     string                 `json:"prepared_at"`
+	SignedAt       string                 `json:"signed_at,omitempty"`
+	BroadcastAt    string                 `json:"broadcast_at,omitempty"`
+	BroadcastAfter string                 `json:"broadcast_after,omitempty"` // RFC3339; broadcast refuses to submit earlier
+	Network        NetworkInfo            `json:"network"`
+	ToolVersion    string                 `json:"tool_version"`
+	SignedWith     *SignerSoftware        `json:"signed_with,omitempty"`
+	ReviewEdits    []ReviewEdit           `json:"review_edits,omitempty"`
+	Bundle         *BundleInfo            `json:"bundle,omitempty"`
+	TxTypeName     string                 `json:"tx_type_name,omitempty"` // Name of the transaction's tx_type, for reviewers
+	Label          string                 `json:"label,omitempty"`        // prepare --label, shared by the operation's files
 	AdditionalInfo map[string]interface{} `json:"additional_info,omitempty"`
 }
 
+// ReviewEdit records a fee or gas field changed during the offline review,
+// so the signed transaction shows it differs from what was prepared
+type ReviewEdit struct {
+	Field    string `json:"field"`
+	Prepared string `json:"prepared"`
+	Signed   string `json:"signed"`
+}
+
+// SignerSoftware records the exact software that signed a transaction, so an
+// auditor can reproduce the signing environment. ToolVersion in Metadata is
+// the version that prepared it, which may differ.
+type SignerSoftware struct {
+	ToolVersion       string `json:"tool_version"`
+	GitCommit         string `json:"git_commit"`
+	GoEthereumVersion string `json:"go_ethereum_version"`
+	GoVersion         string `json:"go_version"`
+}
+
+// Authorization is an EIP-7702 delegation authorization. It is prepared
+// unsigned (R and S empty) and signed by the offline signer alongside the
+// transaction itself.
+type Authorization struct {
+	ChainID uint64         `json:"chain_id"`
+	Address common.Address `json:"address"` // Delegation target contract
+	Nonce   uint64         `json:"nonce"`
+	YParity uint8          `json:"y_parity,omitempty"`
+	R       *BigInt        `json:"r,omitempty"`
+	S       *BigInt        `json:"s,omitempty"`
+}
+
+// IsSigned reports whether the authorization already carries a signature
+func (a *Authorization) IsSigned() bool {
+	return a.R != nil && a.S != nil && a.R.ToBigInt().Sign() > 0 && a.S.ToBigInt().Sign() > 0
+}
+
+// txTypeNames names the EIP-2718 transaction types (the envelope's leading
+// type byte). Metadata and the review screen both use it, so a newly
+// supported type only needs an entry here.
+var txTypeNames = map[uint8]string{
+	0: "legacy",
+	1: "access-list",
+	2: "eip1559",
+	3: "blob",
+	4: "eip7702",
+}
+
+// TxTypeName returns the name of an EIP-2718 transaction type, e.g. "eip1559"
+// for type 2, or "type-N" for a type without one
+func TxTypeName(txType uint8) string {
+	if name, ok := txTypeNames[txType]; ok {
+		return name
+	}
+	return fmt.Sprintf("type-%d", txType)
+}
+
 // TransactionData contains the raw transaction parameters
 type TransactionData struct {
-	TxType                 uint8           `json:"tx_type"` // 0=Legacy, 2=EIP-1559
-	From                   common.Address  `json:"from"`
-	To                     *common.Address `json:"to"` // nil for contract deployment
-	Data                   []byte          `json:"data"`
-	Nonce                  uint64          `json:"nonce"`
-	ChainID                uint64          `json:"chain_id"`
-	GasLimit               *BigInt         `json:"gas_limit"`
-	MaxFeePerGas           *BigInt         `json:"max_fee_per_gas,omitempty"`           // EIP-1559
-	MaxPriorityFeePerGas   *BigInt         `json:"max_priority_fee_per_gas,omitempty"`   // EIP-1559
-	GasPrice               *BigInt         `json:"gas_price,omitempty"`                  // Legacy
-	Value                  *BigInt         `json:"value,omitempty"`
+	TxType               uint8           `json:"tx_type"` // 0=Legacy, 2=EIP-1559, 4=EIP-7702
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to"` // nil for contract deployment
+	Data                 []byte          `json:"data"`
+	Nonce                uint64          `json:"nonce"`
+	ChainID              uint64          `json:"chain_id"`
+	GasLimit             *BigInt         `json:"gas_limit"`
+	MaxFeePerGas         *BigInt         `json:"max_fee_per_gas,omitempty"`          // EIP-1559
+	MaxPriorityFeePerGas *BigInt         `json:"max_priority_fee_per_gas,omitempty"` // EIP-1559
+	GasPrice             *BigInt         `json:"gas_price,omitempty"`                // Legacy
+	Value                *BigInt         `json:"value,omitempty"`
+	AuthorizationList    []Authorization `json:"authorization_list,omitempty"` // EIP-7702
+}
+
+// Clone returns a deep copy of the transaction, sharing no big.Int or slice
+// with the original
+func (t *TransactionData) Clone() (*TransactionData, error) {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy transaction: %w", err)
+	}
+	var clone TransactionData
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil, fmt.Errorf("failed to copy transaction: %w", err)
+	}
+	return &clone, nil
+}
+
+// ApplyReviewEdits applies the fee and gas edits recorded during review to
+// the transaction. Each edit must start from the transaction's value, and
+// only the editable fields may be changed.
+func (t *TransactionData) ApplyReviewEdits(edits []ReviewEdit) error {
+	for _, edit := range edits {
+		var field **BigInt
+		switch edit.Field {
+		case "gas_limit":
+			field = &t.GasLimit
+		case "gas_price":
+			field = &t.GasPrice
+		case "max_fee_per_gas":
+			field = &t.MaxFeePerGas
+		case "max_priority_fee_per_gas":
+			field = &t.MaxPriorityFeePerGas
+		default:
+			return fmt.Errorf("review edit of %s: the field is not editable", edit.Field)
+		}
+		if (*field).ToBigInt().String() != edit.Prepared {
+			return fmt.Errorf("review edit of %s: prepared value %s does not match the file's %s", edit.Field, edit.Prepared, (*field).ToBigInt())
+		}
+		signed, ok := new(big.Int).SetString(edit.Signed, 10)
+		if !ok {
+			return fmt.Errorf("review edit of %s: invalid value %q", edit.Field, edit.Signed)
+		}
+		*field = NewBigInt(signed)
+	}
+	return nil
+}
+
+// DecodedArg is one decoded function argument
+type DecodedArg struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// DecodedCall is a human-readable decoding of a transaction's calldata,
+// embedded by 'prepare --bundle' and 'prepare --self-contained' for signers
+// that cannot decode it locally
+type DecodedCall struct {
+	Function string       `json:"function"` // Canonical signature, or "constructor" for deployments
+	Args     []DecodedArg `json:"args,omitempty"`
+	Summary  string       `json:"summary"`
+}
+
+// Equal reports whether two decodings describe the same call
+func (d *DecodedCall) Equal(other *DecodedCall) bool {
+	if d == nil || other == nil {
+		return d == other
+	}
+	if d.Function != other.Function || len(d.Args) != len(other.Args) {
+		return false
+	}
+	for i := range d.Args {
+		if d.Args[i] != other.Args[i] {
+			return false
+		}
+	}
+	return true
 }
 
 // TxParams represents an unsigned transaction prepared for signing
 type TxParams struct {
-	Mode         TransactionMode `json:"mode"`
-	FunctionName string          `json:"function_name,omitempty"`
-	Params       json.RawMessage `json:"params,omitempty"`
-	Transaction  TransactionData `json:"transaction"`
-	Metadata     Metadata        `json:"metadata"`
+	SchemaVersion string          `json:"schema_version,omitempty"`
+	Mode          TransactionMode `json:"mode"`
+	FunctionName  string          `json:"function_name,omitempty"`
+	Params        json.RawMessage `json:"params,omitempty"`
+	Transaction   TransactionData `json:"transaction"`
+	Decoded       *DecodedCall    `json:"decoded,omitempty"` // --bundle and --self-contained only
+	SelfContained *SelfContained  `json:"self_contained,omitempty"`
+	Metadata      Metadata        `json:"metadata"`
+}
+
+// SelfContained is what 'prepare --self-contained' adds so the offline signer
+// can review a transaction from the file alone, without the contract artifact
+type SelfContained struct {
+	ABI                      json.RawMessage `json:"abi,omitempty"`                        // ABI entry of the called function
+	PredictedContractAddress *common.Address `json:"predicted_contract_address,omitempty"` // Deployments
 }
 
 // SignedTx represents a signed transaction ready for broadcasting
 type SignedTx struct {
+	SchemaVersion            string          `json:"schema_version,omitempty"`
 	SignedTransaction        []byte          `json:"signed_transaction"`
 	TxHash                   common.Hash     `json:"tx_hash"`
 	Mode                     TransactionMode `json:"mode"`
+	FunctionName             string          `json:"function_name,omitempty"`
 	From                     common.Address  `json:"from"`
 	PredictedContractAddress *common.Address `json:"predicted_contract_address,omitempty"`
 	Metadata                 Metadata        `json:"metadata"`
 }
 
+// Nonce decodes the nonce from the signed transaction bytes, returning 0 if
+// the transaction cannot be decoded
+func (s *SignedTx) Nonce() uint64 {
+	tx := new(coretypes.Transaction)
+	if err := tx.UnmarshalBinary(s.SignedTransaction); err != nil {
+		return 0
+	}
+	return tx.Nonce()
+}
+
+// EncryptionECIES identifies signed transaction files encrypted with ECIES
+// over secp256k1 (AES-128-CTR with HMAC-SHA-256)
+const EncryptionECIES = "ecies-secp256k1"
+
+// EncryptedSignedTx wraps a signed transaction file encrypted to a
+// recipient's public key for transfer over an untrusted medium
+type EncryptedSignedTx struct {
+	SchemaVersion string         `json:"schema_version,omitempty"`
+	Encryption    string         `json:"encryption"`
+	Recipient     common.Address `json:"recipient"`
+	Ciphertext    []byte         `json:"ciphertext"`
+}
+
 // TxReceipt represents a transaction receipt after broadcasting
 type TxReceipt struct {
 	TransactionHash common.Hash            `json:"transaction_hash"`
@@ -120,6 +362,33 @@ type TxReceipt struct {
 	Status          uint64                 `json:"status"`
 	ContractAddress *common.Address        `json:"contract_address,omitempty"`
 	Metadata        map[string]interface{} `json:"metadata"`
+
+	// Logs are the raw receipt logs; they are saved decoded as
+	// Metadata["events"]
+	Logs []*coretypes.Log `json:"-"`
+}
+
+// DecodedEvent is one receipt log in human-readable form. Logs that match no
+// known event keep their raw topics and data.
+type DecodedEvent struct {
+	LogIndex  uint           `json:"log_index"`
+	Address   common.Address `json:"address"`
+	Name      string         `json:"name,omitempty"`
+	Signature string         `json:"signature,omitempty"`
+	Args      []DecodedArg   `json:"args,omitempty"`
+	Topics    []common.Hash  `json:"topics,omitempty"`
+	Data      string         `json:"data,omitempty"`
+	Summary   string         `json:"summary"`
+}
+
+// TxSubmission records a transaction that was broadcast without waiting for
+// its receipt (broadcast --no-wait)
+type TxSubmission struct {
+	TransactionHash common.Hash            `json:"transaction_hash"`
+	Status          string                 `json:"status"` // Always "submitted"
+	From            common.Address         `json:"from"`
+	Nonce           uint64                 `json:"nonce"`
+	Metadata        map[string]interface{} `json:"metadata"`
 }
 
 // BigInt is a wrapper around big.Int for custom JSON marshaling
@@ -167,3 +436,32 @@ func (b *BigInt) ToBigInt() *big.Int {
 	}
 	return b.Int
 }
+
+// ToUint64 converts BigInt to uint64, returning an error instead of silently
+// truncating when the value is negative or does not fit in 64 bits
+func (b *BigInt) ToUint64() (uint64, error) {
+	i := b.ToBigInt()
+	if i.Sign() < 0 {
+		return 0, fmt.Errorf("value %s is negative", i.String())
+	}
+	if !i.IsUint64() {
+		return 0, fmt.Errorf("value %s overflows uint64", i.String())
+	}
+	return i.Uint64(), nil
+}
+
+// MaxChainID is the largest chain ID usable with EIP-155 signatures
+// (EIP-2294: floor(MAX_UINT64 / 2) - 36), above which v overflows
+const MaxChainID = uint64(1<<63-1) - 36
+
+// ChainIDToBig converts a chain ID to *big.Int, rejecting zero and values
+// that would overflow the EIP-155 signature v value
+func ChainIDToBig(chainID uint64) (*big.Int, error) {
+	if chainID == 0 {
+		return nil, fmt.Errorf("chain ID must not be zero")
+	}
+	if chainID > MaxChainID {
+		return nil, fmt.Errorf("chain ID %d exceeds maximum %d", chainID, MaxChainID)
+	}
+	return new(big.Int).SetUint64(chainID), nil
+}
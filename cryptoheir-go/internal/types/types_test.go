@@ -0,0 +1,99 @@
+package types
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestBigIntToUint64(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   *BigInt
+		want    uint64
+		wantErr bool
+	}{
+		{"nil", nil, 0, false},
+		{"zero", NewBigInt(big.NewInt(0)), 0, false},
+		{"gas limit", NewBigInt(big.NewInt(21000)), 21000, false},
+		{"max uint64", NewBigInt(new(big.Int).SetUint64(math.MaxUint64)), math.MaxUint64, false},
+		{"overflow", NewBigInt(new(big.Int).Add(new(big.Int).SetUint64(math.MaxUint64), big.NewInt(1))), 0, true},
+		{"negative", NewBigInt(big.NewInt(-1)), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.value.ToUint64()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ToUint64() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ToUint64() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChainIDToBig(t *testing.T) {
+	tests := []struct {
+		name    string
+		chainID uint64
+		wantErr bool
+	}{
+		{"zero", 0, true},
+		{"mainnet", 1, false},
+		{"sepolia", 11155111, false},
+		{"max", MaxChainID, false},
+		{"above max", MaxChainID + 1, true},
+		{"max uint64", math.MaxUint64, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ChainIDToBig(tt.chainID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ChainIDToBig(%d) error = %v, wantErr %v", tt.chainID, err, tt.wantErr)
+			}
+			if err == nil && (!got.IsUint64() || got.Uint64() != tt.chainID) {
+				t.Errorf("ChainIDToBig(%d) = %s", tt.chainID, got)
+			}
+		})
+	}
+}
+
+func TestApplyReviewEdits(t *testing.T) {
+	newTx := func() *TransactionData {
+		return &TransactionData{TxType: 2, GasLimit: NewBigInt(big.NewInt(60000)), MaxFeePerGas: NewBigInt(big.NewInt(100))}
+	}
+	tests := []struct {
+		name    string
+		edit    ReviewEdit
+		wantErr bool
+	}{
+		{"fee", ReviewEdit{Field: "max_fee_per_gas", Prepared: "100", Signed: "150"}, false},
+		{"gas limit", ReviewEdit{Field: "gas_limit", Prepared: "60000", Signed: "70000"}, false},
+		{"not editable", ReviewEdit{Field: "value", Prepared: "0", Signed: "1"}, true},
+		{"wrong prepared value", ReviewEdit{Field: "max_fee_per_gas", Prepared: "99", Signed: "150"}, true},
+		{"invalid value", ReviewEdit{Field: "gas_limit", Prepared: "60000", Signed: "abc"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := newTx()
+			err := tx.ApplyReviewEdits([]ReviewEdit{tt.edit})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyReviewEdits() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTransactionDataCloneIsDeep(t *testing.T) {
+	tx := &TransactionData{MaxFeePerGas: NewBigInt(big.NewInt(100)), Data: []byte{1, 2}}
+	clone, err := tx.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.MaxFeePerGas.ToBigInt().SetInt64(999)
+	tx.Data[0] = 9
+	if clone.MaxFeePerGas.ToBigInt().Int64() != 100 || clone.Data[0] != 1 {
+		t.Errorf("Clone() shares state with the original: fee %s, data %x", clone.MaxFeePerGas.ToBigInt(), clone.Data)
+	}
+}
@@ -0,0 +1,52 @@
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Build information, overridden at build time via -ldflags "-X ..."
+var (
+	Version   = "v0.1.0"
+	GitCommit = "unknown"
+	BuildDate = "unknown"
+)
+
+// goEthereumModule is the module path used to look up the linked go-ethereum version
+const goEthereumModule = "github.com/ethereum/go-ethereum"
+
+// ToolVersion returns the tool identifier recorded in transaction metadata
+func ToolVersion() string {
+	return fmt.Sprintf("cryptoheir-go %s", Version)
+}
+
+// GoEthereumVersion returns the go-ethereum module version linked into the binary
+func GoEthereumVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+
+	for _, dep := range info.Deps {
+		if dep.Path == goEthereumModule {
+			if dep.Replace != nil {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+
+	return "unknown"
+}
+
+// GoVersion returns the Go runtime version the binary was built with
+func GoVersion() string {
+	return runtime.Version()
+}
+
+// Summary returns a one-line description of the tool, its commit and the
+// library and runtime versions it was built with
+func Summary() string {
+	return fmt.Sprintf("%s (commit %s), go-ethereum %s, %s", ToolVersion(), GitCommit, GoEthereumVersion(), GoVersion())
+}